@@ -64,6 +64,7 @@ import (
 	"github.com/ethereum/go-ethereum/p2p/nat"
 	"github.com/ethereum/go-ethereum/p2p/netutil"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
 	pcsclite "github.com/gballet/go-libpcsclite"
 	gopsutil "github.com/shirou/gopsutil/mem"
 	"github.com/urfave/cli/v2"
@@ -86,9 +87,29 @@ var (
 	}
 	RemoteDBFlag = &cli.StringFlag{
 		Name:     "remotedb",
-		Usage:    "URL for remote database",
+		Usage:    "URL for remote database, or socket path of a remote database server if --remotedb.secret is set",
 		Category: flags.LoggingCategory,
 	}
+	RemoteDBSecretFlag = &cli.StringFlag{
+		Name:     "remotedb.secret",
+		Usage:    "Path to the shared secret authenticating remote database clients (default = remotedb.secret inside the datadir)",
+		Category: flags.LoggingCategory,
+	}
+	RemoteDBListenFlag = &cli.StringFlag{
+		Name:     "remotedb.listen",
+		Usage:    "Socket path to expose the chain database on for authenticated remote database clients",
+		Category: flags.LoggingCategory,
+	}
+	DBEngineFlag = &cli.StringFlag{
+		Name:     "db.engine",
+		Usage:    "Backing database implementation to use ('leveldb' or 'pebble', default = engine of the existing database or leveldb)",
+		Category: flags.EthCategory,
+	}
+	DBSecondaryFlag = &cli.BoolFlag{
+		Name:     "db.secondary",
+		Usage:    "Open a read-only, point-in-time view of the database of a running node (read-only commands only)",
+		Category: flags.EthCategory,
+	}
 	AncientFlag = &flags.DirectoryFlag{
 		Name:     "datadir.ancient",
 		Usage:    "Root directory for ancient data (default = inside chaindata)",
@@ -234,6 +255,57 @@ var (
 		Value:    "full",
 		Category: flags.EthCategory,
 	}
+	StateSchemeFlag = &cli.StringFlag{
+		Name:     "state.scheme",
+		Usage:    `Scheme to persist state trie nodes with ("hash", "path", default = scheme of the existing database or hash)`,
+		Category: flags.EthCategory,
+	}
+	StateHistoryFlag = &cli.Uint64Flag{
+		Name:     "state.history",
+		Usage:    "Number of recent state transitions the path scheme can roll back for reorgs (0 = none)",
+		Value:    ethconfig.Defaults.StateHistory,
+		Category: flags.EthCategory,
+	}
+	StateDiffsFlag = &cli.Uint64Flag{
+		Name:     "state.diffs",
+		Usage:    "Number of recent blocks to retain state diffs for, serving their historical state without an archive node (0 = disabled)",
+		Category: flags.EthCategory,
+	}
+	HistoryKeepFlag = &cli.Uint64Flag{
+		Name:     "history.keep",
+		Usage:    "Number of recent blocks to retain bodies, receipts, value transfers and contract creations for, pruning older frozen ones (0 = entire chain)",
+		Category: flags.EthCategory,
+	}
+	ScrubFlag = &cli.BoolFlag{
+		Name:     "scrub",
+		Usage:    "Periodically check the ancient store, the canonical chain and the state for corruption in the background",
+		Category: flags.EthCategory,
+	}
+	CodeIndexFlag = &cli.BoolFlag{
+		Name:     "index.code",
+		Usage:    "Index the contracts created by the imported blocks by their code hash",
+		Category: flags.EthCategory,
+	}
+	LogIndexFlag = &cli.BoolFlag{
+		Name:     "index.logs",
+		Usage:    "Index the logs by their addresses and topics for fast log filtering",
+		Category: flags.EthCategory,
+	}
+	AccountIndexFlag = &cli.BoolFlag{
+		Name:     "index.accounts",
+		Usage:    "Index the transactions by their senders and recipients for account history queries",
+		Category: flags.EthCategory,
+	}
+	AccountIndexLimitFlag = &cli.Uint64Flag{
+		Name:     "index.accounts.limit",
+		Usage:    "Number of recent blocks to maintain the account history index for (0 = entire chain)",
+		Category: flags.EthCategory,
+	}
+	TransferIndexFlag = &cli.BoolFlag{
+		Name:     "index.transfers",
+		Usage:    "Record the value transfers of imported blocks, including internal calls and block rewards",
+		Category: flags.EthCategory,
+	}
 	SnapshotFlag = &cli.BoolFlag{
 		Name:     "snapshot",
 		Usage:    `Enables snapshot-database mode (default = enable)`,
@@ -246,6 +318,27 @@ var (
 		Value:    ethconfig.Defaults.TxLookupLimit,
 		Category: flags.EthCategory,
 	}
+	SafeDepthFlag = &cli.Uint64Flag{
+		Name:     "pow.safedepth",
+		Usage:    "Number of confirmations after which a proof-of-work block is tagged safe (0 = chain config default)",
+		Category: flags.EthCategory,
+	}
+	FinalizedDepthFlag = &cli.Uint64Flag{
+		Name:     "pow.finalizeddepth",
+		Usage:    "Number of confirmations after which a proof-of-work block is tagged finalized (0 = chain config default)",
+		Category: flags.EthCategory,
+	}
+	MaxReorgDepthFlag = &cli.Uint64Flag{
+		Name:     "pow.maxreorgdepth",
+		Usage:    "Maximum number of canonical blocks a proof-of-work reorg may drop (0 = chain config default)",
+		Category: flags.EthCategory,
+	}
+	ReorgCheckpointsFlag = &cli.PathFlag{
+		Name:      "pow.checkpoints",
+		Usage:     "JSON file of trusted block checkpoints the chain must not reorg away from",
+		TakesFile: true,
+		Category:  flags.EthCategory,
+	}
 	LightKDFFlag = &cli.BoolFlag{
 		Name:     "lightkdf",
 		Usage:    "Reduce key-derivation RAM & CPU usage at some expense of KDF strength",
@@ -610,6 +703,16 @@ var (
 		Value:    ethconfig.Defaults.RPCTxFeeCap,
 		Category: flags.APICategory,
 	}
+	RPCLogsMaxResultsFlag = &cli.IntFlag{
+		Name:     "rpc.logs.maxresults",
+		Usage:    "Maximum number of logs returned by eth_getLogs, or a page of eth_getLogsPage (0 = no limit)",
+		Category: flags.APICategory,
+	}
+	RPCLogsMaxRangeFlag = &cli.Uint64Flag{
+		Name:     "rpc.logs.maxrange",
+		Usage:    "Maximum number of blocks an eth_getLogs or eth_getLogsPage query may span (0 = no limit)",
+		Category: flags.APICategory,
+	}
 	// Authenticated RPC HTTP settings
 	AuthListenFlag = &cli.StringFlag{
 		Name:     "authrpc.addr",
@@ -710,6 +813,11 @@ var (
 		Value:    "",
 		Category: flags.APICategory,
 	}
+	HTTPAccessLogFlag = &cli.StringFlag{
+		Name:     "http.accesslog",
+		Usage:    "File to append a JSON access log line to for every HTTP-RPC call",
+		Category: flags.APICategory,
+	}
 	GraphQLEnabledFlag = &cli.BoolFlag{
 		Name:     "graphql",
 		Usage:    "Enable GraphQL on the HTTP-RPC server. Note that GraphQL can only be started if an HTTP server is started as well.",
@@ -762,6 +870,11 @@ var (
 		Value:    "",
 		Category: flags.APICategory,
 	}
+	WSAccessLogFlag = &cli.StringFlag{
+		Name:     "ws.accesslog",
+		Usage:    "File to append a JSON access log line to for every WS-RPC call",
+		Category: flags.APICategory,
+	}
 	ExecFlag = &cli.StringFlag{
 		Name:     "exec",
 		Usage:    "Execute JavaScript statement",
@@ -777,6 +890,48 @@ var (
 		Usage:    "Allow for unprotected (non EIP155 signed) transactions to be submitted via RPC",
 		Category: flags.APICategory,
 	}
+	RPCAPIKeysFlag = &cli.StringFlag{
+		Name:     "rpc.apikeys",
+		Usage:    "API key store file granting HTTP and WebSocket clients access to namespaces beyond http.api and ws.api (see 'yottaflux apikey')",
+		Category: flags.APICategory,
+	}
+	RPCSlowCallFlag = &cli.DurationFlag{
+		Name:     "rpc.slowcall",
+		Usage:    "Duration above which calls are marked slow in the access logs (0 = disabled)",
+		Category: flags.APICategory,
+	}
+	RPCSlowCallSampleFlag = &cli.Float64Flag{
+		Name:     "rpc.slowcall.sample",
+		Usage:    "Fraction of the slow calls logged with their parameters, never including the personal, account and clef namespaces",
+		Value:    node.DefaultConfig.RPCAccessLog.SlowCallSampling,
+		Category: flags.APICategory,
+	}
+	RPCRateLimitFlag = &cli.StringFlag{
+		Name:     "rpc.ratelimit",
+		Usage:    "Comma separated per client HTTP and WebSocket call rate limits as <method|namespace|*>=<rate>[:<burst>] (e.g. eth_getLogs=5:10,debug=1,*=100)",
+		Category: flags.APICategory,
+	}
+	RPCExpensiveFlag = &cli.StringFlag{
+		Name:     "rpc.expensive",
+		Usage:    "Comma separated list of methods and namespaces whose calls are limited by rpc.expensive.max",
+		Value:    strings.Join(rpc.DefaultExpensiveMethods, ","),
+		Category: flags.APICategory,
+	}
+	RPCExpensiveMaxFlag = &cli.IntFlag{
+		Name:     "rpc.expensive.max",
+		Usage:    "Maximum number of concurrent expensive calls per HTTP and WebSocket client (0 = unlimited)",
+		Category: flags.APICategory,
+	}
+	RPCBatchItemsFlag = &cli.IntFlag{
+		Name:     "rpc.batch-request-limit",
+		Usage:    "Maximum number of requests in an HTTP or WebSocket batch (0 = unlimited)",
+		Category: flags.APICategory,
+	}
+	RPCResponseBytesFlag = &cli.IntFlag{
+		Name:     "rpc.response-max-size",
+		Usage:    "Maximum number of bytes returned for an HTTP or WebSocket call or batch (0 = unlimited)",
+		Category: flags.APICategory,
+	}
 
 	// Network Settings
 	MaxPeersFlag = &cli.IntFlag{
@@ -996,6 +1151,9 @@ var (
 		DataDirFlag,
 		AncientFlag,
 		RemoteDBFlag,
+		RemoteDBSecretFlag,
+		DBEngineFlag,
+		DBSecondaryFlag,
 	}
 )
 
@@ -1201,6 +1359,9 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 	if ctx.IsSet(HTTPPathPrefixFlag.Name) {
 		cfg.HTTPPathPrefix = ctx.String(HTTPPathPrefixFlag.Name)
 	}
+	if ctx.IsSet(HTTPAccessLogFlag.Name) {
+		cfg.HTTPAccessLog = ctx.String(HTTPAccessLogFlag.Name)
+	}
 	if ctx.IsSet(AllowUnprotectedTxs.Name) {
 		cfg.AllowUnprotectedTxs = ctx.Bool(AllowUnprotectedTxs.Name)
 	}
@@ -1241,6 +1402,51 @@ func setWS(ctx *cli.Context, cfg *node.Config) {
 	if ctx.IsSet(WSPathPrefixFlag.Name) {
 		cfg.WSPathPrefix = ctx.String(WSPathPrefixFlag.Name)
 	}
+	if ctx.IsSet(WSAccessLogFlag.Name) {
+		cfg.WSAccessLog = ctx.String(WSAccessLogFlag.Name)
+	}
+}
+
+// setRPCLimits configures the limits imposed on the clients of the HTTP and
+// WebSocket RPC servers from the set command line flags.
+func setRPCLimits(ctx *cli.Context, cfg *node.Config) {
+	if ctx.IsSet(RPCRateLimitFlag.Name) {
+		cfg.RPCLimits.Rates = make(map[string]rpc.RateLimit)
+		for _, entry := range SplitAndTrim(ctx.String(RPCRateLimitFlag.Name)) {
+			parts := strings.SplitN(entry, "=", 2)
+			if len(parts) != 2 || parts[0] == "" {
+				Fatalf("Invalid --%s entry %q, want <method|namespace|*>=<rate>[:<burst>]", RPCRateLimitFlag.Name, entry)
+			}
+			var (
+				key    = parts[0]
+				values = strings.SplitN(parts[1], ":", 2)
+				limit  rpc.RateLimit
+				err    error
+			)
+			if limit.Rate, err = strconv.ParseFloat(values[0], 64); err != nil || limit.Rate <= 0 {
+				Fatalf("Invalid --%s rate %q for %s", RPCRateLimitFlag.Name, values[0], key)
+			}
+			limit.Burst = int(math.Ceil(limit.Rate))
+			if len(values) == 2 {
+				if limit.Burst, err = strconv.Atoi(values[1]); err != nil || limit.Burst <= 0 {
+					Fatalf("Invalid --%s burst %q for %s", RPCRateLimitFlag.Name, values[1], key)
+				}
+			}
+			cfg.RPCLimits.Rates[key] = limit
+		}
+	}
+	if ctx.IsSet(RPCExpensiveFlag.Name) {
+		cfg.RPCLimits.Expensive = SplitAndTrim(ctx.String(RPCExpensiveFlag.Name))
+	}
+	if ctx.IsSet(RPCExpensiveMaxFlag.Name) {
+		cfg.RPCLimits.MaxExpensive = ctx.Int(RPCExpensiveMaxFlag.Name)
+	}
+	if ctx.IsSet(RPCBatchItemsFlag.Name) {
+		cfg.RPCLimits.BatchItems = ctx.Int(RPCBatchItemsFlag.Name)
+	}
+	if ctx.IsSet(RPCResponseBytesFlag.Name) {
+		cfg.RPCLimits.ResponseBytes = ctx.Int(RPCResponseBytesFlag.Name)
+	}
 }
 
 // setIPC creates an IPC path configuration from the set command line flags,
@@ -1462,6 +1668,7 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	setHTTP(ctx, cfg)
 	setGraphQL(ctx, cfg)
 	setWS(ctx, cfg)
+	setRPCLimits(ctx, cfg)
 	setNodeUserIdent(ctx, cfg)
 	SetDataDir(ctx, cfg)
 	setSmartCard(ctx, cfg)
@@ -1469,6 +1676,15 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	if ctx.IsSet(JWTSecretFlag.Name) {
 		cfg.JWTSecret = ctx.String(JWTSecretFlag.Name)
 	}
+	if ctx.IsSet(RPCAPIKeysFlag.Name) {
+		cfg.APIKeys = ctx.String(RPCAPIKeysFlag.Name)
+	}
+	if ctx.IsSet(RPCSlowCallFlag.Name) {
+		cfg.RPCAccessLog.SlowCallThreshold = ctx.Duration(RPCSlowCallFlag.Name)
+	}
+	if ctx.IsSet(RPCSlowCallSampleFlag.Name) {
+		cfg.RPCAccessLog.SlowCallSampling = ctx.Float64(RPCSlowCallSampleFlag.Name)
+	}
 
 	if ctx.IsSet(ExternalSignerFlag.Name) {
 		cfg.ExternalSigner = ctx.String(ExternalSignerFlag.Name)
@@ -1477,6 +1693,16 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	if ctx.IsSet(KeyStoreDirFlag.Name) {
 		cfg.KeyStoreDir = ctx.String(KeyStoreDirFlag.Name)
 	}
+	if ctx.IsSet(DBEngineFlag.Name) {
+		dbEngine := ctx.String(DBEngineFlag.Name)
+		if dbEngine != "leveldb" && dbEngine != "pebble" {
+			Fatalf("Invalid choice for db.engine '%s', allowed 'leveldb' or 'pebble'", dbEngine)
+		}
+		cfg.DBEngine = dbEngine
+	}
+	if ctx.IsSet(DBSecondaryFlag.Name) {
+		cfg.DBSecondary = ctx.Bool(DBSecondaryFlag.Name)
+	}
 	if ctx.IsSet(DeveloperFlag.Name) {
 		cfg.UseLightweightKDF = true
 	}
@@ -1800,6 +2026,45 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(CacheNoPrefetchFlag.Name) {
 		cfg.NoPrefetch = ctx.Bool(CacheNoPrefetchFlag.Name)
 	}
+	if ctx.IsSet(StateSchemeFlag.Name) {
+		cfg.StateScheme = ctx.String(StateSchemeFlag.Name)
+	}
+	if ctx.IsSet(StateHistoryFlag.Name) {
+		cfg.StateHistory = ctx.Uint64(StateHistoryFlag.Name)
+	}
+	if ctx.IsSet(StateDiffsFlag.Name) {
+		cfg.StateDiffs = ctx.Uint64(StateDiffsFlag.Name)
+	}
+	if cfg.StateScheme == rawdb.PathScheme && cfg.NoPruning {
+		Fatalf("--%s=%s is incompatible with --%s=archive", StateSchemeFlag.Name, rawdb.PathScheme, GCModeFlag.Name)
+	}
+	if cfg.StateScheme == rawdb.PathScheme && cfg.StateDiffs > 0 {
+		Fatalf("--%s is incompatible with --%s=%s", StateDiffsFlag.Name, StateSchemeFlag.Name, rawdb.PathScheme)
+	}
+	if ctx.IsSet(HistoryKeepFlag.Name) {
+		cfg.HistoryKeep = ctx.Uint64(HistoryKeepFlag.Name)
+	}
+	if cfg.HistoryKeep > 0 && cfg.NoPruning {
+		Fatalf("--%s is incompatible with --%s=archive", HistoryKeepFlag.Name, GCModeFlag.Name)
+	}
+	if ctx.IsSet(ScrubFlag.Name) {
+		cfg.Scrub = ctx.Bool(ScrubFlag.Name)
+	}
+	if ctx.IsSet(CodeIndexFlag.Name) {
+		cfg.CodeIndex = ctx.Bool(CodeIndexFlag.Name)
+	}
+	if ctx.IsSet(LogIndexFlag.Name) {
+		cfg.LogIndex = ctx.Bool(LogIndexFlag.Name)
+	}
+	if ctx.IsSet(AccountIndexFlag.Name) {
+		cfg.AccountIndex = ctx.Bool(AccountIndexFlag.Name)
+	}
+	if ctx.IsSet(AccountIndexLimitFlag.Name) {
+		cfg.AccountIndexLimit = ctx.Uint64(AccountIndexLimitFlag.Name)
+	}
+	if ctx.IsSet(TransferIndexFlag.Name) {
+		cfg.TransferIndex = ctx.Bool(TransferIndexFlag.Name)
+	}
 	// Read the value from the flag no matter if it's set or not.
 	cfg.Preimages = ctx.Bool(CachePreimagesFlag.Name)
 	if cfg.NoPruning && !cfg.Preimages {
@@ -1809,6 +2074,18 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(TxLookupLimitFlag.Name) {
 		cfg.TxLookupLimit = ctx.Uint64(TxLookupLimitFlag.Name)
 	}
+	if ctx.IsSet(SafeDepthFlag.Name) {
+		cfg.SafeDepth = ctx.Uint64(SafeDepthFlag.Name)
+	}
+	if ctx.IsSet(FinalizedDepthFlag.Name) {
+		cfg.FinalizedDepth = ctx.Uint64(FinalizedDepthFlag.Name)
+	}
+	if ctx.IsSet(MaxReorgDepthFlag.Name) {
+		cfg.MaxReorgDepth = ctx.Uint64(MaxReorgDepthFlag.Name)
+	}
+	if ctx.IsSet(ReorgCheckpointsFlag.Name) {
+		cfg.ReorgCheckpoints = ctx.Path(ReorgCheckpointsFlag.Name)
+	}
 	if ctx.IsSet(CacheFlag.Name) || ctx.IsSet(CacheTrieFlag.Name) {
 		cfg.TrieCleanCache = ctx.Int(CacheFlag.Name) * ctx.Int(CacheTrieFlag.Name) / 100
 	}
@@ -1855,6 +2132,12 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(RPCGlobalTxFeeCapFlag.Name) {
 		cfg.RPCTxFeeCap = ctx.Float64(RPCGlobalTxFeeCapFlag.Name)
 	}
+	if ctx.IsSet(RPCLogsMaxResultsFlag.Name) {
+		cfg.RPCLogsMaxResults = ctx.Int(RPCLogsMaxResultsFlag.Name)
+	}
+	if ctx.IsSet(RPCLogsMaxRangeFlag.Name) {
+		cfg.RPCLogsMaxRange = ctx.Uint64(RPCLogsMaxRangeFlag.Name)
+	}
 	if ctx.IsSet(NoDiscoverFlag.Name) {
 		cfg.EthDiscoveryURLs, cfg.SnapDiscoveryURLs = []string{}, []string{}
 	} else if ctx.IsSet(DNSDiscoveryFlag.Name) {
@@ -1993,9 +2276,22 @@ func RegisterEthStatsService(stack *node.Node, backend ethapi.Backend, url strin
 	}
 }
 
+// RegisterRemoteDBService exposes the chain database of the backend on the given
+// socket path to remote database clients authenticated by the given secret file.
+func RegisterRemoteDBService(stack *node.Node, backend ethapi.Backend, endpoint string, secretFile string) {
+	if secretFile == "" {
+		secretFile = stack.ResolvePath("remotedb.secret")
+	}
+	secret, err := remotedb.ObtainSecret(secretFile)
+	if err != nil {
+		Fatalf("Failed to obtain the remote database secret: %v", err)
+	}
+	stack.RegisterLifecycle(remotedb.NewServer(backend.ChainDb(), stack.ResolvePath(endpoint), secret))
+}
+
 // RegisterGraphQLService is a utility function to construct a new service and register it against a node.
-func RegisterGraphQLService(stack *node.Node, backend ethapi.Backend, cfg node.Config) {
-	if err := graphql.New(stack, backend, cfg.GraphQLCors, cfg.GraphQLVirtualHosts); err != nil {
+func RegisterGraphQLService(stack *node.Node, backend ethapi.Backend, lightMode bool, cfg node.Config) {
+	if err := graphql.New(stack, backend, lightMode, cfg.GraphQLCors, cfg.GraphQLVirtualHosts); err != nil {
 		Fatalf("Failed to register the GraphQL service: %v", err)
 	}
 }
@@ -2085,7 +2381,16 @@ func MakeChainDatabase(ctx *cli.Context, stack *node.Node, readonly bool) ethdb.
 		err     error
 		chainDb ethdb.Database
 	)
+	if ctx.Bool(DBSecondaryFlag.Name) && !readonly {
+		Fatalf("--%s is only supported by read-only commands", DBSecondaryFlag.Name)
+	}
 	switch {
+	case ctx.IsSet(RemoteDBFlag.Name) && ctx.IsSet(RemoteDBSecretFlag.Name):
+		log.Info("Using remote db server", "endpoint", ctx.String(RemoteDBFlag.Name))
+		var secret []byte
+		if secret, err = remotedb.ReadSecret(ctx.String(RemoteDBSecretFlag.Name)); err == nil {
+			chainDb, err = remotedb.Dial(ctx.String(RemoteDBFlag.Name), secret)
+		}
 	case ctx.IsSet(RemoteDBFlag.Name):
 		log.Info("Using remote db", "url", ctx.String(RemoteDBFlag.Name))
 		chainDb, err = remotedb.New(ctx.String(RemoteDBFlag.Name))
@@ -2116,7 +2421,9 @@ func MakeGenesis(ctx *cli.Context) *core.Genesis {
 // MakeChain creates a chain manager from set command line flags.
 func MakeChain(ctx *cli.Context, stack *node.Node) (chain *core.BlockChain, chainDb ethdb.Database) {
 	var err error
-	chainDb = MakeChainDatabase(ctx, stack, false) // TODO(rjl493456442) support read-only database
+	// Setting up the chain writes to the database, e.g. when repairing the head
+	// state, so a secondary read-only database (--db.secondary) is refused.
+	chainDb = MakeChainDatabase(ctx, stack, false)
 	config, _, err := core.SetupGenesisBlock(chainDb, MakeGenesis(ctx))
 	if err != nil {
 		Fatalf("%v", err)
@@ -2139,6 +2446,10 @@ func MakeChain(ctx *cli.Context, stack *node.Node) (chain *core.BlockChain, chai
 		TrieTimeLimit:       ethconfig.Defaults.TrieTimeout,
 		SnapshotLimit:       ethconfig.Defaults.SnapshotCache,
 		Preimages:           ctx.Bool(CachePreimagesFlag.Name),
+		StateHistory:        ethconfig.Defaults.StateHistory,
+	}
+	if ctx.IsSet(StateHistoryFlag.Name) {
+		cache.StateHistory = ctx.Uint64(StateHistoryFlag.Name)
 	}
 	if cache.TrieDirtyDisabled && !cache.Preimages {
 		cache.Preimages = true
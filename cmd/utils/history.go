@@ -0,0 +1,228 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/era"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// historyCheckFreq is the frequency of verifying the seals of imported headers.
+const historyCheckFreq = 100
+
+// ExportHistory exports the blocks of the given range into archive files in the
+// given directory, one file per epoch of era.MaxSize blocks.
+func ExportHistory(bc *core.BlockChain, dir string, network string, first, last uint64) error {
+	log.Info("Exporting chain history", "dir", dir, "first", first, "last", last)
+
+	if head := bc.CurrentFastBlock().NumberU64(); last > head {
+		return fmt.Errorf("last block %d beyond head block %d", last, head)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	start := time.Now()
+	for epoch := first / era.MaxSize; epoch*era.MaxSize <= last; epoch++ {
+		from, to := epoch*era.MaxSize, (epoch+1)*era.MaxSize-1
+		if from < first {
+			from = first
+		}
+		if to > last {
+			to = last
+		}
+		if err := exportEpoch(bc, dir, network, epoch, from, to); err != nil {
+			return err
+		}
+		log.Info("Exported chain history epoch", "epoch", epoch, "first", from, "last", to, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	return nil
+}
+
+// exportEpoch writes the blocks of the given range into a single archive file.
+func exportEpoch(bc *core.BlockChain, dir string, network string, epoch, first, last uint64) error {
+	f, err := os.CreateTemp(dir, "export-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	var (
+		builder = era.NewBuilder(f)
+		hash    common.Hash
+	)
+	for n := first; n <= last; n++ {
+		block := bc.GetBlockByNumber(n)
+		if block == nil {
+			return fmt.Errorf("missing block %d", n)
+		}
+		td := bc.GetTd(block.Hash(), n)
+		if td == nil {
+			return fmt.Errorf("missing total difficulty of block %d", n)
+		}
+		if err := builder.Add(block, bc.GetReceiptsByHash(block.Hash()), td); err != nil {
+			return err
+		}
+		hash = block.Hash()
+	}
+	if _, err := builder.Finalize(); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), filepath.Join(dir, era.Filename(network, epoch, hash)))
+}
+
+// historyFiles lists the archive files of the network in the given directory,
+// ordered by epoch.
+func historyFiles(dir string, network string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, network+"-*.era1"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no %s archive files in %s", network, dir)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// iterateHistory opens and verifies the archive files of the network in order,
+// checking that they form a single chain, and calls fn with each of them.
+func iterateHistory(dir string, network string, fn func(e *era.Era) error) (common.Hash, error) {
+	files, err := historyFiles(dir, network)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	var last *types.Header
+	for _, file := range files {
+		e, err := era.Open(file)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		err = func() error {
+			defer e.Close()
+
+			if err := e.Verify(); err != nil {
+				return err
+			}
+			first, err := e.GetHeaderByNumber(e.Start())
+			if err != nil {
+				return err
+			}
+			if last != nil && (first.Number.Uint64() != last.Number.Uint64()+1 || first.ParentHash != last.Hash()) {
+				return fmt.Errorf("not continuing block %d [%x]", last.Number, last.Hash())
+			}
+			if last, err = e.GetHeaderByNumber(e.Start() + e.Count() - 1); err != nil {
+				return err
+			}
+			return fn(e)
+		}()
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("%s: %w", filepath.Base(file), err)
+		}
+	}
+	return last.Hash(), nil
+}
+
+// VerifyHistory verifies the archive files of the network in the given
+// directory without a database. If head is set, the last archived block must be
+// the block with the given hash.
+func VerifyHistory(dir string, network string, head common.Hash) error {
+	start := time.Now()
+	last, err := iterateHistory(dir, network, func(e *era.Era) error {
+		log.Info("Verified chain history file", "first", e.Start(), "count", e.Count(), "elapsed", common.PrettyDuration(time.Since(start)))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if head != (common.Hash{}) && last != head {
+		return fmt.Errorf("last archived block %x is not the expected head %x", last, head)
+	}
+	return nil
+}
+
+// ImportHistory imports the archive files of the network in the given directory
+// into the ancient store of the chain.
+func ImportHistory(chain *core.BlockChain, dir string, network string) error {
+	log.Info("Importing chain history", "dir", dir)
+
+	var (
+		start    = time.Now()
+		headers  []*types.Header
+		blocks   types.Blocks
+		receipts []types.Receipts
+	)
+	flush := func() error {
+		if len(blocks) == 0 {
+			return nil
+		}
+		if _, err := chain.InsertHeaderChain(headers, historyCheckFreq); err != nil {
+			return err
+		}
+		if _, err := chain.InsertReceiptChain(blocks, receipts, math.MaxUint64); err != nil {
+			return err
+		}
+		headers, blocks, receipts = headers[:0], blocks[:0], receipts[:0]
+		return nil
+	}
+	_, err := iterateHistory(dir, network, func(e *era.Era) error {
+		for n := e.Start(); n < e.Start()+e.Count(); n++ {
+			block, err := e.GetBlockByNumber(n)
+			if err != nil {
+				return err
+			}
+			// Skip the blocks already present in the chain
+			if n <= chain.CurrentFastBlock().NumberU64() {
+				if hash := chain.GetCanonicalHash(n); hash != block.Hash() {
+					return fmt.Errorf("block %d [%x] mismatches local block [%x]", n, block.Hash(), hash)
+				}
+				continue
+			}
+			blockReceipts, err := e.GetReceiptsByNumber(n)
+			if err != nil {
+				return err
+			}
+			headers = append(headers, block.Header())
+			blocks = append(blocks, block)
+			receipts = append(receipts, blockReceipts)
+			if len(blocks) == importBatchSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+		log.Info("Imported chain history file", "first", e.Start(), "count", e.Count(), "elapsed", common.PrettyDuration(time.Since(start)))
+		return nil
+	})
+	return err
+}
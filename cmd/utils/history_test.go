@@ -0,0 +1,118 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/internal/era"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that the chain history exported into archive files is verified and
+// imported into the ancient store of a fresh node.
+func TestHistoryExportImport(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		gspec   = &core.Genesis{
+			Config:  params.TestChainConfig,
+			Alloc:   core.GenesisAlloc{address: {Balance: big.NewInt(1000000000000000000)}},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		signer = types.LatestSigner(gspec.Config)
+		engine = ethash.NewFaker()
+		count  = era.MaxSize + 100
+	)
+	db := rawdb.NewMemoryDatabase()
+	genesis := gspec.MustCommit(db)
+	blocks, _ := core.GenerateChain(gspec.Config, genesis, engine, db, count, func(i int, b *core.BlockGen) {
+		if i%1000 == 0 {
+			tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(address), common.Address{0xaa}, big.NewInt(1), 21000, b.BaseFee(), nil), signer, key)
+			b.AddTx(tx)
+		}
+	})
+	chain, err := core.NewBlockChain(db, nil, gspec.Config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	// Export the history, split over two epochs
+	dir := t.TempDir()
+	if err := ExportHistory(chain, dir, "mainnet", 0, uint64(count)); err != nil {
+		t.Fatalf("failed to export history: %v", err)
+	}
+	files, err := historyFiles(dir, "mainnet")
+	if err != nil {
+		t.Fatalf("failed to list archive files: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("archive file count mismatch: have %d, want 2", len(files))
+	}
+	head := blocks[len(blocks)-1].Hash()
+	if err := VerifyHistory(dir, "mainnet", head); err != nil {
+		t.Fatalf("failed to verify history: %v", err)
+	}
+	if err := VerifyHistory(dir, "mainnet", common.Hash{0x01}); err == nil {
+		t.Fatalf("history verified against wrong head")
+	}
+	// Import the history into a fresh node with an ancient store
+	ancientdb, err := rawdb.NewDatabaseWithFreezer(rawdb.NewMemoryDatabase(), t.TempDir(), "", false)
+	if err != nil {
+		t.Fatalf("failed to create ancient database: %v", err)
+	}
+	defer ancientdb.Close()
+	gspec.MustCommit(ancientdb)
+
+	fresh, err := core.NewBlockChain(ancientdb, nil, gspec.Config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer fresh.Stop()
+	if err := ImportHistory(fresh, dir, "mainnet"); err != nil {
+		t.Fatalf("failed to import history: %v", err)
+	}
+	if have := fresh.CurrentFastBlock().Hash(); have != head {
+		t.Fatalf("head mismatch: have %x, want %x", have, head)
+	}
+	if frozen, _ := ancientdb.Ancients(); frozen != uint64(count)+1 {
+		t.Fatalf("ancient count mismatch: have %d, want %d", frozen, count+1)
+	}
+	if receipts := fresh.GetReceiptsByHash(blocks[1000].Hash()); len(receipts) != 1 {
+		t.Fatalf("receipts of block 1001 missing")
+	}
+	// Corrupt an archive file and check it's rejected
+	blob, _ := os.ReadFile(files[1])
+	blob[len(blob)/2] ^= 0xff
+	os.WriteFile(filepath.Join(dir, filepath.Base(files[1])), blob, 0644)
+	if err := VerifyHistory(dir, "mainnet", head); err == nil {
+		t.Fatalf("corrupted history verified")
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	apiKeyNamespacesFlag = &cli.StringFlag{
+		Name:  "namespaces",
+		Usage: "Comma separated list of API namespaces the key grants access to (\"*\" for all)",
+	}
+	apiKeyMethodsFlag = &cli.StringFlag{
+		Name:  "methods",
+		Usage: "Comma separated list of individual methods the key grants access to",
+	}
+
+	apiKeyCommand = &cli.Command{
+		Name:  "apikey",
+		Usage: "Manage the API keys of the HTTP and WebSocket RPC servers",
+		Description: `
+Manage the API keys granting access to RPC namespaces and methods beyond the
+ones exposed by --http.api and --ws.api. Clients present their key in the
+X-API-Key header, or in the apikey URL query parameter.
+
+The keys are stored hashed in the file given by --rpc.apikeys, a running node
+picks up changes of the file.`,
+		Subcommands: []*cli.Command{
+			{
+				Name:      "new",
+				Usage:     "Issue a new API key",
+				ArgsUsage: "<name>",
+				Action:    apiKeyCreate,
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.RPCAPIKeysFlag,
+					apiKeyNamespacesFlag,
+					apiKeyMethodsFlag,
+				},
+				Description: `
+    yottaflux apikey new --rpc.apikeys apikeys.json --namespaces eth,net <name>
+
+Issues a new API key under the given name and prints it. The key cannot be
+retrieved later, only its hash is stored.`,
+			},
+			{
+				Name:   "list",
+				Usage:  "Print the issued API keys",
+				Action: apiKeyList,
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.RPCAPIKeysFlag,
+				},
+			},
+			{
+				Name:      "revoke",
+				Usage:     "Revoke an API key",
+				ArgsUsage: "<name>",
+				Action:    apiKeyRevoke,
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.RPCAPIKeysFlag,
+				},
+			},
+		},
+	}
+)
+
+// openAPIKeyStore opens the API key store configured by the command line flags.
+func openAPIKeyStore(ctx *cli.Context) *node.APIKeyStore {
+	if !ctx.IsSet(utils.RPCAPIKeysFlag.Name) {
+		utils.Fatalf("The API key store path is required (--%s)", utils.RPCAPIKeysFlag.Name)
+	}
+	cfg := defaultNodeConfig()
+	utils.SetDataDir(ctx, &cfg)
+	cfg.APIKeys = ctx.String(utils.RPCAPIKeysFlag.Name)
+
+	store, err := node.OpenAPIKeyStore(cfg.APIKeysPath())
+	if err != nil {
+		utils.Fatalf("Failed to open API key store: %v", err)
+	}
+	return store
+}
+
+func apiKeyCreate(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("required argument: <name>")
+	}
+	var (
+		namespaces = utils.SplitAndTrim(ctx.String(apiKeyNamespacesFlag.Name))
+		methods    = utils.SplitAndTrim(ctx.String(apiKeyMethodsFlag.Name))
+	)
+	if len(namespaces) == 0 && len(methods) == 0 {
+		return fmt.Errorf("API key grants no access, specify --%s or --%s", apiKeyNamespacesFlag.Name, apiKeyMethodsFlag.Name)
+	}
+	key, err := openAPIKeyStore(ctx).Issue(ctx.Args().First(), namespaces, methods)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("API key %q issued: %s\n", ctx.Args().First(), key)
+	fmt.Println("Store the key safely, it cannot be retrieved later.")
+	return nil
+}
+
+func apiKeyList(ctx *cli.Context) error {
+	keys, err := openAPIKeyStore(ctx).Keys()
+	if err != nil {
+		return err
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Name", "Namespaces", "Methods", "Created"})
+	for _, key := range keys {
+		table.Append([]string{key.Name, strings.Join(key.Namespaces, ","), strings.Join(key.Methods, ","), key.Created.Format(time.RFC3339)})
+	}
+	table.Render()
+	return nil
+}
+
+func apiKeyRevoke(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("required argument: <name>")
+	}
+	if err := openAPIKeyStore(ctx).Revoke(ctx.Args().First()); err != nil {
+		return err
+	}
+	fmt.Printf("API key %q revoked\n", ctx.Args().First())
+	return nil
+}
@@ -48,7 +48,7 @@ var (
 		Name:      "init",
 		Usage:     "Bootstrap and initialize a new genesis block",
 		ArgsUsage: "<genesisPath>",
-		Flags:     utils.DatabasePathFlags,
+		Flags:     flags.Merge([]cli.Flag{utils.StateSchemeFlag}, utils.DatabasePathFlags),
 		Description: `
 The init command initializes a new genesis block and definition for the network.
 This is a destructive action and changes the network in which you will be
@@ -115,6 +115,47 @@ Optional second and third arguments control the first and
 last block to write. In this mode, the file will be appended
 if already existing. If the file ends with .gz, the output will
 be gzipped.`,
+	}
+	exportHistoryCommand = &cli.Command{
+		Action:    exportHistory,
+		Name:      "export-history",
+		Usage:     "Export blockchain history into archive files",
+		ArgsUsage: "<dir> <blockNumFirst> <blockNumLast>",
+		Flags: flags.Merge([]cli.Flag{
+			utils.CacheFlag,
+			utils.SyncModeFlag,
+		}, utils.DatabasePathFlags, utils.NetworkFlags),
+		Description: `
+The export-history command exports the blocks, receipts and total difficulties of
+the given range into checksummed archive files in the given directory, one file
+per epoch of 8192 blocks.`,
+	}
+	importHistoryCommand = &cli.Command{
+		Action:    importHistory,
+		Name:      "import-history",
+		Usage:     "Import blockchain history from archive files",
+		ArgsUsage: "<dir>",
+		Flags: flags.Merge([]cli.Flag{
+			utils.CacheFlag,
+			utils.SyncModeFlag,
+			utils.TxLookupLimitFlag,
+		}, utils.DatabasePathFlags, utils.NetworkFlags),
+		Description: `
+The import-history command verifies the archive files in the given directory and
+imports their blocks and receipts into the ancient store, bootstrapping the chain
+history of a fresh node without syncing it from the network.`,
+	}
+	verifyHistoryCommand = &cli.Command{
+		Action:    verifyHistory,
+		Name:      "verify-history",
+		Usage:     "Verify blockchain history archive files",
+		ArgsUsage: "<dir> [<headHash>]",
+		Flags:     utils.NetworkFlags,
+		Description: `
+The verify-history command checks the checksums, accumulators and contents of the
+archive files in the given directory and that they form a single chain, without
+opening a database. If a head hash is given, the last archived block must be the
+block with that hash.`,
 	}
 	importPreimagesCommand = &cli.Command{
 		Action:    importPreimages,
@@ -192,6 +233,16 @@ func initGenesis(ctx *cli.Context) error {
 		if err != nil {
 			utils.Fatalf("Failed to open database: %v", err)
 		}
+		// The light client only keeps the hash scheme
+		if name == "chaindata" {
+			scheme, err := rawdb.ParseStateScheme(ctx.String(utils.StateSchemeFlag.Name), chaindb)
+			if err != nil {
+				utils.Fatalf("Failed to resolve state scheme: %v", err)
+			}
+			if scheme == rawdb.PathScheme {
+				rawdb.WriteStateScheme(chaindb, scheme)
+			}
+		}
 		_, hash, err := core.SetupGenesisBlock(chaindb, genesis)
 		if err != nil {
 			utils.Fatalf("Failed to write genesis block: %v", err)
@@ -331,6 +382,81 @@ func exportChain(ctx *cli.Context) error {
 	return nil
 }
 
+// historyNetwork returns the network name used in the history archive files.
+func historyNetwork(ctx *cli.Context) string {
+	for _, flag := range utils.TestnetFlags {
+		if name := flag.Names()[0]; ctx.Bool(name) {
+			return name
+		}
+	}
+	return "mainnet"
+}
+
+func exportHistory(ctx *cli.Context) error {
+	if ctx.Args().Len() != 3 {
+		utils.Fatalf("This command requires three arguments.")
+	}
+	first, ferr := strconv.ParseUint(ctx.Args().Get(1), 10, 64)
+	last, lerr := strconv.ParseUint(ctx.Args().Get(2), 10, 64)
+	if ferr != nil || lerr != nil {
+		utils.Fatalf("Export error in parsing parameters: block number not an integer\n")
+	}
+	if first > last {
+		utils.Fatalf("Export error: first block %d larger than last block %d\n", first, last)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, _ := utils.MakeChain(ctx, stack)
+	start := time.Now()
+
+	if err := utils.ExportHistory(chain, ctx.Args().First(), historyNetwork(ctx), first, last); err != nil {
+		utils.Fatalf("Export error: %v\n", err)
+	}
+	fmt.Printf("Export done in %v\n", time.Since(start))
+	return nil
+}
+
+func importHistory(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		utils.Fatalf("This command requires an argument.")
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, db := utils.MakeChain(ctx, stack)
+	defer db.Close()
+
+	start := time.Now()
+	err := utils.ImportHistory(chain, ctx.Args().First(), historyNetwork(ctx))
+	chain.Stop()
+	if err != nil {
+		utils.Fatalf("Import error: %v\n", err)
+	}
+	fmt.Printf("Import done in %v\n", time.Since(start))
+	return nil
+}
+
+func verifyHistory(ctx *cli.Context) error {
+	if ctx.Args().Len() < 1 || ctx.Args().Len() > 2 {
+		utils.Fatalf("This command requires one or two arguments.")
+	}
+	var head common.Hash
+	if ctx.Args().Len() == 2 {
+		blob, err := hexutil.Decode(ctx.Args().Get(1))
+		if err != nil || len(blob) != common.HashLength {
+			utils.Fatalf("Invalid head hash %q\n", ctx.Args().Get(1))
+		}
+		head = common.BytesToHash(blob)
+	}
+	start := time.Now()
+	if err := utils.VerifyHistory(ctx.Args().First(), historyNetwork(ctx), head); err != nil {
+		utils.Fatalf("Verification error: %v\n", err)
+	}
+	fmt.Printf("Verification done in %v\n", time.Since(start))
+	return nil
+}
+
 // importPreimages imports preimage data from the specified file.
 func importPreimages(ctx *cli.Context) error {
 	if ctx.Args().Len() < 1 {
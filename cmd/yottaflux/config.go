@@ -176,7 +176,11 @@ func makeFullNode(ctx *cli.Context) (*node.Node, ethapi.Backend) {
 	}
 	// Configure GraphQL if requested
 	if ctx.IsSet(utils.GraphQLEnabledFlag.Name) {
-		utils.RegisterGraphQLService(stack, backend, cfg.Node)
+		utils.RegisterGraphQLService(stack, backend, eth == nil, cfg.Node)
+	}
+	// Expose the chain database to remote database clients if requested
+	if ctx.IsSet(utils.RemoteDBListenFlag.Name) {
+		utils.RegisterRemoteDBService(stack, backend, ctx.String(utils.RemoteDBListenFlag.Name), ctx.String(utils.RemoteDBSecretFlag.Name))
 	}
 	// Add the Ethereum Stats daemon if requested.
 	if cfg.Ethstats.URL != "" {
@@ -18,10 +18,12 @@ package main
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"syscall"
@@ -31,6 +33,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/console/prompt"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state/snapshot"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -38,6 +41,8 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/internal/flags"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/trie"
 	"github.com/olekukonko/tablewriter"
 	"github.com/urfave/cli/v2"
@@ -71,6 +76,11 @@ Remove blockchain and state databases`,
 			dbMetadataCmd,
 			dbMigrateFreezerCmd,
 			dbCheckStateContentCmd,
+			dbMigrateStateCmd,
+			dbPruneHistoryCmd,
+			dbCodeDeploymentsCmd,
+			dbCodeReportCmd,
+			dbRebuildLogIndexCmd,
 		},
 	}
 	dbInspectCmd = &cli.Command{
@@ -206,6 +216,76 @@ WARNING: This is a low-level operation which may cause database corruption!`,
 		Description: `The freezer-migrate command checks your database for receipts in a legacy format and updates those.
 WARNING: please back-up the receipt files in your ancients before running this command.`,
 	}
+	dbMigrateStateCmd = &cli.Command{
+		Action:    migrateState,
+		Name:      "migrate-state",
+		Usage:     "Migrate the head state from the hash scheme to the path scheme (WARNING: may take a long time)",
+		ArgsUsage: "",
+		Flags: flags.Merge([]cli.Flag{
+			utils.SyncModeFlag,
+		}, utils.NetworkFlags, utils.DatabasePathFlags),
+		Description: `The migrate-state command rewrites the state of the head block keyed by trie node
+path, switches the database over to the path state scheme and deletes all the legacy
+hash keyed trie nodes. Historical states are dropped, only the head state is kept.
+An interrupted migration can be resumed by running the command again.
+WARNING: please back-up your database before running this command.`,
+	}
+	dbPruneHistoryCmd = &cli.Command{
+		Action:    pruneHistory,
+		Name:      "prune-history",
+		Usage:     "Prune the bodies and receipts of old blocks from the ancient store",
+		ArgsUsage: "",
+		Flags: flags.Merge([]cli.Flag{
+			utils.SyncModeFlag,
+			utils.HistoryKeepFlag,
+		}, utils.NetworkFlags, utils.DatabasePathFlags),
+		Description: `The prune-history command drops the bodies and receipts of the frozen blocks
+more than --history.keep blocks below the head block, along with the value transfers
+and contract creations recorded for them. Headers are retained, so the
+chain stays verifiable, but the pruned data can no longer be served to peers or
+over RPC. Run the node with the same --history.keep to keep pruning as the chain
+progresses.`,
+	}
+	dbCodeDeploymentsCmd = &cli.Command{
+		Action:    codeDeployments,
+		Name:      "code-deployments",
+		Usage:     "List the contracts running a code from the contract code index",
+		ArgsUsage: "<codehash>",
+		Flags: flags.Merge([]cli.Flag{
+			utils.SyncModeFlag,
+		}, utils.NetworkFlags, utils.DatabasePathFlags),
+		Description: `The code-deployments command lists the contracts running the code with the
+given hash, along with the transactions and the blocks they were deployed in. The
+contract code index is built by running the node with --index.code, and covers
+the contracts created by the blocks imported while it was enabled, including the
+ones created by other contracts.`,
+	}
+	dbCodeReportCmd = &cli.Command{
+		Action:    codeReport,
+		Name:      "code-report",
+		Usage:     "Report how contract codes are shared between the accounts of the head state",
+		ArgsUsage: "",
+		Flags: flags.Merge([]cli.Flag{
+			utils.SyncModeFlag,
+			utils.DumpLimitFlag,
+		}, utils.NetworkFlags, utils.DatabasePathFlags),
+		Description: `The code-report command iterates the accounts of the head state snapshot and
+reports the number of distinct contract codes, the space saved by storing each
+code once, and the codes shared by the most contracts (--limit, default 20).`,
+	}
+	dbRebuildLogIndexCmd = &cli.Command{
+		Action:    rebuildLogIndex,
+		Name:      "rebuild-logindex",
+		Usage:     "Drop and rebuild the log index from the stored receipts",
+		ArgsUsage: "",
+		Flags: flags.Merge([]cli.Flag{
+			utils.SyncModeFlag,
+		}, utils.NetworkFlags, utils.DatabasePathFlags),
+		Description: `The rebuild-logindex command drops the address and topic index of the logs and
+rebuilds it for the confirmed part of the canonical chain. The index is used by
+log filtering when the node runs with --index.logs, which also keeps it up to date
+as the chain progresses. Blocks with pruned receipts are not indexed.`,
+	}
 )
 
 func removeDB(ctx *cli.Context) error {
@@ -830,3 +910,335 @@ func dbHasLegacyReceipts(db ethdb.Database, firstIdx uint64) (bool, uint64, erro
 	legacy, err = types.IsLegacyStoredReceipts(first)
 	return legacy, firstIdx, err
 }
+
+func pruneHistory(ctx *cli.Context) error {
+	keep := ctx.Uint64(utils.HistoryKeepFlag.Name)
+	if keep == 0 {
+		return fmt.Errorf("--%s is required", utils.HistoryKeepFlag.Name)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, false)
+	defer db.Close()
+
+	headBlock := rawdb.ReadHeadBlock(db)
+	if headBlock == nil {
+		return errors.New("no head block")
+	}
+	tail, err := core.PruneHistory(db, headBlock.NumberU64(), keep)
+	if err != nil {
+		return err
+	}
+	log.Info("Chain history retained", "tail", tail, "head", headBlock.NumberU64())
+	return nil
+}
+
+func migrateState(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, false)
+	defer db.Close()
+
+	headBlock := rawdb.ReadHeadBlock(db)
+	if headBlock == nil {
+		return errors.New("no head block")
+	}
+	root := headBlock.Root()
+
+	// Rewrite the head state keyed by path, unless a previous run already did
+	codes := make(map[common.Hash]struct{})
+	if rawdb.ReadStateScheme(db) == rawdb.PathScheme {
+		if !rawdb.HasAccountTrieNode(db, nil, root) {
+			return errors.New("head state is not available")
+		}
+		log.Info("Resuming state migration", "root", root, "number", headBlock.NumberU64())
+	} else {
+		log.Info("Migrating state to the path scheme", "root", root, "number", headBlock.NumberU64())
+		if err := migrateStateNodes(db, root, codes); err != nil {
+			return err
+		}
+		rawdb.WriteStateScheme(db, rawdb.PathScheme)
+	}
+	return deleteLegacyStateNodes(db, root, codes)
+}
+
+// migrateStateNodes writes all the trie nodes of the given state keyed by their
+// path, collecting the contract code hashes referenced by the accounts.
+func migrateStateNodes(db ethdb.Database, root common.Hash, codes map[common.Hash]struct{}) error {
+	triedb := trie.NewDatabaseWithConfig(db, &trie.Config{Scheme: rawdb.HashScheme})
+	accTrie, err := trie.NewStateTrie(common.Hash{}, root, triedb)
+	if err != nil {
+		return err
+	}
+	var (
+		batch    = db.NewBatch()
+		nodes    int
+		accounts int
+		start    = time.Now()
+		logged   = time.Now()
+	)
+	flush := func(force bool) error {
+		if batch.ValueSize() < ethdb.IdealBatchSize && !force {
+			return nil
+		}
+		if err := batch.Write(); err != nil {
+			return err
+		}
+		batch.Reset()
+		return nil
+	}
+	accIter := accTrie.NodeIterator(nil)
+	for accIter.Next(true) {
+		if accIter.Hash() != (common.Hash{}) {
+			rawdb.WriteAccountTrieNode(batch, accIter.Path(), accIter.NodeBlob())
+			nodes++
+		}
+		if accIter.Leaf() {
+			accounts++
+			var acc types.StateAccount
+			if err := rlp.DecodeBytes(accIter.LeafBlob(), &acc); err != nil {
+				return err
+			}
+			if !bytes.Equal(acc.CodeHash, emptyCode) {
+				codes[common.BytesToHash(acc.CodeHash)] = struct{}{}
+			}
+			if acc.Root != emptyRoot {
+				owner := common.BytesToHash(accIter.LeafKey())
+				storageTrie, err := trie.NewStateTrie(owner, acc.Root, triedb)
+				if err != nil {
+					return err
+				}
+				storageIter := storageTrie.NodeIterator(nil)
+				for storageIter.Next(true) {
+					if storageIter.Hash() != (common.Hash{}) {
+						rawdb.WriteStorageTrieNode(batch, owner, storageIter.Path(), storageIter.NodeBlob())
+						nodes++
+					}
+					if err := flush(false); err != nil {
+						return err
+					}
+				}
+				if err := storageIter.Error(); err != nil {
+					return err
+				}
+			}
+		}
+		if err := flush(false); err != nil {
+			return err
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Migrating state nodes", "accounts", accounts, "nodes", nodes, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := accIter.Error(); err != nil {
+		return err
+	}
+	if err := flush(true); err != nil {
+		return err
+	}
+	log.Info("Migrated state nodes", "accounts", accounts, "nodes", nodes, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// deleteLegacyStateNodes deletes all the hash keyed trie nodes from the database.
+// Legacy contract codes share the key format, so the code hashes referenced by
+// the head state are kept.
+func deleteLegacyStateNodes(db ethdb.Database, root common.Hash, codes map[common.Hash]struct{}) error {
+	// Resumed migrations need to recollect the code hashes
+	if len(codes) == 0 {
+		triedb := trie.NewDatabase(db)
+		accTrie, err := trie.NewStateTrie(common.Hash{}, root, triedb)
+		if err != nil {
+			return err
+		}
+		iter := trie.NewIterator(accTrie.NodeIterator(nil))
+		for iter.Next() {
+			var acc types.StateAccount
+			if err := rlp.DecodeBytes(iter.Value, &acc); err != nil {
+				return err
+			}
+			if !bytes.Equal(acc.CodeHash, emptyCode) {
+				codes[common.BytesToHash(acc.CodeHash)] = struct{}{}
+			}
+		}
+		if iter.Err != nil {
+			return iter.Err
+		}
+	}
+	var (
+		it      = rawdb.NewKeyLengthIterator(db.NewIterator(nil, nil), common.HashLength)
+		batch   = db.NewBatch()
+		deleted int
+		start   = time.Now()
+		logged  = time.Now()
+	)
+	defer it.Release()
+
+	for it.Next() {
+		key := common.BytesToHash(it.Key())
+		if _, ok := codes[key]; ok {
+			continue
+		}
+		if crypto.Keccak256Hash(it.Value()) != key {
+			continue
+		}
+		batch.Delete(it.Key())
+		deleted++
+
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Deleting legacy state nodes", "deleted", deleted, "at", key, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	log.Info("Deleted legacy state nodes", "deleted", deleted, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+func codeDeployments(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
+	}
+	var codeHash common.Hash
+	if err := codeHash.UnmarshalText([]byte(ctx.Args().First())); err != nil {
+		return fmt.Errorf("invalid code hash: %v", err)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, true)
+	defer db.Close()
+
+	deployments := rawdb.ReadCodeDeployments(db, codeHash)
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Address", "Transaction", "Block"})
+	for _, deployment := range deployments {
+		table.Append([]string{deployment.Address.Hex(), deployment.TxHash.Hex(), strconv.FormatUint(deployment.BlockNumber, 10)})
+	}
+	table.Render()
+	log.Info("Listed code deployments", "hash", codeHash, "contracts", len(deployments))
+	return nil
+}
+
+func codeReport(ctx *cli.Context) error {
+	limit := uint64(20)
+	if ctx.IsSet(utils.DumpLimitFlag.Name) {
+		limit = ctx.Uint64(utils.DumpLimitFlag.Name)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, true)
+	defer db.Close()
+
+	headBlock := rawdb.ReadHeadBlock(db)
+	if headBlock == nil {
+		return errors.New("no head block")
+	}
+	root := headBlock.Root()
+	snaptree, err := snapshot.New(db, trie.NewDatabase(db), 256, root, false, false, false)
+	if err != nil {
+		return err
+	}
+	accIt, err := snaptree.AccountIterator(root, common.Hash{})
+	if err != nil {
+		return err
+	}
+	defer accIt.Release()
+
+	var (
+		users     = make(map[common.Hash]uint64)
+		contracts uint64
+		start     = time.Now()
+		logged    = time.Now()
+	)
+	for accIt.Next() {
+		account, err := snapshot.FullAccount(accIt.Account())
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(account.CodeHash, emptyCode) {
+			continue
+		}
+		users[common.BytesToHash(account.CodeHash)]++
+		contracts++
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Iterating contract codes", "at", accIt.Hash(), "contracts", contracts, "codes", len(users), "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := accIt.Error(); err != nil {
+		return err
+	}
+	type codeUsage struct {
+		hash  common.Hash
+		users uint64
+		size  common.StorageSize
+	}
+	var (
+		usages   = make([]codeUsage, 0, len(users))
+		stored   common.StorageSize
+		expanded common.StorageSize
+		missing  int
+	)
+	for hash, n := range users {
+		size := common.StorageSize(len(rawdb.ReadCode(db, hash)))
+		if size == 0 {
+			missing++
+		}
+		stored += size
+		expanded += size * common.StorageSize(n)
+		usages = append(usages, codeUsage{hash: hash, users: n, size: size})
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].users != usages[j].users {
+			return usages[i].users > usages[j].users
+		}
+		return bytes.Compare(usages[i].hash[:], usages[j].hash[:]) < 0
+	})
+	if limit > 0 && uint64(len(usages)) > limit {
+		usages = usages[:limit]
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Code hash", "Contracts", "Size", "Indexed deployments"})
+	for _, usage := range usages {
+		indexed := len(rawdb.ReadCodeDeployments(db, usage.hash))
+		table.Append([]string{usage.hash.Hex(), strconv.FormatUint(usage.users, 10), usage.size.String(), strconv.Itoa(indexed)})
+	}
+	table.Render()
+
+	fmt.Printf("Contracts:        %d\n", contracts)
+	fmt.Printf("Distinct codes:   %d\n", len(users))
+	fmt.Printf("Missing codes:    %d\n", missing)
+	fmt.Printf("Stored code size: %v\n", stored)
+	fmt.Printf("Deduplicated:     %v\n", expanded-stored)
+	return nil
+}
+
+func rebuildLogIndex(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, false)
+	defer db.Close()
+
+	headBlock := rawdb.ReadHeadBlock(db)
+	if headBlock == nil {
+		return errors.New("no head block")
+	}
+	return core.RebuildLogIndex(db, headBlock.NumberU64(), params.BloomBitsBlocks, params.BloomConfirms)
+}
@@ -94,8 +94,22 @@ var (
 		utils.SyncModeFlag,
 		utils.ExitWhenSyncedFlag,
 		utils.GCModeFlag,
+		utils.StateSchemeFlag,
+		utils.StateHistoryFlag,
+		utils.StateDiffsFlag,
+		utils.HistoryKeepFlag,
+		utils.ScrubFlag,
+		utils.CodeIndexFlag,
+		utils.LogIndexFlag,
+		utils.AccountIndexFlag,
+		utils.AccountIndexLimitFlag,
+		utils.TransferIndexFlag,
 		utils.SnapshotFlag,
 		utils.TxLookupLimitFlag,
+		utils.SafeDepthFlag,
+		utils.FinalizedDepthFlag,
+		utils.MaxReorgDepthFlag,
+		utils.ReorgCheckpointsFlag,
 		utils.LightServeFlag,
 		utils.LightIngressFlag,
 		utils.LightEgressFlag,
@@ -146,6 +160,7 @@ var (
 		utils.VMEnableDebugFlag,
 		utils.NetworkIdFlag,
 		utils.EthStatsURLFlag,
+		utils.RemoteDBListenFlag,
 		utils.FakePoWFlag,
 		utils.NoCompactionFlag,
 		utils.GpoBlocksFlag,
@@ -172,19 +187,31 @@ var (
 		utils.GraphQLVirtualHostsFlag,
 		utils.HTTPApiFlag,
 		utils.HTTPPathPrefixFlag,
+		utils.HTTPAccessLogFlag,
 		utils.WSEnabledFlag,
 		utils.WSListenAddrFlag,
 		utils.WSPortFlag,
 		utils.WSApiFlag,
 		utils.WSAllowedOriginsFlag,
 		utils.WSPathPrefixFlag,
+		utils.WSAccessLogFlag,
 		utils.IPCDisabledFlag,
 		utils.IPCPathFlag,
 		utils.InsecureUnlockAllowedFlag,
 		utils.RPCGlobalGasCapFlag,
 		utils.RPCGlobalEVMTimeoutFlag,
 		utils.RPCGlobalTxFeeCapFlag,
+		utils.RPCLogsMaxResultsFlag,
+		utils.RPCLogsMaxRangeFlag,
 		utils.AllowUnprotectedTxs,
+		utils.RPCAPIKeysFlag,
+		utils.RPCSlowCallFlag,
+		utils.RPCSlowCallSampleFlag,
+		utils.RPCRateLimitFlag,
+		utils.RPCExpensiveFlag,
+		utils.RPCExpensiveMaxFlag,
+		utils.RPCBatchItemsFlag,
+		utils.RPCResponseBytesFlag,
 	}
 
 	metricsFlags = []cli.Flag{
@@ -215,6 +242,9 @@ func init() {
 		initCommand,
 		importCommand,
 		exportCommand,
+		importHistoryCommand,
+		exportHistoryCommand,
+		verifyHistoryCommand,
 		importPreimagesCommand,
 		exportPreimagesCommand,
 		removedbCommand,
@@ -223,6 +253,8 @@ func init() {
 		// See accountcmd.go:
 		accountCommand,
 		walletCommand,
+		// See apikeycmd.go:
+		apiKeyCommand,
 		// See consolecmd.go:
 		consoleCommand,
 		attachCommand,
@@ -159,6 +159,36 @@ as the backend data source, making this command a lot faster.
 
 The argument is interpreted as block number or hash. If none is provided, the latest
 block is used.
+`,
+			},
+			{
+				Name:      "export",
+				Usage:     "Export the state as verifiable snapshot files",
+				ArgsUsage: "<dir> [<root>]",
+				Action:    exportState,
+				Flags:     flags.Merge(utils.NetworkFlags, utils.DatabasePathFlags),
+				Description: `
+yottaflux snapshot export <dir> [<state-root>]
+will write the accounts, storage slots and contract codes of the specified state
+into chunked files in the given directory, each carrying the range proofs needed
+to verify it against the state root. If the directory already holds part of an
+export of the same state, it is continued after the last file.
+
+The default exporting target is the HEAD state.
+`,
+			},
+			{
+				Name:      "import",
+				Usage:     "Import the state from verifiable snapshot files",
+				ArgsUsage: "<dir>",
+				Action:    importState,
+				Flags:     flags.Merge(utils.NetworkFlags, utils.DatabasePathFlags),
+				Description: `
+yottaflux snapshot import <dir>
+will verify the state files created by 'yottaflux snapshot export' and rebuild
+the snapshot, the state tries and the contract codes from them, replacing the
+existing snapshot. If the imported state belongs to the head header of the chain,
+the head block is moved to it.
 `,
 			},
 		},
@@ -170,6 +200,10 @@ func pruneState(ctx *cli.Context) error {
 	defer stack.Close()
 
 	chaindb := utils.MakeChainDatabase(ctx, stack, false)
+	if rawdb.ReadStateScheme(chaindb) == rawdb.PathScheme {
+		log.Error("Offline pruning is not needed with the path state scheme, stale nodes are overwritten in place")
+		return errors.New("path state scheme")
+	}
 	pruner, err := pruner.NewPruner(chaindb, stack.ResolvePath(""), stack.ResolvePath(config.Eth.TrieCleanCacheJournal), ctx.Uint64(utils.BloomFilterSizeFlag.Name))
 	if err != nil {
 		log.Error("Failed to open snapshot tree", "err", err)
@@ -372,6 +406,7 @@ func traverseRawState(ctx *cli.Context) error {
 		codes      int
 		lastReport time.Time
 		start      = time.Now()
+		scheme     = triedb.Scheme()
 		hasher     = crypto.NewKeccakState()
 		got        = make([]byte, 32)
 	)
@@ -383,7 +418,7 @@ func traverseRawState(ctx *cli.Context) error {
 		// Check the present for non-empty hash node(embedded node doesn't
 		// have their own hash).
 		if node != (common.Hash{}) {
-			blob := rawdb.ReadTrieNode(chaindb, node)
+			blob := rawdb.ReadTrieNodeWithScheme(chaindb, common.Hash{}, accIter.Path(), node, scheme)
 			if len(blob) == 0 {
 				log.Error("Missing trie node(account)", "hash", node)
 				return errors.New("missing account")
@@ -419,7 +454,7 @@ func traverseRawState(ctx *cli.Context) error {
 					// Check the present for non-empty hash node(embedded node doesn't
 					// have their own hash).
 					if node != (common.Hash{}) {
-						blob := rawdb.ReadTrieNode(chaindb, node)
+						blob := rawdb.ReadTrieNodeWithScheme(chaindb, common.BytesToHash(accIter.LeafKey()), storageIter.Path(), node, scheme)
 						if len(blob) == 0 {
 							log.Error("Missing trie node(storage)", "hash", node)
 							return errors.New("missing storage")
@@ -541,6 +576,71 @@ func dumpState(ctx *cli.Context) error {
 	return nil
 }
 
+// exportState writes the state with the given root, or the head state, into
+// verifiable snapshot files.
+func exportState(ctx *cli.Context) error {
+	if ctx.NArg() < 1 || ctx.NArg() > 2 {
+		return errors.New("need <dir> [<root>] args")
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chaindb := utils.MakeChainDatabase(ctx, stack, true)
+	headBlock := rawdb.ReadHeadBlock(chaindb)
+	if headBlock == nil {
+		log.Error("Failed to load head block")
+		return errors.New("no head block")
+	}
+	snaptree, err := snapshot.New(chaindb, trie.NewDatabase(chaindb), 256, headBlock.Root(), false, false, false)
+	if err != nil {
+		log.Error("Failed to open snapshot tree", "err", err)
+		return err
+	}
+	var root = headBlock.Root()
+	if ctx.NArg() == 2 {
+		root, err = parseRoot(ctx.Args().Get(1))
+		if err != nil {
+			log.Error("Failed to resolve state root", "err", err)
+			return err
+		}
+	}
+	if err := snaptree.Export(root, ctx.Args().First()); err != nil {
+		log.Error("Failed to export state", "root", root, "err", err)
+		return err
+	}
+	return nil
+}
+
+// importState rebuilds the snapshot and the state tries from verifiable snapshot
+// files, moving the head block to the imported state if possible.
+func importState(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return errors.New("need <dir> arg")
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chaindb := utils.MakeChainDatabase(ctx, stack, false)
+	root, err := snapshot.Import(chaindb, ctx.Args().First())
+	if err != nil {
+		log.Error("Failed to import state", "err", err)
+		return err
+	}
+	head := rawdb.ReadHeadHeader(chaindb)
+	if head == nil || head.Root != root {
+		log.Warn("Imported state does not belong to the head header", "root", root)
+		return nil
+	}
+	if !rawdb.HasBody(chaindb, head.Hash(), head.Number.Uint64()) {
+		log.Warn("Imported state belongs to a header without body", "number", head.Number, "hash", head.Hash())
+		return nil
+	}
+	rawdb.WriteHeadBlockHash(chaindb, head.Hash())
+	rawdb.WriteHeadFastBlockHash(chaindb, head.Hash())
+	log.Info("Moved head block to imported state", "number", head.Number, "hash", head.Hash(), "root", root)
+	return nil
+}
+
 // checkAccount iterates the snap data layers, and looks up the given account
 // across all layers.
 func checkAccount(ctx *cli.Context) error {
@@ -138,6 +138,11 @@ func SeedHash(block uint64) []byte {
 	return seedHash(block)
 }
 
+// Epoch returns the epoch of the given block number.
+func Epoch(block uint64) uint64 {
+	return block / epochLength
+}
+
 // generateCache creates a verification cache of a given size for an input seed.
 // The cache production process involves first sequentially filling up 32 MB of
 // memory, then performing two passes of Sergio Demian Lerner's RandMemoHash
@@ -42,25 +42,7 @@ type API struct {
 //	result[2] - 32 bytes hex encoded boundary condition ("target"), 2^256/difficulty
 //	result[3] - hex encoded block number
 func (api *API) GetWork() ([4]string, error) {
-	if api.progpow.remote == nil {
-		return [4]string{}, errors.New("not supported")
-	}
-
-	var (
-		workCh = make(chan [4]string, 1)
-		errc   = make(chan error, 1)
-	)
-	select {
-	case api.progpow.remote.fetchWorkCh <- &sealWork{errc: errc, res: workCh}:
-	case <-api.progpow.remote.exitCh:
-		return [4]string{}, errProgpowStopped
-	}
-	select {
-	case work := <-workCh:
-		return work, nil
-	case err := <-errc:
-		return [4]string{}, err
-	}
+	return api.progpow.Work()
 }
 
 // SubmitWork can be used by external miner to submit their POW solution.
@@ -68,7 +68,7 @@ var (
 	// It now returns the initial block reward (4708 YTX).
 	BlockReward = InitialBlockReward
 
-	maxUncles                     = 2     // Maximum number of uncles allowed in a single block
+	maxUncles                     = 2         // Maximum number of uncles allowed in a single block
 	allowedFutureBlockTimeSeconds = int64(15) // Max seconds from current time allowed for blocks, before they're considered future blocks
 )
 
@@ -522,10 +522,20 @@ func CalcBlockReward(blockNumber *big.Int) *big.Int {
 	return reward
 }
 
-// accumulateRewards credits the coinbase of the given block with the mining
-// reward split among miner, dev fund, staker fund, and community fund.
-// Uncle miners also receive rewards based on the full block reward.
-func accumulateRewards(config *params.ChainConfig, state *state.StateDB, header *types.Header, uncles []*types.Header) {
+// Rewards is the breakdown of the rewards credited by a block.
+type Rewards struct {
+	Miner         *big.Int   // Reward of the block miner, including the uncle inclusion rewards
+	Uncles        []*big.Int // Rewards of the uncle miners, in the order of the uncles
+	StakerFund    *big.Int   // Share of the staker fund
+	DevFund       *big.Int   // Share of the development fund
+	CommunityFund *big.Int   // Share of the community fund
+}
+
+// BlockRewards computes the rewards credited by the given block, split among
+// miner, dev fund, staker fund, and community fund. Uncle miners also receive
+// rewards based on the full block reward. The fund shares are only credited if
+// the chain has a progpow configuration, and are zero otherwise.
+func BlockRewards(config *params.ChainConfig, header *types.Header, uncles []*types.Header) *Rewards {
 	blockReward := CalcBlockReward(header.Number)
 
 	// Determine reward split percentages based on year
@@ -544,49 +554,64 @@ func accumulateRewards(config *params.ChainConfig, state *state.StateDB, header
 		devPct = devPctPostYear1
 		communityPct = big.NewInt(0)
 	}
-
+	rewards := &Rewards{
+		Uncles:        make([]*big.Int, len(uncles)),
+		StakerFund:    new(big.Int),
+		DevFund:       new(big.Int),
+		CommunityFund: new(big.Int),
+	}
 	// Compute fund shares
-	stakerShare := new(big.Int).Mul(blockReward, stakerPct)
-	stakerShare.Div(stakerShare, big100)
-
-	devShare := new(big.Int).Mul(blockReward, devPct)
-	devShare.Div(devShare, big100)
+	if config.ProgPow != nil {
+		rewards.StakerFund.Mul(blockReward, stakerPct)
+		rewards.StakerFund.Div(rewards.StakerFund, big100)
 
-	communityShare := new(big.Int).Mul(blockReward, communityPct)
-	communityShare.Div(communityShare, big100)
+		rewards.DevFund.Mul(blockReward, devPct)
+		rewards.DevFund.Div(rewards.DevFund, big100)
 
+		rewards.CommunityFund.Mul(blockReward, communityPct)
+		rewards.CommunityFund.Div(rewards.CommunityFund, big100)
+	}
 	// Miner share = blockReward * minerPct / 100
-	minerShare := new(big.Int).Mul(blockReward, minerPct)
-	minerShare.Div(minerShare, big100)
+	rewards.Miner = new(big.Int).Mul(blockReward, minerPct)
+	rewards.Miner.Div(rewards.Miner, big100)
 
 	// Add uncle rewards on top of miner share
-	r := new(big.Int)
-	for _, uncle := range uncles {
+	for i, uncle := range uncles {
 		// Uncle miner reward: (uncle.Number + 8 - header.Number) * blockReward / 8
-		r.Add(uncle.Number, big8)
+		r := new(big.Int).Add(uncle.Number, big8)
 		r.Sub(r, header.Number)
 		r.Mul(r, blockReward)
 		r.Div(r, big8)
-		state.AddBalance(uncle.Coinbase, r)
+		rewards.Uncles[i] = r
 
 		// Miner inclusion reward: blockReward / 32
-		r.Div(blockReward, big32)
-		minerShare.Add(minerShare, r)
+		rewards.Miner.Add(rewards.Miner, new(big.Int).Div(blockReward, big32))
 	}
+	return rewards
+}
 
+// accumulateRewards credits the coinbase of the given block with the mining
+// reward split among miner, dev fund, staker fund, and community fund.
+// Uncle miners also receive rewards based on the full block reward.
+func accumulateRewards(config *params.ChainConfig, state *state.StateDB, header *types.Header, uncles []*types.Header) {
+	rewards := BlockRewards(config, header, uncles)
+
+	for i, uncle := range uncles {
+		state.AddBalance(uncle.Coinbase, rewards.Uncles[i])
+	}
 	// Credit miner
-	state.AddBalance(header.Coinbase, minerShare)
+	state.AddBalance(header.Coinbase, rewards.Miner)
 
 	// Credit fund addresses (only if ProgPow config exists)
 	if config.ProgPow != nil {
-		if stakerShare.Sign() > 0 {
-			state.AddBalance(config.ProgPow.StakerFundAddress, stakerShare)
+		if rewards.StakerFund.Sign() > 0 {
+			state.AddBalance(config.ProgPow.StakerFundAddress, rewards.StakerFund)
 		}
-		if devShare.Sign() > 0 {
-			state.AddBalance(config.ProgPow.DevFundAddress, devShare)
+		if rewards.DevFund.Sign() > 0 {
+			state.AddBalance(config.ProgPow.DevFundAddress, rewards.DevFund)
 		}
-		if communityShare.Sign() > 0 {
-			state.AddBalance(config.ProgPow.CommunityFundAddress, communityShare)
+		if rewards.CommunityFund.Sign() > 0 {
+			state.AddBalance(config.ProgPow.CommunityFundAddress, rewards.CommunityFund)
 		}
 	}
 }
@@ -683,6 +683,28 @@ func (progpow *Progpow) Hashrate() float64 {
 	return progpow.hashrate.Rate1() + float64(<-res)
 }
 
+// Work returns the work package for external miners, see API.GetWork.
+func (progpow *Progpow) Work() ([4]string, error) {
+	if progpow.remote == nil {
+		return [4]string{}, errors.New("not supported")
+	}
+	var (
+		workCh = make(chan [4]string, 1)
+		errc   = make(chan error, 1)
+	)
+	select {
+	case progpow.remote.fetchWorkCh <- &sealWork{errc: errc, res: workCh}:
+	case <-progpow.remote.exitCh:
+		return [4]string{}, errProgpowStopped
+	}
+	select {
+	case work := <-workCh:
+		return work, nil
+	case err := <-errc:
+		return [4]string{}, err
+	}
+}
+
 // APIs implements consensus.Engine, returning the user facing RPC APIs.
 func (progpow *Progpow) APIs(chain consensus.ChainHeaderReader) []rpc.API {
 	// In order to ensure backward compatibility, we expose progpow RPC APIs
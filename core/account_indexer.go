@@ -0,0 +1,188 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"context"
+	"encoding/binary"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+const (
+	// accountIndexThrottling is the time to wait between processing two
+	// consecutive account history index sections.
+	accountIndexThrottling = 100 * time.Millisecond
+)
+
+// accountIndexTailKey tracks the first block retained by the account history
+// index, within the data table of its chain indexer.
+var accountIndexTailKey = []byte("tail")
+
+// AccountIndexer implements a core.ChainIndexer, building up an index from
+// accounts to the transactions they sent or received, i.e. the transaction
+// history of the accounts. Contract creations are indexed as received by the
+// contracts they create.
+//
+// Akin to the transaction lookup limit, the history can be restricted to the
+// most recent blocks, the entries of older blocks are pruned as the chain
+// progresses.
+type AccountIndexer struct {
+	db     ethdb.Database      // Database to read the blocks from and write the index into
+	table  ethdb.Database      // Data table of the chain indexer, tracking the retained tail
+	config *params.ChainConfig // Chain config to derive the transaction senders with
+	limit  uint64              // Number of recent blocks to retain the history of, zero for all
+
+	batch ethdb.Batch // Index entries of the section being processed
+	tail  uint64      // First block to retain when processing the section
+}
+
+// NewAccountIndexer returns a chain indexer that generates the account history
+// index for the canonical chain, retaining the history of the last limit blocks
+// or the entire chain if limit is zero.
+func NewAccountIndexer(db ethdb.Database, config *params.ChainConfig, limit, size, confirms uint64) *ChainIndexer {
+	table := rawdb.NewTable(db, string(rawdb.AccountIndexPrefix))
+	backend := &AccountIndexer{
+		db:     db,
+		table:  table,
+		config: config,
+		limit:  limit,
+	}
+	return NewChainIndexer(db, table, backend, size, confirms, accountIndexThrottling, "accounts")
+}
+
+// AccountHistoryTail returns the first block whose transactions are retained
+// by the account history index, given the chain head and the retention limit.
+func AccountHistoryTail(db ethdb.Database, head, limit uint64) uint64 {
+	var tail uint64
+	if limit > 0 && head+1 > limit {
+		tail = head + 1 - limit
+	}
+	// Blocks pruned with a smaller limit before are no longer retained
+	if blob, _ := rawdb.NewTable(db, string(rawdb.AccountIndexPrefix)).Get(accountIndexTailKey); len(blob) == 8 {
+		if pruned := binary.BigEndian.Uint64(blob); pruned > tail {
+			tail = pruned
+		}
+	}
+	return tail
+}
+
+// Reset implements core.ChainIndexerBackend, starting a new account history
+// index section.
+func (a *AccountIndexer) Reset(ctx context.Context, section uint64, lastSectionHead common.Hash) error {
+	a.batch, a.tail = a.db.NewBatch(), 0
+	if head := rawdb.ReadHeadHeader(a.db); head != nil && a.limit > 0 && head.Number.Uint64()+1 > a.limit {
+		a.tail = head.Number.Uint64() + 1 - a.limit
+	}
+	return nil
+}
+
+// Process implements core.ChainIndexerBackend, adding the transactions of a
+// block into the index.
+func (a *AccountIndexer) Process(ctx context.Context, header *types.Header) error {
+	number := header.Number.Uint64()
+
+	// Drop the entries of the block if it was indexed before a reorg
+	if stale := rawdb.ReadAccountTxsBlock(a.db, number); stale != nil {
+		rawdb.DeleteAccountTxsBlock(a.batch, number, stale)
+	}
+	// Bodies are missing if the block history was pruned, there is nothing to
+	// index in that case
+	if number < a.tail {
+		return nil
+	}
+	body := rawdb.ReadBody(a.db, header.Hash(), number)
+	if body == nil {
+		return nil
+	}
+	var (
+		signer    = types.MakeSigner(a.config, header.Number)
+		entries   = make(map[common.Address]*rawdb.AccountTxsEntry)
+		addresses []common.Address
+	)
+	entry := func(address common.Address) *rawdb.AccountTxsEntry {
+		if entries[address] == nil {
+			entries[address] = &rawdb.AccountTxsEntry{Number: number}
+			addresses = append(addresses, address)
+		}
+		return entries[address]
+	}
+	for i, tx := range body.Transactions {
+		from, err := types.Sender(signer, tx)
+		if err != nil {
+			return err
+		}
+		sender := entry(from)
+		sender.Sent = append(sender.Sent, uint(i))
+
+		to := tx.To()
+		if to == nil {
+			created := crypto.CreateAddress(from, tx.Nonce())
+			to = &created
+		}
+		recipient := entry(*to)
+		recipient.Received = append(recipient.Received, uint(i))
+	}
+	for _, address := range addresses {
+		rawdb.WriteAccountTxs(a.batch, address, entries[address])
+	}
+	if len(addresses) > 0 {
+		rawdb.WriteAccountTxsBlock(a.batch, number, addresses)
+	}
+	if a.batch.ValueSize() >= ethdb.IdealBatchSize {
+		if err := a.batch.Write(); err != nil {
+			return err
+		}
+		a.batch.Reset()
+	}
+	return nil
+}
+
+// Commit implements core.ChainIndexerBackend, writing out the index entries of
+// the section into the database and pruning the ones of the blocks beyond the
+// retention limit.
+func (a *AccountIndexer) Commit() error {
+	if err := a.batch.Write(); err != nil {
+		return err
+	}
+	if a.tail == 0 {
+		return nil
+	}
+	start := time.Now()
+	pruned, err := rawdb.PruneAccountTxs(a.db, a.tail)
+	if err != nil {
+		return err
+	}
+	if pruned > 0 {
+		log.Debug("Pruned account history", "blocks", pruned, "tail", a.tail, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	enc := make([]byte, 8)
+	binary.BigEndian.PutUint64(enc, a.tail)
+	return a.table.Put(accountIndexTailKey, enc)
+}
+
+// Prune returns an empty error since pruning is driven by the retention limit.
+func (a *AccountIndexer) Prune(threshold uint64) error {
+	return nil
+}
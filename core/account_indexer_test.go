@@ -0,0 +1,128 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Tests that the account history indexer records the transactions sent and
+// received by each account, including the contracts created, replaces the entries of reprocessed blocks and
+// prunes the blocks beyond its retention limit.
+func TestAccountIndexer(t *testing.T) {
+	recipient := common.HexToAddress("0x1111")
+	gspec, blocks := newTestChain(4, func(i int, b *BlockGen) {
+		b.AddTx(testChainTx(b, &recipient, 1, nil))
+		if i == 1 {
+			b.AddTx(testChainTx(b, nil, 0, nil))
+			b.AddTx(testChainTx(b, &recipient, 1, nil))
+		}
+	})
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		engine = ethash.NewFaker()
+	)
+	gspec.MustCommit(db)
+
+	chain, err := NewBlockChain(db, nil, gspec.Config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	index := func(limit uint64, blocks []*types.Block) {
+		indexer := &AccountIndexer{db: db, table: rawdb.NewTable(db, string(rawdb.AccountIndexPrefix)), config: gspec.Config, limit: limit}
+		if err := indexer.Reset(context.Background(), 0, common.Hash{}); err != nil {
+			t.Fatalf("failed to reset indexer: %v", err)
+		}
+		for _, block := range blocks {
+			if err := indexer.Process(context.Background(), block.Header()); err != nil {
+				t.Fatalf("failed to index block %d: %v", block.NumberU64(), err)
+			}
+		}
+		if err := indexer.Commit(); err != nil {
+			t.Fatalf("failed to commit index: %v", err)
+		}
+	}
+	history := func(address common.Address) []rawdb.AccountTxsEntry {
+		var entries []rawdb.AccountTxsEntry
+		rawdb.IterateAccountTxs(db, address, 0, 10, func(entry *rawdb.AccountTxsEntry) bool {
+			entries = append(entries, *entry)
+			return true
+		})
+		return entries
+	}
+	// Leave a stale entry behind for block 2, it must be dropped when the block
+	// is indexed
+	stale := common.HexToAddress("0x2222")
+	rawdb.WriteAccountTxs(db, stale, &rawdb.AccountTxsEntry{Number: 2, Sent: []uint{0}})
+	rawdb.WriteAccountTxsBlock(db, 2, []common.Address{stale})
+
+	index(0, blocks)
+	if have, want := history(testChainAddress), []rawdb.AccountTxsEntry{
+		{Number: 1, Sent: []uint{0}, Received: []uint{}},
+		{Number: 2, Sent: []uint{0, 1, 2}, Received: []uint{}},
+		{Number: 3, Sent: []uint{0}, Received: []uint{}},
+		{Number: 4, Sent: []uint{0}, Received: []uint{}},
+	}; !reflect.DeepEqual(have, want) {
+		t.Errorf("sender history mismatch: have %+v, want %+v", have, want)
+	}
+	if have, want := history(recipient), []rawdb.AccountTxsEntry{
+		{Number: 1, Sent: []uint{}, Received: []uint{0}},
+		{Number: 2, Sent: []uint{}, Received: []uint{0, 2}},
+		{Number: 3, Sent: []uint{}, Received: []uint{0}},
+		{Number: 4, Sent: []uint{}, Received: []uint{0}},
+	}; !reflect.DeepEqual(have, want) {
+		t.Errorf("recipient history mismatch: have %+v, want %+v", have, want)
+	}
+	// The contract created in block 2 received its creation
+	if have, want := history(crypto.CreateAddress(testChainAddress, 2)), []rawdb.AccountTxsEntry{
+		{Number: 2, Sent: []uint{}, Received: []uint{1}},
+	}; !reflect.DeepEqual(have, want) {
+		t.Errorf("created contract history mismatch: have %+v, want %+v", have, want)
+	}
+	if have := history(stale); len(have) != 0 {
+		t.Errorf("stale history not dropped: %+v", have)
+	}
+	// Reindex with a retention limit of two blocks, the older ones must be pruned
+	index(2, blocks[2:])
+	if have, want := history(recipient), []rawdb.AccountTxsEntry{
+		{Number: 3, Sent: []uint{}, Received: []uint{0}},
+		{Number: 4, Sent: []uint{}, Received: []uint{0}},
+	}; !reflect.DeepEqual(have, want) {
+		t.Errorf("pruned recipient history mismatch: have %+v, want %+v", have, want)
+	}
+	if tail := AccountHistoryTail(db, 4, 2); tail != 3 {
+		t.Errorf("tail mismatch: have %d, want 3", tail)
+	}
+	// Lifting the limit does not bring back the pruned history
+	if tail := AccountHistoryTail(db, 4, 0); tail != 3 {
+		t.Errorf("tail mismatch after lifting limit: have %d, want 3", tail)
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// BlockTracer records the data derived from the execution of a block which is
+// stored along with it: its value transfers and the contracts it creates, as
+// enabled in the cache config. Blocks imported by the chain are traced while they
+// are processed, blocks built elsewhere, e.g. by the miner, are traced by their
+// builder, handing the tracer over along with the block.
+type BlockTracer struct {
+	transfers *transferTracer // Value transfer recorder, nil if disabled
+	creations *creationTracer // Contract creation recorder, nil if disabled
+	loggers   []vm.EVMLogger  // Enabled recorders to forward the execution events to
+}
+
+// NewBlockTracer returns a tracer recording the value transfers and contract
+// creations of a block executed on top of the chain, as enabled in the cache
+// config. It is nil if recording both is disabled, or another tracer is
+// configured.
+func (bc *BlockChain) NewBlockTracer() *BlockTracer {
+	if !bc.traceBlocks() {
+		return nil
+	}
+	tracer := new(BlockTracer)
+	if bc.cacheConfig.ValueTransfers {
+		tracer.transfers = newTransferTracer()
+	}
+	if bc.cacheConfig.ContractCreations {
+		tracer.creations = newCreationTracer()
+	}
+	tracer.setLoggers()
+	return tracer
+}
+
+// setLoggers collects the enabled recorders to forward the execution events to.
+func (t *BlockTracer) setLoggers() {
+	t.loggers = nil
+	if t.transfers != nil {
+		t.loggers = append(t.loggers, t.transfers)
+	}
+	if t.creations != nil {
+		t.loggers = append(t.loggers, t.creations)
+	}
+}
+
+// Copy creates a deep copy of the tracer, to continue recording a block apart
+// from the original.
+func (t *BlockTracer) Copy() *BlockTracer {
+	if t == nil {
+		return nil
+	}
+	cpy := new(BlockTracer)
+	if t.transfers != nil {
+		cpy.transfers = t.transfers.copy()
+	}
+	if t.creations != nil {
+		cpy.creations = t.creations.copy()
+	}
+	cpy.setLoggers()
+	return cpy
+}
+
+func (t *BlockTracer) CaptureTxStart(gasLimit uint64) {
+	for _, logger := range t.loggers {
+		logger.CaptureTxStart(gasLimit)
+	}
+}
+
+func (t *BlockTracer) CaptureTxEnd(restGas uint64) {
+	for _, logger := range t.loggers {
+		logger.CaptureTxEnd(restGas)
+	}
+}
+
+func (t *BlockTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	for _, logger := range t.loggers {
+		logger.CaptureStart(env, from, to, create, input, gas, value)
+	}
+}
+
+func (t *BlockTracer) CaptureEnd(output []byte, gasUsed uint64, elapsed time.Duration, err error) {
+	for _, logger := range t.loggers {
+		logger.CaptureEnd(output, gasUsed, elapsed, err)
+	}
+}
+
+func (t *BlockTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	for _, logger := range t.loggers {
+		logger.CaptureEnter(typ, from, to, input, gas, value)
+	}
+}
+
+func (t *BlockTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	for _, logger := range t.loggers {
+		logger.CaptureExit(output, gasUsed, err)
+	}
+}
+
+func (t *BlockTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+}
+
+func (t *BlockTracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+// traceBlocks reports whether the value transfers or the contract creations of
+// the blocks are recorded. Both are disabled while another tracer is configured.
+func (bc *BlockChain) traceBlocks() bool {
+	if bc.vmConfig.Tracer != nil {
+		return false
+	}
+	return bc.cacheConfig.ValueTransfers || bc.cacheConfig.ContractCreations
+}
+
+// blockTracing returns the EVM config to process blocks with, along with the
+// tracer recording their value transfers and contract creations, nil if
+// recording is disabled.
+func (bc *BlockChain) blockTracing() (vm.Config, *BlockTracer) {
+	vmConfig, tracer := bc.vmConfig, bc.NewBlockTracer()
+	if tracer != nil {
+		vmConfig.Debug, vmConfig.Tracer = true, tracer
+	}
+	return vmConfig, tracer
+}
+
+// writeBlockTrace stores the value transfers and contract creations of a block
+// recorded by the given tracer.
+func (bc *BlockChain) writeBlockTrace(db ethdb.KeyValueWriter, block *types.Block, tracer *BlockTracer) {
+	if tracer.transfers != nil {
+		bc.writeValueTransfers(db, block, tracer.transfers.transfers)
+	}
+	if tracer.creations != nil {
+		bc.writeContractCreations(db, block, tracer.creations.creations)
+	}
+}
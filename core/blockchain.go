@@ -89,6 +89,7 @@ const (
 	blockCacheLimit     = 256
 	receiptsCacheLimit  = 32
 	txLookupCacheLimit  = 1024
+	historicCacheLimit  = 16
 	maxFutureBlocks     = 256
 	maxTimeFutureBlocks = 30
 	TriesInMemory       = 128
@@ -131,6 +132,12 @@ type CacheConfig struct {
 	TrieTimeLimit       time.Duration // Time limit after which to flush the current in-memory trie to disk
 	SnapshotLimit       int           // Memory allowance (MB) to use for caching snapshot entries in memory
 	Preimages           bool          // Whether to store preimage of trie key to the disk
+	StateHistory        uint64        // Number of reverse state diffs to retain with the path scheme
+	StateDiffs          uint64        // Number of recent blocks to retain state diffs for, serving historical state (0 = disabled)
+	HistoryKeep         uint64        // Number of recent blocks to retain bodies and receipts for (0 = entire chain)
+	Scrub               bool          // Whether to check the database for inconsistencies in the background
+	ValueTransfers      bool          // Whether to record the value transfers of the imported blocks
+	ContractCreations   bool          // Whether to record the contracts created by the imported blocks
 
 	SnapshotWait bool // Wait for snapshot construction on startup. TODO(karalabe): This is a dirty hack for testing, nuke it
 }
@@ -182,6 +189,8 @@ type BlockChain struct {
 	chainHeadFeed event.Feed
 	logsFeed      event.Feed
 	blockProcFeed event.Feed
+	reorgFeed     event.Feed
+	refusedFeed   event.Feed
 	scope         event.SubscriptionScope
 	genesisBlock  *types.Block
 
@@ -194,14 +203,24 @@ type BlockChain struct {
 	currentFinalizedBlock atomic.Value // Current finalized head
 	currentSafeBlock      atomic.Value // Current safe head
 
+	safeDepth      uint64 // Confirmations below the head to consider a block safe (0 = not tracked)
+	finalizedDepth uint64 // Confirmations below the head to consider a block finalized (0 = not tracked)
+
+	maxReorgDepth  uint64                 // Maximum number of canonical blocks a reorg may drop (0 = unlimited)
+	checkpoints    map[uint64]common.Hash // Trusted block hashes the chain must not deviate from
+	checkpointLock sync.RWMutex           // Protects the checkpoint set
+
 	stateCache    state.Database // State database to reuse between imports (contains state cache)
 	bodyCache     *lru.Cache     // Cache for the most recent block bodies
 	bodyRLPCache  *lru.Cache     // Cache for the most recent block bodies in RLP encoded format
 	receiptsCache *lru.Cache     // Cache for the most recent receipts per block
 	blockCache    *lru.Cache     // Cache for the most recent entire blocks
 	txLookupCache *lru.Cache     // Cache for the most recent transaction lookup data.
+	historicCache *lru.Cache     // Cache for the most recent historical states rebuilt from the state diffs
 	futureBlocks  *lru.Cache     // future blocks are blocks added for later processing
 
+	scrubber *scrubber // Progress of the database scrubber, nil if disabled
+
 	wg            sync.WaitGroup //
 	quit          chan struct{}  // shutdown signal, closed in Stop.
 	running       int32          // 0 if chain is running, 1 when stopped
@@ -227,6 +246,7 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 	receiptsCache, _ := lru.New(receiptsCacheLimit)
 	blockCache, _ := lru.New(blockCacheLimit)
 	txLookupCache, _ := lru.New(txLookupCacheLimit)
+	historicCache, _ := lru.New(historicCacheLimit)
 	futureBlocks, _ := lru.New(maxFutureBlocks)
 
 	bc := &BlockChain{
@@ -238,6 +258,7 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 			Cache:     cacheConfig.TrieCleanLimit,
 			Journal:   cacheConfig.TrieCleanJournal,
 			Preimages: cacheConfig.Preimages,
+			History:   cacheConfig.StateHistory,
 		}),
 		quit:          make(chan struct{}),
 		chainmu:       syncx.NewClosableMutex(),
@@ -246,10 +267,22 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 		receiptsCache: receiptsCache,
 		blockCache:    blockCache,
 		txLookupCache: txLookupCache,
+		historicCache: historicCache,
 		futureBlocks:  futureBlocks,
+		checkpoints:   make(map[uint64]common.Hash),
 		engine:        engine,
 		vmConfig:      vmConfig,
 	}
+	// The recorders of the value transfers and contract creations are EVM tracers
+	// themselves, they can't run along with another one
+	if vmConfig.Tracer != nil && (cacheConfig.ValueTransfers || cacheConfig.ContractCreations) {
+		log.Warn("Value transfers and contract creations not recorded, an EVM tracer is configured")
+	}
+	// The blocks imported without recording the contract creations leave a gap,
+	// the creations are known again from the next block recorded
+	if (!cacheConfig.ContractCreations || vmConfig.Tracer != nil) && rawdb.ReadContractCreationsStart(db) != nil {
+		rawdb.DeleteContractCreationsStart(db)
+	}
 	bc.forker = NewForkChoice(bc, shouldPreserve)
 	bc.validator = NewBlockValidator(chainConfig, bc, engine)
 	bc.prefetcher = newStatePrefetcher(chainConfig, bc, engine)
@@ -262,7 +295,13 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 	}
 	bc.genesisBlock = bc.GetBlockByNumber(0)
 	if bc.genesisBlock == nil {
-		return nil, ErrNoGenesis
+		// The genesis body might have been pruned along with the chain history,
+		// it is empty anyway.
+		header := bc.GetHeaderByNumber(0)
+		if header == nil {
+			return nil, ErrNoGenesis
+		}
+		bc.genesisBlock = types.NewBlockWithHeader(header)
 	}
 
 	var nilBlock *types.Block
@@ -368,6 +407,13 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 		}
 	}
 
+	// Proof-of-work chains have no external finality, so derive the safe and
+	// finalized markers from the confirmation depths if configured.
+	if chainConfig.ProgPow != nil {
+		bc.safeDepth, bc.finalizedDepth = chainConfig.ProgPow.SafeDepth, chainConfig.ProgPow.FinalizedDepth
+		bc.maxReorgDepth = chainConfig.ProgPow.MaxReorgDepth
+		bc.updateFinality(bc.CurrentBlock())
+	}
 	// Load any existing snapshot, regenerating it if loading failed
 	if bc.cacheConfig.SnapshotLimit > 0 {
 		// If the chain was rewound past the snapshot persistent layer (causing
@@ -396,6 +442,19 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 		go bc.maintainTxIndex(txIndexBlock)
 	}
 
+	// Start history pruner.
+	if bc.cacheConfig.HistoryKeep > 0 {
+		bc.wg.Add(1)
+		go bc.maintainHistory()
+	}
+
+	// Start database scrubber.
+	if bc.cacheConfig.Scrub {
+		bc.scrubber = new(scrubber)
+		bc.wg.Add(1)
+		go bc.scrubDatabase()
+	}
+
 	// If periodic cache journal is required, spin it up.
 	if bc.cacheConfig.TrieCleanRejournal > 0 {
 		if bc.cacheConfig.TrieCleanRejournal < time.Minute {
@@ -530,6 +589,63 @@ func (bc *BlockChain) SetSafe(block *types.Block) {
 	}
 }
 
+// SetFinalityDepths overrides the number of confirmations after which blocks
+// are considered safe and finalized. A zero depth stops tracking the marker.
+// Depths are only meaningful for chains without external finality, such as
+// proof-of-work chains.
+func (bc *BlockChain) SetFinalityDepths(safe, finalized uint64) error {
+	if safe != 0 && finalized != 0 && safe > finalized {
+		return fmt.Errorf("safe depth %d above finalized depth %d", safe, finalized)
+	}
+	if !bc.chainmu.TryLock() {
+		return errChainStopped
+	}
+	defer bc.chainmu.Unlock()
+
+	bc.safeDepth, bc.finalizedDepth = safe, finalized
+	bc.updateFinality(bc.CurrentBlock())
+	return nil
+}
+
+// FinalityDepths returns the number of confirmations after which blocks are
+// considered safe and finalized.
+func (bc *BlockChain) FinalityDepths() (safe uint64, finalized uint64) {
+	return bc.safeDepth, bc.finalizedDepth
+}
+
+// updateFinality moves the safe and finalized markers to the configured depth
+// below the given head. Chains younger than the depth mark their genesis. This
+// method assumes that the chain manager mutex is held.
+func (bc *BlockChain) updateFinality(head *types.Block) {
+	if head == nil {
+		return
+	}
+	if bc.safeDepth > 0 {
+		if block := bc.confirmedBlock(head, bc.safeDepth); block != nil {
+			if current := bc.CurrentSafeBlock(); current == nil || current.Hash() != block.Hash() {
+				bc.SetSafe(block)
+			}
+		}
+	}
+	if bc.finalizedDepth > 0 {
+		if block := bc.confirmedBlock(head, bc.finalizedDepth); block != nil {
+			if current := bc.CurrentFinalizedBlock(); current == nil || current.Hash() != block.Hash() {
+				bc.SetFinalized(block)
+			}
+		}
+	}
+}
+
+// confirmedBlock returns the canonical block the given number of confirmations
+// below head, or the genesis block if the chain is not long enough yet.
+func (bc *BlockChain) confirmedBlock(head *types.Block, depth uint64) *types.Block {
+	var number uint64
+	if head.NumberU64() > depth {
+		number = head.NumberU64() - depth
+	}
+	return bc.GetBlockByNumber(number)
+}
+
 // setHeadBeyondRoot rewinds the local chain to a new head with the extra condition
 // that the rewind must pass the specified state root. This method is meant to be
 // used when rewinding with snapshots enabled to ensure that we go back further than
@@ -572,6 +688,10 @@ func (bc *BlockChain) setHeadBeyondRoot(head uint64, root common.Hash, repair bo
 					if root != (common.Hash{}) && !beyondRoot && newHeadBlock.Root() == root {
 						beyondRoot, rootNumber = true, newHeadBlock.NumberU64()
 					}
+					// Roll the persisted state back if it overwrote the one needed
+					if !bc.HasState(newHeadBlock.Root()) && bc.stateRecoverable(newHeadBlock.Root()) {
+						bc.recoverState(newHeadBlock.Root())
+					}
 					if _, err := state.New(newHeadBlock.Root(), bc.stateCache, bc.snaps); err != nil {
 						log.Trace("Block state missing, rewinding further", "number", newHeadBlock.NumberU64(), "hash", newHeadBlock.Hash())
 						if pivot == nil || newHeadBlock.NumberU64() > *pivot {
@@ -828,6 +948,8 @@ func (bc *BlockChain) writeHeadBlock(block *types.Block) {
 
 	bc.currentBlock.Store(block)
 	headBlockGauge.Update(int64(block.NumberU64()))
+
+	bc.updateFinality(block)
 }
 
 // Stop stops the blockchain service. If any imports are currently in progress
@@ -1244,7 +1366,7 @@ func (bc *BlockChain) writeKnownBlock(block *types.Block) error {
 
 // writeBlockWithState writes block, metadata and corresponding state data to the
 // database.
-func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.Receipt, state *state.StateDB) error {
+func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.Receipt, state *state.StateDB, tracer *BlockTracer) error {
 	// Calculate the total difficulty of the block
 	ptd := bc.GetTd(block.ParentHash(), block.NumberU64()-1)
 	if ptd == nil {
@@ -1262,14 +1384,26 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 	rawdb.WriteBlock(blockBatch, block)
 	rawdb.WriteReceipts(blockBatch, block.Hash(), block.NumberU64(), receipts)
 	rawdb.WritePreimages(blockBatch, state.Preimages())
-	if err := blockBatch.Write(); err != nil {
-		log.Crit("Failed to write block into disk", "err", err)
+	if tracer != nil {
+		bc.writeBlockTrace(blockBatch, block, tracer)
 	}
-	// Commit all cached state changes into underlying memory database.
+
+	// Commit all cached state changes into underlying memory database, storing
+	// the reverse state diff along with the block.
 	root, err := state.Commit(bc.chainConfig.IsEIP158(block.Number()))
 	if err != nil {
 		return err
 	}
+	if bc.cacheConfig.StateDiffs > 0 {
+		if diff := state.StateDiff(); diff != nil {
+			bc.writeStateDiff(blockBatch, block, diff)
+		} else {
+			log.Warn("State diff not tracked", "number", block.Number(), "hash", block.Hash())
+		}
+	}
+	if err := blockBatch.Write(); err != nil {
+		log.Crit("Failed to write block into disk", "err", err)
+	}
 	triedb := bc.stateCache.TrieDB()
 
 	// If we're running an archive node, always flush
@@ -1326,20 +1460,27 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 }
 
 // WriteBlockAndSetHead writes the given block and all associated state to the database,
-// and applies the block as the new chain head.
-func (bc *BlockChain) WriteBlockAndSetHead(block *types.Block, receipts []*types.Receipt, logs []*types.Log, state *state.StateDB, emitHeadEvent bool) (status WriteStatus, err error) {
+// and applies the block as the new chain head. The tracer, obtained through
+// NewBlockTracer, is the one the block was executed with while it was built, its
+// recordings are stored along with the block.
+func (bc *BlockChain) WriteBlockAndSetHead(block *types.Block, receipts []*types.Receipt, logs []*types.Log, state *state.StateDB, tracer *BlockTracer, emitHeadEvent bool) (status WriteStatus, err error) {
 	if !bc.chainmu.TryLock() {
 		return NonStatTy, errChainStopped
 	}
 	defer bc.chainmu.Unlock()
 
-	return bc.writeBlockAndSetHead(block, receipts, logs, state, emitHeadEvent)
+	if tracer == nil && bc.traceBlocks() {
+		log.Warn("Block written without its trace, value transfers and contract creations not recorded", "number", block.Number(), "hash", block.Hash())
+	}
+	return bc.writeBlockAndSetHead(block, receipts, logs, state, tracer, emitHeadEvent, nil)
 }
 
 // writeBlockAndSetHead is the internal implementation of WriteBlockAndSetHead.
+// The optional forks map caches the fork points checked by the reorg protection
+// within an import batch.
 // This function expects the chain mutex to be held.
-func (bc *BlockChain) writeBlockAndSetHead(block *types.Block, receipts []*types.Receipt, logs []*types.Log, state *state.StateDB, emitHeadEvent bool) (status WriteStatus, err error) {
-	if err := bc.writeBlockWithState(block, receipts, state); err != nil {
+func (bc *BlockChain) writeBlockAndSetHead(block *types.Block, receipts []*types.Receipt, logs []*types.Log, state *state.StateDB, tracer *BlockTracer, emitHeadEvent bool, forks map[common.Hash]uint64) (status WriteStatus, err error) {
+	if err := bc.writeBlockWithState(block, receipts, state, tracer); err != nil {
 		return NonStatTy, err
 	}
 	currentBlock := bc.CurrentBlock()
@@ -1347,12 +1488,24 @@ func (bc *BlockChain) writeBlockAndSetHead(block *types.Block, receipts []*types
 	if err != nil {
 		return NonStatTy, err
 	}
+	// Keep heavier forks on the side if switching to them would rewrite more
+	// history than the reorg protection permits
+	if reorg && block.ParentHash() != currentBlock.Hash() {
+		if depth, err := bc.checkReorg(currentBlock, block, forks); err != nil {
+			bc.refuseReorg(currentBlock.Header(), block.Header(), depth, err)
+			reorg = false
+		}
+	}
 	if reorg {
 		// Reorganise the chain if the parent is not the head block
 		if block.ParentHash() != currentBlock.Hash() {
 			if err := bc.reorg(currentBlock, block); err != nil {
 				return NonStatTy, err
 			}
+			// The cached fork points are relative to the old canonical chain
+			for hash := range forks {
+				delete(forks, hash)
+			}
 		}
 		status = CanonStatTy
 	} else {
@@ -1429,6 +1582,12 @@ func (bc *BlockChain) InsertChain(chain types.Blocks) (int, error) {
 				prev.Hash().Bytes()[:4], i, block.NumberU64(), block.Hash().Bytes()[:4], block.ParentHash().Bytes()[:4])
 		}
 	}
+	// Refuse any block contradicting a trusted checkpoint
+	for i, block := range chain {
+		if err := bc.checkCheckpoint(block.Header()); err != nil {
+			return i, err
+		}
+	}
 	// Pre-checks passed, start the full block imports
 	if !bc.chainmu.TryLock() {
 		return 0, errChainStopped
@@ -1457,6 +1616,7 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals, setHead bool)
 	var (
 		stats     = insertStats{startTime: mclock.Now()}
 		lastCanon *types.Block
+		forks     = make(map[common.Hash]uint64) // Fork points checked by the reorg protection
 	)
 	// Fire a single chain head event if we've progressed the chain
 	defer func() {
@@ -1639,6 +1799,9 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals, setHead bool)
 		if err != nil {
 			return it.index, err
 		}
+		if bc.cacheConfig.StateDiffs > 0 {
+			statedb.TrackStateDiff()
+		}
 
 		// Enable prefetching to pull in trie node paths while processing transactions
 		statedb.StartPrefetcher("chain")
@@ -1662,9 +1825,11 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals, setHead bool)
 			}
 		}
 
-		// Process block using the parent state as reference point
+		// Process block using the parent state as reference point, tracing the
+		// value transfers and contract creations if requested
+		vmConfig, tracer := bc.blockTracing()
 		substart := time.Now()
-		receipts, logs, usedGas, err := bc.processor.Process(block, statedb, bc.vmConfig)
+		receipts, logs, usedGas, err := bc.processor.Process(block, statedb, vmConfig)
 		if err != nil {
 			bc.reportBlock(block, receipts, err)
 			atomic.StoreUint32(&followupInterrupt, 1)
@@ -1703,9 +1868,9 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals, setHead bool)
 		var status WriteStatus
 		if !setHead {
 			// Don't set the head, only insert the block
-			err = bc.writeBlockWithState(block, receipts, statedb)
+			err = bc.writeBlockWithState(block, receipts, statedb, tracer)
 		} else {
-			status, err = bc.writeBlockAndSetHead(block, receipts, logs, statedb, false)
+			status, err = bc.writeBlockAndSetHead(block, receipts, logs, statedb, tracer, false, forks)
 		}
 		atomic.StoreUint32(&followupInterrupt, 1)
 		if err != nil {
@@ -1855,6 +2020,12 @@ func (bc *BlockChain) insertSideChain(block *types.Block, it *insertIterator) (i
 		log.Info("Sidechain written to disk", "start", it.first().NumberU64(), "end", it.previous().Number, "sidetd", externTd, "localtd", localTd)
 		return it.index, err
 	}
+	// Don't bother regenerating the state of a fork the reorg protection would
+	// refuse to switch over to anyway
+	if depth, err := bc.checkReorg(current, lastBlock, nil); err != nil {
+		bc.refuseReorg(current.Header(), lastBlock.Header(), depth, err)
+		return it.index, nil
+	}
 	// Gather all the sidechain hashes (full blocks may be memory heavy)
 	var (
 		hashes  []common.Hash
@@ -1862,6 +2033,12 @@ func (bc *BlockChain) insertSideChain(block *types.Block, it *insertIterator) (i
 	)
 	parent := it.previous()
 	for parent != nil && !bc.HasState(parent.Root) {
+		if bc.stateRecoverable(parent.Root) {
+			if err := bc.recoverState(parent.Root); err != nil {
+				return it.index, err
+			}
+			break
+		}
 		hashes = append(hashes, parent.Hash())
 		numbers = append(numbers, parent.Number.Uint64())
 
@@ -1906,6 +2083,22 @@ func (bc *BlockChain) insertSideChain(block *types.Block, it *insertIterator) (i
 	return 0, nil
 }
 
+// stateRecoverable reports whether the state of the given root, overwritten in
+// place with the path scheme, can be restored from the retained reverse diffs.
+func (bc *BlockChain) stateRecoverable(root common.Hash) bool {
+	return bc.stateCache.TrieDB().Recoverable(root)
+}
+
+// recoverState rolls the persisted state back to the given root, discarding
+// all the newer states kept in memory.
+func (bc *BlockChain) recoverState(root common.Hash) error {
+	if err := bc.stateCache.TrieDB().Recover(root); err != nil {
+		log.Error("Failed to recover state", "root", root, "err", err)
+		return err
+	}
+	return nil
+}
+
 // recoverAncestors finds the closest ancestor with available state and re-execute
 // all the ancestor blocks since that.
 // recoverAncestors is only used post-merge.
@@ -1918,6 +2111,12 @@ func (bc *BlockChain) recoverAncestors(block *types.Block) (common.Hash, error)
 		parent  = block
 	)
 	for parent != nil && !bc.HasState(parent.Root()) {
+		if bc.stateRecoverable(parent.Root()) {
+			if err := bc.recoverState(parent.Root()); err != nil {
+				return common.Hash{}, err
+			}
+			break
+		}
 		hashes = append(hashes, parent.Hash())
 		numbers = append(numbers, parent.NumberU64())
 		parent = bc.GetBlock(parent.ParentHash(), parent.NumberU64()-1)
@@ -2148,9 +2347,35 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 			bc.chainSideFeed.Send(ChainSideEvent{Block: oldChain[i]})
 		}
 	}
+	if len(oldChain) > 0 && len(newChain) > 0 {
+		bc.reorgFeed.Send(newReorgEvent(commonBlock, oldChain, newChain, deletedTxs))
+	}
 	return nil
 }
 
+// newReorgEvent assembles the reorg notification from the chain segments
+// collected during a reorg. Both segments are ordered from their head block
+// downwards, the event lists them from the common ancestor upwards.
+func newReorgEvent(commonBlock *types.Block, oldChain, newChain types.Blocks, deletedTxs []common.Hash) ReorgEvent {
+	ev := ReorgEvent{
+		CommonAncestor: commonBlock.Header(),
+		OldChain:       make([]common.Hash, 0, len(oldChain)),
+		NewChain:       make([]common.Hash, 0, len(newChain)),
+	}
+	for i := len(oldChain) - 1; i >= 0; i-- {
+		ev.OldChain = append(ev.OldChain, oldChain[i].Hash())
+	}
+	var includedTxs []common.Hash
+	for i := len(newChain) - 1; i >= 0; i-- {
+		ev.NewChain = append(ev.NewChain, newChain[i].Hash())
+		for _, tx := range newChain[i].Transactions() {
+			includedTxs = append(includedTxs, tx.Hash())
+		}
+	}
+	ev.DroppedTxs = types.HashDifference(deletedTxs, includedTxs)
+	return ev
+}
+
 // InsertBlockWithoutSetHead executes the block, runs the necessary verification
 // upon it and then persist the block and the associate state into the database.
 // The key difference between the InsertChain is it won't do the canonical chain
@@ -2401,6 +2626,11 @@ func (bc *BlockChain) InsertHeaderChain(chain []*types.Header, checkFreq int) (i
 	if i, err := bc.hc.ValidateHeaderChain(chain, checkFreq); err != nil {
 		return i, err
 	}
+	for i, header := range chain {
+		if err := bc.checkCheckpoint(header); err != nil {
+			return i, err
+		}
+	}
 
 	if !bc.chainmu.TryLock() {
 		return 0, errChainStopped
@@ -315,9 +315,18 @@ func (bc *BlockChain) State() (*state.StateDB, error) {
 	return bc.StateAt(bc.CurrentBlock().Root())
 }
 
-// StateAt returns a new mutable state based on a particular point in time.
+// StateAt returns a new mutable state based on a particular point in time. The
+// state tracks its reverse diffs if they are retained, so that blocks built on
+// top of it can be written into the chain.
 func (bc *BlockChain) StateAt(root common.Hash) (*state.StateDB, error) {
-	return state.New(root, bc.stateCache, bc.snaps)
+	statedb, err := state.New(root, bc.stateCache, bc.snaps)
+	if err != nil {
+		return nil, err
+	}
+	if bc.cacheConfig.StateDiffs > 0 {
+		statedb.TrackStateDiff()
+	}
+	return statedb, nil
 }
 
 // Config retrieves the chain's fork configuration.
@@ -393,6 +402,16 @@ func (bc *BlockChain) SubscribeChainSideEvent(ch chan<- ChainSideEvent) event.Su
 	return bc.scope.Track(bc.chainSideFeed.Subscribe(ch))
 }
 
+// SubscribeReorgEvent registers a subscription of ReorgEvent.
+func (bc *BlockChain) SubscribeReorgEvent(ch chan<- ReorgEvent) event.Subscription {
+	return bc.scope.Track(bc.reorgFeed.Subscribe(ch))
+}
+
+// SubscribeReorgRefusedEvent registers a subscription of ReorgRefusedEvent.
+func (bc *BlockChain) SubscribeReorgRefusedEvent(ch chan<- ReorgRefusedEvent) event.Subscription {
+	return bc.scope.Track(bc.refusedFeed.Subscribe(ch))
+}
+
 // SubscribeLogsEvent registers a subscription of []*types.Log.
 func (bc *BlockChain) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription {
 	return bc.scope.Track(bc.logsFeed.Subscribe(ch))
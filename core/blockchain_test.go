@@ -1365,6 +1365,134 @@ done:
 	}
 }
 
+// Tests that a reorg event is fired when the canonical chain is switched over to
+// a heavier side chain, listing the dropped blocks and transactions.
+func TestReorgEvent(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		gspec   = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  GenesisAlloc{addr1: {Balance: big.NewInt(10000000000000000)}},
+		}
+		genesis = gspec.MustCommit(db)
+		signer  = types.LatestSigner(gspec.Config)
+	)
+	blockchain, _ := NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	defer blockchain.Stop()
+
+	// The old chain includes a transaction per block, the first of which is
+	// also included by the new chain.
+	var txs []*types.Transaction
+	chain, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 3, func(i int, gen *BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(gen.TxNonce(addr1), common.Address{0xaa}, big.NewInt(1), params.TxGas, gen.header.BaseFee, nil), signer, key1)
+		if err != nil {
+			t.Fatalf("failed to create tx: %v", err)
+		}
+		gen.AddTx(tx)
+		txs = append(txs, tx)
+	})
+	if _, err := blockchain.InsertChain(chain); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	replacementBlocks, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 4, func(i int, gen *BlockGen) {
+		gen.SetExtra([]byte("fork"))
+		if i == 0 {
+			gen.AddTx(txs[0])
+		}
+		if i == 2 {
+			gen.OffsetTime(-9)
+		}
+	})
+	reorgCh := make(chan ReorgEvent, 64)
+	sub := blockchain.SubscribeReorgEvent(reorgCh)
+	defer sub.Unsubscribe()
+
+	if _, err := blockchain.InsertChain(replacementBlocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	select {
+	case ev := <-reorgCh:
+		if ev.CommonAncestor.Hash() != genesis.Hash() {
+			t.Errorf("common ancestor mismatch: have %x, want %x", ev.CommonAncestor.Hash(), genesis.Hash())
+		}
+		if len(ev.OldChain) != len(chain) {
+			t.Fatalf("old chain length mismatch: have %d, want %d", len(ev.OldChain), len(chain))
+		}
+		for i, hash := range ev.OldChain {
+			if hash != chain[i].Hash() {
+				t.Errorf("old chain %d mismatch: have %x, want %x", i, hash, chain[i].Hash())
+			}
+		}
+		if len(ev.NewChain) == 0 || len(ev.NewChain) > len(replacementBlocks) {
+			t.Fatalf("new chain length invalid: %d", len(ev.NewChain))
+		}
+		for i, hash := range ev.NewChain {
+			if hash != replacementBlocks[i].Hash() {
+				t.Errorf("new chain %d mismatch: have %x, want %x", i, hash, replacementBlocks[i].Hash())
+			}
+		}
+		want := []common.Hash{txs[1].Hash(), txs[2].Hash()}
+		if len(ev.DroppedTxs) != len(want) {
+			t.Fatalf("dropped tx count mismatch: have %d, want %d", len(ev.DroppedTxs), len(want))
+		}
+		for _, hash := range want {
+			found := false
+			for _, dropped := range ev.DroppedTxs {
+				found = found || dropped == hash
+			}
+			if !found {
+				t.Errorf("transaction %x not reported as dropped", hash)
+			}
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Timeout. Reorg event was not fired")
+	}
+	// make sure no more events are fired
+	select {
+	case e := <-reorgCh:
+		t.Errorf("unexpected event fired: %v", e)
+	case <-time.After(250 * time.Millisecond):
+	}
+}
+
+// Tests that the safe and finalized markers follow the chain head at the
+// configured confirmation depths.
+func TestFinalityDepths(t *testing.T) {
+	_, blockchain, err := newCanonical(ethash.NewFaker(), 0, true)
+	if err != nil {
+		t.Fatalf("failed to create pristine chain: %v", err)
+	}
+	defer blockchain.Stop()
+
+	if err := blockchain.SetFinalityDepths(10, 2); err == nil {
+		t.Fatalf("expected error for safe depth above finalized depth")
+	}
+	if err := blockchain.SetFinalityDepths(2, 10); err != nil {
+		t.Fatalf("failed to set finality depths: %v", err)
+	}
+	// A chain shorter than the depths marks its genesis.
+	if block := blockchain.CurrentFinalizedBlock(); block == nil || block.NumberU64() != 0 {
+		t.Fatalf("finalized block mismatch on empty chain: %v", block)
+	}
+	chain, _ := GenerateChain(blockchain.chainConfig, blockchain.genesisBlock, ethash.NewFaker(), blockchain.db, 20, func(i int, gen *BlockGen) {})
+	for i, block := range chain {
+		if _, err := blockchain.InsertChain(types.Blocks{block}); err != nil {
+			t.Fatalf("failed to insert block %d: %v", i, err)
+		}
+	}
+	if block := blockchain.CurrentSafeBlock(); block == nil || block.Hash() != chain[17].Hash() {
+		t.Errorf("safe block mismatch: have %v, want %d", block, chain[17].NumberU64())
+	}
+	if block := blockchain.CurrentFinalizedBlock(); block == nil || block.Hash() != chain[9].Hash() {
+		t.Errorf("finalized block mismatch: have %v, want %d", block, chain[9].NumberU64())
+	}
+	if hash := rawdb.ReadFinalizedBlockHash(blockchain.db); hash != chain[9].Hash() {
+		t.Errorf("persisted finalized block mismatch: have %x, want %x", hash, chain[9].Hash())
+	}
+}
+
 // Tests if the canonical block can be fetched from the database during chain insertion.
 func TestCanonicalBlockRetrieval(t *testing.T) {
 	_, blockchain, err := newCanonical(ethash.NewFaker(), 0, true)
@@ -3865,3 +3993,248 @@ func TestCanonicalHashMarker(t *testing.T) {
 		}
 	}
 }
+
+// Tests that with the path state scheme, a reorg to a fork branching off below
+// the persisted state rolls the state back using the reverse diffs.
+func TestPathSchemeReorg(t *testing.T) {
+	engine := ethash.NewFaker()
+
+	db := rawdb.NewMemoryDatabase()
+	genesis := (&Genesis{BaseFee: big.NewInt(params.InitialBaseFee)}).MustCommit(db)
+
+	shared, _ := GenerateChain(params.TestChainConfig, genesis, engine, db, 5, func(i int, b *BlockGen) { b.SetCoinbase(common.Address{1}) })
+	original, _ := GenerateChain(params.TestChainConfig, shared[len(shared)-1], engine, db, 5, func(i int, b *BlockGen) { b.SetCoinbase(common.Address{2}) })
+	competitor, _ := GenerateChain(params.TestChainConfig, shared[len(shared)-1], engine, db, 6, func(i int, b *BlockGen) { b.SetCoinbase(common.Address{3}) })
+
+	// Persist every state in place, so the forking point gets overwritten
+	diskdb := rawdb.NewMemoryDatabase()
+	rawdb.WriteStateScheme(diskdb, rawdb.PathScheme)
+	(&Genesis{BaseFee: big.NewInt(params.InitialBaseFee)}).MustCommit(diskdb)
+
+	cacheConfig := &CacheConfig{
+		TrieCleanLimit:    256,
+		TrieDirtyLimit:    256,
+		TrieDirtyDisabled: true,
+		TrieTimeLimit:     5 * time.Minute,
+		StateHistory:      16,
+	}
+	chain, err := NewBlockChain(diskdb, cacheConfig, params.TestChainConfig, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(append(shared, original...)); err != nil {
+		t.Fatalf("failed to insert original chain: %v", err)
+	}
+	if chain.HasState(shared[len(shared)-1].Root()) {
+		t.Fatalf("forking point state not overwritten")
+	}
+	if _, err := chain.InsertChain(competitor); err != nil {
+		t.Fatalf("failed to insert competitor chain: %v", err)
+	}
+	if head := chain.CurrentBlock(); head.Hash() != competitor[len(competitor)-1].Hash() {
+		t.Fatalf("head mismatch: have %d [%x], want %d [%x]", head.NumberU64(), head.Hash(), competitor[len(competitor)-1].NumberU64(), competitor[len(competitor)-1].Hash())
+	}
+	if !chain.HasState(competitor[len(competitor)-1].Root()) {
+		t.Fatalf("competitor head state missing")
+	}
+}
+
+// Tests that with the path state scheme, the storage trie of a self-destructed
+// contract is wiped from disk, that the contract recreated with CREATE2 only
+// sees its new storage, and that the wiped storage is restored when the state
+// is rolled back.
+func TestPathSchemeDeleteRecreateSlots(t *testing.T) {
+	var (
+		engine    = ethash.NewFaker()
+		key, _    = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address   = crypto.PubkeyToAddress(key.PublicKey)
+		funds     = big.NewInt(1000000000000000)
+		bb        = common.HexToAddress("0x000000000000000000000000000000000000bbbb")
+		aaStorage = map[common.Hash]common.Hash{
+			common.HexToHash("01"): common.HexToHash("01"),
+			common.HexToHash("02"): common.HexToHash("02"),
+		}
+		aaCode = []byte{byte(vm.PC), byte(vm.SELFDESTRUCT)}
+	)
+	// The bb-code recreates aa with CREATE2, setting slots 3 and 4
+	initCode := []byte{
+		byte(vm.PUSH1), 0x3, byte(vm.PUSH1), 0x3, byte(vm.SSTORE),
+		byte(vm.PUSH1), 0x4, byte(vm.PUSH1), 0x4, byte(vm.SSTORE),
+		byte(vm.PUSH2), byte(vm.PC), byte(vm.SELFDESTRUCT),
+		byte(vm.PUSH1), 0x0, byte(vm.MSTORE),
+		byte(vm.PUSH1), 0x2, byte(vm.PUSH1), byte(32 - 2), byte(vm.RETURN),
+	}
+	bbCode := append([]byte{byte(vm.PUSH1) + byte(len(initCode)-1)}, initCode...)
+	bbCode = append(bbCode, []byte{
+		byte(vm.PUSH1), 0x0, byte(vm.MSTORE),
+		byte(vm.PUSH1), 0x00, // salt
+		byte(vm.PUSH1), byte(len(initCode)), // size
+		byte(vm.PUSH1), byte(32 - len(initCode)), // offset
+		byte(vm.PUSH1), 0x00, // endowment
+		byte(vm.CREATE2),
+	}...)
+	initHash := crypto.Keccak256Hash(initCode)
+	aa := crypto.CreateAddress2(bb, [32]byte{}, initHash[:])
+
+	gspec := &Genesis{
+		Config: params.TestChainConfig,
+		Alloc: GenesisAlloc{
+			address: {Balance: funds},
+			aa:      {Code: aaCode, Nonce: 1, Balance: big.NewInt(0), Storage: aaStorage},
+			bb:      {Code: bbCode, Balance: big.NewInt(1)},
+		},
+	}
+	gendb := rawdb.NewMemoryDatabase()
+	genesis := gspec.MustCommit(gendb)
+
+	// Destruct aa in the first block and recreate it in the second one
+	blocks, _ := GenerateChain(params.TestChainConfig, genesis, engine, gendb, 2, func(i int, b *BlockGen) {
+		to, gas := aa, uint64(50000)
+		if i == 1 {
+			to, gas = bb, 100000
+		}
+		tx, _ := types.SignTx(types.NewTransaction(uint64(i), to, big.NewInt(0), gas, b.header.BaseFee, nil), types.HomesteadSigner{}, key)
+		b.AddTx(tx)
+	})
+	// Persist every state in place
+	diskdb := rawdb.NewMemoryDatabase()
+	rawdb.WriteStateScheme(diskdb, rawdb.PathScheme)
+	gspec.MustCommit(diskdb)
+
+	cacheConfig := &CacheConfig{
+		TrieCleanLimit:    256,
+		TrieDirtyLimit:    256,
+		TrieDirtyDisabled: true,
+		TrieTimeLimit:     5 * time.Minute,
+		StateHistory:      16,
+	}
+	chain, err := NewBlockChain(diskdb, cacheConfig, params.TestChainConfig, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	owner := crypto.Keccak256Hash(aa.Bytes())
+	storageNodes := func() (count int) {
+		rawdb.IterateStorageTrieNodes(diskdb, owner, func(path []byte, blob []byte) { count++ })
+		return count
+	}
+	if storageNodes() == 0 {
+		t.Fatalf("genesis storage trie not persisted")
+	}
+	if _, err := chain.InsertChain(blocks[:1]); err != nil {
+		t.Fatalf("failed to insert destructing block: %v", err)
+	}
+	if n := storageNodes(); n != 0 {
+		t.Fatalf("destructed storage trie left %d nodes on disk", n)
+	}
+	if _, err := chain.InsertChain(blocks[1:]); err != nil {
+		t.Fatalf("failed to insert recreating block: %v", err)
+	}
+	checkSlots := func(want map[byte]byte) {
+		t.Helper()
+
+		statedb, err := chain.State()
+		if err != nil {
+			t.Fatalf("failed to open head state: %v", err)
+		}
+		for slot, value := range want {
+			if got, exp := statedb.GetState(aa, common.BytesToHash([]byte{slot})), common.BytesToHash([]byte{value}); got != exp {
+				t.Errorf("slot %d mismatch: have %x, want %x", slot, got, exp)
+			}
+		}
+	}
+	checkSlots(map[byte]byte{1: 0, 2: 0, 3: 3, 4: 4})
+
+	// Roll the state back to genesis, restoring the wiped storage
+	if err := chain.SetHead(0); err != nil {
+		t.Fatalf("failed to rewind chain: %v", err)
+	}
+	checkSlots(map[byte]byte{1: 1, 2: 2, 3: 0, 4: 0})
+}
+
+// Tests that the state of blocks garbage collected from memory is rebuilt from
+// the retained state diffs, and that diffs beyond the window are pruned.
+func TestHistoricStateFromDiffs(t *testing.T) {
+	var (
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address  = crypto.PubkeyToAddress(key.PublicKey)
+		contract = common.HexToAddress("0xc0de")
+		funds    = big.NewInt(1000000000000000000)
+		gspec    = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc: GenesisAlloc{
+				address:  {Balance: funds},
+				contract: {Balance: big.NewInt(0), Code: []byte{byte(vm.NUMBER), byte(vm.PUSH1), 0x00, byte(vm.SSTORE)}},
+			},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		signer = types.LatestSigner(gspec.Config)
+		engine = ethash.NewFaker()
+	)
+	gendb := rawdb.NewMemoryDatabase()
+	genesis := gspec.MustCommit(gendb)
+	blocks, _ := GenerateChain(gspec.Config, genesis, engine, gendb, 2*TriesInMemory, func(i int, b *BlockGen) {
+		for _, to := range []common.Address{common.BigToAddress(big.NewInt(int64(i + 1))), contract} {
+			tx, err := types.SignTx(types.NewTransaction(b.TxNonce(address), to, big.NewInt(1000), 100000, b.header.BaseFee, nil), signer, key)
+			if err != nil {
+				t.Fatalf("failed to sign transaction: %v", err)
+			}
+			b.AddTx(tx)
+		}
+	})
+	db := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(db)
+
+	cacheConfig := &CacheConfig{
+		TrieCleanLimit: 256,
+		TrieDirtyLimit: 256,
+		TrieTimeLimit:  5 * time.Minute,
+		StateDiffs:     TriesInMemory + 64,
+	}
+	chain, err := NewBlockChain(db, cacheConfig, gspec.Config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	// States behind the in-memory ones should be rebuilt from the diffs
+	for _, number := range []uint64{TriesInMemory - 1, TriesInMemory - 32} {
+		header := chain.GetHeaderByNumber(number)
+		if chain.HasState(header.Root) {
+			t.Fatalf("block #%d: state not garbage collected", number)
+		}
+		statedb, err := chain.HistoricState(header)
+		if err != nil {
+			t.Fatalf("block #%d: failed to rebuild state: %v", number, err)
+		}
+		if have := statedb.GetState(contract, common.Hash{}); have != common.BigToHash(new(big.Int).SetUint64(number)) {
+			t.Fatalf("block #%d: contract storage mismatch: have %x", number, have)
+		}
+		if have, want := statedb.GetBalance(common.BigToAddress(big.NewInt(int64(number)))), big.NewInt(1000); have.Cmp(want) != 0 {
+			t.Fatalf("block #%d: balance mismatch: have %v, want %v", number, have, want)
+		}
+		// Rebuilt states should be served from the cache, unaffected by changes
+		// made to the copies handed out before
+		statedb.SetState(contract, common.Hash{}, common.Hash{0xff})
+		if _, ok := chain.historicCache.Get(header.Hash()); !ok {
+			t.Fatalf("block #%d: rebuilt state not cached", number)
+		}
+		cached, err := chain.HistoricState(header)
+		if err != nil {
+			t.Fatalf("block #%d: failed to retrieve cached state: %v", number, err)
+		}
+		if have := cached.GetState(contract, common.Hash{}); have != common.BigToHash(new(big.Int).SetUint64(number)) {
+			t.Fatalf("block #%d: cached contract storage mismatch: have %x", number, have)
+		}
+	}
+	// States beyond the retention window should be unavailable
+	if _, err := chain.HistoricState(chain.GetHeaderByNumber(TriesInMemory - 72)); err == nil {
+		t.Fatalf("state rebuilt beyond the retention window")
+	}
+}
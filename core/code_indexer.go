@@ -0,0 +1,229 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+const (
+	// codeIndexThrottling is the time to wait between processing two consecutive
+	// contract code index sections.
+	codeIndexThrottling = 100 * time.Millisecond
+)
+
+// creation is a contract created by a transaction of the block being traced.
+type creation struct {
+	txIndex  int            // Position of the creating transaction
+	address  common.Address // Address of the created contract
+	codeHash common.Hash    // Hash of the deployed code
+}
+
+// creationFrame is a call frame of the transaction being traced.
+type creationFrame struct {
+	start   int            // Number of creations before the call
+	create  bool           // Whether the call is a contract creation
+	address common.Address // Address of the called or created contract
+}
+
+// creationTracer is a lightweight EVM logger recording the contracts created by
+// the transactions of a block, both by the transactions themselves and by other
+// contracts. The creations of reverted calls are dropped.
+//
+// Transactions are counted as their execution starts, transactions rejected
+// before reaching the EVM are not part of the block and leave no trace.
+type creationTracer struct {
+	creations []creation      // Creations of the block so far
+	txIndex   int             // Position of the running transaction
+	frames    []creationFrame // Running call frames, the outermost one first
+}
+
+// newCreationTracer creates a tracer recording the contract creations of a block.
+func newCreationTracer() *creationTracer {
+	return &creationTracer{txIndex: -1}
+}
+
+// copy creates a deep copy of the tracer, to continue recording a block apart
+// from the original.
+func (t *creationTracer) copy() *creationTracer {
+	cpy := *t
+	cpy.creations = append([]creation{}, t.creations...)
+	cpy.frames = append([]creationFrame{}, t.frames...)
+	return &cpy
+}
+
+func (t *creationTracer) CaptureTxStart(gasLimit uint64) {}
+
+func (t *creationTracer) CaptureTxEnd(restGas uint64) {}
+
+func (t *creationTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.txIndex++
+	t.frames = append(t.frames[:0], creationFrame{start: len(t.creations), create: create, address: to})
+}
+
+func (t *creationTracer) CaptureEnd(output []byte, gasUsed uint64, _ time.Duration, err error) {
+	t.exit(output, err)
+}
+
+func (t *creationTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	t.frames = append(t.frames, creationFrame{start: len(t.creations), create: typ == vm.CREATE || typ == vm.CREATE2, address: to})
+}
+
+func (t *creationTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	t.exit(output, err)
+}
+
+// exit closes the innermost call frame. The contracts created by a reverted call
+// and its subcalls are dropped, the output of a successful creation is the code
+// it deployed.
+func (t *creationTracer) exit(output []byte, err error) {
+	frame := t.frames[len(t.frames)-1]
+	t.frames = t.frames[:len(t.frames)-1]
+
+	if err != nil {
+		t.creations = t.creations[:frame.start]
+		return
+	}
+	if frame.create && len(output) > 0 {
+		t.creations = append(t.creations, creation{
+			txIndex:  t.txIndex,
+			address:  frame.address,
+			codeHash: crypto.Keccak256Hash(output),
+		})
+	}
+}
+
+func (t *creationTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+}
+
+func (t *creationTracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+// writeContractCreations stores the contract creations of the given block. The
+// first block recorded starts the range the creations are known for, unless it
+// is a side block: the canonical blocks up to the head were not recorded.
+func (bc *BlockChain) writeContractCreations(db ethdb.KeyValueWriter, block *types.Block, creations []creation) {
+	if rawdb.ReadContractCreationsStart(bc.db) == nil {
+		start := bc.CurrentBlock().NumberU64() + 1
+		if number := block.NumberU64(); number > start {
+			start = number
+		}
+		rawdb.WriteContractCreationsStart(db, start)
+	}
+	var (
+		txs     = block.Transactions()
+		records = make([]*rawdb.ContractCreation, 0, len(creations))
+	)
+	for _, c := range creations {
+		records = append(records, &rawdb.ContractCreation{
+			TxHash:   txs[c.txIndex].Hash(),
+			Address:  c.address,
+			CodeHash: c.codeHash,
+		})
+	}
+	rawdb.WriteContractCreations(db, block.NumberU64(), block.Hash(), records)
+}
+
+// CodeIndexer implements a core.ChainIndexer, building up an index from contract
+// code hashes to the contracts running them, along with the transactions and the
+// blocks they were deployed in.
+//
+// The index is built from the contract creations recorded while the blocks were
+// imported, covering the contracts created by other contracts too, under the
+// code they were deployed with. Blocks imported without recording them, such as
+// the snap synced ones, are not indexed: the index only covers the blocks from
+// the one tracked by rawdb.ReadContractCreationsStart on.
+type CodeIndexer struct {
+	db ethdb.Database // Database to read the contract creations from and write the index into
+
+	batch   ethdb.Batch                 // Index entries of the section being processed
+	indexed map[codeDeployment]struct{} // Deployments indexed in the section being processed
+}
+
+// codeDeployment identifies a contract deployment in the contract code index.
+type codeDeployment struct {
+	codeHash common.Hash
+	address  common.Address
+}
+
+// NewCodeIndexer returns a chain indexer that generates the contract code index
+// for the canonical chain.
+func NewCodeIndexer(db ethdb.Database, size, confirms uint64) *ChainIndexer {
+	table := rawdb.NewTable(db, string(rawdb.CodeIndexPrefix))
+	return NewChainIndexer(db, table, &CodeIndexer{db: db}, size, confirms, codeIndexThrottling, "codes")
+}
+
+// Reset implements core.ChainIndexerBackend, starting a new contract code index
+// section.
+func (c *CodeIndexer) Reset(ctx context.Context, section uint64, lastSectionHead common.Hash) error {
+	c.batch, c.indexed = c.db.NewBatch(), make(map[codeDeployment]struct{})
+	return nil
+}
+
+// Process implements core.ChainIndexerBackend, adding the contracts created in a
+// block into the index.
+func (c *CodeIndexer) Process(ctx context.Context, header *types.Header) error {
+	number, hash := header.Number.Uint64(), header.Hash()
+
+	// Drop the entries of the block if it was indexed before a reorg
+	if stale := rawdb.ReadCodeBlock(c.db, number); stale != nil {
+		rawdb.DeleteCodeBlock(c.batch, number, stale)
+	}
+	var indexed []*rawdb.ContractCreation
+	for _, creation := range rawdb.ReadContractCreations(c.db, number, hash) {
+		// Retain the first deployment if the address was reused
+		key := codeDeployment{creation.CodeHash, creation.Address}
+		if _, ok := c.indexed[key]; ok {
+			continue
+		}
+		if prev := rawdb.ReadCodeDeployment(c.db, creation.CodeHash, creation.Address); prev != nil && prev.BlockNumber < number {
+			continue
+		}
+		c.indexed[key] = struct{}{}
+
+		rawdb.WriteCodeDeployment(c.batch, creation.CodeHash, &rawdb.CodeDeployment{
+			Address:     creation.Address,
+			TxHash:      creation.TxHash,
+			BlockNumber: number,
+		})
+		indexed = append(indexed, creation)
+	}
+	if len(indexed) > 0 {
+		rawdb.WriteCodeBlock(c.batch, number, indexed)
+	}
+	return nil
+}
+
+// Commit implements core.ChainIndexerBackend, writing out the index entries of
+// the section into the database.
+func (c *CodeIndexer) Commit() error {
+	return c.batch.Write()
+}
+
+// Prune returns an empty error since we don't support pruning here.
+func (c *CodeIndexer) Prune(threshold uint64) error {
+	return nil
+}
@@ -0,0 +1,189 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// deployCode returns the init code deploying the given runtime code.
+func deployCode(code []byte) []byte {
+	return append([]byte{0x60, byte(len(code)), 0x60, 0x0c, 0x60, 0x00, 0x39, 0x60, byte(len(code)), 0x60, 0x00, 0xf3}, code...)
+}
+
+// Tests that the contract code indexer records the contracts created by the
+// transactions and by other contracts under the hash of the code they were
+// deployed with, and that it replaces the entries of reorged blocks.
+func TestCodeIndexer(t *testing.T) {
+	var (
+		// Init codes deploying a single byte of code, and failing
+		deployA = common.FromHex("0x602a60005360016000f3")
+		deployB = common.FromHex("0x602b60005360016000f3")
+		failing = common.FromHex("0xfe")
+
+		// Factory deploying code B on every call, one reverting afterwards and a
+		// contract self-destructing when called
+		create    = append(append([]byte{0x69}, deployB...), 0x60, 0x00, 0x52, 0x60, 0x0a, 0x60, 0x16, 0x60, 0x00, 0xf0)
+		factory   = append(append([]byte{}, create...), 0x00)
+		reverting = append(append([]byte{}, create...), 0x60, 0x00, 0x60, 0x00, 0xfd)
+		destruct  = []byte{0x33, 0xff}
+
+		factoryAddr   = crypto.CreateAddress(testChainAddress, 1)
+		revertingAddr = crypto.CreateAddress(testChainAddress, 2)
+		destructAddr  = crypto.CreateAddress(testChainAddress, 3)
+
+		txs = make(map[common.Hash]*types.Transaction)
+	)
+	type call struct {
+		to   *common.Address
+		data []byte
+	}
+	gspec, blocks := newTestChain(3, func(i int, b *BlockGen) {
+		var calls []call
+		switch i {
+		case 0:
+			calls = []call{{nil, deployA}, {nil, deployCode(factory)}, {nil, deployCode(reverting)}, {nil, deployCode(destruct)}}
+		case 1:
+			calls = []call{{nil, deployA}, {nil, failing}, {&factoryAddr, nil}, {&revertingAddr, nil}, {&destructAddr, nil}}
+		case 2:
+			calls = []call{{&factoryAddr, nil}}
+		}
+		for _, call := range calls {
+			tx := testChainTx(b, call.to, 0, call.data)
+			b.AddTx(tx)
+			txs[tx.Hash()] = tx
+		}
+	})
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		engine = ethash.NewFaker()
+		config = *defaultCacheConfig
+	)
+	gspec.MustCommit(db)
+	config.TrieDirtyDisabled, config.ContractCreations = true, true
+
+	chain, err := NewBlockChain(db, &config, gspec.Config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	index := func(blocks []*types.Block) {
+		indexer := &CodeIndexer{db: db}
+		if err := indexer.Reset(context.Background(), 0, common.Hash{}); err != nil {
+			t.Fatalf("failed to reset indexer: %v", err)
+		}
+		for _, block := range blocks {
+			if err := indexer.Process(context.Background(), block.Header()); err != nil {
+				t.Fatalf("failed to index block %d: %v", block.NumberU64(), err)
+			}
+		}
+		if err := indexer.Commit(); err != nil {
+			t.Fatalf("failed to commit index: %v", err)
+		}
+	}
+	type deployment struct {
+		address common.Address
+		nonce   uint64 // Nonce of the deploying transaction
+		block   uint64
+	}
+	check := func(code []byte, want []deployment) {
+		t.Helper()
+
+		var have []deployment
+		for _, d := range rawdb.ReadCodeDeployments(db, crypto.Keccak256Hash(code)) {
+			tx := txs[d.TxHash]
+			if tx == nil {
+				t.Fatalf("code %x: unknown deploying transaction %x", code, d.TxHash)
+			}
+			have = append(have, deployment{d.Address, tx.Nonce(), d.BlockNumber})
+		}
+		sort.Slice(have, func(i, j int) bool { return have[i].nonce < have[j].nonce })
+		if !reflect.DeepEqual(have, want) {
+			t.Errorf("code %x: deployments mismatch: have %v, want %v", code, have, want)
+		}
+	}
+	index(blocks)
+
+	check([]byte{0x2a}, []deployment{
+		{crypto.CreateAddress(testChainAddress, 0), 0, 1},
+		{crypto.CreateAddress(testChainAddress, 4), 4, 2},
+	})
+	check([]byte{0x2b}, []deployment{
+		{crypto.CreateAddress(factoryAddr, 1), 6, 2},
+		{crypto.CreateAddress(factoryAddr, 2), 9, 3},
+	})
+	check(destruct, []deployment{{destructAddr, 3, 1}})
+
+	// The failed deployment left no code behind
+	check(nil, nil)
+
+	// Reorg the last block away, its deployments must be dropped on reindexing
+	fork, _ := GenerateChain(gspec.Config, blocks[1], engine, db, 2, nil)
+	if _, err := chain.InsertChain(fork); err != nil {
+		t.Fatalf("failed to insert fork: %v", err)
+	}
+	index(fork)
+
+	check([]byte{0x2b}, []deployment{{crypto.CreateAddress(factoryAddr, 1), 6, 2}})
+	check([]byte{0x2a}, []deployment{
+		{crypto.CreateAddress(testChainAddress, 0), 0, 1},
+		{crypto.CreateAddress(testChainAddress, 4), 4, 2},
+	})
+	if start := rawdb.ReadContractCreationsStart(db); start == nil || *start != 1 {
+		t.Fatalf("recording start mismatch: have %v, want 1", start)
+	}
+	// Import blocks without recording, the known range restarts after them
+	chain.Stop()
+	config.ContractCreations = false
+	if chain, err = NewBlockChain(db, &config, gspec.Config, engine, vm.Config{}, nil, nil); err != nil {
+		t.Fatalf("failed to reopen chain: %v", err)
+	}
+	if start := rawdb.ReadContractCreationsStart(db); start != nil {
+		t.Fatalf("recording start retained without recording: %d", *start)
+	}
+	more, _ := GenerateChain(gspec.Config, fork[len(fork)-1], engine, db, 4, nil)
+	if _, err := chain.InsertChain(more[:2]); err != nil {
+		t.Fatalf("failed to insert unrecorded blocks: %v", err)
+	}
+	chain.Stop()
+	config.ContractCreations = true
+	if chain, err = NewBlockChain(db, &config, gspec.Config, engine, vm.Config{}, nil, nil); err != nil {
+		t.Fatalf("failed to reopen chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(more[2:]); err != nil {
+		t.Fatalf("failed to insert recorded blocks: %v", err)
+	}
+	if start := rawdb.ReadContractCreationsStart(db); start == nil || *start != 7 {
+		t.Fatalf("recording start mismatch: have %v, want 7", start)
+	}
+}
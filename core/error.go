@@ -32,6 +32,18 @@ var (
 	// ErrNoGenesis is returned when there is no Genesis Block.
 	ErrNoGenesis = errors.New("genesis not found in chain")
 
+	// ErrCheckpointMismatch is returned if a block to import conflicts with a
+	// trusted checkpoint at the same height.
+	ErrCheckpointMismatch = errors.New("block conflicts with checkpoint")
+
+	// ErrReorgTooDeep is reported if switching to a heavier fork would drop more
+	// canonical blocks than the configured maximum reorg depth allows.
+	ErrReorgTooDeep = errors.New("reorg exceeds maximum depth")
+
+	// ErrHistoryPruned is returned when the body or receipts of a block have been
+	// dropped from the ancient store by history pruning.
+	ErrHistoryPruned = errors.New("pruned history unavailable")
+
 	errSideChainReceipts = errors.New("side blocks can't be accepted as ancient chain data")
 )
 
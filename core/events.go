@@ -41,3 +41,24 @@ type ChainSideEvent struct {
 }
 
 type ChainHeadEvent struct{ Block *types.Block }
+
+// ReorgEvent is posted when the canonical chain is switched over to a side
+// chain. Block hashes are ordered from the common ancestor upwards. Dropped
+// transactions are those of the old chain which are not included in the new
+// one; they are either re-injected into the transaction pool or lost.
+type ReorgEvent struct {
+	CommonAncestor *types.Header
+	OldChain       []common.Hash
+	NewChain       []common.Hash
+	DroppedTxs     []common.Hash
+}
+
+// ReorgRefusedEvent is posted when the chain refuses to switch over to a fork,
+// either because the reorg would exceed the maximum reorg depth or because the
+// fork conflicts with a trusted checkpoint.
+type ReorgRefusedEvent struct {
+	Head   *types.Header // Canonical head that was kept
+	Block  *types.Header // Block of the refused fork
+	Depth  uint64        // Number of canonical blocks the fork would have dropped
+	Reason error
+}
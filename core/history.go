@@ -0,0 +1,140 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// historyPruneBatch is the minimum number of blocks the history tail is moved
+// forward by at once, to avoid rewriting the freezer metadata on every block.
+const historyPruneBatch = 1024
+
+// historyPruneRetry is the time to wait before retrying a failed history prune.
+const historyPruneRetry = time.Minute
+
+// PruneHistory drops the bodies and receipts of the blocks more than keep blocks
+// below the given head from the ancient store, returning the new history tail.
+// Only frozen blocks are pruned, the headers of all blocks are retained. The
+// value transfers and contract creations recorded for the pruned blocks are
+// dropped along with them.
+func PruneHistory(db ethdb.Database, head uint64, keep uint64) (uint64, error) {
+	tail, err := db.Tail()
+	if err != nil {
+		return 0, err
+	}
+	frozen, err := db.Ancients()
+	if err != nil {
+		return 0, err
+	}
+	if head+1 <= keep {
+		return tail, nil
+	}
+	target := head + 1 - keep
+	if target > frozen {
+		target = frozen
+	}
+	if target > tail {
+		start := time.Now()
+		if err := db.TruncateTail(target); err != nil {
+			return tail, err
+		}
+		log.Info("Pruned chain history", "from", tail, "to", target, "elapsed", common.PrettyDuration(time.Since(start)))
+		tail = target
+	}
+	// Drop the recordings up to the tail, including the ones left behind by an
+	// earlier failure or recorded after their block was pruned
+	start := time.Now()
+	transfers, err := rawdb.PruneValueTransfers(db, tail)
+	if err != nil {
+		return tail, err
+	}
+	creations, err := rawdb.PruneContractCreations(db, tail)
+	if err != nil {
+		return tail, err
+	}
+	if transfers > 0 || creations > 0 {
+		log.Debug("Pruned block recordings", "transfers", transfers, "creations", creations, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	return tail, nil
+}
+
+// HistoryTail returns the number of the first block whose body and receipts are
+// still retained in the database.
+func (bc *BlockChain) HistoryTail() uint64 {
+	tail, err := bc.db.Tail()
+	if err != nil {
+		return 0
+	}
+	return tail
+}
+
+// HistoryPruned reports whether the body and receipts of the block with the
+// given number have been dropped by history pruning.
+func (bc *BlockChain) HistoryPruned(number uint64) bool {
+	return number < bc.HistoryTail()
+}
+
+// maintainHistory moves the history tail forward as the chain progresses,
+// retaining the bodies and receipts of the configured number of recent blocks.
+func (bc *BlockChain) maintainHistory() {
+	defer bc.wg.Done()
+
+	var (
+		keep   = bc.cacheConfig.HistoryKeep
+		headCh = make(chan ChainHeadEvent, 1) // Buffered to avoid locking up the event feed
+	)
+	sub := bc.SubscribeChainHeadEvent(headCh)
+	if sub == nil {
+		return
+	}
+	defer sub.Unsubscribe()
+
+	// Prune right away, catching up with any configuration change. Failures are
+	// retried on a later head, once the retry delay has passed.
+	var failed time.Time
+	tail, err := PruneHistory(bc.db, bc.CurrentBlock().NumberU64(), keep)
+	if err != nil {
+		log.Error("Failed to prune chain history", "err", err)
+		failed = time.Now()
+	}
+	for {
+		select {
+		case head := <-headCh:
+			number := head.Block.NumberU64()
+			if number+1 < keep+tail+historyPruneBatch && failed.IsZero() {
+				continue
+			}
+			if time.Since(failed) < historyPruneRetry {
+				continue
+			}
+			if tail, err = PruneHistory(bc.db, number, keep); err != nil {
+				log.Error("Failed to prune chain history", "err", err)
+				failed = time.Now()
+				continue
+			}
+			failed = time.Time{}
+		case <-bc.quit:
+			return
+		}
+	}
+}
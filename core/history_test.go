@@ -0,0 +1,117 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// Tests that pruning the chain history drops the frozen bodies and receipts
+// only, along with the value transfers and contract creations recorded for them,
+// and that the chain can be reopened afterwards.
+func TestHistoryPruning(t *testing.T) {
+	var (
+		gspec, blocks = newTestChain(64, nil)
+		engine        = ethash.NewFaker()
+	)
+	db, err := rawdb.NewDatabaseWithFreezer(rawdb.NewMemoryDatabase(), t.TempDir(), "", false)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+	gspec.MustCommit(db)
+
+	config := *defaultCacheConfig
+	config.ValueTransfers, config.ContractCreations = true, true
+	chain, err := NewBlockChain(db, &config, gspec.Config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	chain.Stop()
+
+	// Freeze all but the last 8 blocks and prune all but the last 16 ones, only
+	// the frozen blocks are affected
+	db.(interface{ Freeze(threshold uint64) error }).Freeze(8)
+	frozen, _ := db.Ancients()
+	if frozen == 0 {
+		t.Fatalf("no blocks frozen")
+	}
+	tail, err := PruneHistory(db, uint64(len(blocks)), 16)
+	if err != nil {
+		t.Fatalf("failed to prune history: %v", err)
+	}
+	if want := uint64(len(blocks) - 15); tail != want {
+		t.Fatalf("history tail mismatch: have %d, want %d", tail, want)
+	}
+	if _, err := PruneHistory(db, uint64(len(blocks)), 1); err != nil {
+		t.Fatalf("failed to prune history: %v", err)
+	}
+	if tail, _ = db.Tail(); tail != frozen {
+		t.Fatalf("history tail not capped at frozen blocks: have %d, want %d", tail, frozen)
+	}
+	chain, err = NewBlockChain(db, nil, gspec.Config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to reopen chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if chain.HistoryTail() != tail {
+		t.Fatalf("chain history tail mismatch: have %d, want %d", chain.HistoryTail(), tail)
+	}
+	for _, block := range blocks {
+		number := block.NumberU64()
+		if chain.GetHeaderByNumber(number) == nil {
+			t.Fatalf("header #%d missing", number)
+		}
+		pruned := number < tail
+		if chain.HistoryPruned(number) != pruned {
+			t.Fatalf("block #%d pruned status mismatch: want %v", number, pruned)
+		}
+		if have := chain.GetBlockByNumber(number) != nil; have == pruned {
+			t.Fatalf("block #%d body presence mismatch: have %v, pruned %v", number, have, pruned)
+		}
+		if have := chain.GetReceiptsByHash(block.Hash()) != nil; have == pruned {
+			t.Fatalf("block #%d receipts presence mismatch: have %v, pruned %v", number, have, pruned)
+		}
+		if have := rawdb.ReadValueTransfers(db, number, block.Hash()) != nil; have == pruned {
+			t.Fatalf("block #%d value transfers presence mismatch: have %v, pruned %v", number, have, pruned)
+		}
+		if have := rawdb.ReadContractCreations(db, number, block.Hash()) != nil; have == pruned {
+			t.Fatalf("block #%d contract creations presence mismatch: have %v, pruned %v", number, have, pruned)
+		}
+		var indexed bool
+		rawdb.IterateAccountTransfers(db, testChainAddress, number, number, func(uint64, common.Hash, []uint) bool {
+			indexed = true
+			return false
+		})
+		if indexed == pruned {
+			t.Fatalf("block #%d value transfer index presence mismatch: have %v, pruned %v", number, indexed, pruned)
+		}
+	}
+	// Pruning with a larger window is a noop
+	if again, err := PruneHistory(db, uint64(len(blocks)), 32); err != nil || again != tail {
+		t.Fatalf("repeated pruning moved tail: have %d (%v), want %d", again, err, tail)
+	}
+}
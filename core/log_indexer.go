@@ -0,0 +1,159 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// logIndexThrottling is the time to wait between processing two consecutive
+	// log index sections.
+	logIndexThrottling = 100 * time.Millisecond
+)
+
+// logTopic is a log topic at a given position.
+type logTopic struct {
+	position int
+	topic    common.Hash
+}
+
+// LogIndexer implements a core.ChainIndexer, building up an inverted index from
+// log addresses and topics to the blocks, and the positions within the blocks,
+// of the logs carrying them. Unlike the bloom bits, the index points to the
+// matching logs exactly.
+//
+// Sections rolled back by reorgs are not deleted, the entries of blocks which
+// are no longer canonical are overwritten or left dangling until the section
+// is processed again. Readers must check the logs the index points to.
+type LogIndexer struct {
+	db    ethdb.Database // Database to read the receipts from and write the index into
+	batch ethdb.Batch    // Index entries of the section being processed
+}
+
+// NewLogIndexer returns a chain indexer that generates the log index for the
+// canonical chain. It is meant to be run as a child of the bloom indexer.
+func NewLogIndexer(db ethdb.Database, size, confirms uint64) *ChainIndexer {
+	table := rawdb.NewTable(db, string(rawdb.LogIndexPrefix))
+	return NewChainIndexer(db, table, &LogIndexer{db: db}, size, confirms, logIndexThrottling, "logs")
+}
+
+// Reset implements core.ChainIndexerBackend, starting a new log index section.
+func (l *LogIndexer) Reset(ctx context.Context, section uint64, lastSectionHead common.Hash) error {
+	l.batch = l.db.NewBatch()
+	return nil
+}
+
+// Process implements core.ChainIndexerBackend, adding the logs of a block into
+// the index.
+func (l *LogIndexer) Process(ctx context.Context, header *types.Header) error {
+	number := header.Number.Uint64()
+
+	// Receipts are missing if the block history was pruned, there is nothing to
+	// index in that case
+	var (
+		addresses = make(map[common.Address][]uint)
+		topics    = make(map[logTopic][]uint)
+		position  uint
+	)
+	for _, receipt := range rawdb.ReadRawReceipts(l.db, header.Hash(), number) {
+		for _, log := range receipt.Logs {
+			addresses[log.Address] = append(addresses[log.Address], position)
+			for i, topic := range log.Topics {
+				key := logTopic{position: i, topic: topic}
+				topics[key] = append(topics[key], position)
+			}
+			position++
+		}
+	}
+	for address, positions := range addresses {
+		rawdb.WriteLogAddressIndex(l.batch, address, number, positions)
+	}
+	for key, positions := range topics {
+		rawdb.WriteLogTopicIndex(l.batch, key.position, key.topic, number, positions)
+	}
+	// Sections of busy chains don't fit into memory, flush them as they grow.
+	// Partially written sections are harmless, they are not marked valid.
+	if l.batch.ValueSize() >= ethdb.IdealBatchSize {
+		if err := l.batch.Write(); err != nil {
+			return err
+		}
+		l.batch.Reset()
+	}
+	return nil
+}
+
+// Commit implements core.ChainIndexerBackend, writing out the index entries of
+// the section into the database.
+func (l *LogIndexer) Commit() error {
+	return l.batch.Write()
+}
+
+// Prune returns an empty error since we don't support pruning here.
+func (l *LogIndexer) Prune(threshold uint64) error {
+	return nil
+}
+
+// RebuildLogIndex drops the log index and rebuilds it offline for all the
+// sections of the canonical chain confirmed by the given head block.
+func RebuildLogIndex(db ethdb.Database, head uint64, size, confirms uint64) error {
+	start := time.Now()
+	deleted, err := rawdb.DeleteLogIndex(db)
+	if err != nil {
+		return err
+	}
+	log.Info("Deleted log index", "entries", deleted, "elapsed", common.PrettyDuration(time.Since(start)))
+
+	indexer := NewLogIndexer(db, size, confirms)
+	defer indexer.Close()
+
+	indexer.lock.Lock()
+	defer indexer.lock.Unlock()
+	indexer.setValidSections(0)
+
+	var sections uint64
+	if head+1 >= confirms {
+		sections = (head + 1 - confirms) / size
+	}
+	var (
+		lastHead common.Hash
+		logged   = time.Now()
+	)
+	for section := uint64(0); section < sections; section++ {
+		head, err := indexer.processSection(section, lastHead)
+		if err != nil {
+			return err
+		}
+		indexer.setSectionHead(section, head)
+		indexer.setValidSections(section + 1)
+		lastHead = head
+
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Rebuilding log index", "section", section+1, "sections", sections, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	log.Info("Rebuilt log index", "sections", sections, "blocks", sections*size, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that the log index records the positions of the logs of each address
+// and topic, and that rebuilding it replaces the previous entries.
+func TestRebuildLogIndex(t *testing.T) {
+	var (
+		db    = rawdb.NewMemoryDatabase()
+		gspec = &Genesis{Config: params.TestChainConfig, BaseFee: big.NewInt(params.InitialBaseFee)}
+
+		addr1 = common.HexToAddress("0x1111")
+		addr2 = common.HexToAddress("0x2222")
+		hash1 = common.HexToHash("0x01")
+		hash2 = common.HexToHash("0x02")
+	)
+	genesis := gspec.MustCommit(db)
+	blocks, receipts := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 6, func(i int, b *BlockGen) {
+		if i%2 == 1 {
+			return
+		}
+		receipt := types.NewReceipt(nil, false, 0)
+		receipt.Logs = []*types.Log{
+			{Address: addr1, Topics: []common.Hash{hash1, hash2}},
+			{Address: addr2, Topics: []common.Hash{hash2}},
+			{Address: addr1, Topics: []common.Hash{hash2, hash1}},
+		}
+		b.AddUncheckedReceipt(receipt)
+		b.AddUncheckedTx(types.NewTransaction(uint64(i), common.Address{}, new(big.Int), 0, b.BaseFee(), nil))
+	})
+	for i, block := range blocks {
+		rawdb.WriteBlock(db, block)
+		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), receipts[i])
+	}
+	// Leave a stale entry behind, it must be dropped by the rebuild
+	rawdb.WriteLogAddressIndex(db, addr2, 2, []uint{0})
+
+	// Index the first two sections of 3 blocks, the last one is not confirmed
+	if err := RebuildLogIndex(db, 6, 3, 1); err != nil {
+		t.Fatalf("failed to rebuild log index: %v", err)
+	}
+	tests := []struct {
+		have []rawdb.LogIndexEntry
+		want []rawdb.LogIndexEntry
+	}{
+		{rawdb.ReadLogAddressIndex(db, addr1, 0, 10), []rawdb.LogIndexEntry{{Number: 1, Positions: []uint{0, 2}}, {Number: 3, Positions: []uint{0, 2}}, {Number: 5, Positions: []uint{0, 2}}}},
+		{rawdb.ReadLogAddressIndex(db, addr2, 2, 4), []rawdb.LogIndexEntry{{Number: 3, Positions: []uint{1}}}},
+		{rawdb.ReadLogTopicIndex(db, 0, hash2, 0, 10), []rawdb.LogIndexEntry{{Number: 1, Positions: []uint{1, 2}}, {Number: 3, Positions: []uint{1, 2}}, {Number: 5, Positions: []uint{1, 2}}}},
+		{rawdb.ReadLogTopicIndex(db, 1, hash1, 4, 5), []rawdb.LogIndexEntry{{Number: 5, Positions: []uint{2}}}},
+		{rawdb.ReadLogTopicIndex(db, 1, hash2, 0, 10), []rawdb.LogIndexEntry{{Number: 1, Positions: []uint{0}}, {Number: 3, Positions: []uint{0}}, {Number: 5, Positions: []uint{0}}}},
+	}
+	for i, tt := range tests {
+		if !reflect.DeepEqual(tt.have, tt.want) {
+			t.Errorf("test %d: index entries mismatch: have %v, want %v", i, tt.have, tt.want)
+		}
+	}
+	indexer := NewLogIndexer(db, 3, 1)
+	defer indexer.Close()
+
+	if sections, _, head := indexer.Sections(); sections != 2 || head != blocks[4].Hash() {
+		t.Errorf("indexed sections mismatch: have %d (head %x), want 2 (head %x)", sections, head, blocks[4].Hash())
+	}
+}
@@ -18,6 +18,7 @@ package rawdb
 
 import (
 	"bytes"
+	"encoding/binary"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -175,3 +176,615 @@ func DeleteBloombits(db ethdb.Database, bit uint, from uint64, to uint64) {
 		log.Crit("Failed to delete bloom bits", "err", it.Error())
 	}
 }
+
+// CodeDeployment is a contract deployed by a transaction, as recorded in the
+// contract code index.
+type CodeDeployment struct {
+	Address     common.Address `rlp:"-"` // Address of the contract, part of the key
+	TxHash      common.Hash    // Hash of the deploying transaction
+	BlockNumber uint64         // Number of the block the contract was deployed in
+}
+
+// ReadCodeDeployment retrieves the deployment of the contract at the given address
+// running the code with the given hash from the contract code index.
+func ReadCodeDeployment(db ethdb.KeyValueReader, codeHash common.Hash, address common.Address) *CodeDeployment {
+	data, _ := db.Get(codeDeploymentKey(codeHash, address))
+	if len(data) == 0 {
+		return nil
+	}
+	deployment := new(CodeDeployment)
+	if err := rlp.DecodeBytes(data, deployment); err != nil {
+		log.Error("Invalid code deployment entry", "hash", codeHash, "address", address, "err", err)
+		return nil
+	}
+	deployment.Address = address
+	return deployment
+}
+
+// ReadCodeDeployments retrieves the deployments of all the contracts running the
+// code with the given hash from the contract code index.
+func ReadCodeDeployments(db ethdb.Iteratee, codeHash common.Hash) []*CodeDeployment {
+	prefix := codeDeploymentKey(codeHash, common.Address{})[:len(codeDeploymentPrefix)+common.HashLength]
+	it := db.NewIterator(prefix, nil)
+	defer it.Release()
+
+	var deployments []*CodeDeployment
+	for it.Next() {
+		if len(it.Key()) != len(prefix)+common.AddressLength {
+			continue
+		}
+		deployment := new(CodeDeployment)
+		if err := rlp.DecodeBytes(it.Value(), deployment); err != nil {
+			log.Error("Invalid code deployment entry", "hash", codeHash, "err", err)
+			continue
+		}
+		deployment.Address = common.BytesToAddress(it.Key()[len(prefix):])
+		deployments = append(deployments, deployment)
+	}
+	return deployments
+}
+
+// WriteCodeDeployment stores the deployment of a contract running the code with
+// the given hash into the contract code index.
+func WriteCodeDeployment(db ethdb.KeyValueWriter, codeHash common.Hash, deployment *CodeDeployment) {
+	data, err := rlp.EncodeToBytes(deployment)
+	if err != nil {
+		log.Crit("Failed to encode code deployment", "err", err)
+	}
+	if err := db.Put(codeDeploymentKey(codeHash, deployment.Address), data); err != nil {
+		log.Crit("Failed to store code deployment", "err", err)
+	}
+}
+
+// ReadCodeBlock retrieves the contract deployments of a block indexed in the
+// contract code index.
+func ReadCodeBlock(db ethdb.KeyValueReader, number uint64) []*ContractCreation {
+	data, _ := db.Get(codeBlockKey(number))
+	if len(data) == 0 {
+		return nil
+	}
+	var creations []*ContractCreation
+	if err := rlp.DecodeBytes(data, &creations); err != nil {
+		log.Error("Invalid code index block entry", "number", number, "err", err)
+		return nil
+	}
+	return creations
+}
+
+// WriteCodeBlock stores the contract deployments of a block indexed in the
+// contract code index, allowing the entries of the block to be removed again.
+func WriteCodeBlock(db ethdb.KeyValueWriter, number uint64, creations []*ContractCreation) {
+	data, err := rlp.EncodeToBytes(creations)
+	if err != nil {
+		log.Crit("Failed to encode code index block entry", "err", err)
+	}
+	if err := db.Put(codeBlockKey(number), data); err != nil {
+		log.Crit("Failed to store code index block entry", "err", err)
+	}
+}
+
+// DeleteCodeBlock removes the contract code index entries of a block, given the
+// contract deployments they were indexed for.
+func DeleteCodeBlock(db ethdb.KeyValueWriter, number uint64, creations []*ContractCreation) {
+	for _, creation := range creations {
+		if err := db.Delete(codeDeploymentKey(creation.CodeHash, creation.Address)); err != nil {
+			log.Crit("Failed to delete code deployment", "err", err)
+		}
+	}
+	if err := db.Delete(codeBlockKey(number)); err != nil {
+		log.Crit("Failed to delete code index block entry", "err", err)
+	}
+}
+
+// ContractCreation is a contract created within a block, as recorded while the
+// block was processed. Unlike the receipts, it covers the contracts created by
+// other contracts too.
+type ContractCreation struct {
+	TxHash   common.Hash    // Hash of the transaction creating the contract
+	Address  common.Address // Address of the created contract
+	CodeHash common.Hash    // Hash of the code deployed at creation
+}
+
+// ReadContractCreations retrieves the contracts created within the given block,
+// or nil if they were not recorded.
+func ReadContractCreations(db ethdb.KeyValueReader, number uint64, hash common.Hash) []*ContractCreation {
+	data, _ := db.Get(creationsKey(number, hash))
+	if len(data) == 0 {
+		return nil
+	}
+	creations := []*ContractCreation{}
+	if err := rlp.DecodeBytes(data, &creations); err != nil {
+		log.Error("Invalid contract creations", "number", number, "hash", hash, "err", err)
+		return nil
+	}
+	return creations
+}
+
+// WriteContractCreations stores the contracts created within the given block.
+func WriteContractCreations(db ethdb.KeyValueWriter, number uint64, hash common.Hash, creations []*ContractCreation) {
+	data, err := rlp.EncodeToBytes(creations)
+	if err != nil {
+		log.Crit("Failed to encode contract creations", "err", err)
+	}
+	if err := db.Put(creationsKey(number, hash), data); err != nil {
+		log.Crit("Failed to store contract creations", "err", err)
+	}
+}
+
+// ReadContractCreationsStart retrieves the number of the first block whose
+// contract creations were recorded, or nil if none were.
+func ReadContractCreationsStart(db ethdb.KeyValueReader) *uint64 {
+	data, _ := db.Get(contractCreationsStartKey)
+	if len(data) != 8 {
+		return nil
+	}
+	number := binary.BigEndian.Uint64(data)
+	return &number
+}
+
+// WriteContractCreationsStart stores the number of the first block whose
+// contract creations were recorded.
+func WriteContractCreationsStart(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Put(contractCreationsStartKey, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store contract creations start", "err", err)
+	}
+}
+
+// DeleteContractCreationsStart deletes the number of the first block whose
+// contract creations were recorded.
+func DeleteContractCreationsStart(db ethdb.KeyValueWriter) {
+	if err := db.Delete(contractCreationsStartKey); err != nil {
+		log.Crit("Failed to remove contract creations start", "err", err)
+	}
+}
+
+// PruneContractCreations removes the contract creations of all the blocks below
+// the given number, returning the number of blocks pruned.
+func PruneContractCreations(db ethdb.Database, limit uint64) (int, error) {
+	it := db.NewIterator(creationsPrefix, nil)
+	defer it.Release()
+
+	var (
+		batch  = db.NewBatch()
+		pruned int
+	)
+	for it.Next() {
+		if len(it.Key()) != len(creationsPrefix)+8+common.HashLength {
+			continue
+		}
+		if binary.BigEndian.Uint64(it.Key()[len(creationsPrefix):]) >= limit {
+			break
+		}
+		if err := batch.Delete(it.Key()); err != nil {
+			return pruned, err
+		}
+		pruned++
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return pruned, err
+			}
+			batch.Reset()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return pruned, err
+	}
+	return pruned, batch.Write()
+}
+
+// LogIndexEntry lists the logs of a block emitted by an address or carrying a
+// topic, as recorded in the log index.
+type LogIndexEntry struct {
+	Number    uint64 // Number of the block
+	Positions []uint // Positions of the logs within the block, in ascending order
+}
+
+// ReadLogAddressIndex retrieves the log index entries of an address within the
+// given block range, in ascending block order.
+func ReadLogAddressIndex(db ethdb.Iteratee, address common.Address, from uint64, to uint64) []LogIndexEntry {
+	prefix := logAddressIndexKey(address, 0)
+	return readLogIndex(db, prefix[:len(prefix)-8], from, to)
+}
+
+// ReadLogTopicIndex retrieves the log index entries of a topic at the given
+// position within the given block range, in ascending block order.
+func ReadLogTopicIndex(db ethdb.Iteratee, position int, topic common.Hash, from uint64, to uint64) []LogIndexEntry {
+	prefix := logTopicIndexKey(position, topic, 0)
+	return readLogIndex(db, prefix[:len(prefix)-8], from, to)
+}
+
+// readLogIndex retrieves the log index entries under a key prefix within the
+// given block range.
+func readLogIndex(db ethdb.Iteratee, prefix []byte, from uint64, to uint64) []LogIndexEntry {
+	it := db.NewIterator(prefix, encodeBlockNumber(from))
+	defer it.Release()
+
+	var entries []LogIndexEntry
+	for it.Next() {
+		if len(it.Key()) != len(prefix)+8 {
+			continue
+		}
+		number := binary.BigEndian.Uint64(it.Key()[len(prefix):])
+		if number > to {
+			break
+		}
+		var positions []uint
+		if err := rlp.DecodeBytes(it.Value(), &positions); err != nil {
+			log.Error("Invalid log index entry", "number", number, "err", err)
+			continue
+		}
+		entries = append(entries, LogIndexEntry{Number: number, Positions: positions})
+	}
+	return entries
+}
+
+// WriteLogAddressIndex stores the positions of the logs emitted by an address
+// within a block into the log index.
+func WriteLogAddressIndex(db ethdb.KeyValueWriter, address common.Address, number uint64, positions []uint) {
+	writeLogIndex(db, logAddressIndexKey(address, number), positions)
+}
+
+// WriteLogTopicIndex stores the positions of the logs carrying a topic at the
+// given position within a block into the log index.
+func WriteLogTopicIndex(db ethdb.KeyValueWriter, position int, topic common.Hash, number uint64, positions []uint) {
+	writeLogIndex(db, logTopicIndexKey(position, topic, number), positions)
+}
+
+func writeLogIndex(db ethdb.KeyValueWriter, key []byte, positions []uint) {
+	data, err := rlp.EncodeToBytes(positions)
+	if err != nil {
+		log.Crit("Failed to encode log index entry", "err", err)
+	}
+	if err := db.Put(key, data); err != nil {
+		log.Crit("Failed to store log index entry", "err", err)
+	}
+}
+
+// DeleteLogIndex removes all entries of the log index, returning the number of
+// entries deleted.
+func DeleteLogIndex(db ethdb.Database) (int, error) {
+	var (
+		batch   = db.NewBatch()
+		deleted int
+	)
+	for _, index := range []struct {
+		prefix []byte
+		keylen int
+	}{
+		{logAddressIndexPrefix, len(logAddressIndexPrefix) + common.AddressLength + 8},
+		{logTopicIndexPrefix, len(logTopicIndexPrefix) + 1 + common.HashLength + 8},
+	} {
+		it := db.NewIterator(index.prefix, nil)
+		for it.Next() {
+			if len(it.Key()) != index.keylen {
+				continue
+			}
+			if err := batch.Delete(it.Key()); err != nil {
+				it.Release()
+				return deleted, err
+			}
+			deleted++
+			if batch.ValueSize() >= ethdb.IdealBatchSize {
+				if err := batch.Write(); err != nil {
+					it.Release()
+					return deleted, err
+				}
+				batch.Reset()
+			}
+		}
+		err := it.Error()
+		it.Release()
+		if err != nil {
+			return deleted, err
+		}
+	}
+	return deleted, batch.Write()
+}
+
+// AccountTxsEntry lists the transactions of a block sent or received by an
+// account, as recorded in the account history index.
+type AccountTxsEntry struct {
+	Number   uint64 `rlp:"-"` // Number of the block, part of the key
+	Sent     []uint // Positions of the transactions sent by the account, in ascending order
+	Received []uint // Positions of the transactions received by the account, in ascending order
+}
+
+// IterateAccountTxs calls fn with the account history index entries of an
+// address within the given block range, in ascending block order, until fn
+// returns false.
+func IterateAccountTxs(db ethdb.Iteratee, address common.Address, from uint64, to uint64, fn func(*AccountTxsEntry) bool) {
+	prefix := accountTxsKey(address, 0)
+	prefix = prefix[:len(prefix)-8]
+
+	it := db.NewIterator(prefix, encodeBlockNumber(from))
+	defer it.Release()
+
+	for it.Next() {
+		if len(it.Key()) != len(prefix)+8 {
+			continue
+		}
+		number := binary.BigEndian.Uint64(it.Key()[len(prefix):])
+		if number > to {
+			return
+		}
+		entry := new(AccountTxsEntry)
+		if err := rlp.DecodeBytes(it.Value(), entry); err != nil {
+			log.Error("Invalid account history entry", "address", address, "number", number, "err", err)
+			continue
+		}
+		entry.Number = number
+		if !fn(entry) {
+			return
+		}
+	}
+}
+
+// WriteAccountTxs stores the transactions of a block sent or received by an
+// account into the account history index.
+func WriteAccountTxs(db ethdb.KeyValueWriter, address common.Address, entry *AccountTxsEntry) {
+	data, err := rlp.EncodeToBytes(entry)
+	if err != nil {
+		log.Crit("Failed to encode account history entry", "err", err)
+	}
+	if err := db.Put(accountTxsKey(address, entry.Number), data); err != nil {
+		log.Crit("Failed to store account history entry", "err", err)
+	}
+}
+
+// ReadAccountTxsBlock retrieves the accounts the transactions of a block were
+// indexed for in the account history index.
+func ReadAccountTxsBlock(db ethdb.KeyValueReader, number uint64) []common.Address {
+	data, _ := db.Get(accountTxsBlockKey(number))
+	if len(data) == 0 {
+		return nil
+	}
+	var addresses []common.Address
+	if err := rlp.DecodeBytes(data, &addresses); err != nil {
+		log.Error("Invalid account history block entry", "number", number, "err", err)
+		return nil
+	}
+	return addresses
+}
+
+// WriteAccountTxsBlock stores the accounts the transactions of a block were
+// indexed for, allowing the entries of the block to be removed again.
+func WriteAccountTxsBlock(db ethdb.KeyValueWriter, number uint64, addresses []common.Address) {
+	data, err := rlp.EncodeToBytes(addresses)
+	if err != nil {
+		log.Crit("Failed to encode account history block entry", "err", err)
+	}
+	if err := db.Put(accountTxsBlockKey(number), data); err != nil {
+		log.Crit("Failed to store account history block entry", "err", err)
+	}
+}
+
+// DeleteAccountTxsBlock removes the account history index entries of a block,
+// given the accounts they were indexed for.
+func DeleteAccountTxsBlock(db ethdb.KeyValueWriter, number uint64, addresses []common.Address) {
+	for _, address := range addresses {
+		if err := db.Delete(accountTxsKey(address, number)); err != nil {
+			log.Crit("Failed to delete account history entry", "err", err)
+		}
+	}
+	if err := db.Delete(accountTxsBlockKey(number)); err != nil {
+		log.Crit("Failed to delete account history block entry", "err", err)
+	}
+}
+
+// PruneAccountTxs removes the account history index entries of all the blocks
+// below the given number, returning the number of blocks pruned.
+func PruneAccountTxs(db ethdb.Database, limit uint64) (int, error) {
+	it := db.NewIterator(accountTxsBlockPrefix, nil)
+	defer it.Release()
+
+	var (
+		batch  = db.NewBatch()
+		pruned int
+	)
+	for it.Next() {
+		if len(it.Key()) != len(accountTxsBlockPrefix)+8 {
+			continue
+		}
+		number := binary.BigEndian.Uint64(it.Key()[len(accountTxsBlockPrefix):])
+		if number >= limit {
+			break
+		}
+		var addresses []common.Address
+		if err := rlp.DecodeBytes(it.Value(), &addresses); err != nil {
+			return pruned, err
+		}
+		DeleteAccountTxsBlock(batch, number, addresses)
+		pruned++
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return pruned, err
+			}
+			batch.Reset()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return pruned, err
+	}
+	return pruned, batch.Write()
+}
+
+// Kinds of the value transfers recorded by the value transfer index.
+const (
+	TransferTransaction  uint8 = iota // Value sent by a transaction
+	TransferCall                      // Value sent by an internal call
+	TransferCreate                    // Value endowed to a contract created by an internal call
+	TransferSelfDestruct              // Balance sent to the beneficiary of a self-destructed contract
+	TransferMinerReward               // Block reward credited to the miner
+	TransferUncleReward               // Block reward credited to the miner of an uncle
+	TransferFundReward                // Block reward share credited to a fund
+	TransferFeeTip                    // Transaction fee tip paid by the sender to the miner
+	TransferFeeBurn                   // Transaction base fee burnt from the sender
+)
+
+// ValueTransfer is a movement of value within a block, as recorded in the value
+// transfer index. Rewards are minted, they have no sender, and burnt fees have
+// no recipient. The fee debited from the sender of a transaction is the sum of
+// its tip and its burnt base fee.
+type ValueTransfer struct {
+	Kind    uint8          // Kind of the transfer
+	TxIndex uint           // Position of the transaction making the transfer, zero for rewards
+	From    common.Address // Sender of the value, zero for rewards
+	To      common.Address // Recipient of the value, zero for burnt fees
+	Value   *big.Int       // Amount of value transferred
+}
+
+// HasSender reports whether the value is taken from an account, i.e. whether
+// the transfer is not a minted reward.
+func (t *ValueTransfer) HasSender() bool {
+	return t.Kind < TransferMinerReward || t.Kind > TransferFundReward
+}
+
+// HasRecipient reports whether the value is credited to an account, i.e. whether
+// the transfer is not a burnt fee.
+func (t *ValueTransfer) HasRecipient() bool {
+	return t.Kind != TransferFeeBurn
+}
+
+// ReadValueTransfers retrieves the value transfers of the given block, or nil if
+// they were not recorded.
+func ReadValueTransfers(db ethdb.KeyValueReader, number uint64, hash common.Hash) []*ValueTransfer {
+	data, _ := db.Get(valueTransfersKey(number, hash))
+	if len(data) == 0 {
+		return nil
+	}
+	transfers := []*ValueTransfer{}
+	if err := rlp.DecodeBytes(data, &transfers); err != nil {
+		log.Error("Invalid value transfers", "number", number, "hash", hash, "err", err)
+		return nil
+	}
+	return transfers
+}
+
+// WriteValueTransfers stores the value transfers of the given block, indexing
+// them by the accounts sending and receiving them.
+func WriteValueTransfers(db ethdb.KeyValueWriter, number uint64, hash common.Hash, transfers []*ValueTransfer) {
+	data, err := rlp.EncodeToBytes(transfers)
+	if err != nil {
+		log.Crit("Failed to encode value transfers", "err", err)
+	}
+	if err := db.Put(valueTransfersKey(number, hash), data); err != nil {
+		log.Crit("Failed to store value transfers", "err", err)
+	}
+	addresses, positions := transferPositions(transfers)
+	for _, address := range addresses {
+		data, err := rlp.EncodeToBytes(positions[address])
+		if err != nil {
+			log.Crit("Failed to encode value transfer positions", "err", err)
+		}
+		if err := db.Put(transferIndexKey(address, number, hash), data); err != nil {
+			log.Crit("Failed to store value transfer positions", "err", err)
+		}
+	}
+}
+
+// transferPositions returns the accounts sending or receiving the given value
+// transfers, in order of appearance, along with the positions of their transfers.
+func transferPositions(transfers []*ValueTransfer) ([]common.Address, map[common.Address][]uint) {
+	var (
+		positions = make(map[common.Address][]uint)
+		addresses []common.Address
+	)
+	add := func(address common.Address, position uint) {
+		list := positions[address]
+		if len(list) > 0 && list[len(list)-1] == position {
+			return
+		}
+		if list == nil {
+			addresses = append(addresses, address)
+		}
+		positions[address] = append(list, position)
+	}
+	for i, transfer := range transfers {
+		if transfer.HasSender() {
+			add(transfer.From, uint(i))
+		}
+		if transfer.HasRecipient() {
+			add(transfer.To, uint(i))
+		}
+	}
+	return addresses, positions
+}
+
+// PruneValueTransfers removes the value transfers of all the blocks below the
+// given number along with their index entries, returning the number of blocks
+// pruned.
+func PruneValueTransfers(db ethdb.Database, limit uint64) (int, error) {
+	it := db.NewIterator(valueTransfersPrefix, nil)
+	defer it.Release()
+
+	var (
+		batch  = db.NewBatch()
+		pruned int
+	)
+	for it.Next() {
+		if len(it.Key()) != len(valueTransfersPrefix)+8+common.HashLength {
+			continue
+		}
+		number := binary.BigEndian.Uint64(it.Key()[len(valueTransfersPrefix):])
+		if number >= limit {
+			break
+		}
+		hash := common.BytesToHash(it.Key()[len(valueTransfersPrefix)+8:])
+
+		var transfers []*ValueTransfer
+		if err := rlp.DecodeBytes(it.Value(), &transfers); err != nil {
+			return pruned, err
+		}
+		addresses, _ := transferPositions(transfers)
+		for _, address := range addresses {
+			if err := batch.Delete(transferIndexKey(address, number, hash)); err != nil {
+				return pruned, err
+			}
+		}
+		if err := batch.Delete(it.Key()); err != nil {
+			return pruned, err
+		}
+		pruned++
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return pruned, err
+			}
+			batch.Reset()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return pruned, err
+	}
+	return pruned, batch.Write()
+}
+
+// IterateAccountTransfers calls fn with the positions of the value transfers of
+// an address within each block of the given range, in ascending block order,
+// until fn returns false. Blocks not part of the canonical chain are included,
+// it is up to the caller to skip them.
+func IterateAccountTransfers(db ethdb.Iteratee, address common.Address, from uint64, to uint64, fn func(number uint64, hash common.Hash, positions []uint) bool) {
+	prefix := append(append([]byte{}, transferIndexPrefix...), address.Bytes()...)
+
+	it := db.NewIterator(prefix, encodeBlockNumber(from))
+	defer it.Release()
+
+	for it.Next() {
+		if len(it.Key()) != len(prefix)+8+common.HashLength {
+			continue
+		}
+		number := binary.BigEndian.Uint64(it.Key()[len(prefix):])
+		if number > to {
+			return
+		}
+		hash := common.BytesToHash(it.Key()[len(prefix)+8:])
+
+		var positions []uint
+		if err := rlp.DecodeBytes(it.Value(), &positions); err != nil {
+			log.Error("Invalid value transfer positions", "address", address, "number", number, "err", err)
+			continue
+		}
+		if !fn(number, hash, positions) {
+			return
+		}
+	}
+}
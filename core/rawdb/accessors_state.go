@@ -17,6 +17,8 @@
 package rawdb
 
 import (
+	"encoding/binary"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
@@ -118,3 +120,48 @@ func DeleteTrieNode(db ethdb.KeyValueWriter, hash common.Hash) {
 		log.Crit("Failed to delete trie node", "err", err)
 	}
 }
+
+// ReadStateDiff retrieves the RLP encoded state diff of the given block.
+func ReadStateDiff(db ethdb.KeyValueReader, number uint64, hash common.Hash) []byte {
+	data, _ := db.Get(stateDiffKey(number, hash))
+	return data
+}
+
+// WriteStateDiff stores the RLP encoded state diff of the given block.
+func WriteStateDiff(db ethdb.KeyValueWriter, number uint64, hash common.Hash, diff []byte) {
+	if err := db.Put(stateDiffKey(number, hash), diff); err != nil {
+		log.Crit("Failed to store state diff", "err", err)
+	}
+}
+
+// DeleteStateDiffs deletes the state diffs of all blocks with the given number
+// found in db, using the given writer.
+func DeleteStateDiffs(db ethdb.Iteratee, w ethdb.KeyValueWriter, number uint64) {
+	it := db.NewIterator(append(stateDiffPrefix, encodeBlockNumber(number)...), nil)
+	defer it.Release()
+
+	for it.Next() {
+		if err := w.Delete(common.CopyBytes(it.Key())); err != nil {
+			log.Crit("Failed to delete state diff", "err", err)
+		}
+	}
+}
+
+// ReadStateDiffTail retrieves the number of the oldest block whose state diffs
+// may still be retained.
+func ReadStateDiffTail(db ethdb.KeyValueReader) *uint64 {
+	data, _ := db.Get(stateDiffTailKey)
+	if len(data) != 8 {
+		return nil
+	}
+	number := binary.BigEndian.Uint64(data)
+	return &number
+}
+
+// WriteStateDiffTail stores the number of the oldest block whose state diffs
+// may still be retained.
+func WriteStateDiffTail(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Put(stateDiffTailKey, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store the state diff tail", "err", err)
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// HashScheme is the legacy state scheme, storing every trie node keyed by
+	// its hash. Stale nodes are only removed by offline pruning.
+	HashScheme = "hash"
+
+	// PathScheme stores trie nodes keyed by their owner and path in the trie,
+	// so newer states overwrite the stale nodes in place.
+	PathScheme = "path"
+)
+
+// ReadStateScheme retrieves the scheme the state trie nodes are persisted with.
+// Databases without a scheme marker are using the legacy hash scheme.
+func ReadStateScheme(db ethdb.KeyValueReader) string {
+	blob, _ := db.Get(stateSchemeKey)
+	if string(blob) == PathScheme {
+		return PathScheme
+	}
+	return HashScheme
+}
+
+// WriteStateScheme stores the scheme the state trie nodes are persisted with.
+func WriteStateScheme(db ethdb.KeyValueWriter, scheme string) {
+	if err := db.Put(stateSchemeKey, []byte(scheme)); err != nil {
+		log.Crit("Failed to store state scheme", "err", err)
+	}
+}
+
+// ParseStateScheme checks the requested state scheme against the one used by
+// the database. An empty request selects the stored scheme, while a database
+// without any chain yet may be initialized with either.
+func ParseStateScheme(provided string, db ethdb.KeyValueReader) (string, error) {
+	stored := ReadStateScheme(db)
+	if provided == "" || provided == stored {
+		return stored, nil
+	}
+	if provided != HashScheme && provided != PathScheme {
+		return "", fmt.Errorf("unknown state scheme %q", provided)
+	}
+	if ReadHeadHeaderHash(db) == (common.Hash{}) {
+		return provided, nil
+	}
+	return "", fmt.Errorf("incompatible state scheme, stored: %s, provided: %s", stored, provided)
+}
+
+// ReadAccountTrieNode retrieves the account trie node stored at the given path.
+func ReadAccountTrieNode(db ethdb.KeyValueReader, path []byte) []byte {
+	data, _ := db.Get(accountTrieNodeKey(path))
+	return data
+}
+
+// HasAccountTrieNode checks whether the account trie node with the provided
+// hash is stored at the given path.
+func HasAccountTrieNode(db ethdb.KeyValueReader, path []byte, hash common.Hash) bool {
+	data := ReadAccountTrieNode(db, path)
+	return len(data) != 0 && crypto.Keccak256Hash(data) == hash
+}
+
+// WriteAccountTrieNode writes the provided account trie node into database.
+func WriteAccountTrieNode(db ethdb.KeyValueWriter, path []byte, node []byte) {
+	if err := db.Put(accountTrieNodeKey(path), node); err != nil {
+		log.Crit("Failed to store account trie node", "err", err)
+	}
+}
+
+// DeleteAccountTrieNode deletes the account trie node stored at the given path.
+func DeleteAccountTrieNode(db ethdb.KeyValueWriter, path []byte) {
+	if err := db.Delete(accountTrieNodeKey(path)); err != nil {
+		log.Crit("Failed to delete account trie node", "err", err)
+	}
+}
+
+// ReadStorageTrieNode retrieves the storage trie node of the given account
+// stored at the given path.
+func ReadStorageTrieNode(db ethdb.KeyValueReader, accountHash common.Hash, path []byte) []byte {
+	data, _ := db.Get(storageTrieNodeKey(accountHash, path))
+	return data
+}
+
+// HasStorageTrieNode checks whether the storage trie node with the provided
+// hash is stored at the given path of the account's storage trie.
+func HasStorageTrieNode(db ethdb.KeyValueReader, accountHash common.Hash, path []byte, hash common.Hash) bool {
+	data := ReadStorageTrieNode(db, accountHash, path)
+	return len(data) != 0 && crypto.Keccak256Hash(data) == hash
+}
+
+// WriteStorageTrieNode writes the provided storage trie node into database.
+func WriteStorageTrieNode(db ethdb.KeyValueWriter, accountHash common.Hash, path []byte, node []byte) {
+	if err := db.Put(storageTrieNodeKey(accountHash, path), node); err != nil {
+		log.Crit("Failed to store storage trie node", "err", err)
+	}
+}
+
+// DeleteStorageTrieNode deletes the storage trie node stored at the given path.
+func DeleteStorageTrieNode(db ethdb.KeyValueWriter, accountHash common.Hash, path []byte) {
+	if err := db.Delete(storageTrieNodeKey(accountHash, path)); err != nil {
+		log.Crit("Failed to delete storage trie node", "err", err)
+	}
+}
+
+// IterateStorageTrieNodes calls fn with the path and content of every persisted
+// storage trie node of the given account, in path order.
+func IterateStorageTrieNodes(db ethdb.Iteratee, accountHash common.Hash, fn func(path []byte, node []byte)) {
+	prefix := storageTrieNodeKey(accountHash, nil)
+	it := db.NewIterator(prefix, nil)
+	defer it.Release()
+
+	for it.Next() {
+		fn(common.CopyBytes(it.Key()[len(prefix):]), common.CopyBytes(it.Value()))
+	}
+}
+
+// ReadTrieNodeWithScheme retrieves the trie node with the provided hash using
+// the given state scheme. The owner is the zero hash for the account trie, and
+// the owning account hash for storage tries. Nodes stored at the path with a
+// mismatching hash are treated as missing.
+func ReadTrieNodeWithScheme(db ethdb.KeyValueReader, owner common.Hash, path []byte, hash common.Hash, scheme string) []byte {
+	if scheme == HashScheme {
+		return ReadTrieNode(db, hash)
+	}
+	var data []byte
+	if owner == (common.Hash{}) {
+		data = ReadAccountTrieNode(db, path)
+	} else {
+		data = ReadStorageTrieNode(db, owner, path)
+	}
+	if len(data) == 0 || crypto.Keccak256Hash(data) != hash {
+		return nil
+	}
+	return data
+}
+
+// HasTrieNodeWithScheme checks whether the trie node with the provided hash is
+// present using the given state scheme.
+func HasTrieNodeWithScheme(db ethdb.KeyValueReader, owner common.Hash, path []byte, hash common.Hash, scheme string) bool {
+	if scheme == HashScheme {
+		return HasTrieNode(db, hash)
+	}
+	if owner == (common.Hash{}) {
+		return HasAccountTrieNode(db, path, hash)
+	}
+	return HasStorageTrieNode(db, owner, path, hash)
+}
+
+// WriteTrieNodeWithScheme writes the provided trie node into database using the
+// given state scheme.
+func WriteTrieNodeWithScheme(db ethdb.KeyValueWriter, owner common.Hash, path []byte, hash common.Hash, node []byte, scheme string) {
+	switch {
+	case scheme == HashScheme:
+		WriteTrieNode(db, hash, node)
+	case owner == (common.Hash{}):
+		WriteAccountTrieNode(db, path, node)
+	default:
+		WriteStorageTrieNode(db, owner, path, node)
+	}
+}
+
+// ReadReverseDiffHead retrieves the id of the latest reverse state diff, or
+// zero if there are none.
+func ReadReverseDiffHead(db ethdb.KeyValueReader) uint64 {
+	data, _ := db.Get(reverseDiffHeadKey)
+	if len(data) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(data)
+}
+
+// WriteReverseDiffHead stores the id of the latest reverse state diff.
+func WriteReverseDiffHead(db ethdb.KeyValueWriter, id uint64) {
+	if err := db.Put(reverseDiffHeadKey, encodeBlockNumber(id)); err != nil {
+		log.Crit("Failed to store reverse diff head", "err", err)
+	}
+}
+
+// ReadReverseDiff retrieves the RLP encoded reverse state diff with the given id.
+func ReadReverseDiff(db ethdb.KeyValueReader, id uint64) []byte {
+	data, _ := db.Get(reverseDiffKey(id))
+	return data
+}
+
+// HasReverseDiff checks whether the reverse state diff with the given id is present.
+func HasReverseDiff(db ethdb.KeyValueReader, id uint64) bool {
+	ok, _ := db.Has(reverseDiffKey(id))
+	return ok
+}
+
+// WriteReverseDiff stores the RLP encoded reverse state diff with the given id.
+func WriteReverseDiff(db ethdb.KeyValueWriter, id uint64, blob []byte) {
+	if err := db.Put(reverseDiffKey(id), blob); err != nil {
+		log.Crit("Failed to store reverse diff", "err", err)
+	}
+}
+
+// DeleteReverseDiff deletes the reverse state diff with the given id.
+func DeleteReverseDiff(db ethdb.KeyValueWriter, id uint64) {
+	if err := db.Delete(reverseDiffKey(id)); err != nil {
+		log.Crit("Failed to delete reverse diff", "err", err)
+	}
+}
@@ -36,14 +36,21 @@ const (
 	chainFreezerDifficultyTable = "diffs"
 )
 
-// chainFreezerNoSnappy configures whether compression is disabled for the ancient-tables.
-// Hashes and difficulties don't compress well.
-var chainFreezerNoSnappy = map[string]bool{
-	chainFreezerHeaderTable:     false,
-	chainFreezerHashTable:       true,
-	chainFreezerBodiesTable:     false,
-	chainFreezerReceiptTable:    false,
-	chainFreezerDifficultyTable: true,
+// freezerTableConfig contains the settings of a freezer table.
+type freezerTableConfig struct {
+	noSnappy bool // Disables item compression
+	prunable bool // Whether the table tail is moved by TruncateTail
+}
+
+// chainFreezerTableConfigs configures the ancient-tables. Hashes and difficulties
+// don't compress well. Only block bodies and receipts can be pruned, the headers
+// are needed to keep the chain verifiable.
+var chainFreezerTableConfigs = map[string]freezerTableConfig{
+	chainFreezerHeaderTable:     {noSnappy: false, prunable: false},
+	chainFreezerHashTable:       {noSnappy: true, prunable: false},
+	chainFreezerBodiesTable:     {noSnappy: false, prunable: true},
+	chainFreezerReceiptTable:    {noSnappy: false, prunable: true},
+	chainFreezerDifficultyTable: {noSnappy: true, prunable: false},
 }
 
 // The list of identifiers of ancient stores.
@@ -61,15 +68,15 @@ var freezers = []string{chainFreezerName}
 func InspectFreezerTable(ancient string, freezerName string, tableName string, start, end int64) error {
 	var (
 		path   string
-		tables map[string]bool
+		tables map[string]freezerTableConfig
 	)
 	switch freezerName {
 	case chainFreezerName:
-		path, tables = resolveChainFreezerDir(ancient), chainFreezerNoSnappy
+		path, tables = resolveChainFreezerDir(ancient), chainFreezerTableConfigs
 	default:
 		return fmt.Errorf("unknown freezer, supported ones: %v", freezers)
 	}
-	noSnappy, exist := tables[tableName]
+	config, exist := tables[tableName]
 	if !exist {
 		var names []string
 		for name := range tables {
@@ -77,7 +84,7 @@ func InspectFreezerTable(ancient string, freezerName string, tableName string, s
 		}
 		return fmt.Errorf("unknown table, supported ones: %v", names)
 	}
-	table, err := newFreezerTable(path, tableName, noSnappy, true)
+	table, err := newFreezerTable(path, tableName, config.noSnappy, true)
 	if err != nil {
 		return err
 	}
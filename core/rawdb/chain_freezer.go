@@ -55,8 +55,10 @@ type chainFreezer struct {
 }
 
 // newChainFreezer initializes the freezer for ancient chain data.
-func newChainFreezer(datadir string, namespace string, readonly bool, maxTableSize uint32, tables map[string]bool) (*chainFreezer, error) {
-	freezer, err := NewFreezer(datadir, namespace, readonly, maxTableSize, tables)
+// A secondary chain freezer is a read-only view of a freezer owned by another
+// process.
+func newChainFreezer(datadir string, namespace string, readonly, secondary bool, maxTableSize uint32, tables map[string]freezerTableConfig) (*chainFreezer, error) {
+	freezer, err := openFreezer(datadir, namespace, readonly || secondary, secondary, maxTableSize, tables)
 	if err != nil {
 		return nil, err
 	}
@@ -22,6 +22,8 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"path/filepath"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -197,8 +199,16 @@ func resolveChainFreezerDir(ancient string) string {
 // storage. The passed ancient indicates the path of root ancient directory
 // where the chain freezer can be opened.
 func NewDatabaseWithFreezer(db ethdb.KeyValueStore, ancient string, namespace string, readonly bool) (ethdb.Database, error) {
+	return newDatabaseWithFreezer(db, ancient, namespace, readonly, false)
+}
+
+// newDatabaseWithFreezer creates a high level database on top of a given key-
+// value data store and the chain freezer in the passed ancient directory. If
+// secondary is set, the freezer is opened as a read-only view of a freezer in
+// use by another process.
+func newDatabaseWithFreezer(db ethdb.KeyValueStore, ancient string, namespace string, readonly, secondary bool) (ethdb.Database, error) {
 	// Create the idle freezer instance
-	frdb, err := newChainFreezer(resolveChainFreezerDir(ancient), namespace, readonly, freezerTableSize, chainFreezerNoSnappy)
+	frdb, err := newChainFreezer(resolveChainFreezerDir(ancient), namespace, readonly, secondary, freezerTableSize, chainFreezerTableConfigs)
 	if err != nil {
 		return nil, err
 	}
@@ -321,6 +331,116 @@ func NewLevelDBDatabaseWithFreezer(file string, cache int, handles int, ancient
 	return frdb, nil
 }
 
+const (
+	dbLeveldb = "leveldb"
+	dbPebble  = "pebble"
+
+	// engineFile is the file within a key-value database directory recording
+	// the engine the database was created with.
+	engineFile = "ENGINE"
+)
+
+// ReadDatabaseEngine returns the engine of the key-value database in the given
+// directory, or an empty string if no database exists there yet. Databases
+// created before the engine was recorded are identified by their files.
+func ReadDatabaseEngine(dir string) (string, error) {
+	blob, err := os.ReadFile(filepath.Join(dir, engineFile))
+	if err == nil {
+		return strings.TrimSpace(string(blob)), nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	if !common.FileExist(filepath.Join(dir, "CURRENT")) {
+		return "", nil
+	}
+	// Pebble writes an OPTIONS file alongside its manifest, LevelDB doesn't
+	if matches, err := filepath.Glob(filepath.Join(dir, "OPTIONS*")); len(matches) > 0 || err != nil {
+		return dbPebble, nil
+	}
+	return dbLeveldb, nil
+}
+
+// OpenOptions contains the options to apply when opening a database.
+type OpenOptions struct {
+	Type              string // "leveldb" | "pebble", empty to use the engine of the existing database
+	Directory         string // the datadir
+	AncientsDirectory string // the ancients-dir
+	Namespace         string // the namespace for database relevant metrics
+	Cache             int    // the capacity(in megabytes) of the data caching
+	Handles           int    // number of files to be open simultaneously
+	ReadOnly          bool
+	// Secondary opens a read-only, point-in-time view of a database in use by
+	// another process, without taking its locks. Requires ReadOnly.
+	Secondary bool
+}
+
+// openKeyValueDatabase opens a disk-based key-value database, e.g. leveldb or
+// pebble. New databases use the requested type, defaulting to leveldb, and the
+// engine is recorded in the database directory. Existing databases are opened
+// with their recorded engine, and requesting a different one fails.
+func openKeyValueDatabase(o OpenOptions) (ethdb.Database, error) {
+	existing, err := ReadDatabaseEngine(o.Directory)
+	if err != nil {
+		return nil, err
+	}
+	if o.Type != "" && existing != "" && o.Type != existing {
+		return nil, fmt.Errorf("db.engine choice was %v but found pre-existing %v database in specified data directory", o.Type, existing)
+	}
+	engine := o.Type
+	if engine == "" {
+		engine = existing
+	}
+	if engine == "" {
+		engine = dbLeveldb
+	}
+	var db ethdb.Database
+	switch engine {
+	case dbLeveldb:
+		log.Info("Using leveldb as the backing database")
+		db, err = NewLevelDBDatabase(o.Directory, o.Cache, o.Handles, o.Namespace, o.ReadOnly)
+	case dbPebble:
+		log.Info("Using pebble as the backing database")
+		db, err = NewPebbleDBDatabase(o.Directory, o.Cache, o.Handles, o.Namespace, o.ReadOnly)
+	default:
+		return nil, fmt.Errorf("unknown db.engine %v", engine)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !o.ReadOnly && !common.FileExist(filepath.Join(o.Directory, engineFile)) {
+		if err := os.WriteFile(filepath.Join(o.Directory, engineFile), []byte(engine), 0644); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	return db, nil
+}
+
+// Open opens both a disk-based key-value database such as leveldb or pebble, but also
+// integrates it with a freezer database -- if the AncientDir option has been
+// set on the provided OpenOptions.
+// The passed o.AncientDir indicates the path of root ancient directory where
+// the chain freezer can be opened.
+func Open(o OpenOptions) (ethdb.Database, error) {
+	if o.Secondary {
+		return openSecondary(o)
+	}
+	kvdb, err := openKeyValueDatabase(o)
+	if err != nil {
+		return nil, err
+	}
+	if len(o.AncientsDirectory) == 0 {
+		return kvdb, nil
+	}
+	frdb, err := NewDatabaseWithFreezer(kvdb, o.AncientsDirectory, o.Namespace, o.ReadOnly)
+	if err != nil {
+		kvdb.Close()
+		return nil, err
+	}
+	return frdb, nil
+}
+
 type counter uint64
 
 func (c counter) String() string {
@@ -370,12 +490,20 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		numHashPairings stat
 		hashNumPairings stat
 		tries           stat
+		pathTries       stat
+		reverseDiffs    stat
+		stateDiffs      stat
 		codes           stat
 		txLookups       stat
 		accountSnaps    stat
 		storageSnaps    stat
 		preimages       stat
 		bloomBits       stat
+		codeDeployments stat
+		creations       stat
+		logIndex        stat
+		accountTxs      stat
+		valueTransfers  stat
 		beaconHeaders   stat
 		cliqueSnaps     stat
 
@@ -419,6 +547,14 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 			hashNumPairings.Add(size)
 		case len(key) == common.HashLength:
 			tries.Add(size)
+		case bytes.HasPrefix(key, TrieNodeAccountPrefix) && len(key) <= len(TrieNodeAccountPrefix)+2*common.HashLength:
+			pathTries.Add(size)
+		case bytes.HasPrefix(key, TrieNodeStoragePrefix) && len(key) >= len(TrieNodeStoragePrefix)+common.HashLength && len(key) <= len(TrieNodeStoragePrefix)+3*common.HashLength:
+			pathTries.Add(size)
+		case bytes.HasPrefix(key, reverseDiffPrefix) && len(key) == len(reverseDiffPrefix)+8:
+			reverseDiffs.Add(size)
+		case bytes.HasPrefix(key, stateDiffPrefix) && len(key) == len(stateDiffPrefix)+8+common.HashLength:
+			stateDiffs.Add(size)
 		case bytes.HasPrefix(key, CodePrefix) && len(key) == len(CodePrefix)+common.HashLength:
 			codes.Add(size)
 		case bytes.HasPrefix(key, txLookupPrefix) && len(key) == (len(txLookupPrefix)+common.HashLength):
@@ -437,6 +573,30 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 			bloomBits.Add(size)
 		case bytes.HasPrefix(key, BloomBitsIndexPrefix):
 			bloomBits.Add(size)
+		case bytes.HasPrefix(key, codeDeploymentPrefix) && len(key) == len(codeDeploymentPrefix)+common.HashLength+common.AddressLength:
+			codeDeployments.Add(size)
+		case bytes.HasPrefix(key, codeBlockPrefix) && len(key) == len(codeBlockPrefix)+8:
+			codeDeployments.Add(size)
+		case bytes.HasPrefix(key, CodeIndexPrefix):
+			codeDeployments.Add(size)
+		case bytes.HasPrefix(key, creationsPrefix) && len(key) == len(creationsPrefix)+8+common.HashLength:
+			creations.Add(size)
+		case bytes.HasPrefix(key, logAddressIndexPrefix) && len(key) == len(logAddressIndexPrefix)+common.AddressLength+8:
+			logIndex.Add(size)
+		case bytes.HasPrefix(key, logTopicIndexPrefix) && len(key) == len(logTopicIndexPrefix)+1+common.HashLength+8:
+			logIndex.Add(size)
+		case bytes.HasPrefix(key, LogIndexPrefix):
+			logIndex.Add(size)
+		case bytes.HasPrefix(key, accountTxsPrefix) && len(key) == len(accountTxsPrefix)+common.AddressLength+8:
+			accountTxs.Add(size)
+		case bytes.HasPrefix(key, accountTxsBlockPrefix) && len(key) == len(accountTxsBlockPrefix)+8:
+			accountTxs.Add(size)
+		case bytes.HasPrefix(key, AccountIndexPrefix):
+			accountTxs.Add(size)
+		case bytes.HasPrefix(key, valueTransfersPrefix) && len(key) == len(valueTransfersPrefix)+8+common.HashLength:
+			valueTransfers.Add(size)
+		case bytes.HasPrefix(key, transferIndexPrefix) && len(key) == len(transferIndexPrefix)+common.AddressLength+8+common.HashLength:
+			valueTransfers.Add(size)
 		case bytes.HasPrefix(key, skeletonHeaderPrefix) && len(key) == (len(skeletonHeaderPrefix)+8):
 			beaconHeaders.Add(size)
 		case bytes.HasPrefix(key, []byte("clique-")) && len(key) == 7+common.HashLength:
@@ -456,6 +616,7 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 				lastPivotKey, fastTrieProgressKey, snapshotDisabledKey, SnapshotRootKey, snapshotJournalKey,
 				snapshotGeneratorKey, snapshotRecoveryKey, txIndexTailKey, fastTxLookupLimitKey,
 				uncleanShutdownKey, badBlockKey, transitionStatusKey, skeletonSyncStatusKey,
+				stateSchemeKey, reverseDiffHeadKey, stateDiffTailKey,
 			} {
 				if bytes.Equal(key, meta) {
 					metadata.Add(size)
@@ -497,7 +658,15 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		{"Key-Value store", "Transaction index", txLookups.Size(), txLookups.Count()},
 		{"Key-Value store", "Bloombit index", bloomBits.Size(), bloomBits.Count()},
 		{"Key-Value store", "Contract codes", codes.Size(), codes.Count()},
+		{"Key-Value store", "Contract code index", codeDeployments.Size(), codeDeployments.Count()},
+		{"Key-Value store", "Contract creations", creations.Size(), creations.Count()},
+		{"Key-Value store", "Log index", logIndex.Size(), logIndex.Count()},
+		{"Key-Value store", "Account history index", accountTxs.Size(), accountTxs.Count()},
+		{"Key-Value store", "Value transfers", valueTransfers.Size(), valueTransfers.Count()},
 		{"Key-Value store", "Trie nodes", tries.Size(), tries.Count()},
+		{"Key-Value store", "Path trie nodes", pathTries.Size(), pathTries.Count()},
+		{"Key-Value store", "Reverse state diffs", reverseDiffs.Size(), reverseDiffs.Count()},
+		{"Key-Value store", "Block state diffs", stateDiffs.Size(), stateDiffs.Count()},
 		{"Key-Value store", "Trie preimages", preimages.Size(), preimages.Count()},
 		{"Key-Value store", "Account snapshot", accountSnaps.Size(), accountSnaps.Count()},
 		{"Key-Value store", "Storage snapshot", storageSnaps.Size(), storageSnaps.Count()},
@@ -15,3 +15,59 @@
 // along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
 
 package rawdb
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Tests that the engine a database is created with is recorded, and reopening
+// it with a different engine is refused.
+func TestDatabaseEngineRecord(t *testing.T) {
+	engines := []string{dbLeveldb}
+	if PebbleEnabled {
+		engines = append(engines, dbPebble)
+	}
+	for _, engine := range engines {
+		dir := filepath.Join(t.TempDir(), "chaindata")
+
+		db, err := Open(OpenOptions{Type: engine, Directory: dir})
+		if err != nil {
+			t.Fatalf("%s: failed to create database: %v", engine, err)
+		}
+		if err := db.Put([]byte("key"), []byte("value")); err != nil {
+			t.Fatalf("%s: failed to write database: %v", engine, err)
+		}
+		db.Close()
+
+		if recorded, err := ReadDatabaseEngine(dir); err != nil || recorded != engine {
+			t.Fatalf("%s: recorded engine mismatch: have %q (%v)", engine, recorded, err)
+		}
+		for _, other := range []string{dbLeveldb, dbPebble} {
+			if other == engine {
+				continue
+			}
+			if _, err := Open(OpenOptions{Type: other, Directory: dir}); err == nil {
+				t.Fatalf("%s: opened database with engine %s", engine, other)
+			}
+		}
+		// Reopening without an explicit engine picks the recorded one
+		db, err = Open(OpenOptions{Directory: dir})
+		if err != nil {
+			t.Fatalf("%s: failed to reopen database: %v", engine, err)
+		}
+		if value, err := db.Get([]byte("key")); err != nil || string(value) != "value" {
+			t.Fatalf("%s: value mismatch after reopen: %q (%v)", engine, value, err)
+		}
+		db.Close()
+
+		// Databases predating the record are detected from their files
+		if err := os.Remove(filepath.Join(dir, engineFile)); err != nil {
+			t.Fatalf("%s: failed to drop engine record: %v", engine, err)
+		}
+		if detected, err := ReadDatabaseEngine(dir); err != nil || detected != engine {
+			t.Fatalf("%s: detected engine mismatch: have %q (%v)", engine, detected, err)
+		}
+	}
+}
@@ -75,6 +75,7 @@ type Freezer struct {
 
 	readonly     bool
 	tables       map[string]*freezerTable // Data tables for storing everything
+	prunable     map[string]bool          // Tables whose tail is moved by TruncateTail
 	instanceLock fileutil.Releaser        // File-system lock to prevent double opens
 	closeOnce    sync.Once
 }
@@ -82,9 +83,21 @@ type Freezer struct {
 // NewFreezer creates a freezer instance for maintaining immutable ordered
 // data according to the given parameters.
 //
-// The 'tables' argument defines the data tables. If the value of a map
-// entry is true, snappy compression is disabled for the table.
-func NewFreezer(datadir string, namespace string, readonly bool, maxTableSize uint32, tables map[string]bool) (*Freezer, error) {
+// The 'tables' argument defines the data tables along with their compression
+// and pruning settings.
+func NewFreezer(datadir string, namespace string, readonly bool, maxTableSize uint32, tables map[string]freezerTableConfig) (*Freezer, error) {
+	return openFreezer(datadir, namespace, readonly, false, maxTableSize, tables)
+}
+
+// newSecondaryFreezer opens a read-only view of a freezer which is owned and
+// concurrently appended to by another process. No lock is taken, and the items
+// present in all tables at the time of opening are exposed.
+func newSecondaryFreezer(datadir string, namespace string, maxTableSize uint32, tables map[string]freezerTableConfig) (*Freezer, error) {
+	return openFreezer(datadir, namespace, true, true, maxTableSize, tables)
+}
+
+// openFreezer opens a freezer instance in the requested mode.
+func openFreezer(datadir string, namespace string, readonly, secondary bool, maxTableSize uint32, tables map[string]freezerTableConfig) (*Freezer, error) {
 	// Create the initial freezer object
 	var (
 		readMeter  = metrics.NewRegisteredMeter(namespace+"ancient/read", nil)
@@ -99,36 +112,61 @@ func NewFreezer(datadir string, namespace string, readonly bool, maxTableSize ui
 		}
 	}
 	// Leveldb uses LOCK as the filelock filename. To prevent the
-	// name collision, we use FLOCK as the lock name.
-	lock, _, err := fileutil.Flock(filepath.Join(datadir, "FLOCK"))
-	if err != nil {
-		return nil, err
+	// name collision, we use FLOCK as the lock name. The lock is held
+	// by the owner of a secondary view.
+	var (
+		lock fileutil.Releaser
+		err  error
+	)
+	if !secondary {
+		if lock, _, err = fileutil.Flock(filepath.Join(datadir, "FLOCK")); err != nil {
+			return nil, err
+		}
+	}
+	release := func() {
+		if lock != nil {
+			lock.Release()
+		}
 	}
 	// Open all the supported data tables
 	freezer := &Freezer{
 		readonly:     readonly,
 		tables:       make(map[string]*freezerTable),
+		prunable:     make(map[string]bool),
 		instanceLock: lock,
 	}
 
 	// Create the tables.
-	for name, disableSnappy := range tables {
-		table, err := newTable(datadir, name, readMeter, writeMeter, sizeGauge, maxTableSize, disableSnappy, readonly)
+	for name, config := range tables {
+		var table *freezerTable
+		if secondary {
+			table, err = newSecondaryTable(datadir, name, readMeter, writeMeter, sizeGauge, maxTableSize, config.noSnappy)
+		} else {
+			table, err = newTable(datadir, name, readMeter, writeMeter, sizeGauge, maxTableSize, config.noSnappy, readonly)
+		}
 		if err != nil {
 			for _, table := range freezer.tables {
 				table.Close()
 			}
-			lock.Release()
+			release()
 			return nil, err
 		}
 		freezer.tables[name] = table
+		if config.prunable {
+			freezer.prunable[name] = true
+		}
 	}
 
-	if freezer.readonly {
+	switch {
+	case secondary:
+		// The owner may be amidst appending to the tables, only expose
+		// the items present in all of them.
+		freezer.align()
+	case freezer.readonly:
 		// In readonly mode only validate, don't truncate.
 		// validate also sets `freezer.frozen`.
 		err = freezer.validate()
-	} else {
+	default:
 		// Truncate all tables to common length.
 		err = freezer.repair()
 	}
@@ -136,7 +174,7 @@ func NewFreezer(datadir string, namespace string, readonly bool, maxTableSize ui
 		for _, table := range freezer.tables {
 			table.Close()
 		}
-		lock.Release()
+		release()
 		return nil, err
 	}
 
@@ -159,8 +197,10 @@ func (f *Freezer) Close() error {
 				errs = append(errs, err)
 			}
 		}
-		if err := f.instanceLock.Release(); err != nil {
-			errs = append(errs, err)
+		if f.instanceLock != nil {
+			if err := f.instanceLock.Release(); err != nil {
+				errs = append(errs, err)
+			}
 		}
 	})
 	if errs != nil {
@@ -203,7 +243,8 @@ func (f *Freezer) Ancients() (uint64, error) {
 	return atomic.LoadUint64(&f.frozen), nil
 }
 
-// Tail returns the number of first stored item in the freezer.
+// Tail returns the number of first stored item in the prunable tables of the
+// freezer. The other tables always retain all their items.
 func (f *Freezer) Tail() (uint64, error) {
 	return atomic.LoadUint64(&f.tail), nil
 }
@@ -284,7 +325,8 @@ func (f *Freezer) TruncateHead(items uint64) error {
 	return nil
 }
 
-// TruncateTail discards any recent data below the provided threshold number.
+// TruncateTail discards any recent data below the provided threshold number
+// from the prunable tables.
 func (f *Freezer) TruncateTail(tail uint64) error {
 	if f.readonly {
 		return errReadOnly
@@ -295,8 +337,8 @@ func (f *Freezer) TruncateTail(tail uint64) error {
 	if atomic.LoadUint64(&f.tail) >= tail {
 		return nil
 	}
-	for _, table := range f.tables {
-		if err := table.truncateTail(tail); err != nil {
+	for kind := range f.prunable {
+		if err := f.tables[kind].truncateTail(tail); err != nil {
 			return err
 		}
 	}
@@ -327,6 +369,7 @@ func (f *Freezer) validate() error {
 	var (
 		length uint64
 		name   string
+		tail   uint64
 	)
 	// Hack to get length of any table
 	for kind, table := range f.tables {
@@ -340,31 +383,68 @@ func (f *Freezer) validate() error {
 		if length != items {
 			return fmt.Errorf("freezer tables %s and %s have differing lengths: %d != %d", kind, name, items, length)
 		}
+		if hidden := atomic.LoadUint64(&table.itemHidden); f.prunable[kind] && hidden > tail {
+			tail = hidden
+		}
 	}
 	atomic.StoreUint64(&f.frozen, length)
+	atomic.StoreUint64(&f.tail, tail)
 	return nil
 }
 
-// repair truncates all data tables to the same length.
+// align limits the read-only tables of a secondary view to the items present
+// in all of them, without touching the files. It also sets `freezer.frozen`
+// and `freezer.tail`.
+func (f *Freezer) align() {
+	var (
+		head = uint64(math.MaxUint64)
+		tail = uint64(0)
+	)
+	for kind, table := range f.tables {
+		if items := atomic.LoadUint64(&table.items); head > items {
+			head = items
+		}
+		if hidden := atomic.LoadUint64(&table.itemHidden); f.prunable[kind] && hidden > tail {
+			tail = hidden
+		}
+	}
+	if len(f.tables) == 0 {
+		head = 0
+	}
+	for kind, table := range f.tables {
+		atomic.StoreUint64(&table.items, head)
+		if f.prunable[kind] && atomic.LoadUint64(&table.itemHidden) < tail {
+			atomic.StoreUint64(&table.itemHidden, tail)
+		}
+	}
+	atomic.StoreUint64(&f.frozen, head)
+	atomic.StoreUint64(&f.tail, tail)
+}
+
+// repair truncates all data tables to the same length and the prunable ones to
+// the same tail.
 func (f *Freezer) repair() error {
 	var (
 		head = uint64(math.MaxUint64)
 		tail = uint64(0)
 	)
-	for _, table := range f.tables {
+	for kind, table := range f.tables {
 		items := atomic.LoadUint64(&table.items)
 		if head > items {
 			head = items
 		}
 		hidden := atomic.LoadUint64(&table.itemHidden)
-		if hidden > tail {
+		if f.prunable[kind] && hidden > tail {
 			tail = hidden
 		}
 	}
-	for _, table := range f.tables {
+	for kind, table := range f.tables {
 		if err := table.truncateHead(head); err != nil {
 			return err
 		}
+		if !f.prunable[kind] {
+			continue
+		}
 		if err := table.truncateTail(tail); err != nil {
 			return err
 		}
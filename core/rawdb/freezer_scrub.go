@@ -0,0 +1,169 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"fmt"
+	"sort"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// scrubReadBytes is the maximum amount of data read from a freezer table at once
+// when checking its items.
+const scrubReadBytes = 1024 * 1024
+
+// AncientScrubError is returned if an item of an ancient table is found to be
+// corrupted or inaccessible.
+type AncientScrubError struct {
+	Kind string // Name of the ancient table
+	Item uint64 // Number of the first corrupted item
+	Err  error  // Inconsistency found
+}
+
+// Error implements error.
+func (e *AncientScrubError) Error() string {
+	return fmt.Sprintf("ancient %s item %d: %v", e.Kind, e.Item, e.Err)
+}
+
+// ScrubAncients checks the consistency of the given range of items in all the
+// ancient tables of the database, skipping the ones removed from the tail. An
+// *AncientScrubError is returned for the first inconsistency found.
+func ScrubAncients(db ethdb.Database, start, count uint64) error {
+	frdb, ok := db.(*freezerdb)
+	if !ok {
+		return errNotSupported
+	}
+	freezer, ok := frdb.AncientStore.(*chainFreezer)
+	if !ok {
+		return errNotSupported
+	}
+	return freezer.scrub(start, count)
+}
+
+// scrub checks the consistency of the given range of items in all the tables,
+// limited to the frozen items.
+func (f *Freezer) scrub(start, count uint64) error {
+	// Hold the write lock in read mode, like ReadAncients: writes and truncations
+	// take it exclusively so they can't run during the scrub, while plain reads
+	// don't take it at all and are not held up.
+	f.writeLock.RLock()
+	defer f.writeLock.RUnlock()
+
+	frozen := atomic.LoadUint64(&f.frozen)
+	if start >= frozen {
+		return nil
+	}
+	if start+count > frozen {
+		count = frozen - start
+	}
+	kinds := make([]string, 0, len(f.tables))
+	for kind := range f.tables {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	for _, kind := range kinds {
+		if item, err := f.tables[kind].scrub(start, count); err != nil {
+			return &AncientScrubError{Kind: kind, Item: item, Err: err}
+		}
+	}
+	return nil
+}
+
+// scrub checks that the index entries of the given range of items are sequential
+// and point into the existing data files, and that the items can be read and
+// decompressed. Items removed from the tail are skipped. The number of the first
+// corrupted item is returned along with the error.
+func (t *freezerTable) scrub(start, count uint64) (uint64, error) {
+	if hidden := atomic.LoadUint64(&t.itemHidden); start < hidden {
+		if start+count <= hidden {
+			return 0, nil
+		}
+		count -= hidden - start
+		start = hidden
+	}
+	if items := atomic.LoadUint64(&t.items); start+count > items {
+		if start >= items {
+			return start, errOutOfBounds
+		}
+		count = items - start
+	}
+	if item, err := t.scrubIndex(start, count); err != nil {
+		return item, err
+	}
+	for read := uint64(0); read < count; {
+		blobs, err := t.RetrieveItems(start+read, count-read, scrubReadBytes)
+		if err != nil {
+			// Locate the offending item by reading them one by one
+			for item := start + read; item < start+count; item++ {
+				if _, err := t.Retrieve(item); err != nil {
+					return item, err
+				}
+			}
+			return start + read, err
+		}
+		read += uint64(len(blobs))
+	}
+	return 0, nil
+}
+
+// scrubIndex checks that the index entries of the given range of items are
+// sequential and point into the existing data files.
+func (t *freezerTable) scrubIndex(start, count uint64) (uint64, error) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if t.index == nil || t.head == nil {
+		return start, errClosed
+	}
+	indices, err := t.getIndices(start, count)
+	if err != nil {
+		return start, err
+	}
+	sizes := make(map[uint32]int64)
+	for i := 0; i < len(indices)-1; i++ {
+		var (
+			item   = start + uint64(i)
+			first  = indices[i]
+			second = indices[i+1]
+		)
+		switch {
+		case second.filenum == first.filenum && second.offset < first.offset:
+			return item, fmt.Errorf("index offset %d below previous offset %d", second.offset, first.offset)
+		case second.filenum != first.filenum && second.filenum != first.filenum+1:
+			return item, fmt.Errorf("index file %d following file %d", second.filenum, first.filenum)
+		}
+		size, ok := sizes[second.filenum]
+		if !ok {
+			file, exist := t.files[second.filenum]
+			if !exist {
+				return item, fmt.Errorf("missing data file %d", second.filenum)
+			}
+			stat, err := file.Stat()
+			if err != nil {
+				return item, err
+			}
+			size, sizes[second.filenum] = stat.Size(), stat.Size()
+		}
+		if int64(second.offset) > size {
+			return item, fmt.Errorf("index offset %d beyond data file %d of size %d", second.offset, second.filenum, size)
+		}
+	}
+	return 0, nil
+}
@@ -103,6 +103,7 @@ type freezerTable struct {
 
 	noCompression bool // if true, disables snappy compression. Note: does not work retroactively
 	readonly      bool
+	secondary     bool   // if true, the table is concurrently appended by another process
 	maxFileSize   uint32 // Max file size for data-files
 	name          string
 	path          string
@@ -132,6 +133,18 @@ func newFreezerTable(path, name string, disableSnappy, readonly bool) (*freezerT
 // non-existent. Both files are truncated to the shortest common length to ensure
 // they don't go out of sync.
 func newTable(path string, name string, readMeter metrics.Meter, writeMeter metrics.Meter, sizeGauge metrics.Gauge, maxFilesize uint32, noCompression, readonly bool) (*freezerTable, error) {
+	return openTable(path, name, readMeter, writeMeter, sizeGauge, maxFilesize, noCompression, readonly, false)
+}
+
+// newSecondaryTable opens a read-only view of a freezer table which is being
+// appended to by another process. Instead of repairing the files, the items
+// not yet fully written by the owner are ignored.
+func newSecondaryTable(path string, name string, readMeter metrics.Meter, writeMeter metrics.Meter, sizeGauge metrics.Gauge, maxFilesize uint32, noCompression bool) (*freezerTable, error) {
+	return openTable(path, name, readMeter, writeMeter, sizeGauge, maxFilesize, noCompression, true, true)
+}
+
+// openTable opens a freezer table in the requested mode.
+func openTable(path string, name string, readMeter metrics.Meter, writeMeter metrics.Meter, sizeGauge metrics.Gauge, maxFilesize uint32, noCompression, readonly, secondary bool) (*freezerTable, error) {
 	// Ensure the containing directory exists and open the indexEntry file
 	if err := os.MkdirAll(path, 0755); err != nil {
 		return nil, err
@@ -188,6 +201,7 @@ func newTable(path string, name string, readMeter metrics.Meter, writeMeter metr
 		logger:        log.New("database", path, "table", name),
 		noCompression: noCompression,
 		readonly:      readonly,
+		secondary:     secondary,
 		maxFileSize:   maxFilesize,
 	}
 	if err := tab.repair(); err != nil {
@@ -221,15 +235,18 @@ func (t *freezerTable) repair() error {
 			return err
 		}
 	}
-	// Ensure the index is a multiple of indexEntrySize bytes
-	if overflow := stat.Size() % indexEntrySize; overflow != 0 {
+	// Ensure the index is a multiple of indexEntrySize bytes. A secondary view
+	// ignores the partially written entry instead.
+	overflow := stat.Size() % indexEntrySize
+	if overflow != 0 && !t.secondary {
 		truncateFreezerFile(t.index, stat.Size()-overflow) // New file can't trigger this path
+		overflow = 0
 	}
 	// Retrieve the file sizes and prepare for truncation
 	if stat, err = t.index.Stat(); err != nil {
 		return err
 	}
-	offsetsSize := stat.Size()
+	offsetsSize := stat.Size() - overflow
 
 	// Open the head file
 	var (
@@ -280,6 +297,12 @@ func (t *freezerTable) repair() error {
 	// Keep truncating both files until they come in sync
 	contentExp = int64(lastIndex.offset)
 	for contentExp != contentSize {
+		// The owner of a secondary view appends the data before the index, the
+		// data beyond the last offset pointer is not yet committed
+		if contentExp < contentSize && t.secondary {
+			contentSize = contentExp
+			break
+		}
 		// Truncate the head file to the last offset pointer
 		if contentExp < contentSize {
 			t.logger.Warn("Truncating dangling head", "indexed", common.StorageSize(contentExp), "stored", common.StorageSize(contentSize))
@@ -290,9 +313,11 @@ func (t *freezerTable) repair() error {
 		}
 		// Truncate the index to point within the head file
 		if contentExp > contentSize {
-			t.logger.Warn("Truncating dangling indexes", "indexed", common.StorageSize(contentExp), "stored", common.StorageSize(contentSize))
-			if err := truncateFreezerFile(t.index, offsetsSize-indexEntrySize); err != nil {
-				return err
+			if !t.secondary {
+				t.logger.Warn("Truncating dangling indexes", "indexed", common.StorageSize(contentExp), "stored", common.StorageSize(contentSize))
+				if err := truncateFreezerFile(t.index, offsetsSize-indexEntrySize); err != nil {
+					return err
+				}
 			}
 			offsetsSize -= indexEntrySize
 
@@ -309,7 +334,12 @@ func (t *freezerTable) repair() error {
 			if newLastIndex.filenum != lastIndex.filenum {
 				// Release earlier opened file
 				t.releaseFile(lastIndex.filenum)
-				if t.head, err = t.openFile(newLastIndex.filenum, openFreezerFileForAppend); err != nil {
+				if t.readonly {
+					t.head, err = t.openFile(newLastIndex.filenum, openFreezerFileForReadOnly)
+				} else {
+					t.head, err = t.openFile(newLastIndex.filenum, openFreezerFileForAppend)
+				}
+				if err != nil {
 					return err
 				}
 				if stat, err = t.head.Stat(); err != nil {
@@ -341,11 +371,13 @@ func (t *freezerTable) repair() error {
 	t.headBytes = contentSize
 	t.headId = lastIndex.filenum
 
-	// Delete the leftover files because of head deletion
-	t.releaseFilesAfter(t.headId, true)
+	// Delete the leftover files because of head deletion. The files beyond
+	// the head of a secondary view are owned by the writer.
+	t.releaseFilesAfter(t.headId, !t.secondary)
 
-	// Delete the leftover files because of tail deletion
-	t.releaseFilesBefore(t.tailId, true)
+	// Delete the leftover files because of tail deletion, unless they are
+	// owned by the writer of a secondary view.
+	t.releaseFilesBefore(t.tailId, !t.secondary)
 
 	// Close opened files and preopen all files
 	if err := t.preopen(); err != nil {
@@ -1086,6 +1086,108 @@ func TestFreezerReadonly(t *testing.T) {
 	}
 }
 
+// Tests that a secondary view of a table being appended to ignores the items
+// not fully written yet, without modifying the files.
+func TestFreezerSecondary(t *testing.T) {
+	dir := t.TempDir()
+	f, err := newTable(dir, "secondary", metrics.NewMeter(), metrics.NewMeter(), metrics.NewGauge(), 50, true, false)
+	if err != nil {
+		t.Fatalf("failed to instantiate table: %v", err)
+	}
+	defer f.Close()
+	writeChunks(t, f, 8, 32)
+
+	// Simulate an append in progress: data written, index entry partially
+	if _, err := f.head.Write(getChunk(32, 8)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.index.Write(make([]byte, indexEntrySize/2)); err != nil {
+		t.Fatal(err)
+	}
+	indexStat, _ := f.index.Stat()
+	headStat, _ := f.head.Stat()
+
+	// Readonly tables refuse to open, secondary ones expose the written items
+	if _, err := newTable(dir, "secondary", metrics.NewMeter(), metrics.NewMeter(), metrics.NewGauge(), 50, true, true); err == nil {
+		t.Fatalf("readonly table instantiation should fail for dangling data")
+	}
+	s, err := newSecondaryTable(dir, "secondary", metrics.NewMeter(), metrics.NewMeter(), metrics.NewGauge(), 50, true)
+	if err != nil {
+		t.Fatalf("failed to instantiate secondary table: %v", err)
+	}
+	defer s.Close()
+
+	if items := atomic.LoadUint64(&s.items); items != 8 {
+		t.Fatalf("secondary item count mismatch: have %d, want 8", items)
+	}
+	for i := uint64(0); i < 8; i++ {
+		blob, err := s.Retrieve(i)
+		if err != nil || !bytes.Equal(blob, getChunk(32, int(i))) {
+			t.Fatalf("item %d mismatch: %x (%v)", i, blob, err)
+		}
+	}
+	if _, err := s.Retrieve(8); err == nil {
+		t.Fatalf("secondary table exposes uncommitted item")
+	}
+	if stat, _ := f.index.Stat(); stat.Size() != indexStat.Size() {
+		t.Fatalf("index file modified: have %d bytes, want %d", stat.Size(), indexStat.Size())
+	}
+	if stat, _ := f.head.Stat(); stat.Size() != headStat.Size() {
+		t.Fatalf("head file modified: have %d bytes, want %d", stat.Size(), headStat.Size())
+	}
+}
+
+// Tests that scrubbing a table detects corrupted index entries and data items.
+func TestFreezerScrub(t *testing.T) {
+	dir := t.TempDir()
+	f, err := newTable(dir, "scrub", metrics.NewMeter(), metrics.NewMeter(), metrics.NewGauge(), 50, false, false)
+	if err != nil {
+		t.Fatalf("failed to instantiate table: %v", err)
+	}
+	defer f.Close()
+	writeChunks(t, f, 30, 32)
+
+	if item, err := f.scrub(0, 30); err != nil {
+		t.Fatalf("intact table reported corrupted at item %d: %v", item, err)
+	}
+	// Point the end of item 7 beyond its data file
+	indices, err := f.getIndices(7, 1)
+	if err != nil {
+		t.Fatalf("failed to read indices: %v", err)
+	}
+	entry := *indices[1]
+	entry.offset = 1 << 20
+	if _, err := f.index.WriteAt(entry.append(nil), 8*indexEntrySize); err != nil {
+		t.Fatal(err)
+	}
+	if item, err := f.scrub(0, 30); err == nil || item != 7 {
+		t.Fatalf("corrupted index mismatch: have item %d (%v), want item 7", item, err)
+	}
+	if item, err := f.scrub(10, 20); err != nil {
+		t.Fatalf("intact range reported corrupted at item %d: %v", item, err)
+	}
+	if _, err := f.index.WriteAt(indices[1].append(nil), 8*indexEntrySize); err != nil {
+		t.Fatal(err)
+	}
+	// Overwrite the compressed data of item 12
+	indices, err = f.getIndices(12, 1)
+	if err != nil {
+		t.Fatalf("failed to read indices: %v", err)
+	}
+	start, end, filenum := indices[0].bounds(indices[1])
+	data, err := os.OpenFile(filepath.Join(dir, fmt.Sprintf("scrub.%04d.cdat", filenum)), os.O_RDWR, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer data.Close()
+	if _, err := data.WriteAt(bytes.Repeat([]byte{0xff}, int(end-start)), int64(start)); err != nil {
+		t.Fatal(err)
+	}
+	if item, err := f.scrub(0, 30); err == nil || item != 12 {
+		t.Fatalf("corrupted data mismatch: have item %d (%v), want item 12", item, err)
+	}
+}
+
 // randTest performs random freezer table operations.
 // Instances of this test are created by Generate.
 type randTest []randTestStep
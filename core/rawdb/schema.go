@@ -84,6 +84,20 @@ var (
 	// transitionStatusKey tracks the eth2 transition status.
 	transitionStatusKey = []byte("eth2-transition")
 
+	// stateSchemeKey tracks the scheme used to persist the state trie nodes.
+	stateSchemeKey = []byte("TrieNodeScheme")
+
+	// reverseDiffHeadKey tracks the id of the latest reverse state diff.
+	reverseDiffHeadKey = []byte("ReverseDiffHead")
+
+	// stateDiffTailKey tracks the oldest block number whose state diffs may
+	// still be retained.
+	stateDiffTailKey = []byte("StateDiffTail")
+
+	// contractCreationsStartKey tracks the first block whose contract creations
+	// were recorded, the ones before it being unknown.
+	contractCreationsStartKey = []byte("ContractCreationsStart")
+
 	// Data item prefixes (use single byte to avoid mixing data types, avoid `i`, used for indexes).
 	headerPrefix       = []byte("h") // headerPrefix + num (uint64 big endian) + hash -> header
 	headerTDSuffix     = []byte("t") // headerPrefix + num (uint64 big endian) + hash + headerTDSuffix -> td
@@ -99,6 +113,19 @@ var (
 	SnapshotStoragePrefix = []byte("o") // SnapshotStoragePrefix + account hash + storage hash -> storage trie value
 	CodePrefix            = []byte("c") // CodePrefix + code hash -> account code
 	skeletonHeaderPrefix  = []byte("S") // skeletonHeaderPrefix + num (uint64 big endian) -> header
+	TrieNodeAccountPrefix = []byte("A") // TrieNodeAccountPrefix + hex path -> account trie node
+	TrieNodeStoragePrefix = []byte("O") // TrieNodeStoragePrefix + account hash + hex path -> storage trie node
+	reverseDiffPrefix     = []byte("D") // reverseDiffPrefix + id (uint64 big endian) -> reverse state diff
+	stateDiffPrefix       = []byte("d") // stateDiffPrefix + num (uint64 big endian) + hash -> block state diff
+	codeDeploymentPrefix  = []byte("C") // codeDeploymentPrefix + code hash + address -> contract deployment
+	codeBlockPrefix       = []byte("K") // codeBlockPrefix + num (uint64 big endian) -> contract deployments indexed in the block
+	creationsPrefix       = []byte("k") // creationsPrefix + num (uint64 big endian) + hash -> block contract creations
+	logAddressIndexPrefix = []byte("x") // logAddressIndexPrefix + address + num (uint64 big endian) -> log positions
+	logTopicIndexPrefix   = []byte("y") // logTopicIndexPrefix + topic position + topic + num (uint64 big endian) -> log positions
+	accountTxsPrefix      = []byte("w") // accountTxsPrefix + address + num (uint64 big endian) -> account transaction positions
+	accountTxsBlockPrefix = []byte("W") // accountTxsBlockPrefix + num (uint64 big endian) -> accounts indexed in the block
+	valueTransfersPrefix  = []byte("v") // valueTransfersPrefix + num (uint64 big endian) + hash -> block value transfers
+	transferIndexPrefix   = []byte("V") // transferIndexPrefix + address + num (uint64 big endian) + hash -> account value transfer positions
 
 	PreimagePrefix = []byte("secure-key-")       // PreimagePrefix + hash -> preimage
 	configPrefix   = []byte("ethereum-config-")  // config prefix for the db
@@ -106,6 +133,9 @@ var (
 
 	// Chain index prefixes (use `i` + single byte to avoid mixing data types).
 	BloomBitsIndexPrefix = []byte("iB") // BloomBitsIndexPrefix is the data table of a chain indexer to track its progress
+	CodeIndexPrefix      = []byte("iC") // CodeIndexPrefix is the data table of the contract code indexer to track its progress
+	LogIndexPrefix       = []byte("iL") // LogIndexPrefix is the data table of the log indexer to track its progress
+	AccountIndexPrefix   = []byte("iH") // AccountIndexPrefix is the data table of the account history indexer to track its progress
 
 	preimageCounter    = metrics.NewRegisteredCounter("db/preimage/total", nil)
 	preimageHitCounter = metrics.NewRegisteredCounter("db/preimage/hits", nil)
@@ -126,6 +156,54 @@ func encodeBlockNumber(number uint64) []byte {
 	return enc
 }
 
+// codeDeploymentKey = codeDeploymentPrefix + code hash + address
+func codeDeploymentKey(codeHash common.Hash, address common.Address) []byte {
+	return append(append(append([]byte{}, codeDeploymentPrefix...), codeHash.Bytes()...), address.Bytes()...)
+}
+
+// codeBlockKey = codeBlockPrefix + num (uint64 big endian)
+func codeBlockKey(number uint64) []byte {
+	return append(append([]byte{}, codeBlockPrefix...), encodeBlockNumber(number)...)
+}
+
+// creationsKey = creationsPrefix + num (uint64 big endian) + hash
+func creationsKey(number uint64, hash common.Hash) []byte {
+	key := append(append([]byte{}, creationsPrefix...), encodeBlockNumber(number)...)
+	return append(key, hash.Bytes()...)
+}
+
+// logAddressIndexKey = logAddressIndexPrefix + address + num (uint64 big endian)
+func logAddressIndexKey(address common.Address, number uint64) []byte {
+	return append(append(append([]byte{}, logAddressIndexPrefix...), address.Bytes()...), encodeBlockNumber(number)...)
+}
+
+// logTopicIndexKey = logTopicIndexPrefix + topic position + topic + num (uint64 big endian)
+func logTopicIndexKey(position int, topic common.Hash, number uint64) []byte {
+	return append(append(append(append([]byte{}, logTopicIndexPrefix...), byte(position)), topic.Bytes()...), encodeBlockNumber(number)...)
+}
+
+// accountTxsKey = accountTxsPrefix + address + num (uint64 big endian)
+func accountTxsKey(address common.Address, number uint64) []byte {
+	return append(append(append([]byte{}, accountTxsPrefix...), address.Bytes()...), encodeBlockNumber(number)...)
+}
+
+// accountTxsBlockKey = accountTxsBlockPrefix + num (uint64 big endian)
+func accountTxsBlockKey(number uint64) []byte {
+	return append(append([]byte{}, accountTxsBlockPrefix...), encodeBlockNumber(number)...)
+}
+
+// valueTransfersKey = valueTransfersPrefix + num (uint64 big endian) + hash
+func valueTransfersKey(number uint64, hash common.Hash) []byte {
+	key := append(append([]byte{}, valueTransfersPrefix...), encodeBlockNumber(number)...)
+	return append(key, hash.Bytes()...)
+}
+
+// transferIndexKey = transferIndexPrefix + address + num (uint64 big endian) + hash
+func transferIndexKey(address common.Address, number uint64, hash common.Hash) []byte {
+	key := append(append([]byte{}, transferIndexPrefix...), address.Bytes()...)
+	return append(append(key, encodeBlockNumber(number)...), hash.Bytes()...)
+}
+
 // headerKeyPrefix = headerPrefix + num (uint64 big endian)
 func headerKeyPrefix(number uint64) []byte {
 	return append(headerPrefix, encodeBlockNumber(number)...)
@@ -215,6 +293,26 @@ func IsCodeKey(key []byte) (bool, []byte) {
 	return false, nil
 }
 
+// accountTrieNodeKey = TrieNodeAccountPrefix + hex path
+func accountTrieNodeKey(path []byte) []byte {
+	return append(TrieNodeAccountPrefix, path...)
+}
+
+// storageTrieNodeKey = TrieNodeStoragePrefix + account hash + hex path
+func storageTrieNodeKey(accountHash common.Hash, path []byte) []byte {
+	return append(append(TrieNodeStoragePrefix, accountHash.Bytes()...), path...)
+}
+
+// reverseDiffKey = reverseDiffPrefix + id (uint64 big endian)
+func reverseDiffKey(id uint64) []byte {
+	return append(reverseDiffPrefix, encodeBlockNumber(id)...)
+}
+
+// stateDiffKey = stateDiffPrefix + num (uint64 big endian) + hash
+func stateDiffKey(number uint64, hash common.Hash) []byte {
+	return append(append(stateDiffPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
+}
+
 // configKey = configPrefix + hash
 func configKey(hash common.Hash) []byte {
 	return append(configPrefix, hash.Bytes()...)
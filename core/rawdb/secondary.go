@@ -0,0 +1,264 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/prometheus/tsdb/fileutil"
+)
+
+// checkpointRetries is the number of attempts made at copying a key-value store
+// which is modified while being copied.
+const checkpointRetries = 8
+
+// checkpointLock is the name of the file locked by the process using a
+// checkpoint, telling stale checkpoints apart from the ones in use.
+const checkpointLock = "SECONDARY.LOCK"
+
+// errSecondaryWritable is returned if a secondary database is requested to be
+// opened for writing.
+var errSecondaryWritable = errors.New("secondary database must be opened read-only")
+
+// checkpointdb is a key-value store opened from a checkpoint of a database in
+// use by another process. The checkpoint is deleted when the store is closed.
+type checkpointdb struct {
+	ethdb.Database
+	dir  string
+	lock fileutil.Releaser
+}
+
+// Close closes the key-value store and deletes the checkpoint.
+func (db *checkpointdb) Close() error {
+	err := db.Database.Close()
+	if rerr := os.RemoveAll(db.dir); err == nil {
+		err = rerr
+	}
+	db.lock.Release()
+	return err
+}
+
+// openSecondary opens a consistent, read-only, point-in-time view of a database
+// which is in use by another process, without taking its locks. The key-value
+// store is checkpointed before the freezer is opened: the owner only deletes
+// data from the key-value store after freezing it, so no block can be missed.
+func openSecondary(o OpenOptions) (ethdb.Database, error) {
+	if !o.ReadOnly {
+		return nil, errSecondaryWritable
+	}
+	if info, err := os.Stat(o.Directory); err != nil {
+		return nil, err
+	} else if !info.IsDir() {
+		return nil, fmt.Errorf("database %s is not a directory", o.Directory)
+	}
+	removeStaleCheckpoints(o.Directory)
+
+	checkpoint, lock, err := checkpointKeyValueStore(o.Directory)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Created secondary database checkpoint", "database", o.Directory, "checkpoint", checkpoint)
+
+	o.Directory = checkpoint
+	db, err := openKeyValueDatabase(o)
+	if err != nil {
+		os.RemoveAll(checkpoint)
+		lock.Release()
+		return nil, err
+	}
+	kvdb := &checkpointdb{Database: db, dir: checkpoint, lock: lock}
+	if len(o.AncientsDirectory) == 0 {
+		return kvdb, nil
+	}
+	frdb, err := newDatabaseWithFreezer(kvdb, o.AncientsDirectory, o.Namespace, true, true)
+	if err != nil {
+		kvdb.Close()
+		return nil, err
+	}
+	return frdb, nil
+}
+
+// checkpointKeyValueStore creates a point-in-time copy of the leveldb or pebble
+// database in the given directory next to it, returning the path of the copy
+// and the lock held on it until the copy is deleted. Table files are never
+// modified once written, so they are hard linked, whilst the rest of the files
+// are copied. The copy is consistent if the manifest has not changed in the
+// meantime, otherwise it is attempted again.
+func checkpointKeyValueStore(dir string) (string, fileutil.Releaser, error) {
+	var err error
+	for i := 0; i < checkpointRetries; i++ {
+		var (
+			checkpoint string
+			lock       fileutil.Releaser
+		)
+		if checkpoint, lock, err = tryCheckpoint(dir); err == nil {
+			return checkpoint, lock, nil
+		}
+		log.Debug("Retrying database checkpoint", "database", dir, "err", err)
+		time.Sleep(time.Duration(i+1) * 100 * time.Millisecond)
+	}
+	return "", nil, fmt.Errorf("failed to checkpoint database %s: %v", dir, err)
+}
+
+// tryCheckpoint attempts to copy the database in the given directory once.
+func tryCheckpoint(dir string) (string, fileutil.Releaser, error) {
+	before, err := checkpointManifest(dir)
+	if err != nil {
+		return "", nil, err
+	}
+	checkpoint, err := os.MkdirTemp(filepath.Dir(dir), filepath.Base(dir)+"-secondary-")
+	if err != nil {
+		return "", nil, err
+	}
+	lock, _, err := fileutil.Flock(filepath.Join(checkpoint, checkpointLock))
+	if err != nil {
+		os.RemoveAll(checkpoint)
+		return "", nil, err
+	}
+	// Drop the half-made copy if anything goes wrong
+	fail := func(err error) (string, fileutil.Releaser, error) {
+		os.RemoveAll(checkpoint)
+		lock.Release()
+		return "", nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fail(err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Type().IsRegular() || isCheckpointSkipped(name) {
+			continue
+		}
+		src, dst := filepath.Join(dir, name), filepath.Join(checkpoint, name)
+		if isTableFile(name) {
+			err = linkOrCopyFile(src, dst)
+		} else {
+			err = copyFile(src, dst)
+		}
+		if err != nil {
+			return fail(err)
+		}
+	}
+	after, err := checkpointManifest(dir)
+	if err != nil {
+		return fail(err)
+	}
+	if len(before) != len(after) {
+		return fail(errors.New("database manifest changed"))
+	}
+	for name, size := range before {
+		if after[name] != size {
+			return fail(errors.New("database manifest changed"))
+		}
+	}
+	return checkpoint, lock, nil
+}
+
+// removeStaleCheckpoints deletes the checkpoints of the database in the given
+// directory left behind by secondary views which were not closed cleanly. The
+// checkpoints of the views still open are locked and kept.
+func removeStaleCheckpoints(dir string) {
+	stale, _ := filepath.Glob(filepath.Join(filepath.Dir(dir), filepath.Base(dir)+"-secondary-*"))
+	for _, checkpoint := range stale {
+		if info, err := os.Stat(checkpoint); err != nil || !info.IsDir() {
+			continue
+		}
+		lock, _, err := fileutil.Flock(filepath.Join(checkpoint, checkpointLock))
+		if err != nil {
+			continue
+		}
+		if err := os.RemoveAll(checkpoint); err != nil {
+			log.Warn("Failed to remove stale database checkpoint", "checkpoint", checkpoint, "err", err)
+		} else {
+			log.Info("Removed stale database checkpoint", "checkpoint", checkpoint)
+		}
+		lock.Release()
+	}
+}
+
+// checkpointManifest returns the sizes of the files describing the contents of
+// the database, which are updated whenever tables or journals are created or
+// removed.
+func checkpointManifest(dir string) (map[string]int64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	manifest := make(map[string]int64)
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Type().IsRegular() || isCheckpointSkipped(name) || isTableFile(name) || filepath.Ext(name) == ".log" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		manifest[name] = info.Size()
+	}
+	return manifest, nil
+}
+
+// isCheckpointSkipped reports whether the file is private to the process using
+// the database and is not part of a checkpoint.
+func isCheckpointSkipped(name string) bool {
+	return name == "LOCK" || name == "LOG" || name == "LOG.old"
+}
+
+// isTableFile reports whether the file is a leveldb or pebble table file, which
+// is immutable once written.
+func isTableFile(name string) bool {
+	ext := filepath.Ext(name)
+	return ext == ".ldb" || ext == ".sst"
+}
+
+// linkOrCopyFile hard links the source file to the destination, falling back
+// to copying it if the two are on different devices.
+func linkOrCopyFile(src, dst string) error {
+	err := os.Link(src, dst)
+	if err == nil || os.IsNotExist(err) {
+		return err
+	}
+	return copyFile(src, dst)
+}
+
+// copyFile copies the current contents of the source file to the destination.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Tests that a secondary database opened next to a live one sees the contents
+// at the time of opening only, and leaves nothing behind once closed, cleaning
+// up the checkpoints of views which were not closed too.
+func TestSecondaryDatabase(t *testing.T) {
+	engines := []string{dbLeveldb}
+	if PebbleEnabled {
+		engines = append(engines, dbPebble)
+	}
+	for _, engine := range engines {
+		var (
+			root    = t.TempDir()
+			dir     = filepath.Join(root, "chaindata")
+			ancient = filepath.Join(dir, "ancient")
+		)
+		db, err := Open(OpenOptions{Type: engine, Directory: dir, AncientsDirectory: ancient})
+		if err != nil {
+			t.Fatalf("%s: failed to create database: %v", engine, err)
+		}
+		defer db.Close()
+
+		for i := 0; i < 100; i++ {
+			db.Put([]byte(fmt.Sprintf("key-%d", i)), []byte(fmt.Sprintf("value-%d", i)))
+		}
+		if _, err := WriteAncientBlocks(db, secondaryTestBlocks(0, 10), make([]types.Receipts, 10), big.NewInt(0)); err != nil {
+			t.Fatalf("%s: failed to write ancients: %v", engine, err)
+		}
+		if _, err := Open(OpenOptions{Directory: dir, AncientsDirectory: ancient, Secondary: true}); err != errSecondaryWritable {
+			t.Fatalf("%s: writable secondary error mismatch: have %v, want %v", engine, err, errSecondaryWritable)
+		}
+		stale := filepath.Join(root, "chaindata-secondary-stale")
+		if err := os.MkdirAll(stale, 0755); err != nil {
+			t.Fatal(err)
+		}
+		secondary, err := Open(OpenOptions{Directory: dir, AncientsDirectory: ancient, ReadOnly: true, Secondary: true})
+		if err != nil {
+			t.Fatalf("%s: failed to open secondary database: %v", engine, err)
+		}
+		if _, err := os.Stat(stale); !os.IsNotExist(err) {
+			t.Fatalf("%s: stale checkpoint not removed: %v", engine, err)
+		}
+		// Another view must leave the checkpoint in use alone
+		other, err := Open(OpenOptions{Directory: dir, AncientsDirectory: ancient, ReadOnly: true, Secondary: true})
+		if err != nil {
+			t.Fatalf("%s: failed to open second secondary database: %v", engine, err)
+		}
+		if err := other.Close(); err != nil {
+			t.Fatalf("%s: failed to close second secondary database: %v", engine, err)
+		}
+		// Keep modifying the live database, the secondary must not be affected
+		db.Put([]byte("key-late"), []byte("value-late"))
+		if _, err := WriteAncientBlocks(db, secondaryTestBlocks(10, 5), make([]types.Receipts, 5), big.NewInt(0)); err != nil {
+			t.Fatalf("%s: failed to write ancients: %v", engine, err)
+		}
+		for i := 0; i < 100; i++ {
+			value, err := secondary.Get([]byte(fmt.Sprintf("key-%d", i)))
+			if err != nil || string(value) != fmt.Sprintf("value-%d", i) {
+				t.Fatalf("%s: key %d mismatch: have %q (%v)", engine, i, value, err)
+			}
+		}
+		if has, _ := secondary.Has([]byte("key-late")); has {
+			t.Fatalf("%s: secondary sees later write", engine)
+		}
+		if frozen, _ := secondary.Ancients(); frozen != 10 {
+			t.Fatalf("%s: secondary ancients mismatch: have %d, want 10", engine, frozen)
+		}
+		if hash := ReadCanonicalHash(secondary, 9); hash != secondaryTestBlocks(9, 1)[0].Hash() {
+			t.Fatalf("%s: frozen hash mismatch: have %x", engine, hash)
+		}
+		if err := secondary.Put([]byte("key"), []byte("value")); err == nil {
+			t.Fatalf("%s: secondary database accepted a write", engine)
+		}
+		if err := secondary.Close(); err != nil {
+			t.Fatalf("%s: failed to close secondary database: %v", engine, err)
+		}
+		entries, _ := os.ReadDir(root)
+		if len(entries) != 1 {
+			t.Fatalf("%s: checkpoint left behind: %v", engine, entries)
+		}
+		// The live database is still intact
+		if frozen, _ := db.Ancients(); frozen != 15 {
+			t.Fatalf("%s: live ancients mismatch: have %d, want 15", engine, frozen)
+		}
+	}
+}
+
+// secondaryTestBlocks creates empty blocks with the given numbers.
+func secondaryTestBlocks(start, count int) []*types.Block {
+	blocks := make([]*types.Block, count)
+	for i := range blocks {
+		blocks[i] = types.NewBlockWithHeader(&types.Header{Number: big.NewInt(int64(start + i))})
+	}
+	return blocks
+}
@@ -0,0 +1,236 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	reorgRefusedMeter       = metrics.NewRegisteredMeter("chain/reorg/refused", nil)
+	checkpointMismatchMeter = metrics.NewRegisteredMeter("chain/checkpoint/mismatch", nil)
+)
+
+// Checkpoint is a trusted block the canonical chain must contain. Checkpoints
+// may carry signatures of the checkpoint oracle signers, produced with the same
+// EIP-191 scheme the on-chain checkpoint oracle contract verifies, with the
+// block number in place of the section index.
+type Checkpoint struct {
+	Number     uint64          `json:"number"`
+	Hash       common.Hash     `json:"hash"`
+	Signatures []hexutil.Bytes `json:"signatures,omitempty"`
+}
+
+// Verify checks that the checkpoint is signed by at least the threshold number
+// of distinct signers of the given checkpoint oracle.
+func (c *Checkpoint) Verify(oracle *params.CheckpointOracleConfig) error {
+	// EIP 191 style signatures
+	//
+	// Arguments when calculating hash to validate
+	// 1: byte(0x19) - the initial 0x19 byte
+	// 2: byte(0) - the version byte (data with intended validator)
+	// 3: this - the validator address
+	// --  Application specific data
+	// 4 : block number (uint64)
+	// 5 : block hash (bytes32)
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, c.Number)
+	data := append([]byte{0x19, 0x00}, append(oracle.Address.Bytes(), append(buf, c.Hash[:]...)...)...)
+	sighash := crypto.Keccak256(data)
+
+	signers := make(map[common.Address]struct{})
+	for _, signature := range c.Signatures {
+		if len(signature) != crypto.SignatureLength {
+			continue
+		}
+		sig := common.CopyBytes(signature)
+		if sig[crypto.RecoveryIDOffset] >= 27 {
+			sig[crypto.RecoveryIDOffset] -= 27 // Transform V from 27/28 to 0/1 according to the yellow paper
+		}
+		pubkey, err := crypto.SigToPub(sighash, sig)
+		if err != nil {
+			continue
+		}
+		signer := crypto.PubkeyToAddress(*pubkey)
+		for _, s := range oracle.Signers {
+			if s == signer {
+				signers[signer] = struct{}{}
+			}
+		}
+	}
+	if uint64(len(signers)) < oracle.Threshold {
+		return fmt.Errorf("checkpoint #%d [%x..] has %d valid signatures, %d required", c.Number, c.Hash[:4], len(signers), oracle.Threshold)
+	}
+	return nil
+}
+
+// LoadCheckpoints reads a JSON list of checkpoints from the given file. If an
+// oracle configuration is supplied, every checkpoint must be signed by enough
+// of its signers, otherwise the file is trusted as is.
+func LoadCheckpoints(file string, oracle *params.CheckpointOracleConfig) ([]Checkpoint, error) {
+	blob, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var checkpoints []Checkpoint
+	if err := json.Unmarshal(blob, &checkpoints); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint file %s: %v", file, err)
+	}
+	if oracle != nil {
+		for _, checkpoint := range checkpoints {
+			if err := checkpoint.Verify(oracle); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return checkpoints, nil
+}
+
+// SetMaxReorgDepth overrides the maximum number of canonical blocks a reorg may
+// drop. Heavier forks branching off deeper are kept as side chains. A zero depth
+// allows reorgs of any depth.
+func (bc *BlockChain) SetMaxReorgDepth(depth uint64) error {
+	if !bc.chainmu.TryLock() {
+		return errChainStopped
+	}
+	defer bc.chainmu.Unlock()
+
+	bc.maxReorgDepth = depth
+	return nil
+}
+
+// MaxReorgDepth returns the maximum number of canonical blocks a reorg may drop.
+func (bc *BlockChain) MaxReorgDepth() uint64 {
+	return bc.maxReorgDepth
+}
+
+// AddCheckpoint registers a trusted block the chain must not deviate from. A
+// canonical block conflicting with the checkpoint is reported, but not rolled
+// back automatically.
+func (bc *BlockChain) AddCheckpoint(number uint64, hash common.Hash) {
+	bc.checkpointLock.Lock()
+	bc.checkpoints[number] = hash
+	bc.checkpointLock.Unlock()
+
+	if canon := bc.GetCanonicalHash(number); canon != (common.Hash{}) && canon != hash {
+		log.Error("Canonical chain conflicts with checkpoint", "number", number, "hash", hash, "canonical", canon)
+	}
+}
+
+// Checkpoints returns the trusted checkpoints ordered by block number.
+func (bc *BlockChain) Checkpoints() []Checkpoint {
+	bc.checkpointLock.RLock()
+	defer bc.checkpointLock.RUnlock()
+
+	checkpoints := make([]Checkpoint, 0, len(bc.checkpoints))
+	for number, hash := range bc.checkpoints {
+		checkpoints = append(checkpoints, Checkpoint{Number: number, Hash: hash})
+	}
+	sort.Slice(checkpoints, func(i, j int) bool { return checkpoints[i].Number < checkpoints[j].Number })
+	return checkpoints
+}
+
+// checkCheckpoint returns an error if the header is at the height of a trusted
+// checkpoint but has a different hash.
+func (bc *BlockChain) checkCheckpoint(header *types.Header) error {
+	bc.checkpointLock.RLock()
+	hash, ok := bc.checkpoints[header.Number.Uint64()]
+	bc.checkpointLock.RUnlock()
+
+	if !ok || hash == header.Hash() {
+		return nil
+	}
+	checkpointMismatchMeter.Mark(1)
+	log.Warn("Rejected block conflicting with checkpoint", "number", header.Number, "hash", header.Hash(), "checkpoint", hash)
+
+	err := fmt.Errorf("%w: #%d [%x..] != [%x..]", ErrCheckpointMismatch, header.Number, header.Hash().Bytes()[:4], hash[:4])
+	if head := bc.CurrentBlock(); head.NumberU64() >= header.Number.Uint64() {
+		bc.refusedFeed.Send(ReorgRefusedEvent{Head: head.Header(), Block: header, Depth: head.NumberU64() - header.Number.Uint64() + 1, Reason: err})
+	}
+	return err
+}
+
+// checkReorg verifies that switching the canonical chain from head over to the
+// fork ending in block neither drops more blocks than the maximum reorg depth
+// permits, nor replaces a checkpointed block. It returns the number of canonical
+// blocks the reorg would drop.
+//
+// The optional forks map caches the fork point of the blocks already checked,
+// so that a batch of side blocks is only walked back once and not once for
+// every block. It must be reset whenever the canonical chain changes.
+func (bc *BlockChain) checkReorg(head *types.Block, block *types.Block, forks map[common.Hash]uint64) (uint64, error) {
+	// Walk the fork back until it joins the canonical chain or a block already
+	// checked. Missing ancestors are left to the reorg itself to report.
+	var (
+		fork     uint64
+		ancestor = block.Header()
+	)
+	for ancestor != nil {
+		if number, ok := forks[ancestor.Hash()]; ok {
+			fork = number
+			break
+		}
+		if bc.GetCanonicalHash(ancestor.Number.Uint64()) == ancestor.Hash() {
+			fork = ancestor.Number.Uint64()
+			break
+		}
+		ancestor = bc.GetHeader(ancestor.ParentHash, ancestor.Number.Uint64()-1)
+	}
+	if ancestor == nil {
+		return 0, nil
+	}
+	if forks != nil {
+		forks[block.Hash()] = fork
+	}
+	if head.NumberU64() <= fork {
+		return 0, nil
+	}
+	depth := head.NumberU64() - fork
+	if bc.maxReorgDepth > 0 && depth > bc.maxReorgDepth {
+		return depth, fmt.Errorf("%w: %d > %d", ErrReorgTooDeep, depth, bc.maxReorgDepth)
+	}
+	bc.checkpointLock.RLock()
+	defer bc.checkpointLock.RUnlock()
+
+	for number, hash := range bc.checkpoints {
+		if number > fork && number <= head.NumberU64() && bc.GetCanonicalHash(number) == hash {
+			return depth, fmt.Errorf("%w: fork at #%d drops checkpoint #%d", ErrCheckpointMismatch, fork, number)
+		}
+	}
+	return depth, nil
+}
+
+// refuseReorg raises the alerts for a reorg the chain refused to perform.
+func (bc *BlockChain) refuseReorg(head *types.Header, block *types.Header, depth uint64, reason error) {
+	reorgRefusedMeter.Mark(1)
+	log.Warn("Refused chain reorganisation", "number", head.Number, "hash", head.Hash(),
+		"fork", block.Number, "forkhash", block.Hash(), "depth", depth, "reason", reason)
+
+	bc.refusedFeed.Send(ReorgRefusedEvent{Head: head, Block: block, Depth: depth, Reason: reason})
+}
@@ -0,0 +1,223 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that a heavier fork branching off deeper than the maximum reorg depth is
+// kept on the side and reported, while shallower ones are still accepted.
+func TestMaxReorgDepth(t *testing.T) {
+	_, blockchain, err := newCanonical(ethash.NewFaker(), 0, true)
+	if err != nil {
+		t.Fatalf("failed to create pristine chain: %v", err)
+	}
+	defer blockchain.Stop()
+
+	if err := blockchain.SetMaxReorgDepth(3); err != nil {
+		t.Fatalf("failed to set max reorg depth: %v", err)
+	}
+	chain, _ := GenerateChain(blockchain.chainConfig, blockchain.genesisBlock, ethash.NewFaker(), blockchain.db, 10, func(i int, gen *BlockGen) {})
+	if _, err := blockchain.InsertChain(chain); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	refusedCh := make(chan ReorgRefusedEvent, 64)
+	sub := blockchain.SubscribeReorgRefusedEvent(refusedCh)
+	defer sub.Unsubscribe()
+
+	// A longer fork from block #5 would drop 5 canonical blocks, refuse it
+	deep, _ := GenerateChain(blockchain.chainConfig, chain[4], ethash.NewFaker(), blockchain.db, 8, func(i int, gen *BlockGen) {
+		gen.SetExtra([]byte("deep"))
+	})
+	if _, err := blockchain.InsertChain(deep); err != nil {
+		t.Fatalf("failed to insert deep fork: %v", err)
+	}
+	if head := blockchain.CurrentBlock(); head.Hash() != chain[len(chain)-1].Hash() {
+		t.Fatalf("head switched to deep fork: #%d [%x]", head.NumberU64(), head.Hash())
+	}
+	select {
+	case ev := <-refusedCh:
+		if !errors.Is(ev.Reason, ErrReorgTooDeep) {
+			t.Errorf("refusal reason mismatch: have %v, want %v", ev.Reason, ErrReorgTooDeep)
+		}
+		if ev.Depth != 5 {
+			t.Errorf("refused depth mismatch: have %d, want 5", ev.Depth)
+		}
+		if ev.Head.Hash() != chain[len(chain)-1].Hash() {
+			t.Errorf("refused head mismatch: have %x, want %x", ev.Head.Hash(), chain[len(chain)-1].Hash())
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Timeout. Refused reorg event was not fired")
+	}
+	// A longer fork from block #8 only drops 2 canonical blocks, accept it
+	shallow, _ := GenerateChain(blockchain.chainConfig, chain[7], ethash.NewFaker(), blockchain.db, 4, func(i int, gen *BlockGen) {
+		gen.SetExtra([]byte("shallow"))
+	})
+	if _, err := blockchain.InsertChain(shallow); err != nil {
+		t.Fatalf("failed to insert shallow fork: %v", err)
+	}
+	if head := blockchain.CurrentBlock(); head.Hash() != shallow[len(shallow)-1].Hash() {
+		t.Fatalf("head mismatch: have #%d [%x], want #%d [%x]", head.NumberU64(), head.Hash(), shallow[len(shallow)-1].NumberU64(), shallow[len(shallow)-1].Hash())
+	}
+}
+
+// Tests that blocks conflicting with a trusted checkpoint are rejected, and that
+// forks dropping a checkpointed block are refused.
+func TestCheckpointReorgProtection(t *testing.T) {
+	_, blockchain, err := newCanonical(ethash.NewFaker(), 0, true)
+	if err != nil {
+		t.Fatalf("failed to create pristine chain: %v", err)
+	}
+	defer blockchain.Stop()
+
+	chain, _ := GenerateChain(blockchain.chainConfig, blockchain.genesisBlock, ethash.NewFaker(), blockchain.db, 10, func(i int, gen *BlockGen) {})
+	if _, err := blockchain.InsertChain(chain[:4]); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	blockchain.AddCheckpoint(chain[5].NumberU64(), chain[5].Hash())
+
+	// A fork disagreeing with the checkpoint is rejected outright
+	fork, _ := GenerateChain(blockchain.chainConfig, chain[3], ethash.NewFaker(), blockchain.db, 4, func(i int, gen *BlockGen) {
+		gen.SetExtra([]byte("fork"))
+	})
+	if n, err := blockchain.InsertChain(fork); !errors.Is(err, ErrCheckpointMismatch) || n != 1 {
+		t.Fatalf("fork insert mismatch: have %d/%v, want 1/%v", n, err, ErrCheckpointMismatch)
+	}
+	if _, err := blockchain.InsertChain(chain[4:]); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	// A checkpoint registered for a canonical block guards against heavier forks
+	blockchain.AddCheckpoint(chain[8].NumberU64(), chain[8].Hash())
+	fork, _ = GenerateChain(blockchain.chainConfig, chain[6], ethash.NewFaker(), blockchain.db, 6, func(i int, gen *BlockGen) {
+		gen.SetExtra([]byte("fork"))
+	})
+	if n, err := blockchain.InsertChain(fork); !errors.Is(err, ErrCheckpointMismatch) || n != 1 {
+		t.Fatalf("fork insert mismatch: have %d/%v, want 1/%v", n, err, ErrCheckpointMismatch)
+	}
+	if head := blockchain.CurrentBlock(); head.Hash() != chain[len(chain)-1].Hash() {
+		t.Fatalf("head switched away from checkpoint: #%d [%x]", head.NumberU64(), head.Hash())
+	}
+	if checkpoints := blockchain.Checkpoints(); len(checkpoints) != 2 || checkpoints[0].Number != chain[5].NumberU64() {
+		t.Errorf("checkpoint list mismatch: %v", checkpoints)
+	}
+}
+
+// Tests that a heavier fork crossing a checkpoint is refused even if its blocks
+// at the checkpoint height were imported before the checkpoint was registered.
+func TestCheckpointReorgProtectionCrossing(t *testing.T) {
+	_, blockchain, err := newCanonical(ethash.NewFaker(), 0, true)
+	if err != nil {
+		t.Fatalf("failed to create pristine chain: %v", err)
+	}
+	defer blockchain.Stop()
+
+	chain, _ := GenerateChain(blockchain.chainConfig, blockchain.genesisBlock, ethash.NewFaker(), blockchain.db, 10, func(i int, gen *BlockGen) {})
+	if _, err := blockchain.InsertChain(chain); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	// Import the lighter part of a fork from block #4 as a side chain
+	fork, _ := GenerateChain(blockchain.chainConfig, chain[3], ethash.NewFaker(), blockchain.db, 10, func(i int, gen *BlockGen) {
+		gen.SetExtra([]byte("fork"))
+	})
+	if _, err := blockchain.InsertChain(fork[:5]); err != nil {
+		t.Fatalf("failed to insert side chain: %v", err)
+	}
+	refusedCh := make(chan ReorgRefusedEvent, 64)
+	sub := blockchain.SubscribeReorgRefusedEvent(refusedCh)
+	defer sub.Unsubscribe()
+
+	// Checkpoint a canonical block the fork replaces, then make the fork heavier
+	blockchain.AddCheckpoint(chain[5].NumberU64(), chain[5].Hash())
+	if _, err := blockchain.InsertChain(fork[5:]); err != nil {
+		t.Fatalf("failed to insert fork: %v", err)
+	}
+	if head := blockchain.CurrentBlock(); head.Hash() != chain[len(chain)-1].Hash() {
+		t.Fatalf("head switched to fork crossing checkpoint: #%d [%x]", head.NumberU64(), head.Hash())
+	}
+	select {
+	case ev := <-refusedCh:
+		if !errors.Is(ev.Reason, ErrCheckpointMismatch) {
+			t.Errorf("refusal reason mismatch: have %v, want %v", ev.Reason, ErrCheckpointMismatch)
+		}
+		if ev.Depth != 6 {
+			t.Errorf("refused depth mismatch: have %d, want 6", ev.Depth)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Timeout. Refused reorg event was not fired")
+	}
+}
+
+// Tests that signed checkpoint files are verified against the oracle signers.
+func TestLoadCheckpoints(t *testing.T) {
+	var (
+		key1, _ = crypto.GenerateKey()
+		key2, _ = crypto.GenerateKey()
+		oracle  = &params.CheckpointOracleConfig{
+			Address:   common.Address{0xc0},
+			Signers:   []common.Address{crypto.PubkeyToAddress(key1.PublicKey), crypto.PubkeyToAddress(key2.PublicKey)},
+			Threshold: 2,
+		}
+		checkpoint = Checkpoint{Number: 4095, Hash: common.Hash{0x01}}
+	)
+	sign := func(key []byte) hexutil.Bytes {
+		buf := make([]byte, 8)
+		binary.BigEndian.PutUint64(buf, checkpoint.Number)
+		data := append([]byte{0x19, 0x00}, append(oracle.Address.Bytes(), append(buf, checkpoint.Hash[:]...)...)...)
+		priv, _ := crypto.ToECDSA(key)
+		sig, _ := crypto.Sign(crypto.Keccak256(data), priv)
+		sig[crypto.RecoveryIDOffset] += 27
+		return sig
+	}
+	write := func(checkpoints []Checkpoint) string {
+		file := filepath.Join(t.TempDir(), "checkpoints.json")
+		blob, _ := json.Marshal(checkpoints)
+		if err := os.WriteFile(file, blob, 0600); err != nil {
+			t.Fatalf("failed to write checkpoints: %v", err)
+		}
+		return file
+	}
+	// Duplicate signatures of the same signer don't reach the threshold
+	checkpoint.Signatures = []hexutil.Bytes{sign(crypto.FromECDSA(key1)), sign(crypto.FromECDSA(key1))}
+	file := write([]Checkpoint{checkpoint})
+	if _, err := LoadCheckpoints(file, oracle); err == nil {
+		t.Fatalf("expected error for insufficient signatures")
+	}
+	if checkpoints, err := LoadCheckpoints(file, nil); err != nil || len(checkpoints) != 1 {
+		t.Fatalf("failed to load unverified checkpoints: %v", err)
+	}
+	checkpoint.Signatures = []hexutil.Bytes{sign(crypto.FromECDSA(key1)), sign(crypto.FromECDSA(key2))}
+	checkpoints, err := LoadCheckpoints(write([]Checkpoint{checkpoint}), oracle)
+	if err != nil {
+		t.Fatalf("failed to load signed checkpoints: %v", err)
+	}
+	if len(checkpoints) != 1 || checkpoints[0].Number != checkpoint.Number || checkpoints[0].Hash != checkpoint.Hash {
+		t.Errorf("checkpoint mismatch: have %v, want %v", checkpoints, checkpoint)
+	}
+}
@@ -0,0 +1,406 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+const (
+	scrubBatch       = 256 // Number of ancient items or blocks checked between pauses
+	scrubStateNodes  = 256 // Number of trie nodes sampled from the head state per round
+	maxScrubFindings = 64  // Maximum number of findings retained per round
+)
+
+var (
+	// scrubPauseFactor is the time paused after each batch relative to the time
+	// spent checking it, keeping the scrubber at roughly a tenth of the IO.
+	scrubPauseFactor = 9
+
+	// scrubRoundInterval is the time waited between two scrubbing rounds.
+	scrubRoundInterval = 6 * time.Hour
+
+	// scrubHeadDistance is the number of blocks below the head skipped by the
+	// chain checks, as they might be reorged whilst being checked.
+	scrubHeadDistance = uint64(64)
+
+	scrubRoundsCounter   = metrics.NewRegisteredCounter("chain/scrub/rounds", nil)
+	scrubCheckedMeter    = metrics.NewRegisteredMeter("chain/scrub/checked", nil)
+	scrubFindingsCounter = metrics.NewRegisteredCounter("chain/scrub/findings", nil)
+	scrubPositionGauge   = metrics.NewRegisteredGauge("chain/scrub/position", nil)
+)
+
+// ScrubFinding is an inconsistency found in the database by the scrubber.
+type ScrubFinding struct {
+	Stage  string    `json:"stage"`  // Scrubbing stage which found the inconsistency
+	Number uint64    `json:"number"` // Number of the affected block, or of the head for the state
+	Error  string    `json:"error"`  // Description of the inconsistency
+	Time   time.Time `json:"time"`   // Time the inconsistency was found at
+}
+
+// ScrubStatus is the progress and the results of the database scrubber.
+type ScrubStatus struct {
+	Round    uint64 `json:"round"`    // Number of the current round, starting from one
+	Stage    string `json:"stage"`    // Current stage: ancients, chain, state or idle
+	Position uint64 `json:"position"` // Number of the next block checked in the current stage
+	Target   uint64 `json:"target"`   // Number of the block the current stage ends at
+
+	Ancients uint64 `json:"ancients"` // Number of ancient items checked in the current round
+	Blocks   uint64 `json:"blocks"`   // Number of blocks checked in the current round
+	Nodes    uint64 `json:"nodes"`    // Number of trie nodes checked in the current round
+
+	// Findings are the inconsistencies found in the last completed and in the
+	// current round.
+	Findings []ScrubFinding `json:"findings"`
+
+	// RepairNeeded is set if chain data is corrupted, which can be dropped
+	// by rewinding the chain to RepairHead via debug_setHead, resyncing the
+	// blocks above it. State corruption is listed in the findings, but can't
+	// be repaired by rewinding.
+	RepairNeeded bool    `json:"repairNeeded"`
+	RepairHead   *uint64 `json:"repairHead,omitempty"`
+}
+
+// scrubber tracks the progress and the findings of the database scrubber.
+type scrubber struct {
+	status   ScrubStatus    // Progress of the current round
+	findings []ScrubFinding // Inconsistencies found in the current round
+	previous []ScrubFinding // Inconsistencies found in the last completed round
+	lock     sync.RWMutex
+}
+
+// ScrubStatus returns the progress and the results of the database scrubber, or
+// nil if it's not enabled.
+func (bc *BlockChain) ScrubStatus() *ScrubStatus {
+	s := bc.scrubber
+	if s == nil {
+		return nil
+	}
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	status := s.status
+	status.Findings = append(append([]ScrubFinding{}, s.previous...), s.findings...)
+	for _, finding := range status.Findings {
+		if finding.Stage == "state" {
+			continue
+		}
+		head := uint64(0)
+		if finding.Number > 0 {
+			head = finding.Number - 1
+		}
+		if status.RepairHead == nil || head < *status.RepairHead {
+			status.RepairHead = &head
+		}
+	}
+	status.RepairNeeded = status.RepairHead != nil
+	return &status
+}
+
+// update modifies the progress of the current round.
+func (s *scrubber) update(fn func(status *ScrubStatus)) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	fn(&s.status)
+	scrubPositionGauge.Update(int64(s.status.Position))
+}
+
+// report records an inconsistency found in the database.
+func (s *scrubber) report(stage string, number uint64, err error) {
+	log.Error("Database scrubber found inconsistency", "stage", stage, "number", number, "err", err)
+	scrubFindingsCounter.Inc(1)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if len(s.findings) < maxScrubFindings {
+		s.findings = append(s.findings, ScrubFinding{Stage: stage, Number: number, Error: err.Error(), Time: time.Now()})
+	}
+}
+
+// scrubDatabase periodically walks the ancient store, the canonical chain and a
+// sample of the head state, checking them for inconsistencies. The scrubber
+// pauses between batches to leave most of the IO to the chain.
+func (bc *BlockChain) scrubDatabase() {
+	defer bc.wg.Done()
+
+	for {
+		if !bc.scrubRound() {
+			return
+		}
+		select {
+		case <-time.After(scrubRoundInterval):
+		case <-bc.quit:
+			return
+		}
+	}
+}
+
+// scrubRound runs a complete scrubbing round, returning false if the chain is
+// stopped in the meantime.
+func (bc *BlockChain) scrubRound() bool {
+	s := bc.scrubber
+	s.lock.Lock()
+	s.status = ScrubStatus{Round: s.status.Round + 1}
+	s.findings = nil
+	round := s.status.Round
+	s.lock.Unlock()
+
+	start := time.Now()
+	log.Info("Started database scrubbing round", "round", round)
+	if !bc.scrubAncients() || !bc.scrubChain() {
+		return false
+	}
+	bc.scrubState()
+
+	s.lock.Lock()
+	s.previous, s.findings = s.findings, nil
+	s.status.Stage = "idle"
+	status := s.status
+	findings := len(s.previous)
+	s.lock.Unlock()
+
+	scrubRoundsCounter.Inc(1)
+	if findings > 0 {
+		log.Error("Database scrubbing found inconsistencies", "round", round, "findings", findings, "elapsed", common.PrettyDuration(time.Since(start)))
+		if repair := bc.ScrubStatus().RepairHead; repair != nil {
+			log.Error("Chain data corrupted, rewind with debug_setHead to repair", "number", *repair)
+		}
+	} else {
+		log.Info("Database scrubbing round complete", "round", round, "ancients", status.Ancients, "blocks", status.Blocks, "nodes", status.Nodes, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	return true
+}
+
+// scrubPause waits in proportion to the time spent on the last batch, returning
+// false if the chain is stopped in the meantime.
+func (bc *BlockChain) scrubPause(elapsed time.Duration) bool {
+	select {
+	case <-time.After(elapsed * time.Duration(scrubPauseFactor)):
+		return true
+	case <-bc.quit:
+		return false
+	}
+}
+
+// scrubAncients checks the index and the data consistency of the ancient tables.
+func (bc *BlockChain) scrubAncients() bool {
+	frozen, err := bc.db.Ancients()
+	if err != nil || frozen == 0 {
+		return true
+	}
+	bc.scrubber.update(func(status *ScrubStatus) {
+		status.Stage, status.Position, status.Target = "ancients", 0, frozen-1
+	})
+	for number := uint64(0); number < frozen; {
+		start := time.Now()
+		count := uint64(scrubBatch)
+		if number+count > frozen {
+			count = frozen - number
+		}
+		next := number + count
+
+		err := rawdb.ScrubAncients(bc.db, number, count)
+		if err != nil {
+			var serr *rawdb.AncientScrubError
+			if !errors.As(err, &serr) {
+				log.Debug("Ancient store not scrubbable", "err", err)
+				return true
+			}
+			bc.scrubber.report("ancients", serr.Item, serr)
+			next = serr.Item + 1
+		}
+		bc.scrubber.update(func(status *ScrubStatus) {
+			status.Position, status.Ancients = next, status.Ancients+next-number
+		})
+		scrubCheckedMeter.Mark(int64(next - number))
+		number = next
+
+		if !bc.scrubPause(time.Since(start)) {
+			return false
+		}
+	}
+	return true
+}
+
+// scrubChain checks the canonical hash chain, and that the bodies and receipts
+// of the canonical blocks are present and match their headers.
+func (bc *BlockChain) scrubChain() bool {
+	head := bc.CurrentBlock().NumberU64()
+	if head < scrubHeadDistance {
+		return true
+	}
+	target := head - scrubHeadDistance
+	bc.scrubber.update(func(status *ScrubStatus) {
+		status.Stage, status.Position, status.Target = "chain", 0, target
+	})
+	var parent common.Hash
+	for number := uint64(0); number <= target; {
+		var (
+			start = time.Now()
+			first = number
+			end   = number + scrubBatch
+		)
+		if end > target+1 {
+			end = target + 1
+		}
+		for ; number < end; number++ {
+			hash, err := bc.scrubBlock(number, parent)
+			if err != nil {
+				bc.scrubber.report("chain", number, err)
+			}
+			parent = hash
+		}
+		bc.scrubber.update(func(status *ScrubStatus) {
+			status.Position, status.Blocks = number, number
+		})
+		scrubCheckedMeter.Mark(int64(number - first))
+
+		if !bc.scrubPause(time.Since(start)) {
+			return false
+		}
+	}
+	return true
+}
+
+// scrubBlock checks a single canonical block against its parent, returning its
+// hash.
+func (bc *BlockChain) scrubBlock(number uint64, parent common.Hash) (common.Hash, error) {
+	hash := rawdb.ReadCanonicalHash(bc.db, number)
+	if hash == (common.Hash{}) {
+		return hash, errors.New("missing canonical hash")
+	}
+	header := rawdb.ReadHeader(bc.db, hash, number)
+	if header == nil {
+		return hash, fmt.Errorf("missing or invalid header %x", hash)
+	}
+	if header.Hash() != hash {
+		return hash, fmt.Errorf("header hash mismatch: have %x, want %x", header.Hash(), hash)
+	}
+	if number > 0 && parent != (common.Hash{}) && header.ParentHash != parent {
+		return hash, fmt.Errorf("parent hash mismatch: have %x, want %x", header.ParentHash, parent)
+	}
+	// The bodies and receipts below the history tail are pruned, the tail might
+	// have moved since the block was looked up
+	blob := rawdb.ReadBodyRLP(bc.db, hash, number)
+	if len(blob) == 0 {
+		if bc.HistoryPruned(number) {
+			return hash, nil
+		}
+		return hash, errors.New("missing body")
+	}
+	body := new(types.Body)
+	if err := rlp.DecodeBytes(blob, body); err != nil {
+		return hash, fmt.Errorf("invalid body: %v", err)
+	}
+	if root := types.DeriveSha(types.Transactions(body.Transactions), trie.NewStackTrie(nil)); root != header.TxHash {
+		return hash, fmt.Errorf("transaction root mismatch: have %x, want %x", root, header.TxHash)
+	}
+	if uncles := types.CalcUncleHash(body.Uncles); uncles != header.UncleHash {
+		return hash, fmt.Errorf("uncle hash mismatch: have %x, want %x", uncles, header.UncleHash)
+	}
+	var receipts []*types.ReceiptForStorage
+	if blob := rawdb.ReadReceiptsRLP(bc.db, hash, number); len(blob) > 0 {
+		if err := rlp.DecodeBytes(blob, &receipts); err != nil {
+			return hash, fmt.Errorf("invalid receipts: %v", err)
+		}
+	}
+	if len(receipts) != len(body.Transactions) && !bc.HistoryPruned(number) {
+		return hash, fmt.Errorf("receipt count mismatch: have %d, want %d", len(receipts), len(body.Transactions))
+	}
+	return hash, nil
+}
+
+// scrubState samples trie nodes of the head state from a random position,
+// checking that they are present and match their hashes, along with the storage
+// roots and the codes of the accounts met.
+func (bc *BlockChain) scrubState() {
+	head := bc.CurrentBlock()
+	bc.scrubber.update(func(status *ScrubStatus) {
+		status.Stage, status.Position, status.Target = "state", head.NumberU64(), head.NumberU64()
+	})
+	triedb := bc.stateCache.TrieDB()
+	tr, err := trie.New(common.Hash{}, head.Root(), triedb)
+	if err != nil {
+		bc.scrubber.report("state", head.NumberU64(), err)
+		return
+	}
+	// Start from a random position, wrapping around if the end is reached
+	seek := make([]byte, common.HashLength)
+	rand.Read(seek)
+
+	nodes := bc.scrubTrie(tr, seek, head.NumberU64(), scrubStateNodes)
+	if nodes < scrubStateNodes {
+		nodes += bc.scrubTrie(tr, nil, head.NumberU64(), scrubStateNodes-nodes)
+	}
+	bc.scrubber.update(func(status *ScrubStatus) {
+		status.Nodes += uint64(nodes)
+	})
+	scrubCheckedMeter.Mark(int64(nodes))
+}
+
+// scrubTrie checks up to the given number of nodes of the account trie from the
+// given position, returning the number of nodes checked.
+func (bc *BlockChain) scrubTrie(tr *trie.Trie, start []byte, number uint64, limit int) int {
+	var (
+		nodes int
+		it    = tr.NodeIterator(start)
+	)
+	for nodes < limit && it.Next(true) {
+		if hash := it.Hash(); hash != (common.Hash{}) {
+			if blob := it.NodeBlob(); blob != nil && crypto.Keccak256Hash(blob) != hash {
+				bc.scrubber.report("state", number, fmt.Errorf("trie node %x at path %x has hash %x", hash, it.Path(), crypto.Keccak256Hash(blob)))
+			}
+			nodes++
+		}
+		if !it.Leaf() {
+			continue
+		}
+		var acc types.StateAccount
+		if err := rlp.DecodeBytes(it.LeafBlob(), &acc); err != nil {
+			bc.scrubber.report("state", number, fmt.Errorf("invalid account %x: %v", it.LeafKey(), err))
+			continue
+		}
+		if acc.Root != types.EmptyRootHash {
+			if _, err := trie.New(common.BytesToHash(it.LeafKey()), acc.Root, bc.stateCache.TrieDB()); err != nil {
+				bc.scrubber.report("state", number, fmt.Errorf("account %x storage: %v", it.LeafKey(), err))
+			}
+			nodes++
+		}
+		if codeHash := common.BytesToHash(acc.CodeHash); codeHash != emptyCodeHash && !rawdb.HasCode(bc.db, codeHash) {
+			bc.scrubber.report("state", number, fmt.Errorf("account %x code %x missing", it.LeafKey(), codeHash))
+		}
+	}
+	if err := it.Error(); err != nil {
+		bc.scrubber.report("state", number, err)
+	}
+	return nodes
+}
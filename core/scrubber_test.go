@@ -0,0 +1,124 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// Tests that the database scrubber walks the ancient store and the canonical
+// chain, and that it reports corrupted chain data along with the block to rewind
+// the chain to.
+func TestScrubDatabase(t *testing.T) {
+	defer func(factor int, distance uint64) {
+		scrubPauseFactor, scrubHeadDistance = factor, distance
+	}(scrubPauseFactor, scrubHeadDistance)
+	scrubPauseFactor, scrubHeadDistance = 0, 8
+
+	var (
+		gspec, blocks = newTestChain(64, nil)
+		engine        = ethash.NewFaker()
+		ancient       = t.TempDir()
+	)
+	db, err := rawdb.NewDatabaseWithFreezer(rawdb.NewMemoryDatabase(), ancient, "", false)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+	gspec.MustCommit(db)
+
+	chain, err := NewBlockChain(db, nil, gspec.Config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	if chain.ScrubStatus() != nil {
+		t.Fatalf("disabled scrubber reported status")
+	}
+	db.(interface{ Freeze(threshold uint64) error }).Freeze(16)
+	frozen, _ := db.Ancients()
+	if frozen == 0 {
+		t.Fatalf("no blocks frozen")
+	}
+	chain.scrubber = new(scrubber)
+
+	// Scrub the intact database
+	if !chain.scrubRound() {
+		t.Fatalf("scrubbing round aborted")
+	}
+	status := chain.ScrubStatus()
+	if status.Round != 1 || status.Stage != "idle" || len(status.Findings) != 0 || status.RepairNeeded {
+		t.Fatalf("intact database status mismatch: %+v", status)
+	}
+	if status.Ancients != frozen || status.Blocks != uint64(len(blocks))-scrubHeadDistance+1 || status.Nodes == 0 {
+		t.Fatalf("scrubbed item count mismatch: %+v", status)
+	}
+	// Drop the receipts of a live block, and corrupt the frozen bodies
+	number := frozen + 2
+	rawdb.DeleteReceipts(db, blocks[number-1].Hash(), number)
+
+	data, err := os.OpenFile(filepath.Join(ancient, "bodies.0000.cdat"), os.O_RDWR, 0644)
+	if err != nil {
+		t.Fatalf("failed to open bodies: %v", err)
+	}
+	stat, _ := data.Stat()
+	if _, err := data.WriteAt(bytes.Repeat([]byte{0xff}, 16), stat.Size()/2); err != nil {
+		t.Fatalf("failed to corrupt bodies: %v", err)
+	}
+	data.Close()
+
+	if !chain.scrubRound() {
+		t.Fatalf("scrubbing round aborted")
+	}
+	status = chain.ScrubStatus()
+	var (
+		corrupted bool
+		missing   bool
+		lowest    = uint64(len(blocks))
+	)
+	for _, finding := range status.Findings {
+		switch {
+		case finding.Stage == "ancients" && finding.Number > 0 && finding.Number < frozen:
+			corrupted = true
+		case finding.Stage == "chain" && finding.Number == number:
+			missing = true
+		}
+		if finding.Number < lowest {
+			lowest = finding.Number
+		}
+	}
+	if !corrupted {
+		t.Fatalf("ancient corruption not reported: %+v", status.Findings)
+	}
+	if !missing {
+		t.Fatalf("missing receipts not reported: %+v", status.Findings)
+	}
+	if !status.RepairNeeded || *status.RepairHead != lowest-1 {
+		t.Fatalf("repair head mismatch: have %v, want %d", status.RepairHead, lowest-1)
+	}
+}
@@ -0,0 +1,717 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/golang/snappy"
+)
+
+// exportChunkSize is the approximate amount of state data held by an export
+// file.
+var exportChunkSize = 16 * 1024 * 1024
+
+// exportRange is a contiguous range of trie leaves starting at the origin, with
+// the proofs of the origin and the last key against the root of the trie.
+type exportRange struct {
+	Origin common.Hash
+	Keys   []common.Hash
+	Values [][]byte
+	Proof  [][]byte
+}
+
+// exportStorage is a range of the storage slots of an account.
+type exportStorage struct {
+	Account common.Hash
+	Slots   exportRange
+}
+
+// exportChunk is the content of a state export file. It either holds a range of
+// accounts along with their codes and storage slots, or, if the storage of the
+// last account of the previous file didn't fit, the continuation of it.
+//
+// Account values are in the consensus (full RLP) format, storage values are the
+// RLP encoded slot values.
+type exportChunk struct {
+	Root     common.Hash  // State root the ranges are proven against
+	Accounts *exportRange `rlp:"nil"`
+	Codes    [][]byte
+	Storages []exportStorage
+}
+
+// exportPending is the storage of an account which is continued in the next
+// export file.
+type exportPending struct {
+	account common.Hash
+	root    common.Hash
+	origin  common.Hash
+}
+
+// exportFilename returns the name of the export file with the given sequence
+// number of the state.
+func exportFilename(root common.Hash, seq int) string {
+	return fmt.Sprintf("state-%x-%06d.snap", root[:8], seq)
+}
+
+// exportFiles lists the export files in the given directory, ordered by their
+// sequence numbers.
+func exportFiles(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "state-*.snap"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// writeExportChunk writes an export file atomically.
+func writeExportChunk(dir string, seq int, chunk *exportChunk) error {
+	f, err := os.CreateTemp(dir, "export-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	w := snappy.NewBufferedWriter(f)
+	if err := rlp.Encode(w, chunk); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), filepath.Join(dir, exportFilename(chunk.Root, seq)))
+}
+
+// readExportChunk reads and decodes an export file.
+func readExportChunk(path string) (*exportChunk, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	blob, err := io.ReadAll(snappy.NewReader(f))
+	if err != nil {
+		return nil, err
+	}
+	chunk := new(exportChunk)
+	if err := rlp.DecodeBytes(blob, chunk); err != nil {
+		return nil, err
+	}
+	return chunk, nil
+}
+
+// Export writes the state of the given root into a sequence of export files in
+// the given directory. Each file holds ranges of the account and storage tries,
+// along with the proofs needed to verify them against the state root, so they
+// can be imported by a node without access to the network.
+//
+// If the directory already holds some files of the export, it's continued after
+// the last one.
+func (t *Tree) Export(root common.Hash, dir string) error {
+	if t.Snapshot(root) == nil {
+		return fmt.Errorf("snapshot [%#x] missing", root)
+	}
+	accTrie, err := trie.New(common.Hash{}, root, t.triedb)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	seq, origin, pending, exhausted, err := t.resumeExport(root, dir)
+	if err != nil {
+		return err
+	}
+	var (
+		start  = time.Now()
+		logged = time.Now()
+	)
+	for !exhausted || pending != nil {
+		chunk := &exportChunk{Root: root}
+		if pending != nil {
+			slots, more, err := t.exportStorage(root, pending.account, pending.root, pending.origin, exportChunkSize)
+			if err != nil {
+				return err
+			}
+			chunk.Storages = []exportStorage{{Account: pending.account, Slots: *slots}}
+			if more {
+				pending.origin = nextKey(slots.Keys[len(slots.Keys)-1])
+			} else {
+				pending = nil
+			}
+		} else {
+			if pending, exhausted, err = t.exportAccounts(accTrie, chunk, origin); err != nil {
+				return err
+			}
+			if keys := chunk.Accounts.Keys; len(keys) > 0 {
+				if origin = nextKey(keys[len(keys)-1]); origin == (common.Hash{}) {
+					exhausted = true
+				}
+			}
+		}
+		if err := writeExportChunk(dir, seq, chunk); err != nil {
+			return err
+		}
+		seq++
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Exporting state", "root", root, "files", seq, "at", origin, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	log.Info("Exported state", "root", root, "files", seq, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// resumeExport determines where to continue the export of the state from, based
+// on the last export file present in the directory.
+func (t *Tree) resumeExport(root common.Hash, dir string) (int, common.Hash, *exportPending, bool, error) {
+	files, err := exportFiles(dir)
+	if err != nil || len(files) == 0 {
+		return 0, common.Hash{}, nil, false, err
+	}
+	last, err := readExportChunk(files[len(files)-1])
+	if err != nil {
+		return 0, common.Hash{}, nil, false, err
+	}
+	if last.Root != root {
+		return 0, common.Hash{}, nil, false, fmt.Errorf("directory holds export of state %#x", last.Root)
+	}
+	var (
+		seq       = len(files)
+		origin    common.Hash
+		pending   *exportPending
+		exhausted bool
+	)
+	// Continue the accounts after the last account range, which precedes the
+	// storage continuations
+	chunk := last
+	for i := len(files) - 2; chunk.Accounts == nil && i >= 0; i-- {
+		if chunk, err = readExportChunk(files[i]); err != nil {
+			return 0, common.Hash{}, nil, false, err
+		}
+	}
+	if accounts := chunk.Accounts; accounts != nil {
+		more, err := accounts.verify(root)
+		if err != nil {
+			return 0, common.Hash{}, nil, false, err
+		}
+		if len(accounts.Keys) > 0 {
+			origin = nextKey(accounts.Keys[len(accounts.Keys)-1])
+		}
+		exhausted = !more || origin == (common.Hash{})
+	}
+	// Continue the storage of the last account if it's incomplete
+	if n := len(last.Storages); n > 0 {
+		storage := last.Storages[n-1]
+		account, err := t.Snapshot(root).Account(storage.Account)
+		if err != nil {
+			return 0, common.Hash{}, nil, false, err
+		}
+		if account == nil {
+			return 0, common.Hash{}, nil, false, fmt.Errorf("account %#x missing", storage.Account)
+		}
+		storageRoot := common.BytesToHash(account.Root)
+		more, err := storage.Slots.verify(storageRoot)
+		if err != nil {
+			return 0, common.Hash{}, nil, false, err
+		}
+		if more {
+			pending = &exportPending{account: storage.Account, root: storageRoot, origin: nextKey(storage.Slots.Keys[len(storage.Slots.Keys)-1])}
+		}
+	}
+	log.Info("Resuming state export", "root", root, "files", seq, "at", origin)
+	return seq, origin, pending, exhausted, nil
+}
+
+// exportAccounts fills the chunk with the accounts starting at the origin,
+// until the chunk size is reached. If the storage of the last account doesn't
+// fit, it's returned to be continued in the next chunk. The returned flag is set
+// if all accounts have been exported.
+func (t *Tree) exportAccounts(accTrie *trie.Trie, chunk *exportChunk, origin common.Hash) (*exportPending, bool, error) {
+	it, err := t.AccountIterator(chunk.Root, origin)
+	if err != nil {
+		return nil, false, err
+	}
+	defer it.Release()
+
+	var (
+		accounts  = &exportRange{Origin: origin}
+		codes     = make(map[common.Hash]struct{})
+		size      int
+		pending   *exportPending
+		exhausted = true
+	)
+	for it.Next() {
+		if size >= exportChunkSize || pending != nil {
+			exhausted = false
+			break
+		}
+		account, err := FullAccount(it.Account())
+		if err != nil {
+			return nil, false, err
+		}
+		value, err := rlp.EncodeToBytes(account)
+		if err != nil {
+			return nil, false, err
+		}
+		hash := it.Hash()
+		accounts.Keys = append(accounts.Keys, hash)
+		accounts.Values = append(accounts.Values, value)
+		size += common.HashLength + len(value)
+
+		if codeHash := common.BytesToHash(account.CodeHash); codeHash != emptyCode {
+			if _, ok := codes[codeHash]; !ok {
+				code := rawdb.ReadCode(t.diskdb, codeHash)
+				if len(code) == 0 {
+					return nil, false, fmt.Errorf("code %#x of account %#x missing", codeHash, hash)
+				}
+				codes[codeHash] = struct{}{}
+				chunk.Codes = append(chunk.Codes, code)
+				size += len(code)
+			}
+		}
+		if storageRoot := common.BytesToHash(account.Root); storageRoot != emptyRoot {
+			slots, more, err := t.exportStorage(chunk.Root, hash, storageRoot, common.Hash{}, exportChunkSize-size)
+			if err != nil {
+				return nil, false, err
+			}
+			chunk.Storages = append(chunk.Storages, exportStorage{Account: hash, Slots: *slots})
+			size += len(slots.Keys) * common.HashLength
+			for _, value := range slots.Values {
+				size += len(value)
+			}
+			if more {
+				pending = &exportPending{account: hash, root: storageRoot, origin: nextKey(slots.Keys[len(slots.Keys)-1])}
+			}
+		}
+	}
+	if err := it.Error(); err != nil {
+		return nil, false, err
+	}
+	if origin != (common.Hash{}) || !exhausted {
+		if accounts.Proof, err = proveRange(accTrie, origin, accounts.Keys); err != nil {
+			return nil, false, err
+		}
+	}
+	chunk.Accounts = accounts
+	return pending, exhausted, nil
+}
+
+// exportStorage collects the storage slots of the account starting at the
+// origin, until the given size is reached. At least one slot is returned. The
+// returned flag is set if there are more slots.
+func (t *Tree) exportStorage(root common.Hash, account common.Hash, storageRoot common.Hash, origin common.Hash, limit int) (*exportRange, bool, error) {
+	it, err := t.StorageIterator(root, account, origin)
+	if err != nil {
+		return nil, false, err
+	}
+	defer it.Release()
+
+	var (
+		slots = &exportRange{Origin: origin}
+		size  int
+		more  bool
+	)
+	for it.Next() {
+		if len(slots.Keys) > 0 && size >= limit {
+			more = true
+			break
+		}
+		slots.Keys = append(slots.Keys, it.Hash())
+		slots.Values = append(slots.Values, common.CopyBytes(it.Slot()))
+		size += common.HashLength + len(it.Slot())
+	}
+	if err := it.Error(); err != nil {
+		return nil, false, err
+	}
+	if len(slots.Keys) == 0 {
+		return nil, false, fmt.Errorf("storage of account %#x missing", account)
+	}
+	if origin != (common.Hash{}) || more {
+		tr, err := trie.New(account, storageRoot, t.triedb)
+		if err != nil {
+			return nil, false, err
+		}
+		if slots.Proof, err = proveRange(tr, origin, slots.Keys); err != nil {
+			return nil, false, err
+		}
+	}
+	return slots, more, nil
+}
+
+// proveRange creates the proof of the range of keys starting at the origin.
+func proveRange(tr *trie.Trie, origin common.Hash, keys []common.Hash) ([][]byte, error) {
+	proof := memorydb.New()
+	if err := tr.Prove(origin[:], 0, proof); err != nil {
+		return nil, err
+	}
+	if len(keys) > 0 {
+		if err := tr.Prove(keys[len(keys)-1][:], 0, proof); err != nil {
+			return nil, err
+		}
+	}
+	var nodes [][]byte
+	it := proof.NewIterator(nil, nil)
+	defer it.Release()
+	for it.Next() {
+		nodes = append(nodes, common.CopyBytes(it.Value()))
+	}
+	return nodes, nil
+}
+
+// verify checks the range against the trie root, returning whether the trie has
+// more leaves after it.
+func (r *exportRange) verify(root common.Hash) (bool, error) {
+	keys := make([][]byte, len(r.Keys))
+	for i := range r.Keys {
+		keys[i] = r.Keys[i][:]
+	}
+	if len(r.Proof) == 0 {
+		if r.Origin != (common.Hash{}) {
+			return false, errors.New("missing range proof")
+		}
+		return trie.VerifyRangeProof(root, nil, nil, keys, r.Values, nil)
+	}
+	proof := memorydb.New()
+	for _, node := range r.Proof {
+		proof.Put(crypto.Keccak256(node), node)
+	}
+	var last []byte
+	if len(keys) > 0 {
+		last = keys[len(keys)-1]
+	}
+	return trie.VerifyRangeProof(root, r.Origin[:], last, keys, r.Values, proof)
+}
+
+// nextKey returns the key following the given one, or the zero hash if there is
+// none.
+func nextKey(key common.Hash) common.Hash {
+	next := key
+	if increaseKey(next[:]) == nil {
+		return common.Hash{}
+	}
+	return next
+}
+
+// Import verifies the state export files in the given directory and writes the
+// snapshot and the tries of the exported state into the database, replacing any
+// existing snapshot. The root of the imported state is returned.
+//
+// The whole export is verified before the existing snapshot is touched, so an
+// invalid or incomplete export leaves the database as it was.
+func Import(db ethdb.Database, dir string) (common.Hash, error) {
+	files, err := exportFiles(dir)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if len(files) == 0 {
+		return common.Hash{}, fmt.Errorf("no state export files in %s", dir)
+	}
+	if _, err := importFiles(newImporter(db, false), files); err != nil {
+		return common.Hash{}, err
+	}
+	// Drop the current snapshot, marking the new one as not yet complete
+	rawdb.DeleteSnapshotRoot(db)
+	rawdb.DeleteSnapshotJournal(db)
+	journalProgress(db, []byte{}, nil)
+	if err := wipeSnapshotRange(db, rawdb.SnapshotAccountPrefix, len(rawdb.SnapshotAccountPrefix)+common.HashLength); err != nil {
+		return common.Hash{}, err
+	}
+	if err := wipeSnapshotRange(db, rawdb.SnapshotStoragePrefix, len(rawdb.SnapshotStoragePrefix)+2*common.HashLength); err != nil {
+		return common.Hash{}, err
+	}
+	return importFiles(newImporter(db, true), files)
+}
+
+// importFiles feeds the given export files into the importer, returning the
+// root of the imported state.
+func importFiles(imp *importer, files []string) (common.Hash, error) {
+	for i, file := range files {
+		chunk, err := readExportChunk(file)
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("%s: %v", filepath.Base(file), err)
+		}
+		if i == 0 {
+			imp.root = chunk.Root
+		}
+		if err := imp.importChunk(chunk); err != nil {
+			return common.Hash{}, fmt.Errorf("%s: %v", filepath.Base(file), err)
+		}
+		if time.Since(imp.logged) > 8*time.Second {
+			msg := "Verifying state export"
+			if imp.write {
+				msg = "Importing state"
+			}
+			log.Info(msg, "root", imp.root, "files", i+1, "accounts", imp.stats.accounts, "slots", imp.stats.slots, "elapsed", common.PrettyDuration(time.Since(imp.stats.start)))
+			imp.logged = time.Now()
+		}
+	}
+	if err := imp.finish(); err != nil {
+		return common.Hash{}, err
+	}
+	return imp.root, nil
+}
+
+// wipeSnapshotRange deletes the snapshot entries with the given prefix and key
+// length.
+func wipeSnapshotRange(db ethdb.KeyValueStore, prefix []byte, keylen int) error {
+	var (
+		batch = db.NewBatch()
+		it    = db.NewIterator(prefix, nil)
+	)
+	defer it.Release()
+
+	for it.Next() {
+		if len(it.Key()) != keylen {
+			continue
+		}
+		batch.Delete(it.Key())
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+// importer rebuilds the snapshot and the tries of a state from export files.
+// Unless writing, the export is only verified and the database left untouched.
+type importer struct {
+	db     ethdb.Database
+	batch  ethdb.Batch
+	write  bool
+	scheme string
+	root   common.Hash
+
+	accTrie  *trie.StackTrie
+	origin   common.Hash // Origin of the next account range
+	finished bool        // Whether the last account range has been imported
+
+	pending     *exportPending  // Storage continued in the next file
+	pendingTrie *trie.StackTrie // Storage trie of the pending account
+
+	stats  *generatorStats
+	logged time.Time
+}
+
+func newImporter(db ethdb.Database, write bool) *importer {
+	imp := &importer{
+		db:     db,
+		batch:  db.NewBatch(),
+		write:  write,
+		scheme: rawdb.ReadStateScheme(db),
+		stats:  &generatorStats{start: time.Now()},
+		logged: time.Now(),
+	}
+	imp.accTrie = imp.newStackTrie(common.Hash{})
+	return imp
+}
+
+// newStackTrie creates a stack trie persisting the generated nodes of the trie
+// with the given owner into the batch, using the scheme of the database.
+func (imp *importer) newStackTrie(owner common.Hash) *trie.StackTrie {
+	return trie.NewStackTrieWithWriter(owner, func(owner common.Hash, path []byte, hash common.Hash, blob []byte) {
+		rawdb.WriteTrieNodeWithScheme(imp.batch, owner, path, hash, blob, imp.scheme)
+	})
+}
+
+// importChunk verifies and imports the content of an export file.
+func (imp *importer) importChunk(chunk *exportChunk) error {
+	if chunk.Root != imp.root {
+		return fmt.Errorf("state root mismatch: have %#x, want %#x", chunk.Root, imp.root)
+	}
+	if chunk.Accounts == nil {
+		if imp.pending == nil || len(chunk.Storages) != 1 || chunk.Storages[0].Account != imp.pending.account {
+			return errors.New("unexpected storage continuation")
+		}
+		if err := imp.importStorage(&chunk.Storages[0], imp.pending.root, imp.pending.origin, true); err != nil {
+			return err
+		}
+		return imp.flush(false)
+	}
+	if imp.finished || imp.pending != nil {
+		return errors.New("unexpected account range")
+	}
+	accounts := chunk.Accounts
+	if accounts.Origin != imp.origin {
+		return fmt.Errorf("account range origin mismatch: have %#x, want %#x", accounts.Origin, imp.origin)
+	}
+	more, err := accounts.verify(imp.root)
+	if err != nil {
+		return fmt.Errorf("invalid account range: %v", err)
+	}
+	codes := make(map[common.Hash][]byte, len(chunk.Codes))
+	for _, code := range chunk.Codes {
+		codes[crypto.Keccak256Hash(code)] = code
+	}
+	storages := chunk.Storages
+	for i, hash := range accounts.Keys {
+		var account Account
+		if err := rlp.DecodeBytes(accounts.Values[i], &account); err != nil {
+			return fmt.Errorf("invalid account %#x: %v", hash, err)
+		}
+		var (
+			codeHash    = common.BytesToHash(account.CodeHash)
+			storageRoot = common.BytesToHash(account.Root)
+		)
+		if codeHash != emptyCode {
+			code, ok := codes[codeHash]
+			if !ok {
+				return fmt.Errorf("code %#x of account %#x missing", codeHash, hash)
+			}
+			rawdb.WriteCode(imp.batch, codeHash, code)
+		}
+		rawdb.WriteAccountSnapshot(imp.batch, hash, SlimAccountRLP(account.Nonce, account.Balance, storageRoot, account.CodeHash))
+		if err := imp.accTrie.TryUpdate(hash[:], accounts.Values[i]); err != nil {
+			return err
+		}
+		imp.stats.accounts++
+		imp.stats.storage += common.StorageSize(common.HashLength + len(accounts.Values[i]))
+
+		if storageRoot == emptyRoot {
+			continue
+		}
+		if len(storages) == 0 || storages[0].Account != hash {
+			return fmt.Errorf("storage of account %#x missing", hash)
+		}
+		imp.pending = &exportPending{account: hash, root: storageRoot}
+		imp.pendingTrie = imp.newStackTrie(hash)
+		if err := imp.importStorage(&storages[0], storageRoot, common.Hash{}, i == len(accounts.Keys)-1); err != nil {
+			return err
+		}
+		storages = storages[1:]
+	}
+	if len(storages) > 0 {
+		return fmt.Errorf("unexpected storage of account %#x", storages[0].Account)
+	}
+	if len(accounts.Keys) > 0 {
+		imp.origin = nextKey(accounts.Keys[len(accounts.Keys)-1])
+	}
+	if !more || imp.origin == (common.Hash{}) {
+		if more {
+			return errors.New("accounts beyond the last key")
+		}
+		imp.finished = true
+	} else if len(accounts.Keys) == 0 {
+		return errors.New("empty account range")
+	}
+	return imp.flush(false)
+}
+
+// importStorage verifies and imports a storage range of the pending account.
+// Only the storage of the last account in a file may be continued.
+func (imp *importer) importStorage(storage *exportStorage, root common.Hash, origin common.Hash, last bool) error {
+	slots := &storage.Slots
+	if slots.Origin != origin {
+		return fmt.Errorf("storage range origin mismatch: have %#x, want %#x", slots.Origin, origin)
+	}
+	more, err := slots.verify(root)
+	if err != nil {
+		return fmt.Errorf("invalid storage range of account %#x: %v", storage.Account, err)
+	}
+	for i, hash := range slots.Keys {
+		rawdb.WriteStorageSnapshot(imp.batch, storage.Account, hash, slots.Values[i])
+		if err := imp.pendingTrie.TryUpdate(hash[:], slots.Values[i]); err != nil {
+			return err
+		}
+		imp.stats.slots++
+		imp.stats.storage += common.StorageSize(common.HashLength + len(slots.Values[i]))
+	}
+	if more {
+		if !last || len(slots.Keys) == 0 {
+			return fmt.Errorf("incomplete storage of account %#x", storage.Account)
+		}
+		if imp.pending.origin = nextKey(slots.Keys[len(slots.Keys)-1]); imp.pending.origin == (common.Hash{}) {
+			return fmt.Errorf("storage beyond the last slot of account %#x", storage.Account)
+		}
+		return nil
+	}
+	if have, err := imp.pendingTrie.Commit(); err != nil {
+		return err
+	} else if have != root {
+		return fmt.Errorf("storage root mismatch of account %#x: have %#x, want %#x", storage.Account, have, root)
+	}
+	imp.pending, imp.pendingTrie = nil, nil
+	return nil
+}
+
+// flush writes out the batch if it's large enough, or if forced. Unless writing,
+// the batch is discarded instead.
+func (imp *importer) flush(force bool) error {
+	if !force && imp.batch.ValueSize() < ethdb.IdealBatchSize {
+		return nil
+	}
+	if imp.write {
+		if err := imp.batch.Write(); err != nil {
+			return err
+		}
+	}
+	imp.batch.Reset()
+	return nil
+}
+
+// finish checks that the whole state was imported and marks the snapshot as
+// complete.
+func (imp *importer) finish() error {
+	if !imp.finished || imp.pending != nil {
+		return errors.New("incomplete state export")
+	}
+	if have, err := imp.accTrie.Commit(); err != nil {
+		return err
+	} else if have != imp.root {
+		return fmt.Errorf("state root mismatch: have %#x, want %#x", have, imp.root)
+	}
+	if !imp.write {
+		imp.batch.Reset()
+		log.Info("Verified state export", "root", imp.root, "accounts", imp.stats.accounts, "slots", imp.stats.slots,
+			"size", imp.stats.storage, "elapsed", common.PrettyDuration(time.Since(imp.stats.start)))
+		return nil
+	}
+	rawdb.WriteSnapshotRoot(imp.batch, imp.root)
+	journalProgress(imp.batch, nil, imp.stats)
+	if err := imp.flush(true); err != nil {
+		return err
+	}
+	log.Info("Imported state", "root", imp.root, "accounts", imp.stats.accounts, "slots", imp.stats.slots,
+		"size", imp.stats.storage, "elapsed", common.PrettyDuration(time.Since(imp.stats.start)))
+	return nil
+}
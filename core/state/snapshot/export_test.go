@@ -0,0 +1,251 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// newExportTestTree creates a generated snapshot tree of a state with plain
+// accounts, contracts with code and contracts with small and large storage.
+func newExportTestTree(t *testing.T) (*Tree, common.Hash, func()) {
+	helper := newHelper()
+	for i := 0; i < 64; i++ {
+		var (
+			acc      = fmt.Sprintf("acc-%d", i)
+			codeHash = emptyCode.Bytes()
+			stRoot   = emptyRoot.Bytes()
+		)
+		if i%4 == 1 {
+			code := []byte(fmt.Sprintf("code-%d", i))
+			rawdb.WriteCode(helper.diskdb, crypto.Keccak256Hash(code), code)
+			codeHash = crypto.Keccak256(code)
+		}
+		if i%8 == 3 || i == 40 {
+			slots := 3
+			if i == 40 {
+				slots = 200
+			}
+			var keys, vals []string
+			for j := 0; j < slots; j++ {
+				keys = append(keys, fmt.Sprintf("key-%d", j))
+				vals = append(vals, fmt.Sprintf("val-%d-%d", i, j))
+			}
+			stRoot = helper.makeStorageTrie(common.Hash{}, hashData([]byte(acc)), keys, vals, true)
+		}
+		helper.addTrieAccount(acc, &Account{Nonce: uint64(i), Balance: big.NewInt(int64(i)), Root: stRoot, CodeHash: codeHash})
+	}
+	root, snap := helper.CommitAndGenerate()
+	select {
+	case <-snap.genPending:
+	case <-time.After(3 * time.Second):
+		t.Fatalf("Snapshot generation failed")
+	}
+	tree := &Tree{
+		diskdb: helper.diskdb,
+		triedb: helper.triedb,
+		cache:  16,
+		layers: map[common.Hash]snapshot{root: snap},
+	}
+	stop := func() {
+		abort := make(chan *generatorStats)
+		snap.genAbort <- abort
+		<-abort
+	}
+	return tree, root, stop
+}
+
+// Tests that a state export can be imported into an empty database, resulting
+// in the same snapshot and the same tries, and that an interrupted export can
+// be continued.
+func TestExportImport(t *testing.T) {
+	defer func(size int) { exportChunkSize = size }(exportChunkSize)
+	exportChunkSize = 1024
+
+	tree, root, stop := newExportTestTree(t)
+	defer stop()
+
+	dir := t.TempDir()
+	if err := tree.Export(root, dir); err != nil {
+		t.Fatalf("Failed to export state: %v", err)
+	}
+	files, err := exportFiles(dir)
+	if err != nil {
+		t.Fatalf("Failed to list export files: %v", err)
+	}
+	if len(files) < 4 {
+		t.Fatalf("Too few export files: %d", len(files))
+	}
+	var continued bool
+	for _, file := range files {
+		chunk, err := readExportChunk(file)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", file, err)
+		}
+		if chunk.Accounts == nil {
+			continued = true
+		}
+	}
+	if !continued {
+		t.Fatalf("Large storage not split across files")
+	}
+	db := rawdb.NewMemoryDatabase()
+	imported, err := Import(db, dir)
+	if err != nil {
+		t.Fatalf("Failed to import state: %v", err)
+	}
+	if imported != root {
+		t.Fatalf("Imported root mismatch: have %#x, want %#x", imported, root)
+	}
+	if have := rawdb.ReadSnapshotRoot(db); have != root {
+		t.Fatalf("Snapshot root mismatch: have %#x, want %#x", have, root)
+	}
+	// The imported snapshot must be complete and match the exported one
+	snaps, err := New(db, trie.NewDatabase(db), 16, root, false, false, false)
+	if err != nil {
+		t.Fatalf("Failed to load imported snapshot: %v", err)
+	}
+	if err := snaps.Verify(root); err != nil {
+		t.Fatalf("Imported snapshot invalid: %v", err)
+	}
+	it := rawdb.NewKeyLengthIterator(tree.diskdb.NewIterator(rawdb.SnapshotAccountPrefix, nil), len(rawdb.SnapshotAccountPrefix)+common.HashLength)
+	for it.Next() {
+		if have, _ := db.Get(it.Key()); !bytes.Equal(have, it.Value()) {
+			t.Fatalf("Account %x mismatch: have %x, want %x", it.Key(), have, it.Value())
+		}
+	}
+	it.Release()
+
+	// The tries and the codes must be complete too
+	accTrie, err := trie.New(common.Hash{}, root, trie.NewDatabase(db))
+	if err != nil {
+		t.Fatalf("Failed to open account trie: %v", err)
+	}
+	var accounts, slots int
+	accIt := trie.NewIterator(accTrie.NodeIterator(nil))
+	for accIt.Next() {
+		accounts++
+		acc, err := FullAccount(accIt.Value)
+		if err != nil {
+			t.Fatalf("Invalid account: %v", err)
+		}
+		if codeHash := common.BytesToHash(acc.CodeHash); codeHash != emptyCode && !rawdb.HasCode(db, codeHash) {
+			t.Fatalf("Code %#x missing", codeHash)
+		}
+		if common.BytesToHash(acc.Root) == emptyRoot {
+			continue
+		}
+		stTrie, err := trie.New(common.BytesToHash(accIt.Key), common.BytesToHash(acc.Root), trie.NewDatabase(db))
+		if err != nil {
+			t.Fatalf("Failed to open storage trie: %v", err)
+		}
+		stIt := trie.NewIterator(stTrie.NodeIterator(nil))
+		for stIt.Next() {
+			slots++
+		}
+		if stIt.Err != nil {
+			t.Fatalf("Failed to iterate storage trie: %v", stIt.Err)
+		}
+	}
+	if accIt.Err != nil {
+		t.Fatalf("Failed to iterate account trie: %v", accIt.Err)
+	}
+	if accounts != 64 || slots != 8*3+200 {
+		t.Fatalf("Imported state mismatch: have %d accounts, %d slots", accounts, slots)
+	}
+	// Drop the tail of the export and continue it, it must produce the same files
+	contents := make([][]byte, len(files))
+	for i, file := range files {
+		contents[i], _ = os.ReadFile(file)
+	}
+	for _, file := range files[len(files)/2:] {
+		os.Remove(file)
+	}
+	if err := tree.Export(root, dir); err != nil {
+		t.Fatalf("Failed to continue export: %v", err)
+	}
+	resumed, _ := exportFiles(dir)
+	if len(resumed) != len(files) {
+		t.Fatalf("Continued export file count mismatch: have %d, want %d", len(resumed), len(files))
+	}
+	for i, file := range resumed {
+		if blob, _ := os.ReadFile(file); !bytes.Equal(blob, contents[i]) {
+			t.Fatalf("Continued export file %d mismatch", i)
+		}
+	}
+}
+
+// Tests that tampered or incomplete exports are rejected on import.
+func TestImportInvalid(t *testing.T) {
+	defer func(size int) { exportChunkSize = size }(exportChunkSize)
+	exportChunkSize = 1024
+
+	tree, root, stop := newExportTestTree(t)
+	defer stop()
+
+	dir := t.TempDir()
+	if err := tree.Export(root, dir); err != nil {
+		t.Fatalf("Failed to export state: %v", err)
+	}
+	files, _ := exportFiles(dir)
+
+	// Modify an exported account, the range proof must not verify
+	chunk, err := readExportChunk(files[0])
+	if err != nil {
+		t.Fatalf("Failed to read export file: %v", err)
+	}
+	original := chunk.Accounts.Values[0]
+	chunk.Accounts.Values[0] = append(common.CopyBytes(original), 0x00)
+	if err := writeExportChunk(dir, 0, chunk); err != nil {
+		t.Fatalf("Failed to write export file: %v", err)
+	}
+	if _, err := Import(rawdb.NewMemoryDatabase(), dir); err == nil {
+		t.Fatalf("Tampered export imported")
+	}
+	chunk.Accounts.Values[0] = original
+	if err := writeExportChunk(dir, 0, chunk); err != nil {
+		t.Fatalf("Failed to write export file: %v", err)
+	}
+	// Drop the last file, the export is incomplete and must not replace the
+	// existing snapshot
+	os.Remove(files[len(files)-1])
+	db := rawdb.NewMemoryDatabase()
+	rawdb.WriteSnapshotRoot(db, common.Hash{0x01})
+	rawdb.WriteAccountSnapshot(db, common.Hash{0x02}, []byte{0x03})
+	if _, err := Import(db, dir); err == nil {
+		t.Fatalf("Incomplete export imported")
+	}
+	if root := rawdb.ReadSnapshotRoot(db); root != (common.Hash{0x01}) {
+		t.Fatalf("Existing snapshot root replaced: %#x", root)
+	}
+	if blob := rawdb.ReadAccountSnapshot(db, common.Hash{0x02}); !bytes.Equal(blob, []byte{0x03}) {
+		t.Fatalf("Existing snapshot account wiped: %x", blob)
+	}
+	if blob := rawdb.ReadAccountSnapshot(db, chunk.Accounts.Keys[0]); len(blob) != 0 {
+		t.Fatalf("Incomplete export partially imported")
+	}
+}
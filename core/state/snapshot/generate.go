@@ -369,7 +369,7 @@ func (dl *diskLayer) generateRange(ctx *generatorContext, owner common.Hash, roo
 		}
 		root, nodes, _ := snapTrie.Commit(false)
 		if nodes != nil {
-			snapTrieDb.Update(trie.NewWithNodeSet(nodes))
+			snapTrieDb.Update(root, common.Hash{}, trie.NewWithNodeSet(nodes))
 		}
 		snapTrieDb.Commit(root, false, nil)
 	}
@@ -86,6 +86,13 @@ type stateObject struct {
 	dirtyStorage   Storage // Storage entries that have been modified in the current transaction execution
 	fakeStorage    Storage // Fake storage which constructed by caller for debugging purpose.
 
+	// State diff tracking.
+	// The original account and the original value of every storage slot
+	// changed since the last commit, used to construct the reverse state diff.
+	origin        *types.StateAccount // Account data at the last commit, nil if the account didn't exist
+	storageOrigin Storage             // Original values of the storage slots changed since the last commit
+	storageReset  bool                // Whether the original storage was wiped since the last commit
+
 	// Cache flags.
 	// When an object is marked suicided it will be delete from the trie
 	// during the "update" phase of the state transition.
@@ -327,6 +334,12 @@ func (s *stateObject) updateTrie(db Database) Trie {
 		if value == s.originStorage[key] {
 			continue
 		}
+		if _, ok := s.storageOrigin[key]; !ok && s.db.diffTracking {
+			if s.storageOrigin == nil {
+				s.storageOrigin = make(Storage)
+			}
+			s.storageOrigin[key] = s.originStorage[key]
+		}
 		s.originStorage[key] = value
 
 		var v []byte
@@ -442,6 +455,11 @@ func (s *stateObject) deepCopy(db *StateDB) *stateObject {
 	stateObject.suicided = s.suicided
 	stateObject.dirtyCode = s.dirtyCode
 	stateObject.deleted = s.deleted
+	stateObject.storageReset = s.storageReset
+	if db.diffTracking {
+		stateObject.origin = s.origin
+		stateObject.storageOrigin = s.storageOrigin.Copy()
+	}
 	return stateObject
 }
 
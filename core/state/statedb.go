@@ -77,6 +77,11 @@ type StateDB struct {
 	snapAccounts  map[common.Hash][]byte
 	snapStorage   map[common.Hash]map[common.Hash][]byte
 
+	// Reverse diff of the last commit, collected along with the original data
+	// of the state objects if tracking was requested
+	diffTracking bool
+	diff         *StateDiff
+
 	// This map holds 'live' objects, which will get modified while processing a state transition.
 	stateObjects        map[common.Address]*stateObject
 	stateObjectsPending map[common.Address]struct{} // State objects finalized but not yet written to the trie
@@ -552,6 +557,9 @@ func (s *StateDB) getDeletedStateObject(addr common.Address) *stateObject {
 	}
 	// Insert into the live set
 	obj := newObject(s, addr, *data)
+	if s.diffTracking {
+		obj.origin = copyAccount(&obj.data)
+	}
 	s.setStateObject(obj)
 	return obj
 }
@@ -582,6 +590,9 @@ func (s *StateDB) createObject(addr common.Address) (newobj, prev *stateObject)
 		}
 	}
 	newobj = newObject(s, addr, types.StateAccount{})
+	if prev != nil {
+		newobj.origin, newobj.storageReset = prev.origin, true
+	}
 	if prev == nil {
 		s.journal.append(createObjectChange{account: &addr})
 	} else {
@@ -657,6 +668,7 @@ func (s *StateDB) Copy() *StateDB {
 		preimages:           make(map[common.Hash][]byte, len(s.preimages)),
 		journal:             newJournal(),
 		hasher:              crypto.NewKeccakState(),
+		diffTracking:        s.diffTracking,
 	}
 	// Copy the dirty states, logs, and preimages
 	for addr := range s.journal.dirties {
@@ -902,6 +914,14 @@ func (s *StateDB) Commit(deleteEmptyObjects bool) (common.Hash, error) {
 	// Finalize any pending changes and merge everything into the tries
 	s.IntermediateRoot(deleteEmptyObjects)
 
+	// Collect the reverse diff before the storage tries are committed
+	if s.diffTracking {
+		diff, err := s.buildStateDiff()
+		if err != nil {
+			return common.Hash{}, err
+		}
+		s.diff = diff
+	}
 	// Commit objects to the trie, measuring the elapsed time
 	var (
 		accountTrieNodes int
@@ -910,7 +930,19 @@ func (s *StateDB) Commit(deleteEmptyObjects bool) (common.Hash, error) {
 	)
 	codeWriter := s.db.TrieDB().DiskDB().NewBatch()
 	for addr := range s.stateObjectsDirty {
-		if obj := s.stateObjects[addr]; !obj.deleted {
+		obj := s.stateObjects[addr]
+
+		// Drop the whole storage trie of destructed and recreated accounts, the
+		// recreated one being committed anew
+		if obj.deleted || obj.storageReset {
+			nodes.Wipe(obj.addrHash)
+		}
+		// The committed data becomes the origin of the next state diff
+		obj.origin, obj.storageOrigin, obj.storageReset = nil, nil, false
+		if !obj.deleted && s.diffTracking {
+			obj.origin = copyAccount(&obj.data)
+		}
+		if !obj.deleted {
 			// Write any contract code associated with the state object
 			if obj.code != nil && obj.dirtyCode {
 				rawdb.WriteCode(codeWriter, common.BytesToHash(obj.CodeHash()), obj.code)
@@ -986,7 +1018,7 @@ func (s *StateDB) Commit(deleteEmptyObjects bool) (common.Hash, error) {
 		}
 		s.snap, s.snapDestructs, s.snapAccounts, s.snapStorage = nil, nil, nil, nil
 	}
-	if err := s.db.TrieDB().Update(nodes); err != nil {
+	if err := s.db.TrieDB().Update(root, s.originalRoot, nodes); err != nil {
 		return common.Hash{}, err
 	}
 	s.originalRoot = root
@@ -0,0 +1,236 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state/snapshot"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// StateDiff is the reverse diff of a single state transition. It holds the
+// original value of every account and storage slot the transition changed,
+// which is enough to roll the post-state back to the pre-state.
+type StateDiff struct {
+	Accounts []DiffAccount // Changed accounts, sorted by hash
+}
+
+// DiffAccount is the original data of a single changed account.
+type DiffAccount struct {
+	Hash    common.Hash   // Hash of the account address
+	Account []byte        // Original account in slim snapshot format, empty if it didn't exist
+	Reset   bool          // Whether the storage was wiped, listing all the original slots
+	Keys    []common.Hash // Hashes of the changed storage slots, sorted
+	Values  [][]byte      // Original RLP encoded slot values, empty if they didn't exist
+}
+
+// copyAccount returns a deep copy of the account data.
+func copyAccount(data *types.StateAccount) *types.StateAccount {
+	cpy := *data
+	if data.Balance != nil {
+		cpy.Balance = new(big.Int).Set(data.Balance)
+	}
+	cpy.CodeHash = common.CopyBytes(data.CodeHash)
+	return &cpy
+}
+
+// TrackStateDiff enables tracking the original data of the state objects, and
+// collecting the reverse diff of every following commit, which can be retrieved
+// with StateDiff afterwards. It must be called before any state is accessed, as
+// the objects loaded earlier are not tracked.
+func (s *StateDB) TrackStateDiff() {
+	s.diffTracking = true
+}
+
+// StateDiff returns the reverse diff of the last commit, or nil if it wasn't
+// tracked.
+func (s *StateDB) StateDiff() *StateDiff {
+	return s.diff
+}
+
+// buildStateDiff collects the original data of all the state objects changed
+// since the last commit. It must be called after the pending changes have been
+// merged into the tries, but before the storage tries are committed.
+func (s *StateDB) buildStateDiff() (*StateDiff, error) {
+	diff := new(StateDiff)
+	for addr := range s.stateObjectsDirty {
+		obj := s.stateObjects[addr]
+		account := DiffAccount{Hash: obj.addrHash}
+		if obj.origin != nil {
+			account.Account = snapshot.SlimAccountRLP(obj.origin.Nonce, obj.origin.Balance, obj.origin.Root, obj.origin.CodeHash)
+		}
+		if obj.storageReset || obj.deleted {
+			// The storage was wiped, list every original slot
+			account.Reset = true
+			if obj.origin != nil && obj.origin.Root != emptyRoot {
+				tr, err := s.db.OpenStorageTrie(obj.addrHash, obj.origin.Root)
+				if err != nil {
+					return nil, err
+				}
+				it := trie.NewIterator(tr.NodeIterator(nil))
+				for it.Next() {
+					account.Keys = append(account.Keys, common.BytesToHash(it.Key))
+					account.Values = append(account.Values, common.CopyBytes(it.Value))
+				}
+				if it.Err != nil {
+					return nil, it.Err
+				}
+			}
+		} else {
+			for key := range obj.storageOrigin {
+				account.Keys = append(account.Keys, crypto.HashData(s.hasher, key[:]))
+			}
+			sort.Slice(account.Keys, func(i, j int) bool {
+				return bytes.Compare(account.Keys[i][:], account.Keys[j][:]) < 0
+			})
+			values := make(map[common.Hash][]byte, len(obj.storageOrigin))
+			for key, value := range obj.storageOrigin {
+				if (value != common.Hash{}) {
+					values[crypto.HashData(s.hasher, key[:])], _ = rlp.EncodeToBytes(common.TrimLeftZeroes(value[:]))
+				}
+			}
+			for _, key := range account.Keys {
+				account.Values = append(account.Values, values[key])
+			}
+		}
+		diff.Accounts = append(diff.Accounts, account)
+	}
+	sort.Slice(diff.Accounts, func(i, j int) bool {
+		return bytes.Compare(diff.Accounts[i].Hash[:], diff.Accounts[j].Hash[:]) < 0
+	})
+	return diff, nil
+}
+
+// diffStorage is the accumulated storage rollback of a single account.
+type diffStorage struct {
+	reset bool                   // Whether to rebuild the storage from scratch
+	slots map[common.Hash][]byte // Slot values to apply, empty to delete
+}
+
+// ApplyStateDiffs rolls the state with the given root back through the given
+// reverse diffs, ordered from the newest to the oldest. The nodes of the rolled
+// back state are inserted into the trie database of db without committing them
+// to disk, and the root of the resulting state is returned.
+func ApplyStateDiffs(db Database, root common.Hash, diffs []*StateDiff) (common.Hash, error) {
+	// Merge the diffs, the oldest original value of each item wins
+	var (
+		accounts = make(map[common.Hash][]byte)
+		storages = make(map[common.Hash]*diffStorage)
+	)
+	for _, diff := range diffs {
+		for _, account := range diff.Accounts {
+			accounts[account.Hash] = account.Account
+
+			storage := storages[account.Hash]
+			if storage == nil || account.Reset {
+				storage = &diffStorage{reset: account.Reset, slots: make(map[common.Hash][]byte)}
+				storages[account.Hash] = storage
+			}
+			for i, key := range account.Keys {
+				storage.slots[key] = account.Values[i]
+			}
+		}
+	}
+	triedb := db.TrieDB()
+	tr, err := trie.New(common.Hash{}, root, triedb)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	nodes := trie.NewMergedNodeSet()
+	for hash, blob := range accounts {
+		if len(blob) == 0 {
+			if err := tr.TryDelete(hash[:]); err != nil {
+				return common.Hash{}, err
+			}
+			continue
+		}
+		origin, err := snapshot.FullAccount(blob)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		// Roll back the storage of the account, starting from the current
+		// storage trie unless the original one was wiped.
+		storageRoot := emptyRoot
+		if storage := storages[hash]; !storage.reset {
+			current, err := tr.TryGet(hash[:])
+			if err != nil {
+				return common.Hash{}, err
+			}
+			if len(current) > 0 {
+				var data types.StateAccount
+				if err := rlp.DecodeBytes(current, &data); err != nil {
+					return common.Hash{}, err
+				}
+				storageRoot = data.Root
+			}
+		}
+		st, err := trie.New(hash, storageRoot, triedb)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		for key, value := range storages[hash].slots {
+			if len(value) == 0 {
+				err = st.TryDelete(key[:])
+			} else {
+				err = st.TryUpdate(key[:], value)
+			}
+			if err != nil {
+				return common.Hash{}, err
+			}
+		}
+		storageRoot, set, err := st.Commit(false)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		if want := common.BytesToHash(origin.Root); storageRoot != want {
+			return common.Hash{}, fmt.Errorf("storage root mismatch of account %x: have %x, want %x", hash, storageRoot, want)
+		}
+		if set != nil {
+			if err := nodes.Merge(set); err != nil {
+				return common.Hash{}, err
+			}
+		}
+		full, err := snapshot.FullAccountRLP(blob)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		if err := tr.TryUpdate(hash[:], full); err != nil {
+			return common.Hash{}, err
+		}
+	}
+	newRoot, set, err := tr.Commit(false)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if set != nil {
+		if err := nodes.Merge(set); err != nil {
+			return common.Hash{}, err
+		}
+	}
+	if err := triedb.Update(newRoot, root, nodes); err != nil {
+		return common.Hash{}, err
+	}
+	return newRoot, nil
+}
@@ -0,0 +1,140 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Tests that the reverse diffs of a series of state transitions roll the state
+// back to each of the original states.
+func TestStateDiffRollback(t *testing.T) {
+	var (
+		diskdb = rawdb.NewMemoryDatabase()
+		db     = NewDatabase(diskdb)
+		roots  []common.Hash
+		diffs  []*StateDiff
+	)
+	addr := func(i int) common.Address { return common.BigToAddress(big.NewInt(int64(i))) }
+	slot := func(i int) common.Hash { return common.BigToHash(big.NewInt(int64(i))) }
+
+	// Each transition mutates balances and storage, and destructs some accounts
+	transitions := []func(s *StateDB){
+		func(s *StateDB) {
+			for i := 0; i < 10; i++ {
+				s.SetBalance(addr(i), big.NewInt(int64(i+1)))
+				s.SetNonce(addr(i), uint64(i))
+				for j := 0; j < 5; j++ {
+					s.SetState(addr(i), slot(j), common.BigToHash(big.NewInt(int64(i*j+1))))
+				}
+			}
+		},
+		func(s *StateDB) {
+			s.SetState(addr(1), slot(0), common.Hash{})
+			s.SetState(addr(1), slot(9), common.HexToHash("0x09"))
+			s.AddBalance(addr(2), big.NewInt(100))
+			s.Suicide(addr(3))
+			s.SetCode(addr(4), []byte{0x60, 0x00})
+		},
+		func(s *StateDB) {
+			s.CreateAccount(addr(5))
+			s.SetState(addr(5), slot(1), common.HexToHash("0xff"))
+			s.SetBalance(addr(3), big.NewInt(7))
+			s.Suicide(addr(6))
+		},
+		func(s *StateDB) {
+			s.SetState(addr(5), slot(1), common.HexToHash("0xfe"))
+			s.SetState(addr(7), slot(2), common.HexToHash("0xfd"))
+			s.SetBalance(addr(20), big.NewInt(20))
+		},
+	}
+	root := common.Hash{}
+	for i, transition := range transitions {
+		state, err := New(root, db, nil)
+		if err != nil {
+			t.Fatalf("transition %d: failed to open state: %v", i, err)
+		}
+		state.TrackStateDiff()
+		transition(state)
+		if root, err = state.Commit(true); err != nil {
+			t.Fatalf("transition %d: failed to commit state: %v", i, err)
+		}
+		if err := db.TrieDB().Commit(root, false, nil); err != nil {
+			t.Fatalf("transition %d: failed to flush state: %v", i, err)
+		}
+		// Round trip the diff through its encoding
+		blob, err := rlp.EncodeToBytes(state.StateDiff())
+		if err != nil {
+			t.Fatalf("transition %d: failed to encode diff: %v", i, err)
+		}
+		diff := new(StateDiff)
+		if err := rlp.DecodeBytes(blob, diff); err != nil {
+			t.Fatalf("transition %d: failed to decode diff: %v", i, err)
+		}
+		roots, diffs = append(roots, root), append(diffs, diff)
+	}
+	// Roll the last state back to every previous one, including the empty one
+	for i := len(roots) - 2; i >= -1; i-- {
+		var rollback []*StateDiff
+		for j := len(diffs) - 1; j > i; j-- {
+			rollback = append(rollback, diffs[j])
+		}
+		want := emptyRoot
+		if i >= 0 {
+			want = roots[i]
+		}
+		have, err := ApplyStateDiffs(NewDatabase(diskdb), roots[len(roots)-1], rollback)
+		if err != nil {
+			t.Fatalf("rollback to %d: failed to apply diffs: %v", i, err)
+		}
+		if have != want {
+			t.Fatalf("rollback to %d: root mismatch: have %x, want %x", i, have, want)
+		}
+	}
+}
+
+// Tests that the original data of the state objects is only kept if the state
+// diffs are tracked.
+func TestStateDiffUntracked(t *testing.T) {
+	db := NewDatabase(rawdb.NewMemoryDatabase())
+	state, _ := New(common.Hash{}, db, nil)
+	state.SetBalance(common.Address{1}, big.NewInt(1))
+	state.SetState(common.Address{1}, common.Hash{1}, common.Hash{1})
+	root, err := state.Commit(true)
+	if err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+	state, _ = New(root, db, nil)
+	state.SetState(common.Address{1}, common.Hash{1}, common.Hash{2})
+	state.IntermediateRoot(true)
+
+	obj := state.Copy().getStateObject(common.Address{1})
+	if obj.origin != nil || obj.storageOrigin != nil {
+		t.Fatalf("untracked state keeps origins: account %v, storage %v", obj.origin, obj.storageOrigin)
+	}
+	if _, err := state.Commit(true); err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+	if diff := state.StateDiff(); diff != nil {
+		t.Fatalf("untracked state collected a diff: %v", diff)
+	}
+}
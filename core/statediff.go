@@ -0,0 +1,177 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// stateDiffPruneLimit is the maximum number of block heights whose state diffs
+// are pruned after a single block import, bounding the time spent on it.
+const stateDiffPruneLimit = 1024
+
+// errStateDiffsDisabled is returned when requesting historical state without
+// retaining the state diffs.
+var errStateDiffsDisabled = errors.New("state diffs are not retained")
+
+// writeStateDiff stores the reverse state diff of the given block into the
+// block's write batch and prunes the diffs falling out of the retention window.
+func (bc *BlockChain) writeStateDiff(db ethdb.KeyValueWriter, block *types.Block, diff *state.StateDiff) {
+	blob, err := rlp.EncodeToBytes(diff)
+	if err != nil {
+		log.Crit("Failed to encode state diff", "err", err)
+	}
+	number := block.NumberU64()
+	rawdb.WriteStateDiff(db, number, block.Hash(), blob)
+
+	tail := rawdb.ReadStateDiffTail(bc.db)
+	if tail == nil {
+		rawdb.WriteStateDiffTail(db, number)
+		return
+	}
+	if number <= bc.cacheConfig.StateDiffs {
+		return
+	}
+	limit := number - bc.cacheConfig.StateDiffs
+	if limit <= *tail {
+		return
+	}
+	if limit > *tail+stateDiffPruneLimit {
+		limit = *tail + stateDiffPruneLimit
+	}
+	for n := *tail; n < limit; n++ {
+		rawdb.DeleteStateDiffs(bc.db, db, n)
+	}
+	rawdb.WriteStateDiffTail(db, limit)
+}
+
+// historicState is a historical state rebuilt from the state diffs, along with
+// the root of the live state it was rolled back from.
+type historicState struct {
+	state *state.StateDB
+	base  common.Hash
+}
+
+// HistoricState rebuilds the state of the given canonical block from the state
+// diffs, rolling back the closest newer state still available. The rebuilt state
+// is kept in an ephemeral database which is never committed. The most recently
+// rebuilt states are cached for as long as the state they were rolled back from
+// remains available, every caller receiving its own copy.
+func (bc *BlockChain) HistoricState(header *types.Header) (*state.StateDB, error) {
+	if bc.cacheConfig.StateDiffs == 0 {
+		return nil, errStateDiffsDisabled
+	}
+	number := header.Number.Uint64()
+	if bc.GetCanonicalHash(number) != header.Hash() {
+		return nil, fmt.Errorf("block #%d [%x] is not canonical", number, header.Hash())
+	}
+	if cached, ok := bc.historicCache.Get(header.Hash()); ok {
+		if historic := cached.(*historicState); bc.HasState(historic.base) {
+			return historic.state.Copy(), nil
+		}
+		bc.historicCache.Remove(header.Hash())
+	}
+	if tail := rawdb.ReadStateDiffTail(bc.db); tail == nil || number+1 < *tail {
+		return nil, fmt.Errorf("historical state of block #%d is not retained", number)
+	}
+	// Gather the diffs from the requested block up to the closest available state
+	var (
+		start = time.Now()
+		head  = bc.CurrentBlock().NumberU64()
+		diffs []*state.StateDiff
+		base  *types.Header
+	)
+	for n := number + 1; n <= head && base == nil; n++ {
+		current := bc.GetHeaderByNumber(n)
+		if current == nil {
+			return nil, fmt.Errorf("missing header #%d", n)
+		}
+		blob := rawdb.ReadStateDiff(bc.db, n, current.Hash())
+		if len(blob) == 0 {
+			return nil, fmt.Errorf("missing state diff of block #%d", n)
+		}
+		diff := new(state.StateDiff)
+		if err := rlp.DecodeBytes(blob, diff); err != nil {
+			return nil, fmt.Errorf("invalid state diff of block #%d: %v", n, err)
+		}
+		diffs = append(diffs, diff)
+		if bc.HasState(current.Root) {
+			base = current
+		}
+	}
+	if base == nil {
+		return nil, fmt.Errorf("no available state after block #%d", number)
+	}
+	// Roll the base state back, newest diff first, on top of the live state
+	for i, j := 0, len(diffs)-1; i < j; i, j = i+1, j-1 {
+		diffs[i], diffs[j] = diffs[j], diffs[i]
+	}
+	database := state.NewDatabaseWithConfig(&liveNodeDatabase{Database: bc.db, triedb: bc.stateCache.TrieDB()}, &trie.Config{Cache: 16})
+	root, err := state.ApplyStateDiffs(database, base.Root, diffs)
+	if err != nil {
+		return nil, err
+	}
+	if root != header.Root {
+		return nil, fmt.Errorf("historical state root mismatch of block #%d: have %x, want %x", number, root, header.Root)
+	}
+	statedb, err := state.New(root, database, nil)
+	if err != nil {
+		return nil, err
+	}
+	bc.historicCache.Add(header.Hash(), &historicState{state: statedb, base: base.Root})
+
+	log.Debug("Rebuilt historical state", "number", number, "base", base.Number, "diffs", len(diffs), "elapsed", common.PrettyDuration(time.Since(start)))
+	return statedb.Copy(), nil
+}
+
+// liveNodeDatabase is a database serving trie nodes from the live trie database
+// of the chain, including the ones not yet flushed to disk.
+type liveNodeDatabase struct {
+	ethdb.Database
+	triedb *trie.Database
+}
+
+// Get retrieves the given key, trying the live trie nodes first.
+func (db *liveNodeDatabase) Get(key []byte) ([]byte, error) {
+	if len(key) == common.HashLength {
+		if blob, err := db.triedb.Node(common.BytesToHash(key)); err == nil && len(blob) > 0 {
+			return blob, nil
+		}
+	}
+	return db.Database.Get(key)
+}
+
+// Has checks whether the given key is present, including the live trie nodes.
+func (db *liveNodeDatabase) Has(key []byte) (bool, error) {
+	if len(key) == common.HashLength {
+		if blob, err := db.triedb.Node(common.BytesToHash(key)); err == nil && len(blob) > 0 {
+			return true, nil
+		}
+	}
+	return db.Database.Has(key)
+}
@@ -0,0 +1,70 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	// testChainKey is the key of the account funded by the test chain genesis.
+	testChainKey, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testChainAddress = crypto.PubkeyToAddress(testChainKey.PublicKey)
+	testChainSigner  = types.LatestSigner(params.TestChainConfig)
+)
+
+// newTestChain creates a genesis funding the test account and generates n blocks
+// on top of it. If gen is nil, each block sends a wei from the test account to
+// 0xaa.
+func newTestChain(n int, gen func(i int, b *BlockGen)) (*Genesis, []*types.Block) {
+	gspec := &Genesis{
+		Config:  params.TestChainConfig,
+		Alloc:   GenesisAlloc{testChainAddress: {Balance: big.NewInt(1000000000000000000)}},
+		BaseFee: big.NewInt(params.InitialBaseFee),
+	}
+	if gen == nil {
+		gen = func(i int, b *BlockGen) {
+			b.AddTx(testChainTx(b, &common.Address{0xaa}, 1, nil))
+		}
+	}
+	db := rawdb.NewMemoryDatabase()
+	blocks, _ := GenerateChain(gspec.Config, gspec.MustCommit(db), ethash.NewFaker(), db, n, gen)
+	return gspec, blocks
+}
+
+// testChainTx signs a transaction from the test account at its next nonce in the
+// given block, deploying the data as init code if to is nil.
+func testChainTx(b *BlockGen, to *common.Address, value int64, data []byte) *types.Transaction {
+	var tx *types.Transaction
+	if to == nil {
+		tx = types.NewContractCreation(b.TxNonce(testChainAddress), big.NewInt(value), 100000, b.BaseFee(), data)
+	} else {
+		tx = types.NewTransaction(b.TxNonce(testChainAddress), *to, big.NewInt(value), 100000, b.BaseFee(), data)
+	}
+	signed, err := types.SignTx(tx, testChainSigner, testChainKey)
+	if err != nil {
+		panic(err)
+	}
+	return signed
+}
@@ -0,0 +1,178 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/progpow"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// transferTracer is a lightweight EVM logger recording the value moved by the
+// transactions of a block, both by the transactions themselves and by their
+// internal calls, along with the fees they pay. The transfers of reverted calls
+// are dropped.
+//
+// Transactions are counted as their execution starts, transactions rejected
+// before reaching the EVM are not part of the block and leave no trace.
+type transferTracer struct {
+	transfers []*rawdb.ValueTransfer // Transfers of the block so far
+	txIndex   int                    // Position of the running transaction
+	txStart   int                    // Number of transfers before the running transaction
+	frames    []int                  // Number of transfers before each running internal call
+
+	executed bool           // Whether the running transaction reached the EVM
+	gasLimit uint64         // Gas purchased by the running transaction
+	sender   common.Address // Sender of the running transaction, paying its fees
+	coinbase common.Address // Miner credited with the fee tips
+	gasPrice *big.Int       // Effective gas price of the running transaction
+	baseFee  *big.Int       // Base fee burnt per gas, nil before London
+}
+
+// newTransferTracer creates a tracer recording the value transfers of a block.
+func newTransferTracer() *transferTracer {
+	return &transferTracer{txIndex: -1}
+}
+
+// record adds a value transfer of the running transaction.
+func (t *transferTracer) record(kind uint8, from common.Address, to common.Address, value *big.Int) {
+	if value == nil || value.Sign() == 0 {
+		return
+	}
+	t.transfers = append(t.transfers, &rawdb.ValueTransfer{
+		Kind:    kind,
+		TxIndex: uint(t.txIndex),
+		From:    from,
+		To:      to,
+		Value:   new(big.Int).Set(value),
+	})
+}
+
+// copy creates a deep copy of the tracer, to continue recording a block apart
+// from the original.
+func (t *transferTracer) copy() *transferTracer {
+	cpy := *t
+	cpy.transfers = append([]*rawdb.ValueTransfer{}, t.transfers...)
+	cpy.frames = append([]int{}, t.frames...)
+	return &cpy
+}
+
+func (t *transferTracer) CaptureTxStart(gasLimit uint64) {
+	t.executed, t.gasLimit = false, gasLimit
+}
+
+func (t *transferTracer) CaptureTxEnd(restGas uint64) {
+	if !t.executed {
+		return
+	}
+	// The sender pays the gas used at the effective gas price, the miner is
+	// credited with the part above the base fee and the rest is burnt
+	var (
+		gasUsed = new(big.Int).SetUint64(t.gasLimit - restGas)
+		tip     = new(big.Int).Set(t.gasPrice)
+	)
+	if t.baseFee != nil {
+		tip.Sub(tip, t.baseFee)
+		t.record(rawdb.TransferFeeBurn, t.sender, common.Address{}, new(big.Int).Mul(gasUsed, t.baseFee))
+	}
+	t.record(rawdb.TransferFeeTip, t.sender, t.coinbase, tip.Mul(tip, gasUsed))
+}
+
+func (t *transferTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.txIndex++
+	t.txStart = len(t.transfers)
+	t.frames = t.frames[:0]
+
+	t.executed, t.sender, t.coinbase = true, from, env.Context.Coinbase
+	t.gasPrice, t.baseFee = env.TxContext.GasPrice, env.Context.BaseFee
+
+	t.record(rawdb.TransferTransaction, from, to, value)
+}
+
+func (t *transferTracer) CaptureEnd(output []byte, gasUsed uint64, _ time.Duration, err error) {
+	// The value sent by a failed transaction is returned to the sender
+	if err != nil {
+		t.transfers = t.transfers[:t.txStart]
+	}
+}
+
+func (t *transferTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	t.frames = append(t.frames, len(t.transfers))
+
+	// Value sent by CALLCODE stays with the caller, DELEGATECALL and STATICCALL
+	// carry none
+	switch typ {
+	case vm.CALL:
+		t.record(rawdb.TransferCall, from, to, value)
+	case vm.CREATE, vm.CREATE2:
+		t.record(rawdb.TransferCreate, from, to, value)
+	case vm.SELFDESTRUCT:
+		t.record(rawdb.TransferSelfDestruct, from, to, value)
+	}
+}
+
+func (t *transferTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	start := t.frames[len(t.frames)-1]
+	t.frames = t.frames[:len(t.frames)-1]
+
+	// The value moved by a reverted call and its subcalls is returned
+	if err != nil {
+		t.transfers = t.transfers[:start]
+	}
+}
+
+func (t *transferTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+}
+
+func (t *transferTracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+// writeValueTransfers stores the value transfers of the given block, along with
+// the block rewards credited by progpow.
+func (bc *BlockChain) writeValueTransfers(db ethdb.KeyValueWriter, block *types.Block, transfers []*rawdb.ValueTransfer) {
+	if _, ok := bc.engine.(*progpow.Progpow); ok {
+		var (
+			header  = block.Header()
+			uncles  = block.Uncles()
+			rewards = progpow.BlockRewards(bc.chainConfig, header, uncles)
+		)
+		credit := func(kind uint8, to common.Address, value *big.Int) {
+			if value.Sign() > 0 {
+				transfers = append(transfers, &rawdb.ValueTransfer{Kind: kind, To: to, Value: value})
+			}
+		}
+		for i, uncle := range uncles {
+			credit(rawdb.TransferUncleReward, uncle.Coinbase, rewards.Uncles[i])
+		}
+		credit(rawdb.TransferMinerReward, header.Coinbase, rewards.Miner)
+		if config := bc.chainConfig.ProgPow; config != nil {
+			credit(rawdb.TransferFundReward, config.StakerFundAddress, rewards.StakerFund)
+			credit(rawdb.TransferFundReward, config.DevFundAddress, rewards.DevFund)
+			credit(rawdb.TransferFundReward, config.CommunityFundAddress, rewards.CommunityFund)
+		}
+	}
+	if transfers == nil {
+		transfers = []*rawdb.ValueTransfer{}
+	}
+	rawdb.WriteValueTransfers(db, block.NumberU64(), block.Hash(), transfers)
+}
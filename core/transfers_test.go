@@ -0,0 +1,194 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/consensus/progpow"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// importWithTransfers imports a chain recording its value transfers.
+func importWithTransfers(t *testing.T, gspec *Genesis, engine consensus.Engine, n int, gen func(int, *BlockGen)) (ethdb.Database, []*types.Block) {
+	gendb := rawdb.NewMemoryDatabase()
+	blocks, _ := GenerateChain(gspec.Config, gspec.MustCommit(gendb), engine, gendb, n, gen)
+
+	db := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(db)
+
+	cacheConfig := *defaultCacheConfig
+	cacheConfig.ValueTransfers = true
+	chain, err := NewBlockChain(db, &cacheConfig, gspec.Config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	return db, blocks
+}
+
+// Tests that the value transfers of transactions and their internal calls are
+// recorded along with the fees paid, leaving out the ones of reverted calls.
+func TestValueTransfers(t *testing.T) {
+	var (
+		sender  = testChainAddress
+		miner   = common.HexToAddress("0x1234")
+		payee   = common.HexToAddress("0xaaaa")
+		forward = common.HexToAddress("0xf0") // Sends 4 wei to the payee
+		revert  = common.HexToAddress("0xe0") // Sends 4 wei to the payee, then reverts
+		outer   = common.HexToAddress("0x0e") // Sends 3 wei to the reverting contract
+		destroy = common.HexToAddress("0xde") // Self-destructs in favour of the payee
+
+		sendTo = func(to common.Address, value byte) []byte {
+			return append(append([]byte{0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x60, value, 0x73}, to.Bytes()...), 0x5a, 0xf1, 0x50)
+		}
+		gspec = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc: GenesisAlloc{
+				sender:  {Balance: big.NewInt(1000000000000000000)},
+				forward: {Balance: new(big.Int), Code: append(sendTo(payee, 4), 0x00)},
+				revert:  {Balance: big.NewInt(100), Code: append(sendTo(payee, 4), 0x60, 0x00, 0x60, 0x00, 0xfd)},
+				outer:   {Balance: new(big.Int), Code: append(sendTo(revert, 3), 0x00)},
+				destroy: {Balance: big.NewInt(5), Code: append(append([]byte{0x73}, payee.Bytes()...), 0xff)},
+			},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		baseFee *big.Int
+	)
+	db, blocks := importWithTransfers(t, gspec, ethash.NewFaker(), 1, func(i int, b *BlockGen) {
+		b.SetCoinbase(miner)
+		baseFee = b.BaseFee()
+		for _, call := range []struct {
+			to    common.Address
+			value int64
+		}{{forward, 10}, {revert, 1}, {outer, 10}, {destroy, 0}, {payee, 7}} {
+			// Tip the miner as much as the base fee
+			tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(sender), call.to, big.NewInt(call.value), 100000, new(big.Int).Mul(baseFee, big.NewInt(2)), nil), testChainSigner, testChainKey)
+			b.AddTx(tx)
+		}
+	})
+	receipts := rawdb.ReadReceipts(db, blocks[0].Hash(), 1, gspec.Config)
+	fees := func(index int) []*rawdb.ValueTransfer {
+		fee := new(big.Int).Mul(new(big.Int).SetUint64(receipts[index].GasUsed), baseFee)
+		return []*rawdb.ValueTransfer{
+			{Kind: rawdb.TransferFeeBurn, TxIndex: uint(index), From: sender, Value: fee},
+			{Kind: rawdb.TransferFeeTip, TxIndex: uint(index), From: sender, To: miner, Value: fee},
+		}
+	}
+	var want []*rawdb.ValueTransfer
+	want = append(want,
+		&rawdb.ValueTransfer{Kind: rawdb.TransferTransaction, TxIndex: 0, From: sender, To: forward, Value: big.NewInt(10)},
+		&rawdb.ValueTransfer{Kind: rawdb.TransferCall, TxIndex: 0, From: forward, To: payee, Value: big.NewInt(4)},
+	)
+	want = append(want, fees(0)...)
+	want = append(want, fees(1)...)
+	want = append(want, &rawdb.ValueTransfer{Kind: rawdb.TransferTransaction, TxIndex: 2, From: sender, To: outer, Value: big.NewInt(10)})
+	want = append(want, fees(2)...)
+	want = append(want, &rawdb.ValueTransfer{Kind: rawdb.TransferSelfDestruct, TxIndex: 3, From: destroy, To: payee, Value: big.NewInt(5)})
+	want = append(want, fees(3)...)
+	want = append(want, &rawdb.ValueTransfer{Kind: rawdb.TransferTransaction, TxIndex: 4, From: sender, To: payee, Value: big.NewInt(7)})
+	want = append(want, fees(4)...)
+
+	transfers := rawdb.ReadValueTransfers(db, 1, blocks[0].Hash())
+	if !reflect.DeepEqual(transfers, want) {
+		t.Fatalf("value transfers mismatch: have %v, want %v", transfers, want)
+	}
+	// The recorded transfers must account for the balance changes of the sender
+	// and the miner, besides the ethash block reward which is not recorded
+	statedb, err := state.New(blocks[0].Root(), state.NewDatabase(db), nil)
+	if err != nil {
+		t.Fatalf("failed to open state: %v", err)
+	}
+	var debited, credited = new(big.Int), new(big.Int)
+	for _, transfer := range transfers {
+		if transfer.HasSender() && transfer.From == sender {
+			debited.Add(debited, transfer.Value)
+		}
+		if transfer.HasRecipient() && transfer.To == miner {
+			credited.Add(credited, transfer.Value)
+		}
+	}
+	if spent := new(big.Int).Sub(gspec.Alloc[sender].Balance, statedb.GetBalance(sender)); spent.Cmp(debited) != 0 {
+		t.Errorf("sender debit mismatch: balance change %v, transfers %v", spent, debited)
+	}
+	if earned := new(big.Int).Sub(statedb.GetBalance(miner), ethash.ConstantinopleBlockReward); earned.Cmp(credited) != 0 {
+		t.Errorf("miner credit mismatch: balance %v, transfers %v", earned, credited)
+	}
+	// Check the transfers indexed for the payee and the miner
+	for _, check := range []struct {
+		address common.Address
+		want    []uint
+	}{
+		{payee, []uint{1, 9, 12}},
+		{miner, []uint{3, 5, 8, 11, 14}},
+	} {
+		var positions []uint
+		rawdb.IterateAccountTransfers(db, check.address, 0, 1, func(number uint64, hash common.Hash, found []uint) bool {
+			if number != 1 || hash != blocks[0].Hash() {
+				t.Errorf("unexpected block #%d [%x]", number, hash)
+			}
+			positions = append(positions, found...)
+			return true
+		})
+		if !reflect.DeepEqual(positions, check.want) {
+			t.Errorf("%x transfers mismatch: have %v, want %v", check.address, positions, check.want)
+		}
+	}
+}
+
+// Tests that the block rewards credited by progpow are recorded as value
+// transfers.
+func TestValueTransfersProgpowRewards(t *testing.T) {
+	config := *params.AllEthashProtocolChanges
+	config.Ethash = nil
+	config.ProgPow = &params.ProgpowConfig{
+		DevFundAddress:       common.HexToAddress("0xde"),
+		CommunityFundAddress: common.HexToAddress("0xc0"),
+		StakerFundAddress:    common.HexToAddress("0x57"),
+	}
+	var (
+		miner = common.HexToAddress("0x1234")
+		gspec = &Genesis{Config: &config, BaseFee: big.NewInt(params.InitialBaseFee)}
+	)
+	db, blocks := importWithTransfers(t, gspec, progpow.NewFaker(), 1, func(i int, b *BlockGen) {
+		b.SetCoinbase(miner)
+	})
+	rewards := progpow.BlockRewards(&config, blocks[0].Header(), nil)
+	want := []*rawdb.ValueTransfer{
+		{Kind: rawdb.TransferMinerReward, To: miner, Value: rewards.Miner},
+		{Kind: rawdb.TransferFundReward, To: config.ProgPow.StakerFundAddress, Value: rewards.StakerFund},
+		{Kind: rawdb.TransferFundReward, To: config.ProgPow.DevFundAddress, Value: rewards.DevFund},
+		{Kind: rawdb.TransferFundReward, To: config.ProgPow.CommunityFundAddress, Value: rewards.CommunityFund},
+	}
+	if have := rawdb.ReadValueTransfers(db, 1, blocks[0].Hash()); !reflect.DeepEqual(have, want) {
+		t.Fatalf("reward transfers mismatch: have %v, want %v", have, want)
+	}
+}
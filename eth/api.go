@@ -30,12 +30,14 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethereum/go-ethereum/trie"
@@ -71,6 +73,402 @@ func (api *EthereumAPI) Mining() bool {
 	return api.e.IsMining()
 }
 
+const (
+	// historyPageSize is the default number of items returned per page of
+	// account history.
+	historyPageSize = 100
+
+	// historyMaxPageSize is the maximum number of items returned per page of
+	// account history.
+	historyMaxPageSize = 1000
+
+	// accountTxsMaxUnindexed is the maximum number of blocks beyond the account
+	// history index scanned directly by a query, covering the sections still
+	// awaiting confirmation.
+	accountTxsMaxUnindexed = 2 * (params.AccountIndexBlocks + params.AccountIndexConfirms)
+)
+
+// AccountTransaction is a transaction sent or received by an account.
+type AccountTransaction struct {
+	BlockNumber      hexutil.Uint64  `json:"blockNumber"`
+	BlockHash        common.Hash     `json:"blockHash"`
+	TransactionIndex hexutil.Uint    `json:"transactionIndex"`
+	TransactionHash  common.Hash     `json:"transactionHash"`
+	From             common.Address  `json:"from"`
+	To               *common.Address `json:"to"` // Nil for contract creations
+}
+
+// AccountTransactionsPage is a page of the transaction history of an account.
+type AccountTransactionsPage struct {
+	Transactions []*AccountTransaction `json:"transactions"`
+	Cursor       *string               `json:"cursor"` // Cursor of the next page, nil if there are no more transactions
+}
+
+// GetTransactionsByAddress returns a page of at most limit transactions sent or
+// received by an address within the given block range, in chain order, along
+// with the cursor of the next page. The following pages are retrieved with the
+// same range and the cursor of the previous page. It requires the account
+// history index, only covering the blocks within its retention limit.
+func (api *EthereumAPI) GetTransactionsByAddress(ctx context.Context, address common.Address, fromBlock, toBlock *rpc.BlockNumber, pageLimit *math.HexOrDecimal64, cursor *string) (*AccountTransactionsPage, error) {
+	if api.e.accountIndexer == nil {
+		return nil, errors.New("account history index not enabled")
+	}
+	limit, err := ethapi.PageLimit(pageLimit, historyPageSize, historyMaxPageSize)
+	if err != nil {
+		return nil, err
+	}
+	head := api.e.blockchain.CurrentBlock().NumberU64()
+	tail := core.AccountHistoryTail(api.e.chainDb, head, api.e.config.AccountIndexLimit)
+
+	from, err := api.resolveBlockNumber(fromBlock, tail)
+	if err != nil {
+		return nil, err
+	}
+	to, err := api.resolveBlockNumber(toBlock, head)
+	if err != nil {
+		return nil, err
+	}
+	if from < tail {
+		return nil, fmt.Errorf("block %d is beyond the account history retention, the oldest retained block is %d", from, tail)
+	}
+	if to > head {
+		to = head
+	}
+	// Resume after the last transaction of the previous page
+	after, err := api.resumeHistory(cursor, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if after != nil {
+		from = after.Number
+	}
+	// Blocks not covered by the index yet are scanned directly, as long as the
+	// index is not lagging behind too far
+	sections, _, _ := api.e.accountIndexer.Sections()
+	indexed := sections * params.AccountIndexBlocks
+	if to >= indexed && to-indexed >= accountTxsMaxUnindexed {
+		return nil, fmt.Errorf("account history index not synced, indexed up to block %d", int64(indexed)-1)
+	}
+	var (
+		page  = &AccountTransactionsPage{Transactions: []*AccountTransaction{}}
+		found []*AccountTransaction
+	)
+	collect := func(block *types.Block, positions []uint) (bool, error) {
+		signer := types.MakeSigner(api.e.blockchain.Config(), block.Number())
+		for _, index := range positions {
+			if after != nil && block.NumberU64() == after.Number && index <= after.Index {
+				continue
+			}
+			txs := block.Transactions()
+			if int(index) >= len(txs) {
+				return false, fmt.Errorf("account history of block %d out of sync", block.NumberU64())
+			}
+			sender, err := types.Sender(signer, txs[index])
+			if err != nil {
+				return false, err
+			}
+			found = append(found, &AccountTransaction{
+				BlockNumber:      hexutil.Uint64(block.NumberU64()),
+				BlockHash:        block.Hash(),
+				TransactionIndex: hexutil.Uint(index),
+				TransactionHash:  txs[index].Hash(),
+				From:             sender,
+				To:               txs[index].To(),
+			})
+			if len(found) > limit {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+	more := true
+	if from < indexed {
+		end := to
+		if end >= indexed {
+			end = indexed - 1
+		}
+		rawdb.IterateAccountTxs(api.e.chainDb, address, from, end, func(entry *rawdb.AccountTxsEntry) bool {
+			if err = ctx.Err(); err != nil {
+				return false
+			}
+			block := api.e.blockchain.GetBlockByNumber(entry.Number)
+			if block == nil {
+				err = fmt.Errorf("block #%d not found", entry.Number)
+				return false
+			}
+			more, err = collect(block, mergePositions(entry.Sent, entry.Received))
+			return more && err == nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		from = indexed
+	}
+	for number := from; more && number <= to; number++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		block := api.e.blockchain.GetBlockByNumber(number)
+		if block == nil {
+			return nil, fmt.Errorf("block #%d not found", number)
+		}
+		signer := types.MakeSigner(api.e.blockchain.Config(), block.Number())
+
+		var positions []uint
+		for i, tx := range block.Transactions() {
+			sender, err := types.Sender(signer, tx)
+			if err != nil {
+				return nil, err
+			}
+			if sender == address || (tx.To() != nil && *tx.To() == address) {
+				positions = append(positions, uint(i))
+			}
+		}
+		if more, err = collect(block, positions); err != nil {
+			return nil, err
+		}
+	}
+	if len(found) > limit {
+		found = found[:limit]
+		last := found[limit-1]
+		next := (&ethapi.PageCursor{Number: uint64(last.BlockNumber), Hash: last.BlockHash, Index: uint(last.TransactionIndex)}).Encode()
+		page.Cursor = &next
+	}
+	page.Transactions = append(page.Transactions, found...)
+	return page, nil
+}
+
+// ValueTransfer is a movement of value within a block, as recorded by the value
+// transfer index.
+type ValueTransfer struct {
+	BlockNumber      hexutil.Uint64  `json:"blockNumber"`
+	BlockHash        common.Hash     `json:"blockHash"`
+	Index            hexutil.Uint    `json:"index"` // Position of the transfer within the block
+	Type             string          `json:"type"`
+	TransactionIndex *hexutil.Uint   `json:"transactionIndex"` // Nil for block rewards
+	TransactionHash  *common.Hash    `json:"transactionHash"`  // Nil for block rewards, or if the block body was pruned
+	From             *common.Address `json:"from"`             // Nil for block rewards
+	To               *common.Address `json:"to"`               // Nil for burnt fees
+	Value            *hexutil.Big    `json:"value"`
+}
+
+// ValueTransfersPage is a page of the value transfers of an account.
+type ValueTransfersPage struct {
+	Transfers []*ValueTransfer `json:"transfers"`
+	Cursor    *string          `json:"cursor"` // Cursor of the next page, nil if there are no more transfers
+}
+
+// valueTransferTypes are the names of the kinds of value transfers.
+var valueTransferTypes = map[uint8]string{
+	rawdb.TransferTransaction:  "transaction",
+	rawdb.TransferCall:         "call",
+	rawdb.TransferCreate:       "create",
+	rawdb.TransferSelfDestruct: "selfdestruct",
+	rawdb.TransferMinerReward:  "minerReward",
+	rawdb.TransferUncleReward:  "uncleReward",
+	rawdb.TransferFundReward:   "fundReward",
+	rawdb.TransferFeeTip:       "feeTip",
+	rawdb.TransferFeeBurn:      "feeBurn",
+}
+
+// newValueTransfer returns the RPC representation of the value transfer at the
+// given position of a block. The transactions of the block may be nil if its
+// body is not available.
+func newValueTransfer(number uint64, hash common.Hash, txs types.Transactions, index uint, transfer *rawdb.ValueTransfer) *ValueTransfer {
+	result := &ValueTransfer{
+		BlockNumber: hexutil.Uint64(number),
+		BlockHash:   hash,
+		Index:       hexutil.Uint(index),
+		Type:        valueTransferTypes[transfer.Kind],
+		Value:       (*hexutil.Big)(transfer.Value),
+	}
+	if transfer.HasRecipient() {
+		to := transfer.To
+		result.To = &to
+	}
+	if transfer.HasSender() {
+		txIndex, from := hexutil.Uint(transfer.TxIndex), transfer.From
+		result.TransactionIndex, result.From = &txIndex, &from
+		if int(transfer.TxIndex) < len(txs) {
+			txHash := txs[transfer.TxIndex].Hash()
+			result.TransactionHash = &txHash
+		}
+	}
+	return result
+}
+
+// GetValueTransfersByBlock returns the value transfers of a block, including the
+// ones of internal calls and the block rewards. It requires the value transfer
+// index, only covering the blocks imported while it was enabled.
+func (api *EthereumAPI) GetValueTransfersByBlock(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]*ValueTransfer, error) {
+	if !api.e.config.TransferIndex {
+		return nil, errors.New("value transfer index not enabled")
+	}
+	header, err := api.e.APIBackend.HeaderByNumberOrHash(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, errors.New("block not found")
+	}
+	number, hash := header.Number.Uint64(), header.Hash()
+
+	transfers := rawdb.ReadValueTransfers(api.e.chainDb, number, hash)
+	if transfers == nil {
+		return nil, fmt.Errorf("value transfers of block #%d not recorded", number)
+	}
+	var txs types.Transactions
+	if block := api.e.blockchain.GetBlock(hash, number); block != nil {
+		txs = block.Transactions()
+	}
+	result := make([]*ValueTransfer, len(transfers))
+	for i, transfer := range transfers {
+		result[i] = newValueTransfer(number, hash, txs, uint(i), transfer)
+	}
+	return result, nil
+}
+
+// GetValueTransfersByAddress returns a page of at most limit value transfers sent
+// or received by an address within the given block range, in chain order, along
+// with the cursor of the next page. The following pages are retrieved with the
+// same range and the cursor of the previous page. It requires the value transfer
+// index, only covering the blocks imported while it was enabled.
+func (api *EthereumAPI) GetValueTransfersByAddress(ctx context.Context, address common.Address, fromBlock, toBlock *rpc.BlockNumber, pageLimit *math.HexOrDecimal64, cursor *string) (*ValueTransfersPage, error) {
+	if !api.e.config.TransferIndex {
+		return nil, errors.New("value transfer index not enabled")
+	}
+	limit, err := ethapi.PageLimit(pageLimit, historyPageSize, historyMaxPageSize)
+	if err != nil {
+		return nil, err
+	}
+	head := api.e.blockchain.CurrentBlock().NumberU64()
+
+	from, err := api.resolveBlockNumber(fromBlock, 0)
+	if err != nil {
+		return nil, err
+	}
+	to, err := api.resolveBlockNumber(toBlock, head)
+	if err != nil {
+		return nil, err
+	}
+	if to > head {
+		to = head
+	}
+	// Resume after the last transfer of the previous page
+	after, err := api.resumeHistory(cursor, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if after != nil {
+		from = after.Number
+	}
+	var found []*ValueTransfer
+	rawdb.IterateAccountTransfers(api.e.chainDb, address, from, to, func(number uint64, hash common.Hash, positions []uint) bool {
+		if err = ctx.Err(); err != nil {
+			return false
+		}
+		// Skip the transfers of blocks reorganised out of the chain
+		if api.e.blockchain.GetCanonicalHash(number) != hash {
+			return true
+		}
+		transfers := rawdb.ReadValueTransfers(api.e.chainDb, number, hash)
+		if transfers == nil {
+			err = fmt.Errorf("value transfers of block #%d not found", number)
+			return false
+		}
+		var txs types.Transactions
+		if block := api.e.blockchain.GetBlock(hash, number); block != nil {
+			txs = block.Transactions()
+		}
+		for _, index := range positions {
+			if after != nil && number == after.Number && index <= after.Index {
+				continue
+			}
+			if int(index) >= len(transfers) {
+				err = fmt.Errorf("value transfer index of block #%d out of sync", number)
+				return false
+			}
+			found = append(found, newValueTransfer(number, hash, txs, index, transfers[index]))
+			if len(found) > limit {
+				return false
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	page := &ValueTransfersPage{Transfers: []*ValueTransfer{}}
+	if len(found) > limit {
+		found = found[:limit]
+		last := found[limit-1]
+		next := (&ethapi.PageCursor{Number: uint64(last.BlockNumber), Hash: last.BlockHash, Index: uint(last.Index)}).Encode()
+		page.Cursor = &next
+	}
+	page.Transfers = append(page.Transfers, found...)
+	return page, nil
+}
+
+// resumeHistory decodes the cursor of a page of account history within the
+// given block range, as long as the chain was not reorganised in the meantime.
+// It returns nil if there is no cursor, i.e. the first page is requested.
+func (api *EthereumAPI) resumeHistory(cursor *string, from, to uint64) (*ethapi.PageCursor, error) {
+	if cursor == nil {
+		return nil, nil
+	}
+	after, err := ethapi.DecodePageCursor(*cursor)
+	if err != nil {
+		return nil, err
+	}
+	if after.Number < from || after.Number > to {
+		return nil, ethapi.ErrInvalidCursor
+	}
+	if api.e.blockchain.GetCanonicalHash(after.Number) != after.Hash {
+		return nil, ethapi.ErrCursorReorged
+	}
+	return after, nil
+}
+
+// resolveBlockNumber returns the number of the block identified by number, or
+// fallback if it is not given.
+func (api *EthereumAPI) resolveBlockNumber(number *rpc.BlockNumber, fallback uint64) (uint64, error) {
+	if number == nil {
+		return fallback, nil
+	}
+	switch *number {
+	case rpc.LatestBlockNumber, rpc.PendingBlockNumber:
+		return api.e.blockchain.CurrentBlock().NumberU64(), nil
+	case rpc.FinalizedBlockNumber:
+		if block := api.e.blockchain.CurrentFinalizedBlock(); block != nil {
+			return block.NumberU64(), nil
+		}
+		return 0, errors.New("finalized block not found")
+	case rpc.SafeBlockNumber:
+		if block := api.e.blockchain.CurrentSafeBlock(); block != nil {
+			return block.NumberU64(), nil
+		}
+		return 0, errors.New("safe block not found")
+	}
+	return uint64(*number), nil
+}
+
+// mergePositions merges two ascending lists of transaction positions into a
+// single ascending list without duplicates.
+func mergePositions(a, b []uint) []uint {
+	merged := make([]uint, 0, len(a)+len(b))
+	for len(a) > 0 || len(b) > 0 {
+		switch {
+		case len(b) == 0 || (len(a) > 0 && a[0] < b[0]):
+			merged, a = append(merged, a[0]), a[1:]
+		case len(a) == 0 || b[0] < a[0]:
+			merged, b = append(merged, b[0]), b[1:]
+		default:
+			merged, a, b = append(merged, a[0]), a[1:], b[1:]
+		}
+	}
+	return merged
+}
+
 // MinerAPI provides an API to control the miner.
 type MinerAPI struct {
 	e *Ethereum
@@ -332,6 +730,65 @@ func (api *DebugAPI) GetBadBlocks(ctx context.Context) ([]*BadBlockArgs, error)
 	return results, nil
 }
 
+// ScrubStatus returns the progress of the background database scrubber and the
+// inconsistencies it found, along with the block to rewind to via SetHead if the
+// chain data needs repairing.
+func (api *DebugAPI) ScrubStatus() (*core.ScrubStatus, error) {
+	status := api.eth.blockchain.ScrubStatus()
+	if status == nil {
+		return nil, errors.New("database scrubber not enabled")
+	}
+	return status, nil
+}
+
+// CodeDeployment is a contract deployed by a transaction.
+type CodeDeployment struct {
+	Address     common.Address `json:"address"`
+	TxHash      common.Hash    `json:"transactionHash"`
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+}
+
+// CodeDeploymentsResult lists the contracts running a code, as far as the chain
+// has been indexed.
+type CodeDeploymentsResult struct {
+	CodeHash    common.Hash      `json:"codeHash"`
+	IndexedFrom hexutil.Uint64   `json:"indexedFrom"` // First block covered by the index
+	Indexed     hexutil.Uint64   `json:"indexed"`     // First block past the ones covered by the index
+	Deployments []CodeDeployment `json:"deployments"`
+}
+
+// GetCodeDeployments returns the contracts running the code with the given hash,
+// along with the transactions that deployed them, including the contracts
+// created by other contracts. The deployments are only known for the blocks
+// imported while recording the contract creations, the covered range being
+// returned along with them.
+func (api *DebugAPI) GetCodeDeployments(codeHash common.Hash) (*CodeDeploymentsResult, error) {
+	if api.eth.codeIndexer == nil {
+		return nil, errors.New("contract code index not enabled")
+	}
+	sections, _, _ := api.eth.codeIndexer.Sections()
+	indexed := sections * params.CodeIndexBlocks
+
+	from := indexed
+	if start := rawdb.ReadContractCreationsStart(api.eth.chainDb); start != nil && *start < indexed {
+		from = *start
+	}
+	result := &CodeDeploymentsResult{
+		CodeHash:    codeHash,
+		IndexedFrom: hexutil.Uint64(from),
+		Indexed:     hexutil.Uint64(indexed),
+		Deployments: []CodeDeployment{},
+	}
+	for _, deployment := range rawdb.ReadCodeDeployments(api.eth.chainDb, codeHash) {
+		result.Deployments = append(result.Deployments, CodeDeployment{
+			Address:     deployment.Address,
+			TxHash:      deployment.TxHash,
+			BlockNumber: hexutil.Uint64(deployment.BlockNumber),
+		})
+	}
+	return result, nil
+}
+
 // AccountRangeMaxResults is the maximum number of results to be returned per call
 const AccountRangeMaxResults = 256
 
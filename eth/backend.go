@@ -87,6 +87,10 @@ type Ethereum struct {
 	bloomIndexer      *core.ChainIndexer             // Bloom indexer operating during block imports
 	closeBloomHandler chan struct{}
 
+	codeIndexer    *core.ChainIndexer // Contract code indexer, nil if disabled
+	logIndexer     *core.ChainIndexer // Log indexer, child of the bloom indexer, nil if disabled
+	accountIndexer *core.ChainIndexer // Account history indexer, nil if disabled
+
 	APIBackend *EthAPIBackend
 
 	miner     *miner.Miner
@@ -137,6 +141,21 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if err != nil {
 		return nil, err
 	}
+	// Resolve the state scheme before any state is written by the genesis setup
+	scheme, err := rawdb.ParseStateScheme(config.StateScheme, chainDb)
+	if err != nil {
+		return nil, err
+	}
+	if scheme == rawdb.PathScheme {
+		if config.NoPruning {
+			return nil, errors.New("path state scheme is incompatible with archive mode")
+		}
+		if config.StateDiffs > 0 {
+			return nil, errors.New("path state scheme is incompatible with retaining state diffs")
+		}
+		rawdb.WriteStateScheme(chainDb, scheme)
+	}
+	log.Info("Initialised state scheme", "scheme", scheme)
 	chainConfig, genesisHash, genesisErr := core.SetupGenesisBlockWithOverride(chainDb, config.Genesis, config.OverrideTerminalTotalDifficulty, config.OverrideTerminalTotalDifficultyPassed)
 	if _, ok := genesisErr.(*params.ConfigCompatError); genesisErr != nil && !ok {
 		return nil, genesisErr
@@ -201,6 +220,12 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 			TrieTimeLimit:       config.TrieTimeout,
 			SnapshotLimit:       config.SnapshotCache,
 			Preimages:           config.Preimages,
+			StateHistory:        config.StateHistory,
+			StateDiffs:          config.StateDiffs,
+			HistoryKeep:         config.HistoryKeep,
+			Scrub:               config.Scrub,
+			ValueTransfers:      config.TransferIndex,
+			ContractCreations:   config.CodeIndex,
 		}
 	)
 	eth.blockchain, err = core.NewBlockChain(chainDb, cacheConfig, chainConfig, eth.engine, vmConfig, eth.shouldPreserve, &config.TxLookupLimit)
@@ -213,7 +238,54 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		eth.blockchain.SetHead(compat.RewindTo)
 		rawdb.WriteChainConfig(chainDb, genesisHash, chainConfig)
 	}
+	// Apply any user supplied confirmation depths on top of the chain config.
+	if config.SafeDepth != 0 || config.FinalizedDepth != 0 {
+		safe, finalized := eth.blockchain.FinalityDepths()
+		if config.SafeDepth != 0 {
+			safe = config.SafeDepth
+		}
+		if config.FinalizedDepth != 0 {
+			finalized = config.FinalizedDepth
+		}
+		if err := eth.blockchain.SetFinalityDepths(safe, finalized); err != nil {
+			return nil, err
+		}
+	}
+	// Configure the reorg protection, seeding the checkpoints with the hard coded
+	// one of the network and any user supplied ones. The stable checkpoint of the
+	// checkpoint oracle contract is pinned by the light server, which maintains
+	// the CHT needed to resolve it to a block.
+	if config.MaxReorgDepth != 0 {
+		if err := eth.blockchain.SetMaxReorgDepth(config.MaxReorgDepth); err != nil {
+			return nil, err
+		}
+	}
+	if config.Checkpoint != nil && !config.Checkpoint.Empty() {
+		eth.blockchain.AddCheckpoint((config.Checkpoint.SectionIndex+1)*params.CHTFrequency-1, config.Checkpoint.SectionHead)
+	}
+	if config.ReorgCheckpoints != "" {
+		checkpoints, err := core.LoadCheckpoints(stack.ResolvePath(config.ReorgCheckpoints), config.CheckpointOracle)
+		if err != nil {
+			return nil, err
+		}
+		for _, checkpoint := range checkpoints {
+			eth.blockchain.AddCheckpoint(checkpoint.Number, checkpoint.Hash)
+		}
+		log.Info("Loaded reorg checkpoints", "count", len(checkpoints), "signed", config.CheckpointOracle != nil)
+	}
+	if config.LogIndex {
+		eth.logIndexer = core.NewLogIndexer(chainDb, params.BloomBitsBlocks, 0)
+		eth.bloomIndexer.AddChildIndexer(eth.logIndexer)
+	}
 	eth.bloomIndexer.Start(eth.blockchain)
+	if config.CodeIndex {
+		eth.codeIndexer = core.NewCodeIndexer(chainDb, params.CodeIndexBlocks, params.CodeIndexConfirms)
+		eth.codeIndexer.Start(eth.blockchain)
+	}
+	if config.AccountIndex {
+		eth.accountIndexer = core.NewAccountIndexer(chainDb, chainConfig, config.AccountIndexLimit, params.AccountIndexBlocks, params.AccountIndexConfirms)
+		eth.accountIndexer.Start(eth.blockchain)
+	}
 
 	if config.TxPool.Journal != "" {
 		config.TxPool.Journal = stack.ResolvePath(config.TxPool.Journal)
@@ -314,7 +386,7 @@ func (s *Ethereum) APIs() []rpc.API {
 			Service:   downloader.NewDownloaderAPI(s.handler.downloader, s.eventMux),
 		}, {
 			Namespace: "eth",
-			Service:   filters.NewFilterAPI(s.APIBackend, false, 5*time.Minute),
+			Service:   filters.NewFilterAPI(s.APIBackend, false, 5*time.Minute, filters.LogLimits{MaxResults: s.config.RPCLogsMaxResults, MaxRange: s.config.RPCLogsMaxRange}),
 		}, {
 			Namespace: "admin",
 			Service:   NewAdminAPI(s),
@@ -552,6 +624,12 @@ func (s *Ethereum) Stop() error {
 	// Then stop everything else.
 	s.bloomIndexer.Close()
 	close(s.closeBloomHandler)
+	if s.codeIndexer != nil {
+		s.codeIndexer.Close()
+	}
+	if s.accountIndexer != nil {
+		s.accountIndexer.Close()
+	}
 	s.txPool.Stop()
 	s.miner.Close()
 	s.blockchain.Stop()
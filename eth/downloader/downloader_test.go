@@ -117,6 +117,12 @@ func (dl *downloadTester) sync(id string, td *big.Int, mode SyncMode) error {
 
 // newPeer registers a new block download source into the downloader.
 func (dl *downloadTester) newPeer(id string, version uint, blocks []*types.Block) *downloadTesterPeer {
+	return dl.newPrunedPeer(id, version, blocks, 0)
+}
+
+// newPrunedPeer registers a new block download source into the downloader, which
+// advertises to serve the bodies and receipts from the given block on.
+func (dl *downloadTester) newPrunedPeer(id string, version uint, blocks []*types.Block, tail uint64) *downloadTesterPeer {
 	dl.lock.Lock()
 	defer dl.lock.Unlock()
 
@@ -124,6 +130,7 @@ func (dl *downloadTester) newPeer(id string, version uint, blocks []*types.Block
 		dl:              dl,
 		id:              id,
 		chain:           newTestBlockchain(blocks),
+		tail:            tail,
 		withholdHeaders: make(map[common.Hash]struct{}),
 	}
 	dl.peers[id] = peer
@@ -152,6 +159,9 @@ type downloadTesterPeer struct {
 	id    string
 	chain *core.BlockChain
 
+	tail   uint64 // First block whose body and receipts are served
+	pruned uint32 // Number of requests for bodies or receipts below the tail
+
 	withholdHeaders map[common.Hash]struct{}
 }
 
@@ -266,6 +276,7 @@ func (dlp *downloadTesterPeer) RequestHeadersByNumber(origin uint64, amount int,
 // peer in the download tester. The returned function can be used to retrieve
 // batches of block bodies from the particularly requested peer.
 func (dlp *downloadTesterPeer) RequestBodies(hashes []common.Hash, sink chan *eth.Response) (*eth.Request, error) {
+	dlp.checkPruned(hashes)
 	blobs := eth.ServiceGetBlockBodiesQuery(dlp.chain, hashes)
 
 	bodies := make([]*eth.BlockBody, len(blobs))
@@ -302,6 +313,7 @@ func (dlp *downloadTesterPeer) RequestBodies(hashes []common.Hash, sink chan *et
 // peer in the download tester. The returned function can be used to retrieve
 // batches of block receipts from the particularly requested peer.
 func (dlp *downloadTesterPeer) RequestReceipts(hashes []common.Hash, sink chan *eth.Response) (*eth.Request, error) {
+	dlp.checkPruned(hashes)
 	blobs := eth.ServiceGetReceiptsQuery(dlp.chain, hashes)
 
 	receipts := make([][]*types.Receipt, len(blobs))
@@ -329,6 +341,22 @@ func (dlp *downloadTesterPeer) RequestReceipts(hashes []common.Hash, sink chan *
 	return req, nil
 }
 
+// HistoryTail retrieves the first block whose body and receipts the peer serves.
+func (dlp *downloadTesterPeer) HistoryTail() uint64 {
+	return dlp.tail
+}
+
+// checkPruned counts the requests for bodies or receipts below the history tail
+// the peer advertises.
+func (dlp *downloadTesterPeer) checkPruned(hashes []common.Hash) {
+	for _, hash := range hashes {
+		if header := dlp.chain.GetHeaderByHash(hash); header != nil && header.Number.Uint64() < dlp.tail {
+			atomic.AddUint32(&dlp.pruned, 1)
+			return
+		}
+	}
+}
+
 // ID retrieves the peer's unique identifier.
 func (dlp *downloadTesterPeer) ID() string {
 	return dlp.id
@@ -733,6 +761,33 @@ func testMultiSynchronisation(t *testing.T, protocol uint, mode SyncMode) {
 	assertOwnChain(t, tester, len(chain.blocks))
 }
 
+// Tests that peers advertising a history tail are not asked for the bodies and
+// receipts below it.
+func TestPrunedPeers66Full(t *testing.T) { testPrunedPeers(t, eth.ETH66, FullSync) }
+func TestPrunedPeers66Snap(t *testing.T) { testPrunedPeers(t, eth.ETH66, SnapSync) }
+func TestPrunedPeers67Full(t *testing.T) { testPrunedPeers(t, eth.ETH67, FullSync) }
+func TestPrunedPeers67Snap(t *testing.T) { testPrunedPeers(t, eth.ETH67, SnapSync) }
+
+func testPrunedPeers(t *testing.T, protocol uint, mode SyncMode) {
+	tester := newTester(t)
+	defer tester.terminate()
+
+	chain := testChainBase.shorten(blockCacheMaxItems - 15)
+	tail := uint64(len(chain.blocks) / 2)
+
+	tester.newPeer("full", protocol, chain.blocks[1:])
+	pruned := tester.newPrunedPeer("pruned", protocol, chain.blocks[1:], tail)
+
+	if err := tester.sync("full", nil, mode); err != nil {
+		t.Fatalf("failed to synchronise blocks: %v", err)
+	}
+	assertOwnChain(t, tester, len(chain.blocks))
+
+	if n := atomic.LoadUint32(&pruned.pruned); n != 0 {
+		t.Errorf("pruned peer asked for history below its tail %d times", n)
+	}
+}
+
 // Tests that synchronisations behave well in multi-version protocol environments
 // and not wreak havoc on other nodes in the network.
 func TestMultiProtoSynchronisation66Full(t *testing.T)  { testMultiProtoSync(t, eth.ETH66, FullSync) }
@@ -47,6 +47,7 @@ type peerConnection struct {
 
 	rates   *msgrate.Tracker         // Tracker to hone in on the number of items retrievable per second
 	lacking map[common.Hash]struct{} // Set of hashes not to request (didn't have previously)
+	tail    uint64                   // First block whose body and receipts to request
 
 	peer Peer
 
@@ -67,6 +68,10 @@ type Peer interface {
 	LightPeer
 	RequestBodies([]common.Hash, chan *eth.Response) (*eth.Request, error)
 	RequestReceipts([]common.Hash, chan *eth.Response) (*eth.Request, error)
+
+	// HistoryTail retrieves the first block whose body and receipts the peer
+	// advertises to serve, zero if it serves the entire history.
+	HistoryTail() uint64
 }
 
 // lightPeerWrapper wraps a LightPeer struct, stubbing out the Peer-only methods.
@@ -87,12 +92,14 @@ func (w *lightPeerWrapper) RequestBodies([]common.Hash, chan *eth.Response) (*et
 func (w *lightPeerWrapper) RequestReceipts([]common.Hash, chan *eth.Response) (*eth.Request, error) {
 	panic("RequestReceipts not supported in light client mode sync")
 }
+func (w *lightPeerWrapper) HistoryTail() uint64 { return 0 }
 
 // newPeerConnection creates a new downloader peer.
 func newPeerConnection(id string, version uint, peer Peer, logger log.Logger) *peerConnection {
 	return &peerConnection{
 		id:      id,
 		lacking: make(map[common.Hash]struct{}),
+		tail:    peer.HistoryTail(),
 		peer:    peer,
 		version: version,
 		log:     logger,
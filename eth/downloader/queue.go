@@ -493,7 +493,8 @@ func (q *queue) reserveHeaders(p *peerConnection, count int, taskPool map[common
 	if _, ok := pendPool[p.id]; ok {
 		return nil, false, false
 	}
-	// Retrieve a batch of tasks, skipping previously failed ones
+	// Retrieve a batch of tasks, skipping previously failed ones and the ones
+	// below the history the peer serves
 	send := make([]*types.Header, 0, count)
 	skip := make([]*types.Header, 0)
 	progress := false
@@ -542,7 +543,7 @@ func (q *queue) reserveHeaders(p *peerConnection, count int, taskPool map[common
 		// Remove it from the task queue
 		taskQueue.PopItem()
 		// Otherwise unless the peer is known not to have the data, add to the retrieve list
-		if p.Lacks(header.Hash()) {
+		if p.Lacks(header.Hash()) || header.Number.Uint64() < p.tail {
 			skip = append(skip, header)
 		} else {
 			send = append(send, header)
@@ -204,6 +204,10 @@ func (p *skeletonTestPeer) RequestReceipts([]common.Hash, chan *eth.Response) (*
 	panic("skeleton sync must not request receipts")
 }
 
+func (p *skeletonTestPeer) HistoryTail() uint64 {
+	return 0
+}
+
 // Tests various sync initialzations based on previous leftovers in the database
 // and announced heads.
 func TestSkeletonSyncInit(t *testing.T) {
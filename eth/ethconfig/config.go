@@ -93,6 +93,7 @@ var Defaults = Config{
 	TrieDirtyCache:          256,
 	TrieTimeout:             60 * time.Minute,
 	SnapshotCache:           102,
+	StateHistory:            2048,
 	Miner: miner.Config{
 		GasCeil:  30000000,
 		GasPrice: big.NewInt(params.GWei),
@@ -150,8 +151,50 @@ type Config struct {
 	NoPruning  bool // Whether to disable pruning and flush everything to disk
 	NoPrefetch bool // Whether to disable prefetching and only load state on demand
 
+	// StateScheme selects how state trie nodes are persisted ("hash" or "path"),
+	// StateHistory is the number of reverse state diffs kept by the path scheme.
+	StateScheme  string `toml:",omitempty"`
+	StateHistory uint64 `toml:",omitempty"`
+
+	// StateDiffs is the number of recent blocks whose state diffs are retained
+	// to serve historical state without an archive node (0 = disabled).
+	StateDiffs uint64 `toml:",omitempty"`
+
+	// HistoryKeep is the number of recent blocks whose bodies, receipts, value
+	// transfers and contract creations are retained, older frozen ones are
+	// pruned (0 = entire chain).
+	HistoryKeep uint64 `toml:",omitempty"`
+
+	// Scrub enables checking the database for inconsistencies in the background.
+	Scrub bool `toml:",omitempty"`
+
+	// CodeIndex enables indexing the contracts deployed with each code.
+	CodeIndex bool `toml:",omitempty"`
+
+	// LogIndex enables indexing the logs by their addresses and topics.
+	LogIndex bool `toml:",omitempty"`
+
+	// AccountIndex enables indexing the transactions by their senders and
+	// recipients, AccountIndexLimit restricts it to the most recent blocks.
+	AccountIndex      bool   `toml:",omitempty"`
+	AccountIndexLimit uint64 `toml:",omitempty"`
+
+	// TransferIndex enables recording the value transfers of the imported
+	// blocks, including internal calls and block rewards.
+	TransferIndex bool `toml:",omitempty"`
+
 	TxLookupLimit uint64 `toml:",omitempty"` // The maximum number of blocks from head whose tx indices are reserved.
 
+	// SafeDepth and FinalizedDepth override the proof-of-work confirmation
+	// depths of the chain config for the safe and finalized block tags.
+	SafeDepth      uint64 `toml:",omitempty"`
+	FinalizedDepth uint64 `toml:",omitempty"`
+
+	// MaxReorgDepth overrides the maximum number of canonical blocks a reorg may
+	// drop, ReorgCheckpoints is a JSON file of trusted block checkpoints.
+	MaxReorgDepth    uint64 `toml:",omitempty"`
+	ReorgCheckpoints string `toml:",omitempty"`
+
 	// RequiredBlocks is a set of block number -> hash mappings which must be in the
 	// canonical chain of all remote peers. Setting the option makes geth verify the
 	// presence of these blocks for every new peer connection.
@@ -216,6 +259,11 @@ type Config struct {
 	// send-transction variants. The unit is ether.
 	RPCTxFeeCap float64
 
+	// RPCLogsMaxResults and RPCLogsMaxRange limit the number of logs returned
+	// by a log query and the number of blocks it may span, zero meaning no limit.
+	RPCLogsMaxResults int    `toml:",omitempty"`
+	RPCLogsMaxRange   uint64 `toml:",omitempty"`
+
 	// Checkpoint is a hardcoded checkpoint which can be nil.
 	Checkpoint *params.TrustedCheckpoint `toml:",omitempty"`
 
@@ -26,7 +26,21 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		SnapDiscoveryURLs                     []string
 		NoPruning                             bool
 		NoPrefetch                            bool
+		StateScheme                           string                 `toml:",omitempty"`
+		StateHistory                          uint64                 `toml:",omitempty"`
+		StateDiffs                            uint64                 `toml:",omitempty"`
+		HistoryKeep                           uint64                 `toml:",omitempty"`
+		Scrub                                 bool                   `toml:",omitempty"`
+		CodeIndex                             bool                   `toml:",omitempty"`
+		LogIndex                              bool                   `toml:",omitempty"`
+		AccountIndex                          bool                   `toml:",omitempty"`
+		AccountIndexLimit                     uint64                 `toml:",omitempty"`
+		TransferIndex                         bool                   `toml:",omitempty"`
 		TxLookupLimit                         uint64                 `toml:",omitempty"`
+		SafeDepth                             uint64                 `toml:",omitempty"`
+		FinalizedDepth                        uint64                 `toml:",omitempty"`
+		MaxReorgDepth                         uint64                 `toml:",omitempty"`
+		ReorgCheckpoints                      string                 `toml:",omitempty"`
 		RequiredBlocks                        map[uint64]common.Hash `toml:"-"`
 		LightServ                             int                    `toml:",omitempty"`
 		LightIngress                          int                    `toml:",omitempty"`
@@ -59,6 +73,8 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		RPCGasCap                             uint64
 		RPCEVMTimeout                         time.Duration
 		RPCTxFeeCap                           float64
+		RPCLogsMaxResults                     int                            `toml:",omitempty"`
+		RPCLogsMaxRange                       uint64                         `toml:",omitempty"`
 		Checkpoint                            *params.TrustedCheckpoint      `toml:",omitempty"`
 		CheckpointOracle                      *params.CheckpointOracleConfig `toml:",omitempty"`
 		OverrideTerminalTotalDifficulty       *big.Int                       `toml:",omitempty"`
@@ -72,7 +88,21 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.SnapDiscoveryURLs = c.SnapDiscoveryURLs
 	enc.NoPruning = c.NoPruning
 	enc.NoPrefetch = c.NoPrefetch
+	enc.StateScheme = c.StateScheme
+	enc.StateHistory = c.StateHistory
+	enc.StateDiffs = c.StateDiffs
+	enc.HistoryKeep = c.HistoryKeep
+	enc.Scrub = c.Scrub
+	enc.CodeIndex = c.CodeIndex
+	enc.LogIndex = c.LogIndex
+	enc.AccountIndex = c.AccountIndex
+	enc.AccountIndexLimit = c.AccountIndexLimit
+	enc.TransferIndex = c.TransferIndex
 	enc.TxLookupLimit = c.TxLookupLimit
+	enc.SafeDepth = c.SafeDepth
+	enc.FinalizedDepth = c.FinalizedDepth
+	enc.MaxReorgDepth = c.MaxReorgDepth
+	enc.ReorgCheckpoints = c.ReorgCheckpoints
 	enc.RequiredBlocks = c.RequiredBlocks
 	enc.LightServ = c.LightServ
 	enc.LightIngress = c.LightIngress
@@ -105,6 +135,8 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.RPCGasCap = c.RPCGasCap
 	enc.RPCEVMTimeout = c.RPCEVMTimeout
 	enc.RPCTxFeeCap = c.RPCTxFeeCap
+	enc.RPCLogsMaxResults = c.RPCLogsMaxResults
+	enc.RPCLogsMaxRange = c.RPCLogsMaxRange
 	enc.Checkpoint = c.Checkpoint
 	enc.CheckpointOracle = c.CheckpointOracle
 	enc.OverrideTerminalTotalDifficulty = c.OverrideTerminalTotalDifficulty
@@ -122,7 +154,21 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		SnapDiscoveryURLs                     []string
 		NoPruning                             *bool
 		NoPrefetch                            *bool
+		StateScheme                           *string                `toml:",omitempty"`
+		StateHistory                          *uint64                `toml:",omitempty"`
+		StateDiffs                            *uint64                `toml:",omitempty"`
+		HistoryKeep                           *uint64                `toml:",omitempty"`
+		Scrub                                 *bool                  `toml:",omitempty"`
+		CodeIndex                             *bool                  `toml:",omitempty"`
+		LogIndex                              *bool                  `toml:",omitempty"`
+		AccountIndex                          *bool                  `toml:",omitempty"`
+		AccountIndexLimit                     *uint64                `toml:",omitempty"`
+		TransferIndex                         *bool                  `toml:",omitempty"`
 		TxLookupLimit                         *uint64                `toml:",omitempty"`
+		SafeDepth                             *uint64                `toml:",omitempty"`
+		FinalizedDepth                        *uint64                `toml:",omitempty"`
+		MaxReorgDepth                         *uint64                `toml:",omitempty"`
+		ReorgCheckpoints                      *string                `toml:",omitempty"`
 		RequiredBlocks                        map[uint64]common.Hash `toml:"-"`
 		LightServ                             *int                   `toml:",omitempty"`
 		LightIngress                          *int                   `toml:",omitempty"`
@@ -155,6 +201,8 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		RPCGasCap                             *uint64
 		RPCEVMTimeout                         *time.Duration
 		RPCTxFeeCap                           *float64
+		RPCLogsMaxResults                     *int                           `toml:",omitempty"`
+		RPCLogsMaxRange                       *uint64                        `toml:",omitempty"`
 		Checkpoint                            *params.TrustedCheckpoint      `toml:",omitempty"`
 		CheckpointOracle                      *params.CheckpointOracleConfig `toml:",omitempty"`
 		OverrideTerminalTotalDifficulty       *big.Int                       `toml:",omitempty"`
@@ -185,9 +233,51 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.NoPrefetch != nil {
 		c.NoPrefetch = *dec.NoPrefetch
 	}
+	if dec.StateScheme != nil {
+		c.StateScheme = *dec.StateScheme
+	}
+	if dec.StateHistory != nil {
+		c.StateHistory = *dec.StateHistory
+	}
+	if dec.StateDiffs != nil {
+		c.StateDiffs = *dec.StateDiffs
+	}
+	if dec.HistoryKeep != nil {
+		c.HistoryKeep = *dec.HistoryKeep
+	}
+	if dec.Scrub != nil {
+		c.Scrub = *dec.Scrub
+	}
+	if dec.CodeIndex != nil {
+		c.CodeIndex = *dec.CodeIndex
+	}
+	if dec.LogIndex != nil {
+		c.LogIndex = *dec.LogIndex
+	}
+	if dec.AccountIndex != nil {
+		c.AccountIndex = *dec.AccountIndex
+	}
+	if dec.AccountIndexLimit != nil {
+		c.AccountIndexLimit = *dec.AccountIndexLimit
+	}
+	if dec.TransferIndex != nil {
+		c.TransferIndex = *dec.TransferIndex
+	}
 	if dec.TxLookupLimit != nil {
 		c.TxLookupLimit = *dec.TxLookupLimit
 	}
+	if dec.SafeDepth != nil {
+		c.SafeDepth = *dec.SafeDepth
+	}
+	if dec.FinalizedDepth != nil {
+		c.FinalizedDepth = *dec.FinalizedDepth
+	}
+	if dec.MaxReorgDepth != nil {
+		c.MaxReorgDepth = *dec.MaxReorgDepth
+	}
+	if dec.ReorgCheckpoints != nil {
+		c.ReorgCheckpoints = *dec.ReorgCheckpoints
+	}
 	if dec.RequiredBlocks != nil {
 		c.RequiredBlocks = dec.RequiredBlocks
 	}
@@ -284,6 +374,12 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.RPCTxFeeCap != nil {
 		c.RPCTxFeeCap = *dec.RPCTxFeeCap
 	}
+	if dec.RPCLogsMaxResults != nil {
+		c.RPCLogsMaxResults = *dec.RPCLogsMaxResults
+	}
+	if dec.RPCLogsMaxRange != nil {
+		c.RPCLogsMaxRange = *dec.RPCLogsMaxRange
+	}
 	if dec.Checkpoint != nil {
 		c.Checkpoint = dec.Checkpoint
 	}
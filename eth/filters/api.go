@@ -28,7 +28,10 @@ import (
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
@@ -51,15 +54,38 @@ type FilterAPI struct {
 	filtersMu sync.Mutex
 	filters   map[rpc.ID]*filter
 	timeout   time.Duration
+	limits    LogLimits
 }
 
+// LogLimits are the server side limits of the log queries, zero meaning no limit.
+type LogLimits struct {
+	MaxResults int    // Maximum number of logs returned by a query, or a page of one
+	MaxRange   uint64 // Maximum number of blocks a query may span
+}
+
+// defaultLogsPageSize is the page size of paginated log queries without limits.
+const defaultLogsPageSize = 1000
+
+var (
+	errPageFull = errors.New("page full")
+)
+
+// logLimitError is returned by the log queries exceeding a server side limit.
+type logLimitError struct{ message string }
+
+func (e *logLimitError) Error() string { return e.message }
+
+// ErrorCode returns the "limit exceeded" JSON-RPC error code of EIP-1474.
+func (e *logLimitError) ErrorCode() int { return -32005 }
+
 // NewFilterAPI returns a new FilterAPI instance.
-func NewFilterAPI(backend Backend, lightMode bool, timeout time.Duration) *FilterAPI {
+func NewFilterAPI(backend Backend, lightMode bool, timeout time.Duration, limits LogLimits) *FilterAPI {
 	api := &FilterAPI{
 		backend: backend,
 		events:  NewEventSystem(backend, lightMode),
 		filters: make(map[rpc.ID]*filter),
 		timeout: timeout,
+		limits:  limits,
 	}
 	go api.timeoutLoop(timeout)
 
@@ -230,6 +256,121 @@ func (api *FilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
 	return rpcSub, nil
 }
 
+// ReorgNotification is the payload sent to "reorgs" subscribers whenever the
+// canonical chain is switched over to a side chain.
+type ReorgNotification struct {
+	CommonAncestor      ReorgBlock     `json:"commonAncestor"`
+	Depth               hexutil.Uint64 `json:"depth"`
+	RemovedBlocks       []common.Hash  `json:"removedBlocks"`
+	AddedBlocks         []common.Hash  `json:"addedBlocks"`
+	DroppedTransactions []common.Hash  `json:"droppedTransactions"`
+}
+
+// ReorgBlock identifies a block involved in a chain reorganisation.
+type ReorgBlock struct {
+	Hash   common.Hash    `json:"hash"`
+	Number hexutil.Uint64 `json:"number"`
+}
+
+func newReorgNotification(ev core.ReorgEvent) *ReorgNotification {
+	n := &ReorgNotification{
+		CommonAncestor: ReorgBlock{
+			Hash:   ev.CommonAncestor.Hash(),
+			Number: hexutil.Uint64(ev.CommonAncestor.Number.Uint64()),
+		},
+		Depth:               hexutil.Uint64(len(ev.OldChain)),
+		RemovedBlocks:       ev.OldChain,
+		AddedBlocks:         ev.NewChain,
+		DroppedTransactions: ev.DroppedTxs,
+	}
+	if n.DroppedTransactions == nil {
+		n.DroppedTransactions = []common.Hash{}
+	}
+	return n
+}
+
+// Reorgs send a notification each time the canonical chain is reorganised,
+// reporting the common ancestor, the removed and added blocks and the hashes
+// of the transactions that were dropped from the canonical chain.
+func (api *FilterAPI) Reorgs(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		reorgs := make(chan core.ReorgEvent)
+		reorgsSub := api.events.SubscribeReorgs(reorgs)
+
+		for {
+			select {
+			case ev := <-reorgs:
+				notifier.Notify(rpcSub.ID, newReorgNotification(ev))
+			case <-rpcSub.Err():
+				reorgsSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				reorgsSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// RefusedReorgNotification is the payload sent to "refusedReorgs" subscribers
+// whenever the chain refuses to switch over to a fork.
+type RefusedReorgNotification struct {
+	Head   ReorgBlock     `json:"head"`
+	Fork   ReorgBlock     `json:"fork"`
+	Depth  hexutil.Uint64 `json:"depth"`
+	Reason string         `json:"reason"`
+}
+
+func newRefusedReorgNotification(ev core.ReorgRefusedEvent) *RefusedReorgNotification {
+	return &RefusedReorgNotification{
+		Head:   ReorgBlock{Hash: ev.Head.Hash(), Number: hexutil.Uint64(ev.Head.Number.Uint64())},
+		Fork:   ReorgBlock{Hash: ev.Block.Hash(), Number: hexutil.Uint64(ev.Block.Number.Uint64())},
+		Depth:  hexutil.Uint64(ev.Depth),
+		Reason: ev.Reason.Error(),
+	}
+}
+
+// RefusedReorgs send a notification each time the chain refuses to switch over
+// to a fork because it would exceed the maximum reorg depth or conflict with a
+// trusted checkpoint.
+func (api *FilterAPI) RefusedReorgs(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		refused := make(chan core.ReorgRefusedEvent)
+		refusedSub := api.events.SubscribeRefusedReorgs(refused)
+
+		for {
+			select {
+			case ev := <-refused:
+				notifier.Notify(rpcSub.ID, newRefusedReorgNotification(ev))
+			case <-rpcSub.Err():
+				refusedSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				refusedSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
 // Logs creates a subscription that fires for all new log that match the given filter criteria.
 func (api *FilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
@@ -317,6 +458,107 @@ func (api *FilterAPI) NewFilter(crit FilterCriteria) (rpc.ID, error) {
 
 // GetLogs returns logs matching the given argument that are stored within the state.
 func (api *FilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([]*types.Log, error) {
+	filter := api.newFilter(crit)
+
+	// Stream the logs if the client requested it
+	if stream := rpc.StreamFromContext(ctx); stream != nil {
+		err := api.runFilter(ctx, filter, func(logs []*types.Log) error {
+			for _, l := range logs {
+				if err := stream.Write(l); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return []*types.Log{}, nil
+	}
+	// Run the filter and return all the logs
+	var logs []*types.Log
+	err := api.runFilter(ctx, filter, func(found []*types.Log) error {
+		logs = append(logs, found...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return returnLogs(logs), err
+}
+
+// LogsPage is a page of the logs matching a query.
+type LogsPage struct {
+	Logs   []*types.Log `json:"logs"`
+	Cursor *string      `json:"cursor"` // Cursor of the next page, nil if there are no more logs
+}
+
+// GetLogsPage returns a page of at most limit logs matching the given criteria,
+// along with the cursor of the next page. The following pages are retrieved with
+// the same criteria and the cursor of the previous page. Without a limit, the
+// largest page allowed is returned.
+func (api *FilterAPI) GetLogsPage(ctx context.Context, crit FilterCriteria, pageLimit *math.HexOrDecimal64, cursor *string) (*LogsPage, error) {
+	size := api.limits.MaxResults
+	if size == 0 {
+		size = defaultLogsPageSize
+	}
+	limit, err := ethapi.PageLimit(pageLimit, size, api.limits.MaxResults)
+	if err != nil {
+		return nil, err
+	}
+	pending := rpc.PendingBlockNumber.Int64()
+	if crit.FromBlock != nil && crit.FromBlock.Int64() == pending || crit.ToBlock != nil && crit.ToBlock.Int64() == pending {
+		return nil, errors.New("pending logs cannot be paginated")
+	}
+	// Resume after the last log of the previous page, as long as the chain was
+	// not reorganised in the meantime
+	var after *ethapi.PageCursor
+	if cursor != nil {
+		if after, err = ethapi.DecodePageCursor(*cursor); err != nil {
+			return nil, err
+		}
+		if crit.BlockHash != nil {
+			if *crit.BlockHash != after.Hash {
+				return nil, ethapi.ErrInvalidCursor
+			}
+		} else {
+			header, err := api.backend.HeaderByNumber(ctx, rpc.BlockNumber(after.Number))
+			if err != nil {
+				return nil, err
+			}
+			if header == nil || header.Hash() != after.Hash {
+				return nil, ethapi.ErrCursorReorged
+			}
+			crit.FromBlock = new(big.Int).SetUint64(after.Number)
+		}
+	}
+	var logs []*types.Log
+	err = api.newFilter(crit).Stream(ctx, func(found []*types.Log) error {
+		for _, l := range found {
+			if after != nil && l.BlockNumber == after.Number && l.Index <= after.Index {
+				continue
+			}
+			if len(logs) == limit {
+				return errPageFull
+			}
+			logs = append(logs, l)
+		}
+		return nil
+	})
+	page := &LogsPage{Logs: returnLogs(logs)}
+	if err == errPageFull {
+		last := logs[len(logs)-1]
+		next := (&ethapi.PageCursor{Number: last.BlockNumber, Hash: last.BlockHash, Index: last.Index}).Encode()
+		page.Cursor, err = &next, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// newFilter creates the filter of a log query, subject to the range limit.
+func (api *FilterAPI) newFilter(crit FilterCriteria) *Filter {
 	var filter *Filter
 	if crit.BlockHash != nil {
 		// Block filter requested, construct a single-shot filter
@@ -334,12 +576,20 @@ func (api *FilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([]*type
 		// Construct the range filter
 		filter = NewRangeFilter(api.backend, begin, end, crit.Addresses, crit.Topics)
 	}
-	// Run the filter and return all the logs
-	logs, err := filter.Logs(ctx)
-	if err != nil {
-		return nil, err
-	}
-	return returnLogs(logs), err
+	filter.maxRange = api.limits.MaxRange
+	return filter
+}
+
+// runFilter runs a log query, passing the logs found to fn in order. The query
+// is aborted as soon as it finds more logs than the result limit allows.
+func (api *FilterAPI) runFilter(ctx context.Context, filter *Filter, fn func([]*types.Log) error) error {
+	var found int
+	return filter.Stream(ctx, func(logs []*types.Log) error {
+		if found += len(logs); api.limits.MaxResults > 0 && found > api.limits.MaxResults {
+			return &logLimitError{fmt.Sprintf("query returned more than %d results", api.limits.MaxResults)}
+		}
+		return fn(logs)
+	})
 }
 
 // UninstallFilter removes the filter with the given filter id.
@@ -368,25 +618,11 @@ func (api *FilterAPI) GetFilterLogs(ctx context.Context, id rpc.ID) ([]*types.Lo
 		return nil, fmt.Errorf("filter not found")
 	}
 
-	var filter *Filter
-	if f.crit.BlockHash != nil {
-		// Block filter requested, construct a single-shot filter
-		filter = NewBlockFilter(api.backend, *f.crit.BlockHash, f.crit.Addresses, f.crit.Topics)
-	} else {
-		// Convert the RPC block numbers into internal representations
-		begin := rpc.LatestBlockNumber.Int64()
-		if f.crit.FromBlock != nil {
-			begin = f.crit.FromBlock.Int64()
-		}
-		end := rpc.LatestBlockNumber.Int64()
-		if f.crit.ToBlock != nil {
-			end = f.crit.ToBlock.Int64()
-		}
-		// Construct the range filter
-		filter = NewRangeFilter(api.backend, begin, end, f.crit.Addresses, f.crit.Topics)
-	}
-	// Run the filter and return all the logs
-	logs, err := filter.Logs(ctx)
+	var logs []*types.Log
+	err := api.runFilter(ctx, api.newFilter(f.crit), func(found []*types.Log) error {
+		logs = append(logs, found...)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -19,11 +19,13 @@ package filters
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/bloombits"
+	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
@@ -43,8 +45,11 @@ type Backend interface {
 	SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription
 	SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription
 	SubscribePendingLogsEvent(ch chan<- []*types.Log) event.Subscription
+	SubscribeReorgEvent(ch chan<- core.ReorgEvent) event.Subscription
+	SubscribeReorgRefusedEvent(ch chan<- core.ReorgRefusedEvent) event.Subscription
 
 	BloomStatus() (uint64, uint64)
+	LogIndexStatus() (uint64, uint64)
 	ServiceFilter(ctx context.Context, session *bloombits.MatcherSession)
 }
 
@@ -59,7 +64,10 @@ type Filter struct {
 	block      common.Hash // Block hash if filtering a single block
 	begin, end int64       // Range interval if filtering multiple blocks
 
-	matcher *bloombits.Matcher
+	matcher  *bloombits.Matcher
+	maxRange uint64 // Maximum number of blocks in the range, zero if unlimited
+
+	stream func([]*types.Log) error // Receiver of the matching logs while streaming
 }
 
 // NewRangeFilter creates a new filter which uses a bloom filter on blocks to
@@ -152,18 +160,53 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 	if f.end == rpc.LatestBlockNumber.Int64() || f.end == rpc.PendingBlockNumber.Int64() {
 		end = head
 	}
-	// Gather all indexed logs, and finish with non indexed ones
+	// Resolve the safe and finalized tags into concrete block numbers
+	if f.begin == rpc.SafeBlockNumber.Int64() || f.begin == rpc.FinalizedBlockNumber.Int64() {
+		number, err := f.resolveTag(ctx, rpc.BlockNumber(f.begin))
+		if err != nil {
+			return nil, err
+		}
+		f.begin = int64(number)
+	}
+	if f.end == rpc.SafeBlockNumber.Int64() || f.end == rpc.FinalizedBlockNumber.Int64() {
+		number, err := f.resolveTag(ctx, rpc.BlockNumber(f.end))
+		if err != nil {
+			return nil, err
+		}
+		end = number
+	}
+	if f.maxRange > 0 && end >= uint64(f.begin) && end-uint64(f.begin) >= f.maxRange {
+		return nil, &logLimitError{fmt.Sprintf("query spans %d blocks, more than the maximum of %d", end-uint64(f.begin)+1, f.maxRange)}
+	}
+	// Gather all indexed logs, and finish with non indexed ones. The log index
+	// is preferred over the bloom bits, but only helps if there are criteria.
+	// It lacks the blocks pruned before being indexed, so ranges reaching into
+	// the pruned history are left to the bloom bits to report them.
 	var (
 		logs           []*types.Log
 		err            error
 		size, sections = f.backend.BloomStatus()
 	)
-	if indexed := sections * size; indexed > uint64(f.begin) {
+	if logSize, logSections := f.backend.LogIndexStatus(); f.hasCriteria() && logSections*logSize > uint64(f.begin) && !f.historyPruned() {
+		var found []*types.Log
+		if indexed := logSections * logSize; indexed > end {
+			found, err = f.logIndexLogs(ctx, end)
+		} else {
+			found, err = f.logIndexLogs(ctx, indexed-1)
+		}
+		logs = append(logs, found...)
+		if err != nil {
+			return logs, err
+		}
+	}
+	if indexed := sections * size; indexed > uint64(f.begin) && uint64(f.begin) <= end {
+		var found []*types.Log
 		if indexed > end {
-			logs, err = f.indexedLogs(ctx, end)
+			found, err = f.indexedLogs(ctx, end)
 		} else {
-			logs, err = f.indexedLogs(ctx, indexed-1)
+			found, err = f.indexedLogs(ctx, indexed-1)
 		}
+		logs = append(logs, found...)
 		if err != nil {
 			return logs, err
 		}
@@ -180,6 +223,43 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 	return logs, err
 }
 
+// Stream searches the blockchain for matching log entries like Logs does, but
+// passes them to fn in order as they are found instead of collecting them.
+func (f *Filter) Stream(ctx context.Context, fn func([]*types.Log) error) error {
+	f.stream = fn
+	defer func() { f.stream = nil }()
+
+	logs, err := f.Logs(ctx)
+	if err == nil && len(logs) > 0 {
+		err = fn(logs)
+	}
+	return err
+}
+
+// collect appends the logs found to the ones gathered so far, or passes them on
+// if the filter is streaming.
+func (f *Filter) collect(logs []*types.Log, found []*types.Log) ([]*types.Log, error) {
+	if f.stream == nil {
+		return append(logs, found...), nil
+	}
+	if len(found) == 0 {
+		return logs, nil
+	}
+	return logs, f.stream(found)
+}
+
+// resolveTag converts the safe or finalized block tag into a block number.
+func (f *Filter) resolveTag(ctx context.Context, tag rpc.BlockNumber) (uint64, error) {
+	header, err := f.backend.HeaderByNumber(ctx, tag)
+	if err != nil {
+		return 0, err
+	}
+	if header == nil {
+		return 0, errors.New("unknown block")
+	}
+	return header.Number.Uint64(), nil
+}
+
 // indexedLogs returns the logs matching the filter criteria based on the bloom
 // bits indexed available locally or via the network.
 func (f *Filter) indexedLogs(ctx context.Context, end uint64) ([]*types.Log, error) {
@@ -219,7 +299,9 @@ func (f *Filter) indexedLogs(ctx context.Context, end uint64) ([]*types.Log, err
 			if err != nil {
 				return logs, err
 			}
-			logs = append(logs, found...)
+			if logs, err = f.collect(logs, found); err != nil {
+				return logs, err
+			}
 
 		case <-ctx.Done():
 			return logs, ctx.Err()
@@ -227,6 +309,190 @@ func (f *Filter) indexedLogs(ctx context.Context, end uint64) ([]*types.Log, err
 	}
 }
 
+// hasCriteria reports whether the filter restricts the addresses or topics of
+// the logs, i.e. whether the log index can be used to look them up.
+func (f *Filter) hasCriteria() bool {
+	if len(f.addresses) > 0 {
+		return true
+	}
+	for _, topics := range f.topics {
+		if len(topics) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// logIndexLogs returns the logs matching the filter criteria based on the log
+// index, which points to the matching logs exactly. The index is looked up a
+// section at a time to keep the memory use of wide ranges in check.
+func (f *Filter) logIndexLogs(ctx context.Context, end uint64) ([]*types.Log, error) {
+	var (
+		logs    []*types.Log
+		size, _ = f.backend.LogIndexStatus()
+	)
+	for f.begin <= int64(end) {
+		last := (uint64(f.begin)/size+1)*size - 1
+		if last > end {
+			last = end
+		}
+		for _, entry := range f.lookupLogIndex(uint64(f.begin), last) {
+			if err := ctx.Err(); err != nil {
+				return logs, err
+			}
+			f.begin = int64(entry.Number) + 1
+
+			header, err := f.backend.HeaderByNumber(ctx, rpc.BlockNumber(entry.Number))
+			if err != nil {
+				return logs, err
+			}
+			if header == nil {
+				return logs, fmt.Errorf("indexed block #%d not found", entry.Number)
+			}
+			found, err := f.indexedMatches(ctx, header, entry.Positions)
+			if err != nil {
+				return logs, err
+			}
+			if logs, err = f.collect(logs, found); err != nil {
+				return logs, err
+			}
+		}
+		f.begin = int64(last) + 1
+	}
+	return logs, nil
+}
+
+// historyPruned reports whether the start of the filter range falls below the
+// history tail, where the bodies and receipts have been pruned.
+func (f *Filter) historyPruned() bool {
+	tail, err := f.db.Tail()
+	return err == nil && uint64(f.begin) < tail
+}
+
+// lookupLogIndex returns the positions of the logs matching the filter criteria
+// within the given block range from the log index.
+func (f *Filter) lookupLogIndex(from, to uint64) []rawdb.LogIndexEntry {
+	var clauses [][]rawdb.LogIndexEntry
+	if len(f.addresses) > 0 {
+		var clause []rawdb.LogIndexEntry
+		for _, address := range f.addresses {
+			clause = unionLogIndex(clause, rawdb.ReadLogAddressIndex(f.db, address, from, to))
+		}
+		clauses = append(clauses, clause)
+	}
+	for i, topics := range f.topics {
+		if len(topics) == 0 {
+			continue
+		}
+		var clause []rawdb.LogIndexEntry
+		for _, topic := range topics {
+			clause = unionLogIndex(clause, rawdb.ReadLogTopicIndex(f.db, i, topic, from, to))
+		}
+		clauses = append(clauses, clause)
+	}
+	entries := clauses[0]
+	for _, clause := range clauses[1:] {
+		entries = intersectLogIndex(entries, clause)
+	}
+	return entries
+}
+
+// indexedMatches returns the logs at the given positions within a block which
+// match the filter criteria. The criteria are checked again, since the index
+// may be stale for blocks reorged since they were indexed.
+func (f *Filter) indexedMatches(ctx context.Context, header *types.Header, positions []uint) ([]*types.Log, error) {
+	logsList, err := f.backend.GetLogs(ctx, header.Hash())
+	if err != nil {
+		return nil, err
+	}
+	var (
+		unfiltered []*types.Log
+		candidates []*types.Log
+	)
+	for _, logs := range logsList {
+		unfiltered = append(unfiltered, logs...)
+	}
+	for _, position := range positions {
+		if position < uint(len(unfiltered)) {
+			candidates = append(candidates, unfiltered[position])
+		}
+	}
+	return filterLogs(candidates, nil, nil, f.addresses, f.topics), nil
+}
+
+// unionLogIndex merges two log index entry lists, both in ascending block order.
+func unionLogIndex(a, b []rawdb.LogIndexEntry) []rawdb.LogIndexEntry {
+	if len(a) == 0 {
+		return b
+	}
+	merged := make([]rawdb.LogIndexEntry, 0, len(a)+len(b))
+	for len(a) > 0 || len(b) > 0 {
+		switch {
+		case len(b) == 0 || len(a) > 0 && a[0].Number < b[0].Number:
+			merged, a = append(merged, a[0]), a[1:]
+		case len(a) == 0 || b[0].Number < a[0].Number:
+			merged, b = append(merged, b[0]), b[1:]
+		default:
+			positions := unionPositions(a[0].Positions, b[0].Positions)
+			merged = append(merged, rawdb.LogIndexEntry{Number: a[0].Number, Positions: positions})
+			a, b = a[1:], b[1:]
+		}
+	}
+	return merged
+}
+
+// intersectLogIndex returns the logs present in both log index entry lists, in
+// ascending block order.
+func intersectLogIndex(a, b []rawdb.LogIndexEntry) []rawdb.LogIndexEntry {
+	var shared []rawdb.LogIndexEntry
+	for len(a) > 0 && len(b) > 0 {
+		switch {
+		case a[0].Number < b[0].Number:
+			a = a[1:]
+		case b[0].Number < a[0].Number:
+			b = b[1:]
+		default:
+			if positions := intersectPositions(a[0].Positions, b[0].Positions); len(positions) > 0 {
+				shared = append(shared, rawdb.LogIndexEntry{Number: a[0].Number, Positions: positions})
+			}
+			a, b = a[1:], b[1:]
+		}
+	}
+	return shared
+}
+
+// unionPositions merges two ascending position lists.
+func unionPositions(a, b []uint) []uint {
+	merged := make([]uint, 0, len(a)+len(b))
+	for len(a) > 0 || len(b) > 0 {
+		switch {
+		case len(b) == 0 || len(a) > 0 && a[0] < b[0]:
+			merged, a = append(merged, a[0]), a[1:]
+		case len(a) == 0 || b[0] < a[0]:
+			merged, b = append(merged, b[0]), b[1:]
+		default:
+			merged, a, b = append(merged, a[0]), a[1:], b[1:]
+		}
+	}
+	return merged
+}
+
+// intersectPositions returns the positions present in both ascending lists.
+func intersectPositions(a, b []uint) []uint {
+	var shared []uint
+	for len(a) > 0 && len(b) > 0 {
+		switch {
+		case a[0] < b[0]:
+			a = a[1:]
+		case b[0] < a[0]:
+			b = b[1:]
+		default:
+			shared, a, b = append(shared, a[0]), a[1:], b[1:]
+		}
+	}
+	return shared
+}
+
 // unindexedLogs returns the logs matching the filter criteria based on raw block
 // iteration and bloom matching.
 func (f *Filter) unindexedLogs(ctx context.Context, end uint64) ([]*types.Log, error) {
@@ -241,7 +507,9 @@ func (f *Filter) unindexedLogs(ctx context.Context, end uint64) ([]*types.Log, e
 		if err != nil {
 			return logs, err
 		}
-		logs = append(logs, found...)
+		if logs, err = f.collect(logs, found); err != nil {
+			return logs, err
+		}
 	}
 	return logs, nil
 }
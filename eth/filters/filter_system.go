@@ -52,6 +52,10 @@ const (
 	PendingTransactionsSubscription
 	// BlocksSubscription queries hashes for blocks that are imported
 	BlocksSubscription
+	// ReorgsSubscription queries for canonical chain reorganisations
+	ReorgsSubscription
+	// RefusedReorgsSubscription queries for reorganisations refused by the chain
+	RefusedReorgsSubscription
 	// LastSubscription keeps track of the last index
 	LastIndexSubscription
 )
@@ -66,6 +70,10 @@ const (
 	logsChanSize = 10
 	// chainEvChanSize is the size of channel listening to ChainEvent.
 	chainEvChanSize = 10
+	// reorgEvChanSize is the size of channel listening to ReorgEvent.
+	reorgEvChanSize = 10
+	// refusedEvChanSize is the size of channel listening to ReorgRefusedEvent.
+	refusedEvChanSize = 10
 )
 
 type subscription struct {
@@ -76,6 +84,8 @@ type subscription struct {
 	logs      chan []*types.Log
 	hashes    chan []common.Hash
 	headers   chan *types.Header
+	reorgs    chan core.ReorgEvent
+	refused   chan core.ReorgRefusedEvent
 	installed chan struct{} // closed when the filter is installed
 	err       chan error    // closed when the filter is uninstalled
 }
@@ -93,15 +103,19 @@ type EventSystem struct {
 	rmLogsSub      event.Subscription // Subscription for removed log event
 	pendingLogsSub event.Subscription // Subscription for pending log event
 	chainSub       event.Subscription // Subscription for new chain event
+	reorgSub       event.Subscription // Subscription for chain reorg event
+	refusedSub     event.Subscription // Subscription for refused chain reorg event
 
 	// Channels
-	install       chan *subscription         // install filter for event notification
-	uninstall     chan *subscription         // remove filter for event notification
-	txsCh         chan core.NewTxsEvent      // Channel to receive new transactions event
-	logsCh        chan []*types.Log          // Channel to receive new log event
-	pendingLogsCh chan []*types.Log          // Channel to receive new log event
-	rmLogsCh      chan core.RemovedLogsEvent // Channel to receive removed log event
-	chainCh       chan core.ChainEvent       // Channel to receive new chain event
+	install       chan *subscription          // install filter for event notification
+	uninstall     chan *subscription          // remove filter for event notification
+	txsCh         chan core.NewTxsEvent       // Channel to receive new transactions event
+	logsCh        chan []*types.Log           // Channel to receive new log event
+	pendingLogsCh chan []*types.Log           // Channel to receive new log event
+	rmLogsCh      chan core.RemovedLogsEvent  // Channel to receive removed log event
+	chainCh       chan core.ChainEvent        // Channel to receive new chain event
+	reorgCh       chan core.ReorgEvent        // Channel to receive chain reorg event
+	refusedCh     chan core.ReorgRefusedEvent // Channel to receive refused chain reorg event
 }
 
 // NewEventSystem creates a new manager that listens for event on the given mux,
@@ -121,6 +135,8 @@ func NewEventSystem(backend Backend, lightMode bool) *EventSystem {
 		rmLogsCh:      make(chan core.RemovedLogsEvent, rmLogsChanSize),
 		pendingLogsCh: make(chan []*types.Log, logsChanSize),
 		chainCh:       make(chan core.ChainEvent, chainEvChanSize),
+		reorgCh:       make(chan core.ReorgEvent, reorgEvChanSize),
+		refusedCh:     make(chan core.ReorgRefusedEvent, refusedEvChanSize),
 	}
 
 	// Subscribe events
@@ -129,9 +145,11 @@ func NewEventSystem(backend Backend, lightMode bool) *EventSystem {
 	m.rmLogsSub = m.backend.SubscribeRemovedLogsEvent(m.rmLogsCh)
 	m.chainSub = m.backend.SubscribeChainEvent(m.chainCh)
 	m.pendingLogsSub = m.backend.SubscribePendingLogsEvent(m.pendingLogsCh)
+	m.reorgSub = m.backend.SubscribeReorgEvent(m.reorgCh)
+	m.refusedSub = m.backend.SubscribeReorgRefusedEvent(m.refusedCh)
 
 	// Make sure none of the subscriptions are empty
-	if m.txsSub == nil || m.logsSub == nil || m.rmLogsSub == nil || m.chainSub == nil || m.pendingLogsSub == nil {
+	if m.txsSub == nil || m.logsSub == nil || m.rmLogsSub == nil || m.chainSub == nil || m.pendingLogsSub == nil || m.reorgSub == nil || m.refusedSub == nil {
 		log.Crit("Subscribe for event system failed")
 	}
 
@@ -167,6 +185,8 @@ func (sub *Subscription) Unsubscribe() {
 			case <-sub.f.logs:
 			case <-sub.f.hashes:
 			case <-sub.f.headers:
+			case <-sub.f.reorgs:
+			case <-sub.f.refused:
 			}
 		}
 
@@ -234,6 +254,8 @@ func (es *EventSystem) subscribeMinedPendingLogs(crit ethereum.FilterQuery, logs
 		logs:      logs,
 		hashes:    make(chan []common.Hash),
 		headers:   make(chan *types.Header),
+		reorgs:    make(chan core.ReorgEvent),
+		refused:   make(chan core.ReorgRefusedEvent),
 		installed: make(chan struct{}),
 		err:       make(chan error),
 	}
@@ -251,6 +273,8 @@ func (es *EventSystem) subscribeLogs(crit ethereum.FilterQuery, logs chan []*typ
 		logs:      logs,
 		hashes:    make(chan []common.Hash),
 		headers:   make(chan *types.Header),
+		reorgs:    make(chan core.ReorgEvent),
+		refused:   make(chan core.ReorgRefusedEvent),
 		installed: make(chan struct{}),
 		err:       make(chan error),
 	}
@@ -268,6 +292,8 @@ func (es *EventSystem) subscribePendingLogs(crit ethereum.FilterQuery, logs chan
 		logs:      logs,
 		hashes:    make(chan []common.Hash),
 		headers:   make(chan *types.Header),
+		reorgs:    make(chan core.ReorgEvent),
+		refused:   make(chan core.ReorgRefusedEvent),
 		installed: make(chan struct{}),
 		err:       make(chan error),
 	}
@@ -284,6 +310,8 @@ func (es *EventSystem) SubscribeNewHeads(headers chan *types.Header) *Subscripti
 		logs:      make(chan []*types.Log),
 		hashes:    make(chan []common.Hash),
 		headers:   headers,
+		reorgs:    make(chan core.ReorgEvent),
+		refused:   make(chan core.ReorgRefusedEvent),
 		installed: make(chan struct{}),
 		err:       make(chan error),
 	}
@@ -300,6 +328,44 @@ func (es *EventSystem) SubscribePendingTxs(hashes chan []common.Hash) *Subscript
 		logs:      make(chan []*types.Log),
 		hashes:    hashes,
 		headers:   make(chan *types.Header),
+		reorgs:    make(chan core.ReorgEvent),
+		refused:   make(chan core.ReorgRefusedEvent),
+		installed: make(chan struct{}),
+		err:       make(chan error),
+	}
+	return es.subscribe(sub)
+}
+
+// SubscribeReorgs creates a subscription that writes a notification for every
+// reorganisation of the canonical chain.
+func (es *EventSystem) SubscribeReorgs(reorgs chan core.ReorgEvent) *Subscription {
+	sub := &subscription{
+		id:        rpc.NewID(),
+		typ:       ReorgsSubscription,
+		created:   time.Now(),
+		logs:      make(chan []*types.Log),
+		hashes:    make(chan []common.Hash),
+		headers:   make(chan *types.Header),
+		reorgs:    reorgs,
+		refused:   make(chan core.ReorgRefusedEvent),
+		installed: make(chan struct{}),
+		err:       make(chan error),
+	}
+	return es.subscribe(sub)
+}
+
+// SubscribeRefusedReorgs creates a subscription that writes a notification for
+// every reorganisation the chain refused to perform.
+func (es *EventSystem) SubscribeRefusedReorgs(refused chan core.ReorgRefusedEvent) *Subscription {
+	sub := &subscription{
+		id:        rpc.NewID(),
+		typ:       RefusedReorgsSubscription,
+		created:   time.Now(),
+		logs:      make(chan []*types.Log),
+		hashes:    make(chan []common.Hash),
+		headers:   make(chan *types.Header),
+		reorgs:    make(chan core.ReorgEvent),
+		refused:   refused,
 		installed: make(chan struct{}),
 		err:       make(chan error),
 	}
@@ -366,6 +432,18 @@ func (es *EventSystem) handleChainEvent(filters filterIndex, ev core.ChainEvent)
 	}
 }
 
+func (es *EventSystem) handleReorgEvent(filters filterIndex, ev core.ReorgEvent) {
+	for _, f := range filters[ReorgsSubscription] {
+		f.reorgs <- ev
+	}
+}
+
+func (es *EventSystem) handleRefusedReorgEvent(filters filterIndex, ev core.ReorgRefusedEvent) {
+	for _, f := range filters[RefusedReorgsSubscription] {
+		f.refused <- ev
+	}
+}
+
 func (es *EventSystem) lightFilterNewHead(newHeader *types.Header, callBack func(*types.Header, bool)) {
 	oldh := es.lastHead
 	es.lastHead = newHeader
@@ -448,6 +526,8 @@ func (es *EventSystem) eventLoop() {
 		es.rmLogsSub.Unsubscribe()
 		es.pendingLogsSub.Unsubscribe()
 		es.chainSub.Unsubscribe()
+		es.reorgSub.Unsubscribe()
+		es.refusedSub.Unsubscribe()
 	}()
 
 	index := make(filterIndex)
@@ -467,6 +547,10 @@ func (es *EventSystem) eventLoop() {
 			es.handlePendingLogs(index, ev)
 		case ev := <-es.chainCh:
 			es.handleChainEvent(index, ev)
+		case ev := <-es.reorgCh:
+			es.handleReorgEvent(index, ev)
+		case ev := <-es.refusedCh:
+			es.handleRefusedReorgEvent(index, ev)
 
 		case f := <-es.install:
 			if f.typ == MinedAndPendingLogsSubscription {
@@ -497,6 +581,10 @@ func (es *EventSystem) eventLoop() {
 			return
 		case <-es.chainSub.Err():
 			return
+		case <-es.reorgSub.Err():
+			return
+		case <-es.refusedSub.Err():
+			return
 		}
 	}
 }
@@ -28,6 +28,8 @@ import (
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/bitutil"
+	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/bloombits"
@@ -35,6 +37,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rpc"
 )
@@ -45,18 +48,36 @@ var (
 
 type testBackend struct {
 	db              ethdb.Database
+	sectionSize     uint64 // Blocks per bloom and log index section, params.BloomBitsBlocks if unset
 	sections        uint64
+	logSections     uint64
+	tail            uint64 // First block with retained receipts, simulating history pruning
 	txFeed          event.Feed
 	logsFeed        event.Feed
 	rmLogsFeed      event.Feed
 	pendingLogsFeed event.Feed
 	chainFeed       event.Feed
+	reorgFeed       event.Feed
+	refusedFeed     event.Feed
 }
 
 func (b *testBackend) ChainDb() ethdb.Database {
+	if b.tail > 0 {
+		return &prunedDatabase{Database: b.db, tail: b.tail}
+	}
 	return b.db
 }
 
+// prunedDatabase is a database reporting the given history tail.
+type prunedDatabase struct {
+	ethdb.Database
+	tail uint64
+}
+
+func (db *prunedDatabase) Tail() (uint64, error) {
+	return db.tail, nil
+}
+
 func (b *testBackend) HeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.Header, error) {
 	var (
 		hash common.Hash
@@ -96,6 +117,9 @@ func (b *testBackend) GetLogs(ctx context.Context, hash common.Hash) ([][]*types
 	if number == nil {
 		return nil, nil
 	}
+	if *number < b.tail {
+		return nil, core.ErrHistoryPruned
+	}
 	receipts := rawdb.ReadReceipts(b.db, hash, *number, params.TestChainConfig)
 
 	logs := make([][]*types.Log, len(receipts))
@@ -129,8 +153,27 @@ func (b *testBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subsc
 	return b.chainFeed.Subscribe(ch)
 }
 
+func (b *testBackend) SubscribeReorgEvent(ch chan<- core.ReorgEvent) event.Subscription {
+	return b.reorgFeed.Subscribe(ch)
+}
+
+func (b *testBackend) SubscribeReorgRefusedEvent(ch chan<- core.ReorgRefusedEvent) event.Subscription {
+	return b.refusedFeed.Subscribe(ch)
+}
+
+func (b *testBackend) size() uint64 {
+	if b.sectionSize == 0 {
+		return params.BloomBitsBlocks
+	}
+	return b.sectionSize
+}
+
 func (b *testBackend) BloomStatus() (uint64, uint64) {
-	return params.BloomBitsBlocks, b.sections
+	return b.size(), b.sections
+}
+
+func (b *testBackend) LogIndexStatus() (uint64, uint64) {
+	return b.size(), b.logSections
 }
 
 func (b *testBackend) ServiceFilter(ctx context.Context, session *bloombits.MatcherSession) {
@@ -150,8 +193,10 @@ func (b *testBackend) ServiceFilter(ctx context.Context, session *bloombits.Matc
 				task.Bitsets = make([][]byte, len(task.Sections))
 				for i, section := range task.Sections {
 					if rand.Int()%4 != 0 { // Handle occasional missing deliveries
-						head := rawdb.ReadCanonicalHash(b.db, (section+1)*params.BloomBitsBlocks-1)
-						task.Bitsets[i], _ = rawdb.ReadBloomBits(b.db, task.Bit, section, head)
+						head := rawdb.ReadCanonicalHash(b.db, (section+1)*b.size()-1)
+						if comp, err := rawdb.ReadBloomBits(b.db, task.Bit, section, head); err == nil {
+							task.Bitsets[i], _ = bitutil.DecompressBytes(comp, int(b.size()/8))
+						}
 					}
 				}
 				request <- task
@@ -171,7 +216,7 @@ func TestBlockSubscription(t *testing.T) {
 	var (
 		db          = rawdb.NewMemoryDatabase()
 		backend     = &testBackend{db: db}
-		api         = NewFilterAPI(backend, false, deadline)
+		api         = NewFilterAPI(backend, false, deadline, LogLimits{})
 		genesis     = (&core.Genesis{BaseFee: big.NewInt(params.InitialBaseFee)}).MustCommit(db)
 		chain, _    = core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), db, 10, func(i int, gen *core.BlockGen) {})
 		chainEvents = []core.ChainEvent{}
@@ -223,7 +268,7 @@ func TestPendingTxFilter(t *testing.T) {
 	var (
 		db      = rawdb.NewMemoryDatabase()
 		backend = &testBackend{db: db}
-		api     = NewFilterAPI(backend, false, deadline)
+		api     = NewFilterAPI(backend, false, deadline, LogLimits{})
 
 		transactions = []*types.Transaction{
 			types.NewTransaction(0, common.HexToAddress("0xb794f5ea0ba39494ce83a213fffba74279579268"), new(big.Int), 0, new(big.Int), nil),
@@ -278,7 +323,7 @@ func TestLogFilterCreation(t *testing.T) {
 	var (
 		db      = rawdb.NewMemoryDatabase()
 		backend = &testBackend{db: db}
-		api     = NewFilterAPI(backend, false, deadline)
+		api     = NewFilterAPI(backend, false, deadline, LogLimits{})
 
 		testCases = []struct {
 			crit    FilterCriteria
@@ -325,7 +370,7 @@ func TestInvalidLogFilterCreation(t *testing.T) {
 	var (
 		db      = rawdb.NewMemoryDatabase()
 		backend = &testBackend{db: db}
-		api     = NewFilterAPI(backend, false, deadline)
+		api     = NewFilterAPI(backend, false, deadline, LogLimits{})
 	)
 
 	// different situations where log filter creation should fail.
@@ -347,7 +392,7 @@ func TestInvalidGetLogsRequest(t *testing.T) {
 	var (
 		db        = rawdb.NewMemoryDatabase()
 		backend   = &testBackend{db: db}
-		api       = NewFilterAPI(backend, false, deadline)
+		api       = NewFilterAPI(backend, false, deadline, LogLimits{})
 		blockHash = common.HexToHash("0x1111111111111111111111111111111111111111111111111111111111111111")
 	)
 
@@ -365,6 +410,135 @@ func TestInvalidGetLogsRequest(t *testing.T) {
 	}
 }
 
+// newLogsTestBackend creates a backend with a chain of blocks each containing
+// three logs of the given address.
+func newLogsTestBackend(blocks int, addr common.Address) *testBackend {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		gspec   = &core.Genesis{Config: params.TestChainConfig, BaseFee: big.NewInt(params.InitialBaseFee)}
+		genesis = gspec.MustCommit(db)
+	)
+	chain, receipts := core.GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, blocks, func(i int, gen *core.BlockGen) {
+		receipt := types.NewReceipt(nil, false, 0)
+		receipt.Logs = []*types.Log{{Address: addr}, {Address: addr}, {Address: addr}}
+		receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+		gen.AddUncheckedReceipt(receipt)
+		gen.AddUncheckedTx(types.NewTransaction(uint64(i), common.Address{}, new(big.Int), 0, gen.BaseFee(), nil))
+	})
+	for i, block := range chain {
+		rawdb.WriteBlock(db, block)
+		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		rawdb.WriteHeadBlockHash(db, block.Hash())
+		rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), receipts[i])
+	}
+	return &testBackend{db: db}
+}
+
+// Tests that log queries exceeding the result or range limits are refused.
+func TestGetLogsLimits(t *testing.T) {
+	var (
+		addr    = common.HexToAddress("0x1234")
+		backend = newLogsTestBackend(10, addr)
+		crit    = FilterCriteria{FromBlock: big.NewInt(1), ToBlock: big.NewInt(10), Addresses: []common.Address{addr}}
+	)
+	tests := []struct {
+		limits LogLimits
+		crit   FilterCriteria
+		logs   int
+		fail   bool
+	}{
+		{LogLimits{}, crit, 30, false},
+		{LogLimits{MaxResults: 30, MaxRange: 10}, crit, 30, false},
+		{LogLimits{MaxResults: 29}, crit, 0, true},
+		{LogLimits{MaxRange: 9}, crit, 0, true},
+		{LogLimits{MaxRange: 9}, FilterCriteria{FromBlock: big.NewInt(2), Addresses: []common.Address{addr}}, 27, false},
+		{LogLimits{MaxRange: 1, MaxResults: 3}, FilterCriteria{BlockHash: new(common.Hash), Addresses: []common.Address{addr}}, 3, false},
+	}
+	for i, tt := range tests {
+		if tt.crit.BlockHash != nil {
+			*tt.crit.BlockHash = rawdb.ReadCanonicalHash(backend.db, 3)
+		}
+		api := NewFilterAPI(backend, false, deadline, tt.limits)
+		logs, err := api.GetLogs(context.Background(), tt.crit)
+		if tt.fail {
+			if err == nil {
+				t.Errorf("test %d: query succeeded beyond limits", i)
+			} else if code := err.(rpc.Error).ErrorCode(); code != -32005 {
+				t.Errorf("test %d: error code mismatch: have %d, want -32005", i, code)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("test %d: query failed: %v", i, err)
+		} else if len(logs) != tt.logs {
+			t.Errorf("test %d: log count mismatch: have %d, want %d", i, len(logs), tt.logs)
+		}
+	}
+}
+
+// Tests that paginated log queries return all the logs in order, and that the
+// cursors are validated.
+func TestGetLogsPage(t *testing.T) {
+	var (
+		addr    = common.HexToAddress("0x1234")
+		backend = newLogsTestBackend(10, addr)
+		api     = NewFilterAPI(backend, false, deadline, LogLimits{MaxResults: 8})
+		crit    = FilterCriteria{FromBlock: big.NewInt(0), Addresses: []common.Address{addr}}
+
+		pageLimit = math.HexOrDecimal64(4)
+		tooLarge  = math.HexOrDecimal64(9)
+	)
+	if _, err := api.GetLogs(context.Background(), crit); err == nil {
+		t.Fatalf("unpaginated query succeeded beyond limits")
+	}
+	want, _ := NewFilterAPI(backend, false, deadline, LogLimits{}).GetLogs(context.Background(), crit)
+
+	var (
+		have   []*types.Log
+		cursor *string
+		pages  int
+	)
+	for {
+		page, err := api.GetLogsPage(context.Background(), crit, &pageLimit, cursor)
+		if err != nil {
+			t.Fatalf("page %d: query failed: %v", pages, err)
+		}
+		pages++
+		have = append(have, page.Logs...)
+		if page.Cursor == nil {
+			break
+		}
+		if len(page.Logs) != 4 {
+			t.Fatalf("page %d: log count mismatch: have %d, want 4", pages, len(page.Logs))
+		}
+		cursor = page.Cursor
+	}
+	if pages != 8 || len(have) != len(want) {
+		t.Fatalf("paginated result mismatch: have %d logs in %d pages, want %d logs in 8 pages", len(have), pages, len(want))
+	}
+	for i := range have {
+		if have[i].BlockNumber != want[i].BlockNumber || have[i].Index != want[i].Index {
+			t.Errorf("log %d mismatch: have %d/%d, want %d/%d", i, have[i].BlockNumber, have[i].Index, want[i].BlockNumber, want[i].Index)
+		}
+	}
+	// Pages are capped by the result limit, unless smaller
+	if page, err := api.GetLogsPage(context.Background(), crit, nil, nil); err != nil || len(page.Logs) != 8 {
+		t.Errorf("default page mismatch: %v", err)
+	}
+	if _, err := api.GetLogsPage(context.Background(), crit, &tooLarge, nil); err == nil {
+		t.Errorf("page beyond the result limit succeeded")
+	}
+	// Cursors must be valid and refer to the canonical chain
+	invalid := "invalid"
+	if _, err := api.GetLogsPage(context.Background(), crit, &pageLimit, &invalid); err != ethapi.ErrInvalidCursor {
+		t.Errorf("invalid cursor error mismatch: have %v, want %v", err, ethapi.ErrInvalidCursor)
+	}
+	reorged := (&ethapi.PageCursor{Number: 5, Hash: common.HexToHash("0x01"), Index: 1}).Encode()
+	if _, err := api.GetLogsPage(context.Background(), crit, &pageLimit, &reorged); err == nil {
+		t.Errorf("reorged cursor accepted")
+	}
+}
+
 // TestLogFilter tests whether log filters match the correct logs that are posted to the event feed.
 func TestLogFilter(t *testing.T) {
 	t.Parallel()
@@ -372,7 +546,7 @@ func TestLogFilter(t *testing.T) {
 	var (
 		db      = rawdb.NewMemoryDatabase()
 		backend = &testBackend{db: db}
-		api     = NewFilterAPI(backend, false, deadline)
+		api     = NewFilterAPI(backend, false, deadline, LogLimits{})
 
 		firstAddr      = common.HexToAddress("0x1111111111111111111111111111111111111111")
 		secondAddr     = common.HexToAddress("0x2222222222222222222222222222222222222222")
@@ -486,7 +660,7 @@ func TestPendingLogsSubscription(t *testing.T) {
 	var (
 		db      = rawdb.NewMemoryDatabase()
 		backend = &testBackend{db: db}
-		api     = NewFilterAPI(backend, false, deadline)
+		api     = NewFilterAPI(backend, false, deadline, LogLimits{})
 
 		firstAddr      = common.HexToAddress("0x1111111111111111111111111111111111111111")
 		secondAddr     = common.HexToAddress("0x2222222222222222222222222222222222222222")
@@ -670,7 +844,7 @@ func TestPendingTxFilterDeadlock(t *testing.T) {
 	var (
 		db      = rawdb.NewMemoryDatabase()
 		backend = &testBackend{db: db}
-		api     = NewFilterAPI(backend, false, timeout)
+		api     = NewFilterAPI(backend, false, timeout, LogLimits{})
 		done    = make(chan struct{})
 	)
 
@@ -18,12 +18,15 @@ package filters
 
 import (
 	"context"
+	"errors"
 	"math/big"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/bitutil"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/bloombits"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -207,6 +210,23 @@ func TestFilters(t *testing.T) {
 		t.Error("expected 2 log, got", len(logs))
 	}
 
+	var streamed []*types.Log
+	filter = NewRangeFilter(backend, 0, -1, []common.Address{addr}, [][]common.Hash{{hash1, hash2, hash3, hash4}})
+	if err := filter.Stream(context.Background(), func(found []*types.Log) error {
+		streamed = append(streamed, found...)
+		return nil
+	}); err != nil {
+		t.Fatal("failed to stream logs:", err)
+	}
+	if len(streamed) != 4 {
+		t.Error("expected 4 streamed log, got", len(streamed))
+	}
+	for i := 1; i < len(streamed); i++ {
+		if streamed[i].BlockNumber < streamed[i-1].BlockNumber {
+			t.Errorf("streamed log %d out of order", i)
+		}
+	}
+
 	failHash := common.BytesToHash([]byte("fail"))
 	filter = NewRangeFilter(backend, 0, -1, nil, [][]common.Hash{{failHash}})
 
@@ -230,3 +250,141 @@ func TestFilters(t *testing.T) {
 		t.Error("expected 0 log, got", len(logs))
 	}
 }
+
+// Tests that filtering with the log index finds the same logs as filtering with
+// the block blooms, and that stale index entries are ignored.
+func TestLogIndexFilters(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		backend = &testBackend{db: db, sectionSize: 8}
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr    = crypto.PubkeyToAddress(key.PublicKey)
+
+		addr1 = common.HexToAddress("0x1111")
+		addr2 = common.HexToAddress("0x2222")
+		addr3 = common.HexToAddress("0x3333")
+
+		hash1 = common.BytesToHash([]byte("topic1"))
+		hash2 = common.BytesToHash([]byte("topic2"))
+		hash3 = common.BytesToHash([]byte("topic3"))
+
+		gspec = core.Genesis{
+			Alloc:   core.GenesisAlloc{addr: {Balance: big.NewInt(1000000)}},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		genesis = gspec.ToBlock()
+	)
+	gspec.MustCommit(db)
+
+	blockLogs := map[int][][]*types.Log{
+		1:  {{{Address: addr1, Topics: []common.Hash{hash1, hash2}}, {Address: addr2, Topics: []common.Hash{hash1}}}},
+		3:  {{{Address: addr2, Topics: []common.Hash{hash2, hash1}}}, {{Address: addr1, Topics: []common.Hash{hash3}}}},
+		5:  {{{Address: addr1, Topics: []common.Hash{hash1}}}, {{Address: addr1, Topics: []common.Hash{hash2, hash3}}, {Address: addr2}}},
+		10: {{{Address: addr1, Topics: []common.Hash{hash1}}}},
+		20: {{{Address: addr2, Topics: []common.Hash{hash3}}}},
+	}
+	var nonce uint64
+	chain, receipts := core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), db, 24, func(i int, gen *core.BlockGen) {
+		for _, logs := range blockLogs[i+1] {
+			receipt := types.NewReceipt(nil, false, 0)
+			receipt.Logs = logs
+			receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+			gen.AddUncheckedReceipt(receipt)
+			gen.AddUncheckedTx(types.NewTransaction(nonce, common.HexToAddress("0x1"), big.NewInt(1), 1, gen.BaseFee(), nil))
+			nonce++
+		}
+	})
+	for i, block := range chain {
+		rawdb.WriteBlock(db, block)
+		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		rawdb.WriteHeadBlockHash(db, block.Hash())
+		rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), receipts[i])
+	}
+	if err := core.RebuildLogIndex(db, chain[len(chain)-1].NumberU64(), backend.size(), 0); err != nil {
+		t.Fatalf("failed to build log index: %v", err)
+	}
+	// Build the bloom bits of the first two sections
+	for section := uint64(0); section < 2; section++ {
+		gen, err := bloombits.NewGenerator(uint(backend.size()))
+		if err != nil {
+			t.Fatalf("failed to create bloom generator: %v", err)
+		}
+		for i := uint64(0); i < backend.size(); i++ {
+			header := rawdb.ReadHeader(db, rawdb.ReadCanonicalHash(db, section*backend.size()+i), section*backend.size()+i)
+			gen.AddBloom(uint(i), header.Bloom)
+		}
+		head := rawdb.ReadCanonicalHash(db, (section+1)*backend.size()-1)
+		for i := 0; i < types.BloomBitLength; i++ {
+			bits, err := gen.Bitset(uint(i))
+			if err != nil {
+				t.Fatalf("failed to retrieve bloom bits: %v", err)
+			}
+			rawdb.WriteBloomBits(db, uint(i), section, head, bitutil.CompressBytes(bits))
+		}
+	}
+	// Add a stale entry, pointing to a log of another address
+	rawdb.WriteLogAddressIndex(db, addr3, 1, []uint{0, 1})
+
+	tests := []struct {
+		addresses []common.Address
+		topics    [][]common.Hash
+		want      int
+	}{
+		{[]common.Address{addr1}, nil, 5},
+		{[]common.Address{addr1, addr2}, [][]common.Hash{{hash1}}, 4},
+		{nil, [][]common.Hash{{hash2}, {hash1}}, 1},
+		{[]common.Address{addr2}, [][]common.Hash{{}, {hash1}}, 1},
+		{nil, [][]common.Hash{{hash1, hash3}}, 6},
+		{[]common.Address{addr1}, [][]common.Hash{{hash1}, {hash2}}, 1},
+		{[]common.Address{addr3}, nil, 0},
+		{nil, [][]common.Hash{{hash3}, {hash3}}, 0},
+	}
+	// Filter with the log index covering all blocks, and with the log index
+	// covering fewer sections than the bloom bits, leaving the rest unindexed
+	configs := []struct {
+		sections, logSections uint64
+	}{
+		{0, 3},
+		{2, 1},
+	}
+	for i, tt := range tests {
+		backend.sections, backend.logSections = 0, 0
+		want, err := NewRangeFilter(backend, 0, -1, tt.addresses, tt.topics).Logs(context.Background())
+		if err != nil {
+			t.Fatalf("test %d: unindexed filtering failed: %v", i, err)
+		}
+		if len(want) != tt.want {
+			t.Fatalf("test %d: unindexed log count mismatch: have %d, want %d", i, len(want), tt.want)
+		}
+		for _, config := range configs {
+			backend.sections, backend.logSections = config.sections, config.logSections
+			have, err := NewRangeFilter(backend, 0, -1, tt.addresses, tt.topics).Logs(context.Background())
+			if err != nil {
+				t.Fatalf("test %d, sections %d/%d: indexed filtering failed: %v", i, config.sections, config.logSections, err)
+			}
+			if len(have) != tt.want {
+				t.Fatalf("test %d, sections %d/%d: log count mismatch: have %d, want %d", i, config.sections, config.logSections, len(have), tt.want)
+			}
+			for j := range have {
+				if have[j].BlockNumber != want[j].BlockNumber || have[j].Index != want[j].Index {
+					t.Errorf("test %d, sections %d/%d: log %d mismatch: have %d/%d, want %d/%d", i, config.sections, config.logSections, j, have[j].BlockNumber, have[j].Index, want[j].BlockNumber, want[j].Index)
+				}
+			}
+		}
+	}
+	// Prune the receipts of the first blocks before indexing, filtering the
+	// pruned range must fail whichever index is used
+	backend.tail = 4
+	for _, block := range chain[:backend.tail-1] {
+		rawdb.DeleteReceipts(db, block.Hash(), block.NumberU64())
+	}
+	if err := core.RebuildLogIndex(db, chain[len(chain)-1].NumberU64(), backend.size(), 0); err != nil {
+		t.Fatalf("failed to rebuild log index: %v", err)
+	}
+	for _, config := range append(configs, struct{ sections, logSections uint64 }{0, 0}) {
+		backend.sections, backend.logSections = config.sections, config.logSections
+		if _, err := NewRangeFilter(backend, 0, -1, []common.Address{addr1}, nil).Logs(context.Background()); !errors.Is(err, core.ErrHistoryPruned) {
+			t.Errorf("sections %d/%d: pruned filtering error mismatch: have %v, want %v", config.sections, config.logSections, err, core.ErrHistoryPruned)
+		}
+	}
+}
@@ -25,7 +25,8 @@ import (
 
 // enrEntry is the ENR entry which advertises `eth` protocol on the discovery.
 type enrEntry struct {
-	ForkID forkid.ID // Fork identifier per EIP-2124
+	ForkID      forkid.ID // Fork identifier per EIP-2124
+	HistoryTail uint64    `rlp:"optional"` // First block whose body and receipts are served, omitted if all are
 
 	// Ignore additional fields (for forward compatibility).
 	Rest []rlp.RawValue `rlp:"tail"`
@@ -37,7 +38,8 @@ func (e enrEntry) ENRKey() string {
 }
 
 // StartENRUpdater starts the `eth` ENR updater loop, which listens for chain
-// head events and updates the requested node record whenever a fork is passed.
+// head events and updates the requested node record whenever a fork is passed
+// or the history tail moves.
 func StartENRUpdater(chain *core.BlockChain, ln *enode.LocalNode) {
 	var newHead = make(chan core.ChainHeadEvent, 10)
 	sub := chain.SubscribeChainHeadEvent(newHead)
@@ -60,6 +62,7 @@ func StartENRUpdater(chain *core.BlockChain, ln *enode.LocalNode) {
 // currentENREntry constructs an `eth` ENR entry based on the current state of the chain.
 func currentENREntry(chain *core.BlockChain) *enrEntry {
 	return &enrEntry{
-		ForkID: forkid.NewID(chain.Config(), chain.Genesis().Hash(), chain.CurrentHeader().Number.Uint64()),
+		ForkID:      forkid.NewID(chain.Config(), chain.Genesis().Hash(), chain.CurrentHeader().Number.Uint64()),
+		HistoryTail: chain.HistoryTail(),
 	}
 }
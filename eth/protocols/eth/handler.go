@@ -127,22 +127,24 @@ func MakeProtocols(backend Backend, network uint64, dnsdisc enode.Iterator) []p2
 // NodeInfo represents a short summary of the `eth` sub-protocol metadata
 // known about the host peer.
 type NodeInfo struct {
-	Network    uint64              `json:"network"`    // Ethereum network ID (1=Frontier, 2=Morden, Ropsten=3, Rinkeby=4)
-	Difficulty *big.Int            `json:"difficulty"` // Total difficulty of the host's blockchain
-	Genesis    common.Hash         `json:"genesis"`    // SHA3 hash of the host's genesis block
-	Config     *params.ChainConfig `json:"config"`     // Chain configuration for the fork rules
-	Head       common.Hash         `json:"head"`       // Hex hash of the host's best owned block
+	Network     uint64              `json:"network"`     // Ethereum network ID (1=Frontier, 2=Morden, Ropsten=3, Rinkeby=4)
+	Difficulty  *big.Int            `json:"difficulty"`  // Total difficulty of the host's blockchain
+	Genesis     common.Hash         `json:"genesis"`     // SHA3 hash of the host's genesis block
+	Config      *params.ChainConfig `json:"config"`      // Chain configuration for the fork rules
+	Head        common.Hash         `json:"head"`        // Hex hash of the host's best owned block
+	HistoryTail uint64              `json:"historyTail"` // First block whose body and receipts are served
 }
 
 // nodeInfo retrieves some `eth` protocol metadata about the running host node.
 func nodeInfo(chain *core.BlockChain, network uint64) *NodeInfo {
 	head := chain.CurrentBlock()
 	return &NodeInfo{
-		Network:    network,
-		Difficulty: chain.GetTd(head.Hash(), head.NumberU64()),
-		Genesis:    chain.Genesis().Hash(),
-		Config:     chain.Config(),
-		Head:       head.Hash(),
+		Network:     network,
+		Difficulty:  chain.GetTd(head.Hash(), head.NumberU64()),
+		Genesis:     chain.Genesis().Hash(),
+		Config:      chain.Config(),
+		Head:        head.Hash(),
+		HistoryTail: chain.HistoryTail(),
 	}
 }
 
@@ -202,6 +202,12 @@ func serviceContiguousBlockHeaderQuery(chain *core.BlockChain, query *GetBlockHe
 	}
 }
 
+// handleGetBlockBodies66 serves a block body query. The replies of the `eth`
+// protocol cannot carry errors, and failing the handler would disconnect the
+// requesting peer, so bodies dropped by history pruning are left out of the
+// reply like any other missing data. The history tail is instead advertised in
+// the `eth` entry of the node record, which downloaders use to avoid asking for
+// pruned history in the first place.
 func handleGetBlockBodies66(backend Backend, msg Decoder, peer *Peer) error {
 	// Decode the block body retrieval message
 	var query GetBlockBodiesPacket66
@@ -270,6 +276,9 @@ func ServiceGetNodeDataQuery(chain *core.BlockChain, query GetNodeDataPacket) []
 	return nodes
 }
 
+// handleGetReceipts66 serves a receipt query. As with bodies, receipts dropped
+// by history pruning are left out of the reply, the history tail advertised in
+// the node record signals which blocks are served.
 func handleGetReceipts66(backend Backend, msg Decoder, peer *Peer) error {
 	// Decode the block receipts retrieval message
 	var query GetReceiptsPacket66
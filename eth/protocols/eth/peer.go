@@ -156,6 +156,17 @@ func (p *Peer) SetHead(hash common.Hash, td *big.Int) {
 	p.td.Set(td)
 }
 
+// HistoryTail retrieves the first block whose body and receipts the peer serves,
+// as advertised in the `eth` entry of its node record. Peers not advertising a
+// tail, or whose record is not known, are assumed to serve the entire history.
+func (p *Peer) HistoryTail() uint64 {
+	var entry enrEntry
+	if err := p.Node().Load(&entry); err != nil {
+		return 0
+	}
+	return entry.HistoryTail
+}
+
 // KnownBlock returns whether peer is known to already have a block.
 func (p *Peer) KnownBlock(hash common.Hash) bool {
 	return p.knownBlocks.Contains(hash)
@@ -22,6 +22,7 @@ import (
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/forkid"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rlp"
 )
@@ -264,3 +265,43 @@ func TestEth66Messages(t *testing.T) {
 		}
 	}
 }
+
+// Tests that the history tail in the `eth` ENR entry stays compatible with nodes
+// not aware of it, in both directions.
+func TestENREntryHistoryTail(t *testing.T) {
+	type legacyEntry struct {
+		ForkID forkid.ID
+		Rest   []rlp.RawValue `rlp:"tail"`
+	}
+	id := forkid.ID{Hash: [4]byte{0xde, 0xad, 0xbe, 0xef}, Next: 1000}
+
+	blob, err := rlp.EncodeToBytes(&enrEntry{ForkID: id, HistoryTail: 500})
+	if err != nil {
+		t.Fatalf("failed to encode entry: %v", err)
+	}
+	var legacy legacyEntry
+	if err := rlp.DecodeBytes(blob, &legacy); err != nil {
+		t.Fatalf("failed to decode entry as legacy: %v", err)
+	}
+	if legacy.ForkID != id {
+		t.Errorf("legacy fork ID mismatch: have %v, want %v", legacy.ForkID, id)
+	}
+	var entry enrEntry
+	if err := rlp.DecodeBytes(blob, &entry); err != nil {
+		t.Fatalf("failed to decode entry: %v", err)
+	}
+	if entry.ForkID != id || entry.HistoryTail != 500 {
+		t.Errorf("entry mismatch: have %v/%d, want %v/500", entry.ForkID, entry.HistoryTail, id)
+	}
+	// Entries of nodes unaware of the history tail decode as serving it all
+	if blob, err = rlp.EncodeToBytes(&legacyEntry{ForkID: id}); err != nil {
+		t.Fatalf("failed to encode legacy entry: %v", err)
+	}
+	entry = enrEntry{}
+	if err := rlp.DecodeBytes(blob, &entry); err != nil {
+		t.Fatalf("failed to decode legacy entry: %v", err)
+	}
+	if entry.ForkID != id || entry.HistoryTail != 0 {
+		t.Errorf("legacy entry mismatch: have %v/%d, want %v/0", entry.ForkID, entry.HistoryTail, id)
+	}
+}
@@ -392,7 +392,8 @@ type SyncPeer interface {
 //   - The peer delivers a stale response after a previous timeout
 //   - The peer delivers a refusal to serve the requested state
 type Syncer struct {
-	db ethdb.KeyValueStore // Database to store the trie nodes into (and dedup)
+	db     ethdb.KeyValueStore // Database to store the trie nodes into (and dedup)
+	scheme string              // Scheme the trie nodes are persisted with
 
 	root    common.Hash    // Current state trie root being synced
 	tasks   []*accountTask // Current account task set being synced
@@ -457,7 +458,8 @@ type Syncer struct {
 // snap protocol.
 func NewSyncer(db ethdb.KeyValueStore) *Syncer {
 	return &Syncer{
-		db: db,
+		db:     db,
+		scheme: rawdb.ReadStateScheme(db),
 
 		peers:    make(map[string]SyncPeer),
 		peerJoin: new(event.Feed),
@@ -710,7 +712,7 @@ func (s *Syncer) loadSyncStatus() {
 						s.accountBytes += common.StorageSize(len(key) + len(value))
 					},
 				}
-				task.genTrie = trie.NewStackTrie(task.genBatch)
+				task.genTrie = s.newStackTrie(task.genBatch, common.Hash{})
 
 				for accountHash, subtasks := range task.SubTasks {
 					for _, subtask := range subtasks {
@@ -720,7 +722,7 @@ func (s *Syncer) loadSyncStatus() {
 								s.storageBytes += common.StorageSize(len(key) + len(value))
 							},
 						}
-						subtask.genTrie = trie.NewStackTrieWithOwner(subtask.genBatch, accountHash)
+						subtask.genTrie = s.newStackTrie(subtask.genBatch, accountHash)
 					}
 				}
 			}
@@ -777,13 +779,21 @@ func (s *Syncer) loadSyncStatus() {
 			Last:     last,
 			SubTasks: make(map[common.Hash][]*storageTask),
 			genBatch: batch,
-			genTrie:  trie.NewStackTrie(batch),
+			genTrie:  s.newStackTrie(batch, common.Hash{}),
 		})
 		log.Debug("Created account sync task", "from", next, "last", last)
 		next = common.BigToHash(new(big.Int).Add(last.Big(), common.Big1))
 	}
 }
 
+// newStackTrie creates a stack trie persisting the generated nodes of the trie
+// with the given owner into the batch, using the scheme of the database.
+func (s *Syncer) newStackTrie(batch ethdb.KeyValueWriter, owner common.Hash) *trie.StackTrie {
+	return trie.NewStackTrieWithWriter(owner, func(owner common.Hash, path []byte, hash common.Hash, blob []byte) {
+		rawdb.WriteTrieNodeWithScheme(batch, owner, path, hash, blob, s.scheme)
+	})
+}
+
 // saveSyncStatus marshals the remaining sync tasks into leveldb.
 func (s *Syncer) saveSyncStatus() {
 	// Serialize any partial progress to disk before spinning down
@@ -1798,7 +1808,7 @@ func (s *Syncer) processAccountResponse(res *accountResponse) {
 		}
 		// Check if the account is a contract with an unknown storage trie
 		if account.Root != emptyRoot {
-			if ok, err := s.db.Has(account.Root[:]); err != nil || !ok {
+			if !rawdb.HasTrieNodeWithScheme(s.db, res.hashes[i], nil, account.Root, s.scheme) {
 				// If there was a previous large state retrieval in progress,
 				// don't restart it from scratch. This happens if a sync cycle
 				// is interrupted and resumed later. However, *do* update the
@@ -1970,7 +1980,7 @@ func (s *Syncer) processStorageResponse(res *storageResponse) {
 						Last:     r.End(),
 						root:     acc.Root,
 						genBatch: batch,
-						genTrie:  trie.NewStackTrieWithOwner(batch, account),
+						genTrie:  s.newStackTrie(batch, account),
 					})
 					for r.Next() {
 						batch := ethdb.HookedBatch{
@@ -1984,7 +1994,7 @@ func (s *Syncer) processStorageResponse(res *storageResponse) {
 							Last:     r.End(),
 							root:     acc.Root,
 							genBatch: batch,
-							genTrie:  trie.NewStackTrieWithOwner(batch, account),
+							genTrie:  s.newStackTrie(batch, account),
 						})
 					}
 					for _, task := range tasks {
@@ -2029,7 +2039,7 @@ func (s *Syncer) processStorageResponse(res *storageResponse) {
 		slots += len(res.hashes[i])
 
 		if i < len(res.hashes)-1 || res.subTask == nil {
-			tr := trie.NewStackTrieWithOwner(batch, account)
+			tr := s.newStackTrie(batch, account)
 			for j := 0; j < len(res.hashes[i]); j++ {
 				tr.Update(res.hashes[i][j][:], res.slots[i][j])
 			}
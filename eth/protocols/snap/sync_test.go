@@ -1370,7 +1370,7 @@ func makeAccountTrieNoStorage(n int) (*trie.Trie, entrySlice) {
 	// Commit the state changes into db and re-create the trie
 	// for accessing later.
 	root, nodes, _ := accTrie.Commit(false)
-	db.Update(trie.NewWithNodeSet(nodes))
+	db.Update(root, common.Hash{}, trie.NewWithNodeSet(nodes))
 
 	accTrie, _ = trie.New(common.Hash{}, root, db)
 	return accTrie, entries
@@ -1432,7 +1432,7 @@ func makeBoundaryAccountTrie(n int) (*trie.Trie, entrySlice) {
 	// Commit the state changes into db and re-create the trie
 	// for accessing later.
 	root, nodes, _ := accTrie.Commit(false)
-	db.Update(trie.NewWithNodeSet(nodes))
+	db.Update(root, common.Hash{}, trie.NewWithNodeSet(nodes))
 
 	accTrie, _ = trie.New(common.Hash{}, root, db)
 	return accTrie, entries
@@ -1481,7 +1481,7 @@ func makeAccountTrieWithStorageWithUniqueStorage(accounts, slots int, code bool)
 	nodes.Merge(set)
 
 	// Commit gathered dirty nodes into database
-	db.Update(nodes)
+	db.Update(root, common.Hash{}, nodes)
 
 	// Re-create tries with new root
 	accTrie, _ = trie.New(common.Hash{}, root, db)
@@ -1545,7 +1545,7 @@ func makeAccountTrieWithStorage(accounts, slots int, code, boundary bool) (*trie
 	nodes.Merge(set)
 
 	// Commit gathered dirty nodes into database
-	db.Update(nodes)
+	db.Update(root, common.Hash{}, nodes)
 
 	// Re-create tries with new root
 	accTrie, err := trie.New(common.Hash{}, root, db)
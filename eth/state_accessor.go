@@ -89,6 +89,12 @@ func (eth *Ethereum) StateAtBlock(block *types.Block, reexec uint64, base *state
 				return statedb, nil
 			}
 		}
+		// If the state diffs are retained, try to rebuild the state from them
+		if eth.config.StateDiffs > 0 {
+			if statedb, err = eth.blockchain.HistoricState(current.Header()); err == nil {
+				return statedb, nil
+			}
+		}
 		// Database does not have the state for the given block, try to regenerate
 		for i := uint64(0); i < reexec; i++ {
 			if current.NumberU64() == 0 {
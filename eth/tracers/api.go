@@ -588,7 +588,16 @@ func (api *API) traceBlock(ctx context.Context, block *types.Block, config *Trac
 
 		pend = new(sync.WaitGroup)
 		jobs = make(chan *txTraceTask, len(txs))
+
+		done    chan int // Indices of the finished traces if streaming
+		emitted = make(chan error, 1)
 	)
+	// If the client requested streaming, write the traces out in order as they
+	// finish instead of returning them all at once
+	if stream := rpc.StreamFromContext(ctx); stream != nil {
+		done = make(chan int, len(txs))
+		go func() { emitted <- streamTraces(stream, results, done) }()
+	}
 	threads := runtime.NumCPU()
 	if threads > len(txs) {
 		threads = len(txs)
@@ -610,9 +619,12 @@ func (api *API) traceBlock(ctx context.Context, block *types.Block, config *Trac
 				res, err := api.traceTx(ctx, msg, txctx, blockCtx, task.statedb, config)
 				if err != nil {
 					results[task.index] = &txTraceResult{Error: err.Error()}
-					continue
+				} else {
+					results[task.index] = &txTraceResult{Result: res}
+				}
+				if done != nil {
+					done <- task.index
 				}
-				results[task.index] = &txTraceResult{Result: res}
 			}
 		}()
 	}
@@ -638,6 +650,12 @@ func (api *API) traceBlock(ctx context.Context, block *types.Block, config *Trac
 	close(jobs)
 	pend.Wait()
 
+	if done != nil {
+		close(done)
+		if err := <-emitted; err != nil && failed == nil {
+			failed = err
+		}
+	}
 	// If execution failed in between, abort
 	if failed != nil {
 		return nil, failed
@@ -645,6 +663,24 @@ func (api *API) traceBlock(ctx context.Context, block *types.Block, config *Trac
 	return results, nil
 }
 
+// streamTraces writes the transaction traces to the result stream in order as
+// their indices arrive on done, releasing each once it was written.
+func streamTraces(stream *rpc.ResultStream, results []*txTraceResult, done <-chan int) error {
+	var (
+		finished = make([]bool, len(results))
+		next     int
+		err      error
+	)
+	for index := range done {
+		finished[index] = true
+		for ; err == nil && next < len(results) && finished[next]; next++ {
+			err = stream.Write(results[next])
+			results[next] = nil
+		}
+	}
+	return err
+}
+
 // standardTraceBlockToFile configures a new tracer which uses standard JSON output,
 // and traces either a full block or an individual transaction. The return value will
 // be one filename per transaction traced.
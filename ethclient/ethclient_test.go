@@ -285,6 +285,9 @@ func TestEthClient(t *testing.T) {
 		"CallContract": {
 			func(t *testing.T) { testCallContract(t, client) },
 		},
+		"BlockReceipts": {
+			func(t *testing.T) { testBlockReceipts(t, chain, client) },
+		},
 		"CallContractAtHash": {
 			func(t *testing.T) { testCallContractAtHash(t, client) },
 		},
@@ -587,6 +590,36 @@ func testCallContract(t *testing.T, client *rpc.Client) {
 	}
 }
 
+func testBlockReceipts(t *testing.T, chain []*types.Block, client *rpc.Client) {
+	ec := NewClient(client)
+
+	// Block #2 contains both test transactions.
+	block := chain[2]
+	byNumber, err := ec.BlockReceipts(context.Background(), rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(2)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	byHash, err := ec.BlockReceipts(context.Background(), rpc.BlockNumberOrHashWithHash(block.Hash(), false))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(byNumber) != len(block.Transactions()) || len(byHash) != len(block.Transactions()) {
+		t.Fatalf("receipt count mismatch: have %d/%d, want %d", len(byNumber), len(byHash), len(block.Transactions()))
+	}
+	for i, tx := range block.Transactions() {
+		if byNumber[i].TxHash != tx.Hash() || byHash[i].TxHash != tx.Hash() {
+			t.Errorf("receipt %d: tx hash mismatch", i)
+		}
+		if byNumber[i].BlockHash != block.Hash() || byNumber[i].Status != types.ReceiptStatusSuccessful {
+			t.Errorf("receipt %d: unexpected content %+v", i, byNumber[i])
+		}
+	}
+	// Unknown blocks should report not found.
+	if _, err := ec.BlockReceipts(context.Background(), rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(100))); err != ethereum.NotFound {
+		t.Fatalf("unexpected error for missing block: %v", err)
+	}
+}
+
 func testAtFunctions(t *testing.T, client *rpc.Client) {
 	ec := NewClient(client)
 
@@ -89,6 +89,8 @@ type AncientReaderOp interface {
 
 	// Tail returns the number of first stored item in the freezer.
 	// This number can also be interpreted as the total deleted item numbers.
+	// Only the prunable tables are affected by the tail, the others always
+	// retain all their items.
 	Tail() (uint64, error)
 
 	// AncientSize returns the ancient size of the specified category.
@@ -119,7 +121,8 @@ type AncientWriter interface {
 	// deleted items are ignored. After the truncation, the earliest item can be accessed
 	// is item_n(start from 0). The deleted items may not be removed from the ancient store
 	// immediately, but only when the accumulated deleted data reach the threshold then
-	// will be removed all together.
+	// will be removed all together. Only the prunable tables of the ancient store are
+	// truncated.
 	TruncateTail(n uint64) error
 
 	// Sync flushes all in-memory ancient store data to disk.
@@ -0,0 +1,401 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package remotedb
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// iteratePageItems is the number of key-value pairs requested at once by the
+// iterators.
+const iteratePageItems = 1024
+
+var (
+	errNotFound     = errors.New("not found")
+	errNotSupported = errors.New("not supported by remote database")
+)
+
+// Client is a database operating on a database exposed by a Server. Reads and
+// writes are forwarded as is, so the client has no exclusive access: the owner
+// of the database keeps modifying it concurrently.
+type Client struct {
+	conn   net.Conn
+	remote *rpc.Client
+}
+
+// Dial connects to the remote database server listening on the unix socket at
+// the given path, authenticating with the given shared secret.
+func Dial(endpoint string, secret []byte) (*Client, error) {
+	conn, err := net.DialTimeout("unix", endpoint, handshakeTimeout)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(time.Now().Add(handshakeTimeout))
+	challenge := make([]byte, secretLength)
+	if _, err := io.ReadFull(conn, challenge); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if _, err := conn.Write(authResponse(secret, challenge)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+
+	remote, err := rpc.DialIO(context.Background(), conn, conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	// Make sure the server accepted the answer before handing out the client
+	var ancients hexutil.Uint64
+	if err := remote.Call(&ancients, "remotedb_ancients"); err != nil && !isRemoteError(err) {
+		conn.Close()
+		remote.Close()
+		return nil, errAuthFailed
+	}
+	return &Client{conn: conn, remote: remote}, nil
+}
+
+// isRemoteError reports whether the error was returned by the server, as opposed
+// to a connection failure.
+func isRemoteError(err error) bool {
+	var rpcErr rpc.Error
+	return errors.As(err, &rpcErr)
+}
+
+func (db *Client) Has(key []byte) (bool, error) {
+	var has bool
+	err := db.remote.Call(&has, "remotedb_has", hexutil.Bytes(key))
+	return has, err
+}
+
+func (db *Client) Get(key []byte) ([]byte, error) {
+	var value *hexutil.Bytes
+	if err := db.remote.Call(&value, "remotedb_get", hexutil.Bytes(key)); err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, errNotFound
+	}
+	return *value, nil
+}
+
+func (db *Client) Put(key []byte, value []byte) error {
+	return db.write([]batchOp{{Key: key, Value: value}})
+}
+
+func (db *Client) Delete(key []byte) error {
+	return db.write([]batchOp{{Key: key, Delete: true}})
+}
+
+// write atomically applies the given writes on the remote database.
+func (db *Client) write(ops []batchOp) error {
+	return db.remote.Call(nil, "remotedb_write", ops)
+}
+
+func (db *Client) NewBatch() ethdb.Batch {
+	return &batch{db: db}
+}
+
+func (db *Client) NewBatchWithSize(size int) ethdb.Batch {
+	return &batch{db: db}
+}
+
+func (db *Client) NewIterator(prefix []byte, start []byte) ethdb.Iterator {
+	return &iterator{
+		db:     db,
+		prefix: common.CopyBytes(prefix),
+		start:  common.CopyBytes(start),
+		more:   true,
+		pos:    -1,
+	}
+}
+
+func (db *Client) Stat(property string) (string, error) {
+	var stat string
+	err := db.remote.Call(&stat, "remotedb_stat", property)
+	return stat, err
+}
+
+func (db *Client) Compact(start []byte, limit []byte) error {
+	return db.remote.Call(nil, "remotedb_compact", hexutil.Bytes(start), hexutil.Bytes(limit))
+}
+
+func (db *Client) NewSnapshot() (ethdb.Snapshot, error) {
+	var id hexutil.Uint64
+	if err := db.remote.Call(&id, "remotedb_newSnapshot"); err != nil {
+		return nil, err
+	}
+	return &snapshot{db: db, id: id}, nil
+}
+
+func (db *Client) HasAncient(kind string, number uint64) (bool, error) {
+	var has bool
+	err := db.remote.Call(&has, "remotedb_hasAncient", kind, hexutil.Uint64(number))
+	return has, err
+}
+
+func (db *Client) Ancient(kind string, number uint64) ([]byte, error) {
+	var item hexutil.Bytes
+	err := db.remote.Call(&item, "remotedb_ancient", kind, hexutil.Uint64(number))
+	return item, err
+}
+
+func (db *Client) AncientRange(kind string, start, count, maxBytes uint64) ([][]byte, error) {
+	var blobs []hexutil.Bytes
+	if err := db.remote.Call(&blobs, "remotedb_ancientRange", kind, hexutil.Uint64(start), hexutil.Uint64(count), hexutil.Uint64(maxBytes)); err != nil {
+		return nil, err
+	}
+	items := make([][]byte, len(blobs))
+	for i, blob := range blobs {
+		items[i] = blob
+	}
+	return items, nil
+}
+
+func (db *Client) Ancients() (uint64, error) {
+	var n hexutil.Uint64
+	err := db.remote.Call(&n, "remotedb_ancients")
+	return uint64(n), err
+}
+
+func (db *Client) Tail() (uint64, error) {
+	var n hexutil.Uint64
+	err := db.remote.Call(&n, "remotedb_tail")
+	return uint64(n), err
+}
+
+func (db *Client) AncientSize(kind string) (uint64, error) {
+	var n hexutil.Uint64
+	err := db.remote.Call(&n, "remotedb_ancientSize", kind)
+	return uint64(n), err
+}
+
+// ReadAncients runs the given read operation directly on the client, the remote
+// writer is not paused in the meantime.
+func (db *Client) ReadAncients(fn func(op ethdb.AncientReaderOp) error) error {
+	return fn(db)
+}
+
+// ModifyAncients collects the items appended by the given write operation and
+// applies them atomically on the remote database. Nothing is sent if the
+// operation fails.
+func (db *Client) ModifyAncients(fn func(ethdb.AncientWriteOp) error) (int64, error) {
+	op := new(ancientWriteOp)
+	if err := fn(op); err != nil {
+		return 0, err
+	}
+	var size hexutil.Uint64
+	if err := db.remote.Call(&size, "remotedb_modifyAncients", op.ops); err != nil {
+		return 0, err
+	}
+	return int64(size), nil
+}
+
+func (db *Client) TruncateHead(n uint64) error {
+	return db.remote.Call(nil, "remotedb_truncateHead", hexutil.Uint64(n))
+}
+
+func (db *Client) TruncateTail(n uint64) error {
+	return db.remote.Call(nil, "remotedb_truncateTail", hexutil.Uint64(n))
+}
+
+func (db *Client) Sync() error {
+	return db.remote.Call(nil, "remotedb_sync")
+}
+
+func (db *Client) MigrateTable(s string, f func([]byte) ([]byte, error)) error {
+	return errNotSupported
+}
+
+func (db *Client) AncientDatadir() (string, error) {
+	return "", errNotSupported
+}
+
+func (db *Client) Close() error {
+	// Close the connection first, the RPC client can't interrupt reads on it
+	err := db.conn.Close()
+	db.remote.Close()
+	return err
+}
+
+// batch is a write-only batch buffering the writes until Write is called.
+type batch struct {
+	db   *Client
+	ops  []batchOp
+	size int
+}
+
+func (b *batch) Put(key []byte, value []byte) error {
+	b.ops = append(b.ops, batchOp{Key: common.CopyBytes(key), Value: common.CopyBytes(value)})
+	b.size += len(key) + len(value)
+	return nil
+}
+
+func (b *batch) Delete(key []byte) error {
+	b.ops = append(b.ops, batchOp{Key: common.CopyBytes(key), Delete: true})
+	b.size += len(key)
+	return nil
+}
+
+func (b *batch) ValueSize() int {
+	return b.size
+}
+
+func (b *batch) Write() error {
+	if len(b.ops) == 0 {
+		return nil
+	}
+	return b.db.write(b.ops)
+}
+
+func (b *batch) Reset() {
+	b.ops = b.ops[:0]
+	b.size = 0
+}
+
+func (b *batch) Replay(w ethdb.KeyValueWriter) error {
+	for _, op := range b.ops {
+		if op.Delete {
+			if err := w.Delete(op.Key); err != nil {
+				return err
+			}
+		} else if err := w.Put(op.Key, op.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// iterator walks the remote key-value store page by page. Pages are fetched
+// independently, so the iterator does not see a consistent view of a database
+// that is modified concurrently.
+type iterator struct {
+	db     *Client
+	prefix []byte
+	start  []byte
+	page   *iteratePage
+	pos    int
+	more   bool
+	err    error
+}
+
+func (it *iterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if it.page != nil && it.pos+1 < len(it.page.Keys) {
+		it.pos++
+		return true
+	}
+	if !it.more {
+		it.page = nil
+		return false
+	}
+	page := new(iteratePage)
+	if err := it.db.remote.Call(page, "remotedb_iterate", hexutil.Bytes(it.prefix), hexutil.Bytes(it.start), iteratePageItems); err != nil {
+		it.err, it.page = err, nil
+		return false
+	}
+	it.page, it.pos, it.more = page, 0, page.More
+	if len(page.Keys) == 0 {
+		it.page = nil
+		return false
+	}
+	// Continue right after the last returned key on the next page
+	last := page.Keys[len(page.Keys)-1]
+	it.start = append(common.CopyBytes(last[len(it.prefix):]), 0x00)
+	return true
+}
+
+func (it *iterator) Error() error {
+	return it.err
+}
+
+func (it *iterator) Key() []byte {
+	if it.page == nil {
+		return nil
+	}
+	return it.page.Keys[it.pos]
+}
+
+func (it *iterator) Value() []byte {
+	if it.page == nil {
+		return nil
+	}
+	return it.page.Values[it.pos]
+}
+
+func (it *iterator) Release() {
+	it.page, it.more = nil, false
+}
+
+// snapshot is a snapshot of the key-value store held by the server.
+type snapshot struct {
+	db *Client
+	id hexutil.Uint64
+}
+
+func (snap *snapshot) Has(key []byte) (bool, error) {
+	var has bool
+	err := snap.db.remote.Call(&has, "remotedb_snapshotHas", snap.id, hexutil.Bytes(key))
+	return has, err
+}
+
+func (snap *snapshot) Get(key []byte) ([]byte, error) {
+	var value *hexutil.Bytes
+	if err := snap.db.remote.Call(&value, "remotedb_snapshotGet", snap.id, hexutil.Bytes(key)); err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, errNotFound
+	}
+	return *value, nil
+}
+
+func (snap *snapshot) Release() {
+	snap.db.remote.Call(nil, "remotedb_releaseSnapshot", snap.id)
+}
+
+// ancientWriteOp collects the items appended to the ancient store.
+type ancientWriteOp struct {
+	ops []ancientOp
+}
+
+func (op *ancientWriteOp) Append(kind string, number uint64, item interface{}) error {
+	blob, err := rlp.EncodeToBytes(item)
+	if err != nil {
+		return err
+	}
+	return op.AppendRaw(kind, number, blob)
+}
+
+func (op *ancientWriteOp) AppendRaw(kind string, number uint64, item []byte) error {
+	op.ops = append(op.ops, ancientOp{Kind: kind, Number: hexutil.Uint64(number), Item: common.CopyBytes(item)})
+	return nil
+}
@@ -19,6 +19,9 @@
 // read-only database.
 // There really are no guarantees in this database, since the local geth does not
 // exclusive access, but it can be used for basic diagnostics of a remote node.
+//
+// The package also contains a Server exposing a node's database over an
+// authenticated local socket, and a writable Client operating on it.
 package remotedb
 
 import (
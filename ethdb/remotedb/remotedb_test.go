@@ -0,0 +1,223 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package remotedb
+
+import (
+	"bytes"
+	"net"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/dbtest"
+)
+
+// startServer exposes the given database over a socket in a temporary directory,
+// returning the socket path and the shared secret.
+func startServer(t *testing.T, db ethdb.Database) (string, []byte) {
+	t.Helper()
+
+	dir := t.TempDir()
+	secret, err := ObtainSecret(filepath.Join(dir, "secret"))
+	if err != nil {
+		t.Fatalf("failed to generate secret: %v", err)
+	}
+	endpoint := filepath.Join(dir, "db.ipc")
+	srv := NewServer(db, endpoint, secret)
+	if err := srv.Start(); err != nil {
+		t.Fatalf("failed to start server: %v", err)
+	}
+	t.Cleanup(func() { srv.Stop() })
+	return endpoint, secret
+}
+
+func TestRemoteDB(t *testing.T) {
+	t.Run("DatabaseSuite", func(t *testing.T) {
+		dbtest.TestDatabaseSuite(t, func() ethdb.KeyValueStore {
+			endpoint, secret := startServer(t, rawdb.NewMemoryDatabase())
+			client, err := Dial(endpoint, secret)
+			if err != nil {
+				t.Fatalf("failed to dial server: %v", err)
+			}
+			return client
+		})
+	})
+}
+
+// Tests that clients without the shared secret are rejected.
+func TestRemoteDBAuthentication(t *testing.T) {
+	endpoint, _ := startServer(t, rawdb.NewMemoryDatabase())
+	if client, err := Dial(endpoint, make([]byte, secretLength)); err == nil {
+		client.Close()
+		t.Fatalf("client with wrong secret accepted")
+	}
+}
+
+// Tests that ancient data can be written and read through the remote database.
+func TestRemoteDBAncients(t *testing.T) {
+	db, err := rawdb.NewDatabaseWithFreezer(rawdb.NewMemoryDatabase(), t.TempDir(), "", false)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	endpoint, secret := startServer(t, db)
+	client, err := Dial(endpoint, secret)
+	if err != nil {
+		t.Fatalf("failed to dial server: %v", err)
+	}
+	defer client.Close()
+
+	kinds := []string{"headers", "hashes", "bodies", "receipts", "diffs"}
+	_, err = client.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		for i := uint64(0); i < 4; i++ {
+			for _, kind := range kinds {
+				if err := op.AppendRaw(kind, i, []byte{byte(i)}); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to write ancients: %v", err)
+	}
+	if n, err := client.Ancients(); err != nil || n != 4 {
+		t.Fatalf("ancient count mismatch: have %d (%v), want 4", n, err)
+	}
+	items, err := client.AncientRange("bodies", 1, 2, 1024)
+	if err != nil {
+		t.Fatalf("failed to read ancients: %v", err)
+	}
+	if len(items) != 2 || !bytes.Equal(items[0], []byte{1}) || !bytes.Equal(items[1], []byte{2}) {
+		t.Fatalf("ancient range mismatch: %x", items)
+	}
+	if err := client.TruncateHead(2); err != nil {
+		t.Fatalf("failed to truncate ancients: %v", err)
+	}
+	if n, _ := db.Ancients(); n != 2 {
+		t.Fatalf("local ancient count mismatch: have %d, want 2", n)
+	}
+}
+
+// snapshotCountingDB is a database tracking the number of live snapshots.
+type snapshotCountingDB struct {
+	ethdb.Database
+	live int32
+}
+
+func (db *snapshotCountingDB) NewSnapshot() (ethdb.Snapshot, error) {
+	snap, err := db.Database.NewSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	atomic.AddInt32(&db.live, 1)
+	return &countedSnapshot{Snapshot: snap, live: &db.live}, nil
+}
+
+type countedSnapshot struct {
+	ethdb.Snapshot
+	live *int32
+}
+
+func (snap *countedSnapshot) Release() {
+	atomic.AddInt32(snap.live, -1)
+	snap.Snapshot.Release()
+}
+
+// Tests that the snapshots a client leaves behind are released when it
+// disconnects, without affecting the ones of other clients.
+func TestRemoteDBSnapshotRelease(t *testing.T) {
+	db := &snapshotCountingDB{Database: rawdb.NewMemoryDatabase()}
+	endpoint, secret := startServer(t, db)
+
+	var clients []*Client
+	for i := 0; i < 2; i++ {
+		client, err := Dial(endpoint, secret)
+		if err != nil {
+			t.Fatalf("failed to dial server: %v", err)
+		}
+		if _, err := client.NewSnapshot(); err != nil {
+			t.Fatalf("failed to create snapshot: %v", err)
+		}
+		clients = append(clients, client)
+	}
+	defer clients[1].Close()
+
+	clients[0].Close()
+	for start := time.Now(); atomic.LoadInt32(&db.live) != 1; {
+		if time.Since(start) > 5*time.Second {
+			t.Fatalf("live snapshot count mismatch: have %d, want 1", atomic.LoadInt32(&db.live))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Tests that iterations without a positive item count are rejected.
+func TestRemoteDBIterateCount(t *testing.T) {
+	api := &serverAPI{db: rawdb.NewMemoryDatabase()}
+	for _, count := range []int{0, -1} {
+		if _, err := api.Iterate(nil, nil, count); err == nil {
+			t.Errorf("iteration of %d items accepted", count)
+		}
+	}
+}
+
+// Tests that the server only replaces a stale socket at its endpoint.
+func TestRemoteDBEndpoint(t *testing.T) {
+	endpoint := filepath.Join(t.TempDir(), "db.ipc")
+	if err := os.WriteFile(endpoint, []byte("data"), 0600); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	srv := NewServer(rawdb.NewMemoryDatabase(), endpoint, make([]byte, secretLength))
+	if err := srv.Start(); err == nil {
+		srv.Stop()
+		t.Fatalf("server replaced a regular file")
+	}
+	if _, err := os.Stat(endpoint); err != nil {
+		t.Fatalf("regular file removed: %v", err)
+	}
+	// A socket in use is left alone, a stale one is replaced
+	os.Remove(endpoint)
+	live := NewServer(rawdb.NewMemoryDatabase(), endpoint, make([]byte, secretLength))
+	if err := live.Start(); err != nil {
+		t.Fatalf("failed to start server: %v", err)
+	}
+	if err := srv.Start(); err == nil {
+		srv.Stop()
+		t.Fatalf("server replaced a live socket")
+	}
+	live.Stop()
+
+	stale, err := net.ListenUnix("unix", &net.UnixAddr{Name: endpoint, Net: "unix"})
+	if err != nil {
+		t.Fatalf("failed to create socket: %v", err)
+	}
+	stale.SetUnlinkOnClose(false)
+	stale.Close()
+	if _, err := os.Stat(endpoint); err != nil {
+		t.Fatalf("stale socket missing: %v", err)
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatalf("failed to replace stale socket: %v", err)
+	}
+	srv.Stop()
+}
@@ -0,0 +1,490 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package remotedb
+
+import (
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/netutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// secretLength is the length of the shared secret authenticating clients.
+	secretLength = 32
+
+	// handshakeTimeout is the time allowed for a client to authenticate.
+	handshakeTimeout = 5 * time.Second
+
+	// iteratePageBytes is the maximum size of the key-value pairs returned by
+	// a single iteration request.
+	iteratePageBytes = 1024 * 1024
+)
+
+var errAuthFailed = errors.New("remote database authentication failed")
+
+// ReadSecret loads the hex encoded shared secret from the given file.
+func ReadSecret(file string) ([]byte, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	secret := common.FromHex(strings.TrimSpace(string(data)))
+	if len(secret) != secretLength {
+		return nil, fmt.Errorf("invalid remote database secret length %d", len(secret))
+	}
+	return secret, nil
+}
+
+// ObtainSecret loads the shared secret from the given file, generating and
+// storing a new one if the file doesn't exist yet.
+func ObtainSecret(file string) ([]byte, error) {
+	if _, err := os.Stat(file); err == nil {
+		return ReadSecret(file)
+	}
+	secret := make([]byte, secretLength)
+	if _, err := crand.Read(secret); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(file, []byte(hexutil.Encode(secret)), 0600); err != nil {
+		return nil, err
+	}
+	log.Info("Generated remote database secret", "path", file)
+	return secret, nil
+}
+
+// authResponse computes the answer to an authentication challenge.
+func authResponse(secret []byte, challenge []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(challenge)
+	return mac.Sum(nil)
+}
+
+// Server exposes a database over a local socket. Connecting clients have to
+// prove knowledge of the shared secret by answering a random challenge before
+// any request is served. Every connection is served by its own API instance, so
+// the snapshots a client leaves behind are released when it disconnects.
+type Server struct {
+	db       ethdb.Database
+	endpoint string
+	secret   []byte
+	listener net.Listener
+
+	servers map[*rpc.Server]struct{} // RPC servers of the live connections, nil once stopped
+	wg      sync.WaitGroup
+	lock    sync.Mutex
+}
+
+// NewServer creates a server exposing the given database on the unix socket
+// at the given path once started.
+func NewServer(db ethdb.Database, endpoint string, secret []byte) *Server {
+	return &Server{
+		db:       db,
+		endpoint: endpoint,
+		secret:   secret,
+		servers:  make(map[*rpc.Server]struct{}),
+	}
+}
+
+// Start implements node.Lifecycle, starting to accept connections.
+func (s *Server) Start() error {
+	if err := removeStaleSocket(s.endpoint); err != nil {
+		return err
+	}
+	listener, err := net.Listen("unix", s.endpoint)
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(s.endpoint, 0600); err != nil {
+		listener.Close()
+		return err
+	}
+	s.listener = listener
+	s.wg.Add(1)
+	go s.loop()
+
+	log.Info("Remote database server started", "endpoint", s.endpoint)
+	return nil
+}
+
+// removeStaleSocket removes the socket left behind at the given path by an
+// unclean shutdown. Anything else than a socket nobody listens on is left alone.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("remote database endpoint %s exists and is not a socket", path)
+	}
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		return fmt.Errorf("remote database endpoint %s is already in use", path)
+	}
+	return os.Remove(path)
+}
+
+// Stop implements node.Lifecycle, closing the listener and all connections.
+func (s *Server) Stop() error {
+	if s.listener != nil {
+		s.listener.Close()
+		os.Remove(s.endpoint)
+	}
+	s.lock.Lock()
+	for srv := range s.servers {
+		srv.Stop()
+	}
+	s.servers = nil
+	s.lock.Unlock()
+
+	s.wg.Wait()
+	log.Info("Remote database server stopped", "endpoint", s.endpoint)
+	return nil
+}
+
+// loop accepts the incoming connections.
+func (s *Server) loop() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if netutil.IsTemporaryError(err) {
+			log.Warn("Remote database accept error", "err", err)
+			continue
+		} else if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go s.serve(conn)
+	}
+}
+
+// serve authenticates the client and serves its requests, releasing the
+// snapshots it created once it disconnects.
+func (s *Server) serve(conn net.Conn) {
+	defer s.wg.Done()
+
+	if err := s.authenticate(conn); err != nil {
+		log.Warn("Rejected remote database connection", "err", err)
+		conn.Close()
+		return
+	}
+	api := &serverAPI{db: s.db, snapshots: make(map[uint64]ethdb.Snapshot)}
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("remotedb", api); err != nil {
+		panic(err) // The API is static, registration can't fail
+	}
+	s.lock.Lock()
+	if s.servers == nil {
+		s.lock.Unlock()
+		conn.Close()
+		return
+	}
+	s.servers[srv] = struct{}{}
+	s.lock.Unlock()
+
+	srv.ServeCodec(rpc.NewCodec(conn), 0)
+
+	s.lock.Lock()
+	delete(s.servers, srv)
+	s.lock.Unlock()
+	api.releaseSnapshots()
+}
+
+// authenticate sends a random challenge to the client and checks the answer.
+func (s *Server) authenticate(conn net.Conn) error {
+	conn.SetDeadline(time.Now().Add(handshakeTimeout))
+	defer conn.SetDeadline(time.Time{})
+
+	challenge := make([]byte, secretLength)
+	if _, err := crand.Read(challenge); err != nil {
+		return err
+	}
+	if _, err := conn.Write(challenge); err != nil {
+		return err
+	}
+	response := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(conn, response); err != nil {
+		return err
+	}
+	if !hmac.Equal(response, authResponse(s.secret, challenge)) {
+		return errAuthFailed
+	}
+	return nil
+}
+
+// batchOp is a single key-value store write of a batch.
+type batchOp struct {
+	Key    hexutil.Bytes `json:"key"`
+	Value  hexutil.Bytes `json:"value,omitempty"`
+	Delete bool          `json:"delete,omitempty"`
+}
+
+// ancientOp is a single ancient store append.
+type ancientOp struct {
+	Kind   string         `json:"kind"`
+	Number hexutil.Uint64 `json:"number"`
+	Item   hexutil.Bytes  `json:"item"`
+}
+
+// iteratePage is a batch of key-value pairs returned by an iteration request.
+type iteratePage struct {
+	Keys   []hexutil.Bytes `json:"keys"`
+	Values []hexutil.Bytes `json:"values"`
+	More   bool            `json:"more"`
+}
+
+// serverAPI is the RPC service operating on the exposed database for a single
+// connection.
+type serverAPI struct {
+	db ethdb.Database
+
+	snapshots map[uint64]ethdb.Snapshot
+	snapID    uint64
+	lock      sync.Mutex
+}
+
+// Has retrieves if a key is present in the key-value store.
+func (api *serverAPI) Has(key hexutil.Bytes) (bool, error) {
+	return api.db.Has(key)
+}
+
+// Get retrieves the given key from the key-value store, returning null if it's
+// not present.
+func (api *serverAPI) Get(key hexutil.Bytes) (*hexutil.Bytes, error) {
+	value, err := api.db.Get(key)
+	if err != nil {
+		if has, herr := api.db.Has(key); herr == nil && !has {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return (*hexutil.Bytes)(&value), nil
+}
+
+// Write atomically applies the given batch of writes to the key-value store.
+func (api *serverAPI) Write(ops []batchOp) error {
+	batch := api.db.NewBatch()
+	for _, op := range ops {
+		var err error
+		if op.Delete {
+			err = batch.Delete(op.Key)
+		} else {
+			err = batch.Put(op.Key, op.Value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return batch.Write()
+}
+
+// Iterate returns the key-value pairs with the given prefix, starting at the
+// given key, up to the given count.
+func (api *serverAPI) Iterate(prefix hexutil.Bytes, start hexutil.Bytes, count int) (*iteratePage, error) {
+	if count <= 0 {
+		return nil, fmt.Errorf("invalid iteration count %d", count)
+	}
+	it := api.db.NewIterator(prefix, start)
+	defer it.Release()
+
+	var (
+		page  = new(iteratePage)
+		bytes int
+	)
+	for len(page.Keys) < count && bytes < iteratePageBytes {
+		if !it.Next() {
+			return page, it.Error()
+		}
+		page.Keys = append(page.Keys, common.CopyBytes(it.Key()))
+		page.Values = append(page.Values, common.CopyBytes(it.Value()))
+		bytes += len(it.Key()) + len(it.Value())
+	}
+	page.More = true
+	return page, it.Error()
+}
+
+// Stat returns a particular internal stat of the key-value store.
+func (api *serverAPI) Stat(property string) (string, error) {
+	return api.db.Stat(property)
+}
+
+// Compact flattens the key-value store in the given key range.
+func (api *serverAPI) Compact(start hexutil.Bytes, limit hexutil.Bytes) error {
+	return api.db.Compact(start, limit)
+}
+
+// NewSnapshot creates a snapshot of the key-value store, returning its id.
+func (api *serverAPI) NewSnapshot() (hexutil.Uint64, error) {
+	snap, err := api.db.NewSnapshot()
+	if err != nil {
+		return 0, err
+	}
+	api.lock.Lock()
+	defer api.lock.Unlock()
+
+	api.snapID++
+	api.snapshots[api.snapID] = snap
+	return hexutil.Uint64(api.snapID), nil
+}
+
+// snapshot retrieves a live snapshot by id.
+func (api *serverAPI) snapshot(id hexutil.Uint64) (ethdb.Snapshot, error) {
+	api.lock.Lock()
+	defer api.lock.Unlock()
+
+	snap, ok := api.snapshots[uint64(id)]
+	if !ok {
+		return nil, fmt.Errorf("unknown snapshot %d", id)
+	}
+	return snap, nil
+}
+
+// SnapshotHas retrieves if a key is present in the given snapshot.
+func (api *serverAPI) SnapshotHas(id hexutil.Uint64, key hexutil.Bytes) (bool, error) {
+	snap, err := api.snapshot(id)
+	if err != nil {
+		return false, err
+	}
+	return snap.Has(key)
+}
+
+// SnapshotGet retrieves the given key from the given snapshot, returning null
+// if it's not present.
+func (api *serverAPI) SnapshotGet(id hexutil.Uint64, key hexutil.Bytes) (*hexutil.Bytes, error) {
+	snap, err := api.snapshot(id)
+	if err != nil {
+		return nil, err
+	}
+	value, err := snap.Get(key)
+	if err != nil {
+		if has, herr := snap.Has(key); herr == nil && !has {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return (*hexutil.Bytes)(&value), nil
+}
+
+// ReleaseSnapshot releases the given snapshot.
+func (api *serverAPI) ReleaseSnapshot(id hexutil.Uint64) {
+	api.lock.Lock()
+	defer api.lock.Unlock()
+
+	if snap, ok := api.snapshots[uint64(id)]; ok {
+		snap.Release()
+		delete(api.snapshots, uint64(id))
+	}
+}
+
+// releaseSnapshots releases all the snapshots left behind by the client.
+func (api *serverAPI) releaseSnapshots() {
+	api.lock.Lock()
+	defer api.lock.Unlock()
+
+	for id, snap := range api.snapshots {
+		snap.Release()
+		delete(api.snapshots, id)
+	}
+}
+
+// HasAncient returns whether the given ancient item exists.
+func (api *serverAPI) HasAncient(kind string, number hexutil.Uint64) (bool, error) {
+	return api.db.HasAncient(kind, uint64(number))
+}
+
+// Ancient retrieves an ancient item.
+func (api *serverAPI) Ancient(kind string, number hexutil.Uint64) (hexutil.Bytes, error) {
+	return api.db.Ancient(kind, uint64(number))
+}
+
+// AncientRange retrieves multiple ancient items in sequence.
+func (api *serverAPI) AncientRange(kind string, start, count, maxBytes hexutil.Uint64) ([]hexutil.Bytes, error) {
+	items, err := api.db.AncientRange(kind, uint64(start), uint64(count), uint64(maxBytes))
+	if err != nil {
+		return nil, err
+	}
+	blobs := make([]hexutil.Bytes, len(items))
+	for i, item := range items {
+		blobs[i] = item
+	}
+	return blobs, nil
+}
+
+// Ancients returns the number of items in the ancient store.
+func (api *serverAPI) Ancients() (hexutil.Uint64, error) {
+	n, err := api.db.Ancients()
+	return hexutil.Uint64(n), err
+}
+
+// Tail returns the number of the first stored item in the ancient store.
+func (api *serverAPI) Tail() (hexutil.Uint64, error) {
+	n, err := api.db.Tail()
+	return hexutil.Uint64(n), err
+}
+
+// AncientSize returns the size of the given ancient table.
+func (api *serverAPI) AncientSize(kind string) (hexutil.Uint64, error) {
+	n, err := api.db.AncientSize(kind)
+	return hexutil.Uint64(n), err
+}
+
+// ModifyAncients atomically appends the given items to the ancient store,
+// returning the size of the written data.
+func (api *serverAPI) ModifyAncients(ops []ancientOp) (hexutil.Uint64, error) {
+	size, err := api.db.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		for _, item := range ops {
+			if err := op.AppendRaw(item.Kind, uint64(item.Number), item.Item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return hexutil.Uint64(size), err
+}
+
+// TruncateHead discards all but the first n ancient items.
+func (api *serverAPI) TruncateHead(n hexutil.Uint64) error {
+	return api.db.TruncateHead(uint64(n))
+}
+
+// TruncateTail discards the first n ancient items.
+func (api *serverAPI) TruncateTail(n hexutil.Uint64) error {
+	return api.db.TruncateTail(uint64(n))
+}
+
+// Sync flushes the ancient store to disk.
+func (api *serverAPI) Sync() error {
+	return api.db.Sync()
+}
@@ -28,11 +28,14 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
 	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/consensus/progpow"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/eth/filters"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
 )
@@ -166,6 +169,10 @@ func (l *Log) Data(ctx context.Context) hexutil.Bytes {
 	return l.log.Data
 }
 
+func (l *Log) Removed(ctx context.Context) bool {
+	return l.log.Removed
+}
+
 // AccessTuple represents EIP-2930
 type AccessTuple struct {
 	address     common.Address
@@ -717,6 +724,47 @@ func (b *Block) MixHash(ctx context.Context) (common.Hash, error) {
 	return header.MixDigest, nil
 }
 
+func (b *Block) MixDigest(ctx context.Context) (common.Hash, error) {
+	return b.MixHash(ctx)
+}
+
+func (b *Block) Epoch(ctx context.Context) (Long, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return Long(progpow.Epoch(header.Number.Uint64())), nil
+}
+
+func (b *Block) SeedHash(ctx context.Context) (common.Hash, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(progpow.SeedHash(header.Number.Uint64())), nil
+}
+
+func (b *Block) Reward(ctx context.Context) (*BlockReward, error) {
+	if progpowEngine(b.r.backend) == nil {
+		return nil, nil
+	}
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	// The genesis block and blocks without difficulty are not mined, and credit
+	// no rewards
+	if block.NumberU64() == 0 || block.Difficulty().Sign() == 0 {
+		return nil, nil
+	}
+	config := b.r.backend.ChainConfig()
+	return &BlockReward{
+		config:  config,
+		uncles:  block.Uncles(),
+		rewards: progpow.BlockRewards(config, block.Header(), block.Uncles()),
+	}, nil
+}
+
 func (b *Block) TransactionsRoot(ctx context.Context) (common.Hash, error) {
 	header, err := b.resolveHeader(ctx)
 	if err != nil {
@@ -1311,6 +1359,145 @@ func (r *Resolver) ChainID(ctx context.Context) (hexutil.Big, error) {
 	return hexutil.Big(*r.backend.ChainConfig().ChainID), nil
 }
 
+func (r *Resolver) Mining(ctx context.Context) *Mining {
+	engine := progpowEngine(r.backend)
+	if engine == nil {
+		return nil
+	}
+	return &Mining{engine: engine}
+}
+
+// progpowEngine returns the progpow engine of the backend, or nil if the chain
+// is not mined with progpow.
+func progpowEngine(backend ethapi.Backend) *progpow.Progpow {
+	engine := backend.Engine()
+	if b, ok := engine.(*beacon.Beacon); ok {
+		engine = b.InnerEngine()
+	}
+	pp, _ := engine.(*progpow.Progpow)
+	return pp
+}
+
+// BlockReward represents the rewards credited by a block, as split by progpow.
+type BlockReward struct {
+	config  *params.ChainConfig
+	uncles  []*types.Header
+	rewards *progpow.Rewards
+}
+
+func (r *BlockReward) Total(ctx context.Context) hexutil.Big {
+	total := new(big.Int).Add(r.rewards.Miner, r.rewards.StakerFund)
+	total.Add(total, r.rewards.DevFund)
+	total.Add(total, r.rewards.CommunityFund)
+	for _, reward := range r.rewards.Uncles {
+		total.Add(total, reward)
+	}
+	return hexutil.Big(*total)
+}
+
+func (r *BlockReward) Miner(ctx context.Context) hexutil.Big {
+	return hexutil.Big(*r.rewards.Miner)
+}
+
+func (r *BlockReward) Ommers(ctx context.Context) []*OmmerReward {
+	ommers := make([]*OmmerReward, len(r.uncles))
+	for i, uncle := range r.uncles {
+		ommers[i] = &OmmerReward{miner: uncle.Coinbase, amount: r.rewards.Uncles[i]}
+	}
+	return ommers
+}
+
+func (r *BlockReward) Funds(ctx context.Context) []*FundReward {
+	// The fund shares are only credited if the chain configures the funds
+	if r.config.ProgPow == nil {
+		return []*FundReward{}
+	}
+	funds := []*FundReward{
+		{name: "staker", address: r.config.ProgPow.StakerFundAddress, amount: r.rewards.StakerFund},
+		{name: "dev", address: r.config.ProgPow.DevFundAddress, amount: r.rewards.DevFund},
+		{name: "community", address: r.config.ProgPow.CommunityFundAddress, amount: r.rewards.CommunityFund},
+	}
+	credited := funds[:0]
+	for _, fund := range funds {
+		if fund.amount.Sign() > 0 {
+			credited = append(credited, fund)
+		}
+	}
+	return credited
+}
+
+// OmmerReward represents the reward of the miner of an ommer block.
+type OmmerReward struct {
+	miner  common.Address
+	amount *big.Int
+}
+
+func (r *OmmerReward) Miner(ctx context.Context) common.Address {
+	return r.miner
+}
+
+func (r *OmmerReward) Amount(ctx context.Context) hexutil.Big {
+	return hexutil.Big(*r.amount)
+}
+
+// FundReward represents the share of a block reward credited to a fund.
+type FundReward struct {
+	name    string
+	address common.Address
+	amount  *big.Int
+}
+
+func (r *FundReward) Name(ctx context.Context) string {
+	return r.name
+}
+
+func (r *FundReward) Address(ctx context.Context) common.Address {
+	return r.address
+}
+
+func (r *FundReward) Amount(ctx context.Context) hexutil.Big {
+	return hexutil.Big(*r.amount)
+}
+
+// Mining represents the mining state of the node.
+type Mining struct {
+	engine *progpow.Progpow
+}
+
+func (m *Mining) Hashrate(ctx context.Context) Long {
+	return Long(m.engine.Hashrate())
+}
+
+func (m *Mining) Work(ctx context.Context) *Work {
+	work, err := m.engine.Work()
+	if err != nil {
+		return nil
+	}
+	return &Work{work: work}
+}
+
+// Work represents a work package for remote miners.
+type Work struct {
+	work [4]string
+}
+
+func (w *Work) HeaderHash(ctx context.Context) common.Hash {
+	return common.HexToHash(w.work[0])
+}
+
+func (w *Work) SeedHash(ctx context.Context) common.Hash {
+	return common.HexToHash(w.work[1])
+}
+
+func (w *Work) Target(ctx context.Context) common.Hash {
+	return common.HexToHash(w.work[2])
+}
+
+func (w *Work) Number(ctx context.Context) (Long, error) {
+	number, err := hexutil.DecodeUint64(w.work[3])
+	return Long(number), err
+}
+
 // SyncState represents the synchronisation status returned from the `syncing` accessor.
 type SyncState struct {
 	progress ethereum.SyncProgress
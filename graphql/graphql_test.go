@@ -17,6 +17,8 @@
 package graphql
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"math/big"
@@ -26,7 +28,9 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/consensus/progpow"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
@@ -35,6 +39,7 @@ import (
 	"github.com/ethereum/go-ethereum/eth/ethconfig"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/gorilla/websocket"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -50,7 +55,7 @@ func TestBuildSchema(t *testing.T) {
 	}
 	defer stack.Close()
 	// Make sure the schema can be parsed and matched up to the object model.
-	if err := newHandler(stack, nil, []string{}, []string{}); err != nil {
+	if err := newHandler(stack, nil, false, []string{}, []string{}); err != nil {
 		t.Errorf("Could not construct GraphQL handler: %v", err)
 	}
 }
@@ -195,6 +200,399 @@ func TestGraphQLBlockSerializationEIP2718(t *testing.T) {
 	}
 }
 
+// Tests the progpow specific fields of blocks and the mining state.
+func TestGraphQLProgpow(t *testing.T) {
+	stack := createNode(t, false, false)
+	defer stack.Close()
+
+	config := *params.AllEthashProtocolChanges
+	config.Ethash = nil
+	config.ProgPow = &params.ProgpowConfig{
+		DevFundAddress:       common.HexToAddress("0xde"),
+		CommunityFundAddress: common.HexToAddress("0xc0"),
+		StakerFundAddress:    common.HexToAddress("0x57"),
+	}
+	ethBackend, err := eth.New(stack, &ethconfig.Config{
+		Genesis: &core.Genesis{
+			Config:     &config,
+			GasLimit:   11500000,
+			Difficulty: big.NewInt(1048576),
+		},
+		Ethash:         ethash.Config{PowMode: ethash.ModeFake},
+		NetworkId:      1337,
+		TrieCleanCache: 5,
+		TrieDirtyCache: 5,
+		TrieTimeout:    60 * time.Minute,
+		SnapshotCache:  5,
+	})
+	if err != nil {
+		t.Fatalf("could not create eth backend: %v", err)
+	}
+	var (
+		miner      = common.HexToAddress("0x1234")
+		uncleMiner = common.HexToAddress("0x5678")
+		genesis    = ethBackend.BlockChain().Genesis()
+	)
+	chain, _ := core.GenerateChain(&config, genesis, progpow.NewFaker(), ethBackend.ChainDb(), 3, func(i int, gen *core.BlockGen) {
+		gen.SetCoinbase(miner)
+		if i == 2 {
+			gen.AddUncle(&types.Header{ParentHash: gen.PrevBlock(0).Hash(), Number: big.NewInt(2), Coinbase: uncleMiner})
+		}
+	})
+	if _, err := ethBackend.BlockChain().InsertChain(chain); err != nil {
+		t.Fatalf("could not import blocks: %v", err)
+	}
+	if err := New(stack, ethBackend.APIBackend, false, []string{}, []string{}); err != nil {
+		t.Fatalf("could not create graphql service: %v", err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("could not start node: %v", err)
+	}
+	query := func(body string, result interface{}) {
+		t.Helper()
+
+		resp, err := http.Post(fmt.Sprintf("%s/graphql", stack.HTTPEndpoint()), "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("could not post: %v", err)
+		}
+		defer resp.Body.Close()
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			t.Fatalf("could not decode response: %v", err)
+		}
+	}
+	// The genesis block credits no rewards
+	var genesisResult struct {
+		Data struct {
+			Block struct {
+				Reward *json.RawMessage `json:"reward"`
+			} `json:"block"`
+		} `json:"data"`
+	}
+	query(`{"query": "{block(number:0){reward{total}}}"}`, &genesisResult)
+	if genesisResult.Data.Block.Reward != nil {
+		t.Errorf("genesis reward mismatch: have %s, want null", *genesisResult.Data.Block.Reward)
+	}
+	// The rewards reported for the head block must match the balance changes of
+	// the credited accounts
+	var result struct {
+		Data struct {
+			Block struct {
+				Number    uint64      `json:"number"`
+				Epoch     uint64      `json:"epoch"`
+				SeedHash  common.Hash `json:"seedHash"`
+				MixDigest common.Hash `json:"mixDigest"`
+				Reward    struct {
+					Total  hexutil.Big `json:"total"`
+					Miner  hexutil.Big `json:"miner"`
+					Ommers []struct {
+						Miner  common.Address `json:"miner"`
+						Amount hexutil.Big    `json:"amount"`
+					} `json:"ommers"`
+					Funds []struct {
+						Name    string         `json:"name"`
+						Address common.Address `json:"address"`
+						Amount  hexutil.Big    `json:"amount"`
+					} `json:"funds"`
+				} `json:"reward"`
+			} `json:"block"`
+			Mining struct {
+				Hashrate uint64           `json:"hashrate"`
+				Work     *json.RawMessage `json:"work"`
+			} `json:"mining"`
+		} `json:"data"`
+	}
+	query(`{"query": "{block{number epoch seedHash mixDigest reward{total miner ommers{miner amount} funds{name address amount}}} mining{hashrate work{number}}}"}`, &result)
+
+	block := result.Data.Block
+	if block.Number != 3 || block.Epoch != 0 || block.SeedHash != (common.Hash{}) || block.MixDigest != chain[2].MixDigest() {
+		t.Errorf("block mismatch: have number %d, epoch %d, seed hash %x, mix digest %x", block.Number, block.Epoch, block.SeedHash, block.MixDigest)
+	}
+	if result.Data.Mining.Hashrate != 0 || result.Data.Mining.Work != nil {
+		t.Errorf("mining state mismatch: have hashrate %d, work %s", result.Data.Mining.Hashrate, *result.Data.Mining.Work)
+	}
+	parent, err := ethBackend.BlockChain().StateAt(chain[1].Root())
+	if err != nil {
+		t.Fatalf("could not open parent state: %v", err)
+	}
+	head, err := ethBackend.BlockChain().StateAt(chain[2].Root())
+	if err != nil {
+		t.Fatalf("could not open head state: %v", err)
+	}
+	credited := func(addr common.Address) *big.Int {
+		return new(big.Int).Sub(head.GetBalance(addr), parent.GetBalance(addr))
+	}
+	total := new(big.Int)
+	check := func(name string, addr common.Address, have hexutil.Big) {
+		t.Helper()
+
+		want := credited(addr)
+		if want.Sign() == 0 {
+			t.Errorf("%s reward: %x was not credited", name, addr)
+		}
+		if have.ToInt().Cmp(want) != 0 {
+			t.Errorf("%s reward mismatch: have %v, want %v", name, have.ToInt(), want)
+		}
+		total.Add(total, want)
+	}
+	check("miner", miner, block.Reward.Miner)
+
+	if len(block.Reward.Ommers) != 1 || block.Reward.Ommers[0].Miner != uncleMiner {
+		t.Fatalf("ommer rewards mismatch: have %v, want one for %x", block.Reward.Ommers, uncleMiner)
+	}
+	check("ommer", uncleMiner, block.Reward.Ommers[0].Amount)
+
+	funds := []struct {
+		name    string
+		address common.Address
+	}{
+		{"staker", config.ProgPow.StakerFundAddress},
+		{"dev", config.ProgPow.DevFundAddress},
+		{"community", config.ProgPow.CommunityFundAddress},
+	}
+	if len(block.Reward.Funds) != len(funds) {
+		t.Fatalf("fund rewards mismatch: have %d, want %d", len(block.Reward.Funds), len(funds))
+	}
+	for i, fund := range funds {
+		if have := block.Reward.Funds[i]; have.Name != fund.name || have.Address != fund.address {
+			t.Errorf("fund %d mismatch: have %s %x, want %s %x", i, have.Name, have.Address, fund.name, fund.address)
+		}
+		check(fund.name, fund.address, block.Reward.Funds[i].Amount)
+	}
+	if block.Reward.Total.ToInt().Cmp(total) != 0 {
+		t.Errorf("total reward mismatch: have %v, want %v", block.Reward.Total.ToInt(), total)
+	}
+}
+
+// Tests that queries and subscriptions are served over WebSocket connections.
+func TestGraphQLWebsocket(t *testing.T) {
+	stack := createNode(t, false, false)
+	defer stack.Close()
+	backend := createGQLService(t, stack)
+	if err := stack.Start(); err != nil {
+		t.Fatalf("could not start node: %v", err)
+	}
+	for _, protocol := range []*wsProtocol{wsProtocolTransport, wsProtocolLegacy} {
+		dialer := websocket.Dialer{Subprotocols: []string{protocol.name}}
+		conn, _, err := dialer.Dial(strings.Replace(stack.HTTPEndpoint(), "http", "ws", 1)+"/graphql", nil)
+		if err != nil {
+			t.Fatalf("%s: could not dial: %v", protocol.name, err)
+		}
+		expect := func(want string) {
+			t.Helper()
+			conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+			_, have, err := conn.ReadMessage()
+			if err != nil {
+				t.Fatalf("%s: could not read: %v", protocol.name, err)
+			}
+			if strings.TrimSpace(string(have)) != want {
+				t.Fatalf("%s: message mismatch: have %s, want %s", protocol.name, have, want)
+			}
+		}
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"connection_init"}`))
+		expect(`{"type":"connection_ack"}`)
+
+		// Queries yield a single result
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"id":"1","type":"`+protocol.start+`","payload":{"query":"{block{number}}"}}`))
+		expect(fmt.Sprintf(`{"id":"1","type":"%s","payload":{"data":{"block":{"number":%d}}}}`, protocol.next, backend.BlockChain().CurrentBlock().NumberU64()))
+		expect(`{"id":"1","type":"complete"}`)
+
+		// Invalid operations are rejected
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"id":"2","type":"`+protocol.start+`","payload":{"query":"subscription{unknown}"}}`))
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		if _, msg, err := conn.ReadMessage(); err != nil || !strings.HasPrefix(string(msg), `{"id":"2","type":"error"`) {
+			t.Fatalf("%s: invalid operation not rejected: %s, %v", protocol.name, msg, err)
+		}
+		if protocol == wsProtocolLegacy {
+			expect(`{"id":"2","type":"complete"}`)
+		}
+		// Subscriptions yield the new blocks
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"id":"3","type":"`+protocol.start+`","payload":{"query":"subscription{newBlocks{number}}"}}`))
+		time.Sleep(100 * time.Millisecond)
+
+		chain := backend.BlockChain()
+		blocks, _ := core.GenerateChain(params.AllEthashProtocolChanges, chain.CurrentBlock(), ethash.NewFaker(), backend.ChainDb(), 1, func(i int, gen *core.BlockGen) {})
+		if _, err := chain.InsertChain(blocks); err != nil {
+			t.Fatalf("could not import block: %v", err)
+		}
+		expect(fmt.Sprintf(`{"id":"3","type":"%s","payload":{"data":{"newBlocks":{"number":%d}}}}`, protocol.next, blocks[0].NumberU64()))
+
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"id":"3","type":"`+protocol.stop+`"}`))
+		conn.Close()
+	}
+}
+
+// Tests that the number of operations running on a WebSocket connection at once
+// is capped.
+func TestGraphQLWebsocketOperationLimit(t *testing.T) {
+	stack := createNode(t, false, false)
+	defer stack.Close()
+	backend := createGQLService(t, stack)
+	if err := stack.Start(); err != nil {
+		t.Fatalf("could not start node: %v", err)
+	}
+	dialer := websocket.Dialer{Subprotocols: []string{wsProtocolTransport.name}}
+	conn, _, err := dialer.Dial(strings.Replace(stack.HTTPEndpoint(), "http", "ws", 1)+"/graphql", nil)
+	if err != nil {
+		t.Fatalf("could not dial: %v", err)
+	}
+	defer conn.Close()
+
+	read := func() string {
+		t.Helper()
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("could not read: %v", err)
+		}
+		return strings.TrimSpace(string(msg))
+	}
+	conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"connection_init"}`))
+	if msg := read(); msg != `{"type":"connection_ack"}` {
+		t.Fatalf("unexpected message: %s", msg)
+	}
+	for i := 0; i < wsMaxOperations; i++ {
+		conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(`{"id":"%d","type":"subscribe","payload":{"query":"subscription{newBlocks{number}}"}}`, i)))
+	}
+	conn.WriteMessage(websocket.TextMessage, []byte(`{"id":"over","type":"subscribe","payload":{"query":"{block{number}}"}}`))
+	if msg := read(); !strings.HasPrefix(msg, `{"id":"over","type":"error"`) {
+		t.Fatalf("operation over the limit not rejected: %s", msg)
+	}
+	// Stopping an operation makes room for another one
+	conn.WriteMessage(websocket.TextMessage, []byte(`{"id":"0","type":"complete"}`))
+	conn.WriteMessage(websocket.TextMessage, []byte(`{"id":"query","type":"subscribe","payload":{"query":"{block{number}}"}}`))
+	if have, want := read(), fmt.Sprintf(`{"id":"query","type":"next","payload":{"data":{"block":{"number":%d}}}}`, backend.BlockChain().CurrentBlock().NumberU64()); have != want {
+		t.Fatalf("message mismatch: have %s, want %s", have, want)
+	}
+}
+
+// Tests that log subscriptions yield the logs of new blocks, and yield them again
+// as removed when a reorg drops their block.
+func TestGraphQLWebsocketLogs(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		emitter = common.HexToAddress("0x00000000000000000000000000000000000000e7")
+	)
+	stack := createNode(t, false, false)
+	defer stack.Close()
+	ethConf := &ethconfig.Config{
+		Genesis: &core.Genesis{
+			Config:   params.AllEthashProtocolChanges,
+			GasLimit: 11500000,
+			Alloc: core.GenesisAlloc{
+				address: {Balance: big.NewInt(1000000000000000)},
+				// The address 0xe7 emits an empty log
+				emitter: {Code: []byte{byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.LOG0)}, Balance: big.NewInt(0)},
+			},
+			Difficulty: big.NewInt(1048576),
+			BaseFee:    big.NewInt(params.InitialBaseFee),
+		},
+		Ethash:         ethash.Config{PowMode: ethash.ModeFake},
+		NetworkId:      1337,
+		TrieDirtyCache: 5,
+		SnapshotCache:  5,
+	}
+	backend, err := eth.New(stack, ethConf)
+	if err != nil {
+		t.Fatalf("could not create eth backend: %v", err)
+	}
+	if err := New(stack, backend.APIBackend, false, []string{}, []string{}); err != nil {
+		t.Fatalf("could not create graphql service: %v", err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("could not start node: %v", err)
+	}
+	conn, _, err := websocket.DefaultDialer.Dial(strings.Replace(stack.HTTPEndpoint(), "http", "ws", 1)+"/graphql", nil)
+	if err != nil {
+		t.Fatalf("could not dial: %v", err)
+	}
+	defer conn.Close()
+
+	expect := func(want string) {
+		t.Helper()
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		_, have, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("could not read: %v", err)
+		}
+		if strings.TrimSpace(string(have)) != want {
+			t.Fatalf("message mismatch: have %s, want %s", have, want)
+		}
+	}
+	conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"connection_init"}`))
+	expect(`{"type":"connection_ack"}`)
+	conn.WriteMessage(websocket.TextMessage, []byte(`{"id":"1","type":"start","payload":{"query":"subscription{logs(filter:{}){transaction{hash} removed}}"}}`))
+	time.Sleep(100 * time.Millisecond)
+
+	var (
+		chain  = backend.BlockChain()
+		signer = types.LatestSigner(ethConf.Genesis.Config)
+	)
+	tx, _ := types.SignNewTx(key, signer, &types.LegacyTx{
+		To:       &emitter,
+		Gas:      50000,
+		GasPrice: big.NewInt(params.InitialBaseFee),
+	})
+	blocks, _ := core.GenerateChain(ethConf.Genesis.Config, chain.Genesis(), ethash.NewFaker(), backend.ChainDb(), 1, func(i int, gen *core.BlockGen) {
+		gen.AddTx(tx)
+	})
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("could not import block: %v", err)
+	}
+	expect(fmt.Sprintf(`{"id":"1","type":"data","payload":{"data":{"logs":{"transaction":{"hash":"%s"},"removed":false}}}}`, tx.Hash().Hex()))
+
+	// Reorg the block away with a longer chain
+	forks, _ := core.GenerateChain(ethConf.Genesis.Config, chain.Genesis(), ethash.NewFaker(), backend.ChainDb(), 2, func(i int, gen *core.BlockGen) {
+		gen.SetCoinbase(common.Address{1})
+	})
+	if _, err := chain.InsertChain(forks); err != nil {
+		t.Fatalf("could not import fork: %v", err)
+	}
+	expect(fmt.Sprintf(`{"id":"1","type":"data","payload":{"data":{"logs":{"transaction":{"hash":"%s"},"removed":true}}}}`, tx.Hash().Hex()))
+}
+
+// Tests that subscribers not keeping up with the events are dropped instead of
+// holding up the event system.
+func TestGraphQLSubscriptionSlowSubscriber(t *testing.T) {
+	stack := createNode(t, false, false)
+	defer stack.Close()
+	backend := createGQLService(t, stack)
+	if err := stack.Start(); err != nil {
+		t.Fatalf("could not start node: %v", err)
+	}
+	subs := &subscriptionResolver{r: &Resolver{backend.APIBackend}}
+	blocks, err := subs.NewBlocks(context.Background())
+	if err != nil {
+		t.Fatalf("could not subscribe: %v", err)
+	}
+	chain := backend.BlockChain()
+	forks, _ := core.GenerateChain(params.AllEthashProtocolChanges, chain.CurrentBlock(), ethash.NewFaker(), backend.ChainDb(), subscriptionBuffer+10, func(i int, gen *core.BlockGen) {})
+
+	// Import the blocks one by one, each announcing a new head
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, block := range forks {
+			if _, err := chain.InsertChain(types.Blocks{block}); err != nil {
+				t.Errorf("could not import block: %v", err)
+				return
+			}
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("block import held up by a slow subscriber")
+	}
+	var count int
+	for range blocks {
+		count++
+	}
+	if count != subscriptionBuffer {
+		t.Errorf("delivered blocks mismatch: have %d, want %d", count, subscriptionBuffer)
+	}
+}
+
 // Tests that a graphQL request is not handled successfully when graphql is not enabled on the specified endpoint
 func TestGraphQLHTTPOnSamePort_GQLRequest_Unsuccessful(t *testing.T) {
 	stack := createNode(t, false, false)
@@ -232,7 +630,7 @@ func createNode(t *testing.T, gqlEnabled bool, txEnabled bool) *node.Node {
 	return stack
 }
 
-func createGQLService(t *testing.T, stack *node.Node) {
+func createGQLService(t *testing.T, stack *node.Node) *eth.Ethereum {
 	// create backend
 	ethConf := &ethconfig.Config{
 		Genesis: &core.Genesis{
@@ -263,10 +661,11 @@ func createGQLService(t *testing.T, stack *node.Node) {
 		t.Fatalf("could not create import blocks: %v", err)
 	}
 	// create gql service
-	err = New(stack, ethBackend.APIBackend, []string{}, []string{})
+	err = New(stack, ethBackend.APIBackend, false, []string{}, []string{})
 	if err != nil {
 		t.Fatalf("could not create graphql service: %v", err)
 	}
+	return ethBackend
 }
 
 func createGQLServiceWithTransactions(t *testing.T, stack *node.Node) {
@@ -348,7 +747,7 @@ func createGQLServiceWithTransactions(t *testing.T, stack *node.Node) {
 		t.Fatalf("could not create import blocks: %v", err)
 	}
 	// create gql service
-	err = New(stack, ethBackend.APIBackend, []string{}, []string{})
+	err = New(stack, ethBackend.APIBackend, false, []string{}, []string{})
 	if err != nil {
 		t.Fatalf("could not create graphql service: %v", err)
 	}
@@ -16,6 +16,16 @@
 
 package graphql
 
+// The schema is served as two schemas with different root operation types, as
+// graphql-go resolves all the root types of a schema with a single resolver and
+// the Query and Subscription types both have a logs field. Subscriptions are
+// only ever run on the subscription schema, whose mandatory query root is never
+// used.
+const (
+	querySchema        = schema + `schema { query: Query mutation: Mutation }`
+	subscriptionSchema = schema + `schema { query: Subscription subscription: Subscription }`
+)
+
 const schema string = `
     # Bytes32 is a 32 byte binary string, represented as 0x-prefixed hexadecimal.
     scalar Bytes32
@@ -31,11 +41,6 @@ const schema string = `
     # Long is a 64 bit unsigned integer.
     scalar Long
 
-    schema {
-        query: Query
-        mutation: Mutation
-    }
-
     # Account is an Ethereum account at a particular block.
     type Account {
         # Address is the address owning the account.
@@ -67,6 +72,9 @@ const schema string = `
         data: Bytes!
         # Transaction is the transaction that generated this log entry.
         transaction: Transaction!
+        # Removed is true if the log was dropped from the canonical chain by a
+        # reorganisation. Only logs yielded by subscriptions may be removed.
+        removed: Boolean!
     }
 
     #EIP-2718
@@ -169,6 +177,38 @@ const schema string = `
         topics: [[Bytes32!]!]
     }
 
+    # BlockReward is the breakdown of the rewards credited by a block.
+    type BlockReward {
+        # Total is the sum of all the rewards credited by the block.
+        total: BigInt!
+        # Miner is the reward of the block miner, including the rewards for
+        # including ommers.
+        miner: BigInt!
+        # Ommers are the rewards of the ommer (AKA uncle) miners, in the order
+        # of the ommers of the block.
+        ommers: [OmmerReward!]!
+        # Funds are the shares of the block reward credited to the funds.
+        funds: [FundReward!]!
+    }
+
+    # OmmerReward is the reward of the miner of an ommer (AKA uncle) block.
+    type OmmerReward {
+        # Miner is the address credited with the reward.
+        miner: Address!
+        # Amount is the reward credited.
+        amount: BigInt!
+    }
+
+    # FundReward is the share of a block reward credited to a fund.
+    type FundReward {
+        # Name is the name of the fund, one of "staker", "dev" and "community".
+        name: String!
+        # Address is the address of the fund.
+        address: Address!
+        # Amount is the share credited.
+        amount: BigInt!
+    }
+
     # Block is an Ethereum block.
     type Block {
         # Number is the number of this block, starting at 0 for the genesis block.
@@ -207,6 +247,17 @@ const schema string = `
         logsBloom: Bytes!
         # MixHash is the hash that was used as an input to the PoW process.
         mixHash: Bytes32!
+        # MixDigest is the progpow mix digest proving the work of this block, the
+        # same as mixHash.
+        mixDigest: Bytes32!
+        # Epoch is the progpow epoch of this block.
+        epoch: Long!
+        # SeedHash is the seed of the progpow verification cache and mining
+        # dataset of this block.
+        seedHash: Bytes32!
+        # Reward is the breakdown of the rewards credited by this block, or null
+        # if the block was not mined with progpow or is the genesis block.
+        reward: BlockReward
         # Difficulty is a measure of the difficulty of mining this block.
         difficulty: BigInt!
         # TotalDifficulty is the sum of all difficulty values up to and including
@@ -314,6 +365,28 @@ const schema string = `
         highestBlock: Long!
     }
 
+    # Mining describes the progpow mining state of the node.
+    type Mining {
+        # Hashrate is the combined hash rate of the local and remote miners,
+        # in hashes per second.
+        hashrate: Long!
+        # Work is the current work package for remote miners, or null if the
+        # node has none.
+        work: Work
+    }
+
+    # Work is a work package for remote miners.
+    type Work {
+        # HeaderHash is the progpow hash of the header to mine.
+        headerHash: Bytes32!
+        # SeedHash is the seed of the progpow mining dataset.
+        seedHash: Bytes32!
+        # Target is the boundary condition to meet, 2^256/difficulty.
+        target: Bytes32!
+        # Number is the number of the block to mine.
+        number: Long!
+    }
+
     # Pending represents the current pending state.
     type Pending {
       # TransactionCount is the number of transactions in the pending state.
@@ -352,10 +425,24 @@ const schema string = `
         syncing: SyncState
         # ChainID returns the current chain ID for transaction replay protection.
         chainID: BigInt!
+        # Mining returns the progpow mining state of the node, or null if the
+        # chain is not mined with progpow.
+        mining: Mining
     }
 
     type Mutation {
         # SendRawTransaction sends an RLP-encoded transaction to the network.
         sendRawTransaction(data: Bytes!): Bytes32!
     }
+
+    type Subscription {
+        # NewBlocks yields the blocks added to the head of the canonical chain.
+        newBlocks: Block!
+        # Logs yields the log entries matching the provided filter as they are
+        # included in new blocks, and again with removed set if a chain
+        # reorganisation drops their block.
+        logs(filter: BlockFilterCriteria!): Log!
+        # PendingTransactions yields the transactions entering the transaction pool.
+        pendingTransactions: Transaction!
+    }
 `
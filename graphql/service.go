@@ -22,14 +22,22 @@ import (
 
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/node"
+	"github.com/gorilla/websocket"
 	"github.com/graph-gophers/graphql-go"
 )
 
 type handler struct {
 	Schema *graphql.Schema
+
+	subscriptions *graphql.Schema // Serves the subscriptions over WebSocket connections
+	upgrader      *websocket.Upgrader
 }
 
-func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if websocket.IsWebSocketUpgrade(r) {
+		h.serveWebsocket(w, r)
+		return
+	}
 	var params struct {
 		Query         string                 `json:"query"`
 		OperationName string                 `json:"operationName"`
@@ -54,21 +62,27 @@ func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	w.Write(responseJSON)
 }
 
-// New constructs a new GraphQL service instance.
-func New(stack *node.Node, backend ethapi.Backend, cors, vhosts []string) error {
-	return newHandler(stack, backend, cors, vhosts)
+// New constructs a new GraphQL service instance. Subscriptions are fed by an
+// event system in light mode if lightMode is set.
+func New(stack *node.Node, backend ethapi.Backend, lightMode bool, cors, vhosts []string) error {
+	return newHandler(stack, backend, lightMode, cors, vhosts)
 }
 
-// newHandler returns a new `http.Handler` that will answer GraphQL queries.
-// It additionally exports an interactive query browser on the / endpoint.
-func newHandler(stack *node.Node, backend ethapi.Backend, cors, vhosts []string) error {
+// newHandler returns a new `http.Handler` that will answer GraphQL queries, and
+// serve subscriptions over WebSocket connections. It additionally exports an
+// interactive query browser on the / endpoint.
+func newHandler(stack *node.Node, backend ethapi.Backend, lightMode bool, cors, vhosts []string) error {
 	q := Resolver{backend}
 
-	s, err := graphql.ParseSchema(schema, &q)
+	s, err := graphql.ParseSchema(querySchema, &q)
+	if err != nil {
+		return err
+	}
+	subs, err := graphql.ParseSchema(subscriptionSchema, &subscriptionResolver{r: &q, lightMode: lightMode})
 	if err != nil {
 		return err
 	}
-	h := handler{Schema: s}
+	h := &handler{Schema: s, subscriptions: subs, upgrader: newWSUpgrader(cors)}
 	handler := node.NewHTTPHandlerStack(h, cors, vhosts, nil)
 
 	stack.RegisterHandler("GraphQL UI", "/graphql/ui", GraphiQL{})
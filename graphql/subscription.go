@@ -0,0 +1,157 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/filters"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// subscriptionBuffer is the number of results buffered for each subscription.
+// Subscribers falling further behind are dropped rather than holding up the
+// event system shared by all the subscriptions.
+const subscriptionBuffer = 256
+
+// subscriptionResolver is the root resolver of the subscription schema.
+type subscriptionResolver struct {
+	r         *Resolver
+	lightMode bool
+
+	eventsOnce sync.Once
+	events     *filters.EventSystem // Created on the first subscription
+}
+
+// eventSystem returns the event system feeding the subscriptions.
+func (s *subscriptionResolver) eventSystem() *filters.EventSystem {
+	s.eventsOnce.Do(func() {
+		s.events = filters.NewEventSystem(s.r.backend, s.lightMode)
+	})
+	return s.events
+}
+
+func (s *subscriptionResolver) NewBlocks(ctx context.Context) (<-chan *Block, error) {
+	var (
+		headers = make(chan *types.Header)
+		sub     = s.eventSystem().SubscribeNewHeads(headers)
+		blocks  = make(chan *Block, subscriptionBuffer)
+	)
+	go func() {
+		defer close(blocks)
+		defer sub.Unsubscribe()
+
+		for {
+			select {
+			case header := <-headers:
+				numberOrHash := rpc.BlockNumberOrHashWithHash(header.Hash(), false)
+				block := &Block{
+					r:            s.r,
+					numberOrHash: &numberOrHash,
+					hash:         header.Hash(),
+					header:       header,
+				}
+				select {
+				case blocks <- block:
+				default:
+					log.Debug("Dropping slow GraphQL subscriber", "subscription", "newBlocks")
+					return
+				}
+			case <-sub.Err():
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return blocks, nil
+}
+
+func (s *subscriptionResolver) Logs(ctx context.Context, args struct{ Filter BlockFilterCriteria }) (<-chan *Log, error) {
+	var crit ethereum.FilterQuery
+	if args.Filter.Addresses != nil {
+		crit.Addresses = *args.Filter.Addresses
+	}
+	if args.Filter.Topics != nil {
+		crit.Topics = *args.Filter.Topics
+	}
+	matches := make(chan []*types.Log)
+	sub, err := s.eventSystem().SubscribeLogs(crit, matches)
+	if err != nil {
+		return nil, err
+	}
+	logs := make(chan *Log, subscriptionBuffer)
+	go func() {
+		defer close(logs)
+		defer sub.Unsubscribe()
+
+		for {
+			select {
+			case found := <-matches:
+				for _, entry := range found {
+					select {
+					case logs <- &Log{r: s.r, transaction: &Transaction{r: s.r, hash: entry.TxHash}, log: entry}:
+					default:
+						log.Debug("Dropping slow GraphQL subscriber", "subscription", "logs")
+						return
+					}
+				}
+			case <-sub.Err():
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return logs, nil
+}
+
+func (s *subscriptionResolver) PendingTransactions(ctx context.Context) (<-chan *Transaction, error) {
+	var (
+		hashes = make(chan []common.Hash)
+		sub    = s.eventSystem().SubscribePendingTxs(hashes)
+		txs    = make(chan *Transaction, subscriptionBuffer)
+	)
+	go func() {
+		defer close(txs)
+		defer sub.Unsubscribe()
+
+		for {
+			select {
+			case found := <-hashes:
+				for _, hash := range found {
+					select {
+					case txs <- &Transaction{r: s.r, hash: hash}:
+					default:
+						log.Debug("Dropping slow GraphQL subscriber", "subscription", "pendingTransactions")
+						return
+					}
+				}
+			case <-sub.Err():
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return txs, nil
+}
@@ -0,0 +1,351 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/gorilla/websocket"
+	"github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+)
+
+const (
+	wsInitTimeout    = 10 * time.Second // Time allowed for the client to initialise the connection
+	wsKeepAlive      = 30 * time.Second // Interval of the keep-alive messages sent to the client
+	wsWriteTimeout   = 10 * time.Second
+	wsMaxMessageSize = 1024 * 1024
+	wsMaxOperations  = 100 // Maximum number of operations running on a connection at once
+)
+
+// errSubscriptionOp is the message of the error graphql-go answers subscriptions
+// executed as queries with.
+const errSubscriptionOp = "graphql-ws protocol header is missing"
+
+var (
+	errOperationExists = errors.New("operation already exists")
+	errTooManyOps      = errors.New("too many operations")
+)
+
+// wsProtocol holds the message types of a GraphQL over WebSocket protocol. Both
+// the graphql-ws protocol of subscriptions-transport-ws and its successor, the
+// graphql-transport-ws protocol of the graphql-ws library are supported.
+type wsProtocol struct {
+	name      string
+	start     string // Client message starting an operation
+	stop      string // Client message stopping an operation
+	next      string // Server message carrying a result of an operation
+	keepAlive string // Server message keeping the connection alive
+}
+
+var (
+	wsProtocolLegacy    = &wsProtocol{name: "graphql-ws", start: "start", stop: "stop", next: "data", keepAlive: "ka"}
+	wsProtocolTransport = &wsProtocol{name: "graphql-transport-ws", start: "subscribe", stop: "complete", next: "next", keepAlive: "ping"}
+)
+
+// wsMessage is a message of the GraphQL over WebSocket protocols.
+type wsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// wsOperation is the payload of a message starting an operation.
+type wsOperation struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// wsConn is a GraphQL over WebSocket connection.
+type wsConn struct {
+	handler  *handler
+	conn     *websocket.Conn
+	protocol *wsProtocol
+
+	writeLock sync.Mutex
+
+	opsLock sync.Mutex
+	ops     map[string]context.CancelFunc // Cancel functions of the running operations
+	opsWG   sync.WaitGroup
+}
+
+// newWSUpgrader creates the upgrader of GraphQL over WebSocket connections,
+// accepting the origins allowed by CORS.
+func newWSUpgrader(cors []string) *websocket.Upgrader {
+	return &websocket.Upgrader{
+		Subprotocols: []string{wsProtocolTransport.name, wsProtocolLegacy.name},
+		CheckOrigin: func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				return true
+			}
+			for _, allowed := range cors {
+				if allowed == "*" || strings.EqualFold(allowed, origin) {
+					return true
+				}
+			}
+			// Without CORS, only same origin requests are allowed
+			u, err := url.Parse(origin)
+			return err == nil && strings.EqualFold(u.Host, r.Host)
+		},
+	}
+}
+
+// serveWebsocket upgrades the request to a GraphQL over WebSocket connection and
+// serves operations on it until it is closed.
+func (h *handler) serveWebsocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Debug("GraphQL WebSocket upgrade failed", "err", err)
+		return
+	}
+	c := &wsConn{
+		handler:  h,
+		conn:     conn,
+		protocol: wsProtocolLegacy,
+		ops:      make(map[string]context.CancelFunc),
+	}
+	if conn.Subprotocol() == wsProtocolTransport.name {
+		c.protocol = wsProtocolTransport
+	}
+	c.serve(r.Context())
+}
+
+// serve reads the client messages until the connection fails.
+func (c *wsConn) serve(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer func() {
+		cancel()
+		c.opsWG.Wait()
+		c.conn.Close()
+	}()
+	c.conn.SetReadLimit(wsMaxMessageSize)
+
+	// Wait for the client to initialise the connection
+	c.conn.SetReadDeadline(time.Now().Add(wsInitTimeout))
+	var msg wsMessage
+	if err := c.conn.ReadJSON(&msg); err != nil || msg.Type != "connection_init" {
+		c.close(4408, "Connection initialisation timeout")
+		return
+	}
+	c.conn.SetReadDeadline(time.Time{})
+	if err := c.write(&wsMessage{Type: "connection_ack"}); err != nil {
+		return
+	}
+	go c.keepAlive(ctx)
+
+	for {
+		var msg wsMessage
+		if err := c.conn.ReadJSON(&msg); err != nil {
+			return
+		}
+		switch msg.Type {
+		case c.protocol.start:
+			var op wsOperation
+			if err := json.Unmarshal(msg.Payload, &op); err != nil || msg.ID == "" {
+				c.close(4400, "Invalid message")
+				return
+			}
+			switch err := c.start(ctx, msg.ID, &op); err {
+			case errOperationExists:
+				c.close(4409, "Subscriber for "+msg.ID+" already exists")
+				return
+			case errTooManyOps:
+				c.reject(msg.ID, err)
+			}
+		case c.protocol.stop:
+			c.stop(msg.ID)
+		case "ping":
+			c.write(&wsMessage{Type: "pong"})
+		case "pong":
+		case "connection_terminate":
+			return
+		default:
+			c.close(4400, "Unknown message type "+msg.Type)
+			return
+		}
+	}
+}
+
+// start runs an operation, sending its results to the client. It fails if an
+// operation with the same id is already running, or too many are.
+func (c *wsConn) start(ctx context.Context, id string, op *wsOperation) error {
+	c.opsLock.Lock()
+	defer c.opsLock.Unlock()
+
+	if _, ok := c.ops[id]; ok {
+		return errOperationExists
+	}
+	if len(c.ops) >= wsMaxOperations {
+		return errTooManyOps
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	c.ops[id] = cancel
+
+	c.opsWG.Add(1)
+	go func() {
+		defer c.opsWG.Done()
+
+		var (
+			responses = c.run(ctx, op)
+			first     = true
+			failed    bool
+		)
+		for resp := range responses {
+			resp := resp.(*graphql.Response)
+			failed = first && resp.Data == nil && len(resp.Errors) > 0
+			if err := c.send(id, resp, failed); err != nil {
+				break
+			}
+			first = false
+		}
+		// The graphql-transport-ws protocol ends operations with errors
+		// without completing them
+		if c.finish(id) && !(failed && c.protocol == wsProtocolTransport) {
+			c.write(&wsMessage{ID: id, Type: "complete"})
+		}
+		// Release the operation if sending failed
+		for range responses {
+		}
+	}()
+	return nil
+}
+
+// reject reports an operation which could not be started to the client.
+func (c *wsConn) reject(id string, err error) {
+	c.send(id, &graphql.Response{Errors: []*gqlerrors.QueryError{gqlerrors.Errorf("%s", err)}}, true)
+	if c.protocol == wsProtocolLegacy {
+		c.write(&wsMessage{ID: id, Type: "complete"})
+	}
+}
+
+// run executes an operation. Queries and mutations are executed by the main
+// schema and yield a single result. The main schema rejects subscriptions
+// without running them, these are run on the subscription schema instead.
+func (c *wsConn) run(ctx context.Context, op *wsOperation) <-chan interface{} {
+	resp := c.handler.Schema.Exec(ctx, op.Query, op.OperationName, op.Variables)
+	if !isSubscription(resp) {
+		result := make(chan interface{}, 1)
+		result <- resp
+		close(result)
+		return result
+	}
+	responses, err := c.handler.subscriptions.Subscribe(ctx, op.Query, op.OperationName, op.Variables)
+	if err != nil {
+		failed := make(chan interface{}, 1)
+		failed <- &graphql.Response{Errors: []*gqlerrors.QueryError{gqlerrors.Errorf("%s", err)}}
+		close(failed)
+		return failed
+	}
+	return responses
+}
+
+// isSubscription reports whether a response of the main schema is the rejection
+// of a subscription, which graphql-go only reports by its message.
+func isSubscription(resp *graphql.Response) bool {
+	return resp.Data == nil && len(resp.Errors) == 1 && resp.Errors[0].Message == errSubscriptionOp
+}
+
+// send delivers a result of an operation to the client. Errors preventing the
+// operation from running are reported as error messages.
+func (c *wsConn) send(id string, resp *graphql.Response, failed bool) error {
+	if failed {
+		var payload interface{} = resp.Errors
+		if c.protocol == wsProtocolLegacy {
+			payload = resp.Errors[0]
+		}
+		blob, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		return c.write(&wsMessage{ID: id, Type: "error", Payload: blob})
+	}
+	blob, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	return c.write(&wsMessage{ID: id, Type: c.protocol.next, Payload: blob})
+}
+
+// stop cancels an operation on request of the client.
+func (c *wsConn) stop(id string) {
+	c.opsLock.Lock()
+	defer c.opsLock.Unlock()
+
+	if cancel, ok := c.ops[id]; ok {
+		cancel()
+		delete(c.ops, id)
+	}
+}
+
+// finish removes a completed operation, reporting whether it was still running,
+// i.e. not stopped by the client.
+func (c *wsConn) finish(id string) bool {
+	c.opsLock.Lock()
+	defer c.opsLock.Unlock()
+
+	cancel, ok := c.ops[id]
+	if ok {
+		cancel()
+		delete(c.ops, id)
+	}
+	return ok
+}
+
+// keepAlive periodically sends keep-alive messages until ctx is canceled.
+func (c *wsConn) keepAlive(ctx context.Context) {
+	ticker := time.NewTicker(wsKeepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.write(&wsMessage{Type: c.protocol.keepAlive}); err != nil {
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// write sends a message to the client.
+func (c *wsConn) write(msg *wsMessage) error {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+
+	c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	return c.conn.WriteJSON(msg)
+}
+
+// close closes the connection with the given close code and reason.
+func (c *wsConn) close(code int, reason string) {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+
+	deadline := time.Now().Add(wsWriteTimeout)
+	c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), deadline)
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package era
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// headerSize is the size of the type-length header preceding each entry.
+const headerSize = 8
+
+// entry is a single type-length-value record of an e2store file. The header
+// holds the type as a little endian uint16, the value length as a little endian
+// uint32 and two reserved zero bytes.
+type entry struct {
+	Type  uint16
+	Value []byte
+}
+
+// entryWriter writes e2store entries into the wrapped writer.
+type entryWriter struct {
+	w io.Writer
+}
+
+// Write writes a single entry, returning the number of bytes written.
+func (w *entryWriter) Write(typ uint16, value []byte) (int, error) {
+	var header [headerSize]byte
+	binary.LittleEndian.PutUint16(header[0:], typ)
+	binary.LittleEndian.PutUint32(header[2:], uint32(len(value)))
+	if n, err := w.w.Write(header[:]); err != nil {
+		return n, err
+	}
+	n, err := w.w.Write(value)
+	return headerSize + n, err
+}
+
+// readEntry reads the entry starting at the given offset of a file of the given
+// size, returning it along with its total size. The length in the header is
+// checked against the remainder of the file before allocating the value.
+func readEntry(r io.ReaderAt, off int64, size int64) (*entry, int64, error) {
+	var header [headerSize]byte
+	if _, err := r.ReadAt(header[:], off); err != nil {
+		return nil, 0, err
+	}
+	if header[6] != 0 || header[7] != 0 {
+		return nil, 0, errors.New("reserved bytes are non-zero")
+	}
+	length := int64(binary.LittleEndian.Uint32(header[2:]))
+	if length > size-off-headerSize {
+		return nil, 0, fmt.Errorf("entry at offset %d exceeds the file: %d bytes, %d left", off, length, size-off-headerSize)
+	}
+	e := &entry{
+		Type:  binary.LittleEndian.Uint16(header[0:]),
+		Value: make([]byte, length),
+	}
+	if _, err := r.ReadAt(e.Value, off+headerSize); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, 0, fmt.Errorf("truncated entry at offset %d: %w", off, err)
+	}
+	return e, headerSize + int64(len(e.Value)), nil
+}
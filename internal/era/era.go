@@ -0,0 +1,465 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package era implements a self-describing archive format for ranges of chain
+// history, modeled after the e2store based era files.
+//
+// An archive file is a sequence of type-length-value entries:
+//
+//	Version | [CompressedHeader | CompressedBody | CompressedReceipts | TotalDifficulty]* |
+//	Accumulator | BlockIndex | Checksum
+//
+// Headers, bodies and receipts are RLP encoded and snappy framed, the total
+// difficulty is a 32 byte big endian integer. The accumulator is the keccak256
+// hash of the concatenated block hashes and total difficulties, the block index
+// holds the number of the first block, the file offset of every block and the
+// block count, all as little endian uint64s. The checksum is the keccak256 hash
+// of all the preceding bytes of the file.
+package era
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/golang/snappy"
+)
+
+const (
+	typeVersion            uint16 = 0x3265
+	typeCompressedHeader   uint16 = 0x03
+	typeCompressedBody     uint16 = 0x04
+	typeCompressedReceipts uint16 = 0x05
+	typeTotalDifficulty    uint16 = 0x06
+	typeAccumulator        uint16 = 0x07
+	typeChecksum           uint16 = 0x08
+	typeBlockIndex         uint16 = 0x3266
+
+	// MaxSize is the number of blocks in a full archive file, each file holds
+	// the blocks of a single epoch of this size.
+	MaxSize = 8192
+
+	// trailerSize is the size of the checksum entry closing the file.
+	trailerSize = headerSize + common.HashLength
+)
+
+// Filename returns the name of the archive file holding the given epoch of the
+// network, ending with the block of the given hash.
+func Filename(network string, epoch uint64, last common.Hash) string {
+	return fmt.Sprintf("%s-%05d-%x.era1", network, epoch, last[:4])
+}
+
+// Builder writes blocks into an archive file.
+type Builder struct {
+	raw      io.Writer
+	w        *entryWriter
+	checksum crypto.KeccakState
+	acc      crypto.KeccakState
+
+	start   uint64
+	offsets []uint64
+	written uint64
+}
+
+// NewBuilder creates a builder writing an archive file into w.
+func NewBuilder(w io.Writer) *Builder {
+	checksum := crypto.NewKeccakState()
+	return &Builder{
+		raw:      w,
+		w:        &entryWriter{w: io.MultiWriter(w, checksum)},
+		checksum: checksum,
+		acc:      crypto.NewKeccakState(),
+	}
+}
+
+// Add appends a block with its receipts and total difficulty to the archive.
+// Blocks must be added in order, without gaps.
+func (b *Builder) Add(block *types.Block, receipts types.Receipts, td *big.Int) error {
+	if len(b.offsets) == 0 {
+		if err := b.write(typeVersion, nil); err != nil {
+			return err
+		}
+		b.start = block.NumberU64()
+	}
+	if len(b.offsets) == MaxSize {
+		return fmt.Errorf("archive full, holding %d blocks", MaxSize)
+	}
+	if want := b.start + uint64(len(b.offsets)); block.NumberU64() != want {
+		return fmt.Errorf("non-contiguous block #%d, want #%d", block.NumberU64(), want)
+	}
+	header, err := rlp.EncodeToBytes(block.Header())
+	if err != nil {
+		return err
+	}
+	body, err := rlp.EncodeToBytes(block.Body())
+	if err != nil {
+		return err
+	}
+	receiptsBlob, err := rlp.EncodeToBytes(receipts)
+	if err != nil {
+		return err
+	}
+	b.offsets = append(b.offsets, b.written)
+	for _, item := range []struct {
+		typ  uint16
+		blob []byte
+	}{
+		{typeCompressedHeader, header},
+		{typeCompressedBody, body},
+		{typeCompressedReceipts, receiptsBlob},
+	} {
+		compressed, err := compress(item.blob)
+		if err != nil {
+			return err
+		}
+		if err := b.write(item.typ, compressed); err != nil {
+			return err
+		}
+	}
+	difficulty := td.FillBytes(make([]byte, common.HashLength))
+	if err := b.write(typeTotalDifficulty, difficulty); err != nil {
+		return err
+	}
+	b.acc.Write(block.Hash().Bytes())
+	b.acc.Write(difficulty)
+	return nil
+}
+
+// Finalize writes the accumulator, the block index and the checksum closing the
+// archive, returning the accumulator.
+func (b *Builder) Finalize() (common.Hash, error) {
+	if len(b.offsets) == 0 {
+		return common.Hash{}, errors.New("empty archive")
+	}
+	var acc common.Hash
+	b.acc.Read(acc[:])
+	if err := b.write(typeAccumulator, acc[:]); err != nil {
+		return common.Hash{}, err
+	}
+	index := make([]byte, 8*(len(b.offsets)+2))
+	binary.LittleEndian.PutUint64(index, b.start)
+	for i, offset := range b.offsets {
+		binary.LittleEndian.PutUint64(index[8*(i+1):], offset)
+	}
+	binary.LittleEndian.PutUint64(index[8*(len(b.offsets)+1):], uint64(len(b.offsets)))
+	if err := b.write(typeBlockIndex, index); err != nil {
+		return common.Hash{}, err
+	}
+	// The checksum covers everything before it, bypass the hasher
+	var checksum common.Hash
+	b.checksum.Read(checksum[:])
+	if _, err := (&entryWriter{w: b.raw}).Write(typeChecksum, checksum[:]); err != nil {
+		return common.Hash{}, err
+	}
+	return acc, nil
+}
+
+// write writes a single entry, tracking the file offset.
+func (b *Builder) write(typ uint16, value []byte) error {
+	n, err := b.w.Write(typ, value)
+	b.written += uint64(n)
+	return err
+}
+
+// compress snappy frames the given data.
+func compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := snappy.NewBufferedWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ReadAtCloser is the file interface required to read an archive.
+type ReadAtCloser interface {
+	io.ReaderAt
+	io.Closer
+}
+
+// Era is an archive file opened for reading.
+type Era struct {
+	f       ReadAtCloser
+	size    int64
+	start   uint64
+	offsets []uint64
+	index   int64 // File offset of the block index entry
+}
+
+// Open opens the archive file at the given path.
+func Open(path string) (*Era, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	e, err := From(f, info.Size())
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return e, nil
+}
+
+// From opens the archive held in f, which is of the given size.
+func From(f ReadAtCloser, size int64) (*Era, error) {
+	version, _, err := readEntry(f, 0, size)
+	if err != nil {
+		return nil, err
+	}
+	if version.Type != typeVersion {
+		return nil, fmt.Errorf("invalid version entry type %#x", version.Type)
+	}
+	// Locate the block index from its trailing block count
+	if size < headerSize+trailerSize+8 {
+		return nil, errors.New("archive too short")
+	}
+	var count [8]byte
+	if _, err := f.ReadAt(count[:], size-trailerSize-8); err != nil {
+		return nil, err
+	}
+	n := binary.LittleEndian.Uint64(count[:])
+	if n == 0 || n > MaxSize {
+		return nil, fmt.Errorf("invalid block count %d", n)
+	}
+	offset := size - trailerSize - headerSize - int64(8*(n+2))
+	if offset < headerSize {
+		return nil, fmt.Errorf("invalid block count %d", n)
+	}
+	index, _, err := readEntry(f, offset, size)
+	if err != nil {
+		return nil, err
+	}
+	if index.Type != typeBlockIndex || uint64(len(index.Value)) != 8*(n+2) {
+		return nil, errors.New("invalid block index")
+	}
+	e := &Era{
+		f:       f,
+		size:    size,
+		start:   binary.LittleEndian.Uint64(index.Value),
+		offsets: make([]uint64, n),
+		index:   offset,
+	}
+	for i := range e.offsets {
+		e.offsets[i] = binary.LittleEndian.Uint64(index.Value[8*(i+1):])
+	}
+	return e, nil
+}
+
+// Close closes the underlying file.
+func (e *Era) Close() error {
+	return e.f.Close()
+}
+
+// Start returns the number of the first block in the archive.
+func (e *Era) Start() uint64 {
+	return e.start
+}
+
+// Count returns the number of blocks in the archive.
+func (e *Era) Count() uint64 {
+	return uint64(len(e.offsets))
+}
+
+// entries reads the entries of the given block, up to the requested one.
+func (e *Era) entries(number uint64, n int) ([]*entry, error) {
+	if number < e.start || number-e.start >= uint64(len(e.offsets)) {
+		return nil, fmt.Errorf("block #%d not in archive", number)
+	}
+	var (
+		entries []*entry
+		offset  = int64(e.offsets[number-e.start])
+	)
+	for i, typ := range []uint16{typeCompressedHeader, typeCompressedBody, typeCompressedReceipts, typeTotalDifficulty}[:n] {
+		entry, size, err := readEntry(e.f, offset, e.size)
+		if err != nil {
+			return nil, err
+		}
+		if entry.Type != typ {
+			return nil, fmt.Errorf("block #%d: invalid entry %d type %#x", number, i, entry.Type)
+		}
+		entries = append(entries, entry)
+		offset += size
+	}
+	return entries, nil
+}
+
+// decode decompresses and decodes a compressed entry into val.
+func decode(e *entry, val interface{}) error {
+	data, err := io.ReadAll(snappy.NewReader(bytes.NewReader(e.Value)))
+	if err != nil {
+		return err
+	}
+	return rlp.DecodeBytes(data, val)
+}
+
+// GetHeaderByNumber retrieves the header of the given block.
+func (e *Era) GetHeaderByNumber(number uint64) (*types.Header, error) {
+	entries, err := e.entries(number, 1)
+	if err != nil {
+		return nil, err
+	}
+	header := new(types.Header)
+	if err := decode(entries[0], header); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+// GetBlockByNumber retrieves the given block.
+func (e *Era) GetBlockByNumber(number uint64) (*types.Block, error) {
+	entries, err := e.entries(number, 2)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		header = new(types.Header)
+		body   = new(types.Body)
+	)
+	if err := decode(entries[0], header); err != nil {
+		return nil, err
+	}
+	if err := decode(entries[1], body); err != nil {
+		return nil, err
+	}
+	return types.NewBlockWithHeader(header).WithBody(body.Transactions, body.Uncles), nil
+}
+
+// GetReceiptsByNumber retrieves the receipts of the given block.
+func (e *Era) GetReceiptsByNumber(number uint64) (types.Receipts, error) {
+	entries, err := e.entries(number, 3)
+	if err != nil {
+		return nil, err
+	}
+	var receipts types.Receipts
+	if err := decode(entries[2], &receipts); err != nil {
+		return nil, err
+	}
+	return receipts, nil
+}
+
+// GetTD retrieves the total difficulty of the given block.
+func (e *Era) GetTD(number uint64) (*big.Int, error) {
+	entries, err := e.entries(number, 4)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(entries[3].Value), nil
+}
+
+// Accumulator retrieves the accumulator of the archive.
+func (e *Era) Accumulator() (common.Hash, error) {
+	entry, _, err := readEntry(e.f, e.index-headerSize-common.HashLength, e.size)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if entry.Type != typeAccumulator || len(entry.Value) != common.HashLength {
+		return common.Hash{}, errors.New("invalid accumulator")
+	}
+	return common.BytesToHash(entry.Value), nil
+}
+
+// Verify checks the integrity of the archive: the file checksum, the linkage of
+// the headers, the bodies and receipts against their headers, the progression
+// of the total difficulty and the accumulator.
+func (e *Era) Verify() error {
+	// Check the checksum of the entire file
+	entry, _, err := readEntry(e.f, e.size-trailerSize, e.size)
+	if err != nil {
+		return err
+	}
+	if entry.Type != typeChecksum {
+		return fmt.Errorf("invalid checksum entry type %#x", entry.Type)
+	}
+	hasher := crypto.NewKeccakState()
+	if _, err := io.Copy(hasher, io.NewSectionReader(e.f, 0, e.size-trailerSize)); err != nil {
+		return err
+	}
+	var checksum common.Hash
+	hasher.Read(checksum[:])
+	if !bytes.Equal(checksum[:], entry.Value) {
+		return fmt.Errorf("checksum mismatch: have %x, want %x", checksum, entry.Value)
+	}
+	// Check the contents of every block
+	var (
+		acc    = crypto.NewKeccakState()
+		parent *types.Header
+		ptd    *big.Int
+	)
+	for number := e.start; number < e.start+e.Count(); number++ {
+		block, err := e.GetBlockByNumber(number)
+		if err != nil {
+			return err
+		}
+		receipts, err := e.GetReceiptsByNumber(number)
+		if err != nil {
+			return err
+		}
+		td, err := e.GetTD(number)
+		if err != nil {
+			return err
+		}
+		header := block.Header()
+		if header.Number.Uint64() != number {
+			return fmt.Errorf("block #%d: number mismatch %d", number, header.Number)
+		}
+		if parent != nil && header.ParentHash != parent.Hash() {
+			return fmt.Errorf("block #%d: parent hash mismatch", number)
+		}
+		if ptd != nil && td.Cmp(new(big.Int).Add(ptd, header.Difficulty)) != 0 {
+			return fmt.Errorf("block #%d: total difficulty mismatch", number)
+		}
+		if hash := types.DeriveSha(block.Transactions(), trie.NewStackTrie(nil)); hash != header.TxHash {
+			return fmt.Errorf("block #%d: transaction root mismatch", number)
+		}
+		if hash := types.CalcUncleHash(block.Uncles()); hash != header.UncleHash {
+			return fmt.Errorf("block #%d: uncle hash mismatch", number)
+		}
+		if hash := types.DeriveSha(receipts, trie.NewStackTrie(nil)); hash != header.ReceiptHash {
+			return fmt.Errorf("block #%d: receipt root mismatch", number)
+		}
+		acc.Write(block.Hash().Bytes())
+		acc.Write(td.FillBytes(make([]byte, common.HashLength)))
+
+		parent, ptd = header, td
+	}
+	want, err := e.Accumulator()
+	if err != nil {
+		return err
+	}
+	var have common.Hash
+	acc.Read(have[:])
+	if have != want {
+		return fmt.Errorf("accumulator mismatch: have %x, want %x", have, want)
+	}
+	return nil
+}
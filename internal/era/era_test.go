@@ -0,0 +1,151 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package era
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// memFile is an in-memory archive file.
+type memFile struct {
+	*bytes.Reader
+}
+
+func (f memFile) Close() error { return nil }
+
+// makeArchive generates a chain with transactions and builds an archive out of
+// all its blocks.
+func makeArchive(t *testing.T, n int) ([]*types.Block, []types.Receipts, []*big.Int, []byte) {
+	t.Helper()
+
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		gspec   = &core.Genesis{
+			Config:  params.TestChainConfig,
+			Alloc:   core.GenesisAlloc{address: {Balance: big.NewInt(1000000000000000000)}},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		db      = rawdb.NewMemoryDatabase()
+		genesis = gspec.MustCommit(db)
+		signer  = types.LatestSigner(gspec.Config)
+	)
+	blocks, receipts := core.GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, n, func(i int, b *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(address), common.Address{0xaa}, big.NewInt(1), 21000, b.BaseFee(), nil), signer, key)
+		b.AddTx(tx)
+	})
+	blocks = append([]*types.Block{genesis}, blocks...)
+	receipts = append([]types.Receipts{nil}, receipts...)
+
+	var (
+		buf     bytes.Buffer
+		builder = NewBuilder(&buf)
+		tds     []*big.Int
+		td      = new(big.Int)
+	)
+	for i, block := range blocks {
+		td = new(big.Int).Add(td, block.Difficulty())
+		tds = append(tds, td)
+		if err := builder.Add(block, receipts[i], td); err != nil {
+			t.Fatalf("failed to add block #%d: %v", i, err)
+		}
+	}
+	if _, err := builder.Finalize(); err != nil {
+		t.Fatalf("failed to finalize archive: %v", err)
+	}
+	return blocks, receipts, tds, buf.Bytes()
+}
+
+// Tests that the archived blocks are read back and verified.
+func TestArchiveRoundtrip(t *testing.T) {
+	blocks, receipts, tds, blob := makeArchive(t, 16)
+
+	e, err := From(memFile{bytes.NewReader(blob)}, int64(len(blob)))
+	if err != nil {
+		t.Fatalf("failed to open archive: %v", err)
+	}
+	if e.Start() != 0 || e.Count() != uint64(len(blocks)) {
+		t.Fatalf("range mismatch: have %d+%d, want 0+%d", e.Start(), e.Count(), len(blocks))
+	}
+	for i, want := range blocks {
+		block, err := e.GetBlockByNumber(uint64(i))
+		if err != nil {
+			t.Fatalf("failed to read block #%d: %v", i, err)
+		}
+		if block.Hash() != want.Hash() || len(block.Transactions()) != len(want.Transactions()) {
+			t.Fatalf("block #%d mismatch", i)
+		}
+		have, err := e.GetReceiptsByNumber(uint64(i))
+		if err != nil {
+			t.Fatalf("failed to read receipts #%d: %v", i, err)
+		}
+		if len(have) != len(receipts[i]) {
+			t.Fatalf("receipts #%d count mismatch: have %d, want %d", i, len(have), len(receipts[i]))
+		}
+		td, err := e.GetTD(uint64(i))
+		if err != nil {
+			t.Fatalf("failed to read td #%d: %v", i, err)
+		}
+		if td.Cmp(tds[i]) != 0 {
+			t.Fatalf("td #%d mismatch: have %v, want %v", i, td, tds[i])
+		}
+	}
+	if _, err := e.GetBlockByNumber(uint64(len(blocks))); err == nil {
+		t.Fatalf("retrieved block beyond the archive")
+	}
+	if err := e.Verify(); err != nil {
+		t.Fatalf("failed to verify archive: %v", err)
+	}
+}
+
+// Tests that corrupted archives and out of order blocks are rejected.
+func TestArchiveCorruption(t *testing.T) {
+	blocks, receipts, tds, blob := makeArchive(t, 4)
+
+	corrupt := common.CopyBytes(blob)
+	corrupt[len(corrupt)/2] ^= 0xff
+	if e, err := From(memFile{bytes.NewReader(corrupt)}, int64(len(corrupt))); err == nil {
+		if err := e.Verify(); err == nil {
+			t.Fatalf("corrupted archive verified")
+		}
+	}
+	// Entry lengths beyond the end of the file are rejected before allocating
+	corrupt = common.CopyBytes(blob)
+	binary.LittleEndian.PutUint32(corrupt[2:], math.MaxUint32)
+	if _, err := From(memFile{bytes.NewReader(corrupt)}, int64(len(corrupt))); err == nil {
+		t.Fatalf("oversized entry accepted")
+	}
+	builder := NewBuilder(new(bytes.Buffer))
+	if err := builder.Add(blocks[1], receipts[1], tds[1]); err != nil {
+		t.Fatalf("failed to add block: %v", err)
+	}
+	if err := builder.Add(blocks[3], receipts[3], tds[3]); err == nil {
+		t.Fatalf("non-contiguous block accepted")
+	}
+}
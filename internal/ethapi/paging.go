@@ -0,0 +1,85 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+var (
+	// ErrInvalidCursor is returned if the cursor of a page is malformed or lies
+	// outside of the queried range.
+	ErrInvalidCursor = errors.New("invalid cursor")
+
+	// ErrCursorReorged is returned if the block of the last item of a page was
+	// reorganised out of the chain since the page was returned.
+	ErrCursorReorged = errors.New("cursor invalidated by chain reorganisation")
+)
+
+// PageCursor is the position of the last item of a page of results, the opaque
+// cursor of the next page.
+type PageCursor struct {
+	Number uint64
+	Hash   common.Hash
+	Index  uint
+}
+
+// Encode returns the opaque representation of the cursor.
+func (c *PageCursor) Encode() string {
+	blob, _ := rlp.EncodeToBytes(c)
+	return base64.RawURLEncoding.EncodeToString(blob)
+}
+
+// DecodePageCursor parses an opaque cursor.
+func DecodePageCursor(cursor string) (*PageCursor, error) {
+	blob, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	c := new(PageCursor)
+	if err := rlp.DecodeBytes(blob, c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// pageLimitError is returned if the requested page size exceeds the maximum.
+type pageLimitError struct{ message string }
+
+func (e *pageLimitError) Error() string { return e.message }
+
+// ErrorCode returns the "limit exceeded" JSON-RPC error code of EIP-1474.
+func (e *pageLimitError) ErrorCode() int { return -32005 }
+
+// PageLimit returns the number of items to return per page given the limit
+// requested, falling back to the default page size if none is requested. A zero
+// maximum page size allows any limit.
+func PageLimit(pageLimit *math.HexOrDecimal64, def, max int) (int, error) {
+	if pageLimit == nil || *pageLimit == 0 {
+		return def, nil
+	}
+	if max > 0 && uint64(*pageLimit) > uint64(max) {
+		return 0, &pageLimitError{fmt.Sprintf("page limit %d exceeds the maximum of %d", *pageLimit, max)}
+	}
+	return int(*pageLimit), nil
+}
@@ -0,0 +1,354 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// maxSimulateBlocks is the maximum number of blocks that can be simulated
+	// in a single eth_simulateBlocks request.
+	maxSimulateBlocks = 256
+
+	// simulateBlockPeriod is the timestamp increment used for simulated blocks
+	// that do not override their time, matching the progpow target block time.
+	simulateBlockPeriod = 15
+)
+
+// errSimulateVMError is the JSON-RPC error code reported for simulated calls
+// that failed inside the EVM for reasons other than a revert.
+const errSimulateVMError = -32015
+
+// SimBlock is a batch of calls executed sequentially on top of the state left
+// behind by the previous simulated block, after applying the optional header
+// and state overrides.
+type SimBlock struct {
+	BlockOverrides *BlockOverrides   `json:"blockOverrides"`
+	StateOverrides *StateOverride    `json:"stateOverrides"`
+	Calls          []TransactionArgs `json:"calls"`
+}
+
+// SimOpts is the set of parameters accepted by eth_simulateBlocks.
+type SimOpts struct {
+	BlockStateCalls []SimBlock `json:"blockStateCalls"`
+	ApplyRewards    bool       `json:"applyRewards"` // credit the consensus block reward between blocks
+}
+
+// simCallError is the error reported for a single failed simulated call.
+type simCallError struct {
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+	Data    string `json:"data,omitempty"`
+}
+
+// simCallResult is the outcome of a single simulated call.
+type simCallResult struct {
+	ReturnValue hexutil.Bytes  `json:"returnData"`
+	Logs        []*types.Log   `json:"logs"`
+	GasUsed     hexutil.Uint64 `json:"gasUsed"`
+	Status      hexutil.Uint64 `json:"status"`
+	Error       *simCallError  `json:"error,omitempty"`
+}
+
+// simChain is the chain context handed to the EVM and the consensus engine
+// while simulating. It serves the simulated headers first and falls back to
+// the backend for everything already part of the canonical chain.
+type simChain struct {
+	ctx     context.Context
+	b       Backend
+	base    *types.Header
+	headers map[common.Hash]*types.Header
+}
+
+func newSimChain(ctx context.Context, b Backend, base *types.Header) *simChain {
+	return &simChain{ctx: ctx, b: b, base: base, headers: make(map[common.Hash]*types.Header)}
+}
+
+func (c *simChain) Engine() consensus.Engine           { return c.b.Engine() }
+func (c *simChain) Config() *params.ChainConfig        { return c.b.ChainConfig() }
+func (c *simChain) CurrentHeader() *types.Header       { return c.base }
+func (c *simChain) GetTd(common.Hash, uint64) *big.Int { return nil }
+
+func (c *simChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if header, ok := c.headers[hash]; ok {
+		if header.Number.Uint64() != number {
+			return nil
+		}
+		return header
+	}
+	header, err := c.b.HeaderByHash(c.ctx, hash)
+	if err != nil || header == nil || header.Number.Uint64() != number {
+		return nil
+	}
+	return header
+}
+
+func (c *simChain) GetHeaderByNumber(number uint64) *types.Header {
+	for _, header := range c.headers {
+		if header.Number.Uint64() == number {
+			return header
+		}
+	}
+	header, _ := c.b.HeaderByNumber(c.ctx, rpc.BlockNumber(number))
+	return header
+}
+
+func (c *simChain) GetHeaderByHash(hash common.Hash) *types.Header {
+	if header, ok := c.headers[hash]; ok {
+		return header
+	}
+	header, _ := c.b.HeaderByHash(c.ctx, hash)
+	return header
+}
+
+// makeSimHeader assembles the header of the next simulated block on top of
+// parent, applying the user supplied overrides.
+func makeSimHeader(config *params.ChainConfig, parent *types.Header, overrides *BlockOverrides) (*types.Header, error) {
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Coinbase:   parent.Coinbase,
+		Difficulty: new(big.Int).Set(parent.Difficulty),
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		GasLimit:   parent.GasLimit,
+		Time:       parent.Time + simulateBlockPeriod,
+		UncleHash:  types.EmptyUncleHash,
+		TxHash:     types.EmptyRootHash,
+	}
+	if config.IsLondon(header.Number) {
+		if config.IsLondon(parent.Number) {
+			header.BaseFee = misc.CalcBaseFee(config, parent)
+		} else {
+			header.BaseFee = new(big.Int).SetUint64(params.InitialBaseFee)
+		}
+	}
+	if overrides == nil {
+		return header, nil
+	}
+	if overrides.Number != nil {
+		number := overrides.Number.ToInt()
+		if number.Cmp(parent.Number) <= 0 {
+			return nil, fmt.Errorf("block number %d not above parent %d", number, parent.Number)
+		}
+		header.Number = new(big.Int).Set(number)
+	}
+	if overrides.Time != nil {
+		time := overrides.Time.ToInt()
+		if !time.IsUint64() || time.Uint64() <= parent.Time {
+			return nil, fmt.Errorf("block timestamp %d not above parent %d", time, parent.Time)
+		}
+		header.Time = time.Uint64()
+	}
+	if overrides.Difficulty != nil {
+		header.Difficulty = new(big.Int).Set(overrides.Difficulty.ToInt())
+	}
+	if overrides.GasLimit != nil {
+		header.GasLimit = uint64(*overrides.GasLimit)
+	}
+	if overrides.Coinbase != nil {
+		header.Coinbase = *overrides.Coinbase
+	}
+	if overrides.Random != nil {
+		header.MixDigest = *overrides.Random
+	}
+	if overrides.BaseFee != nil {
+		header.BaseFee = new(big.Int).Set(overrides.BaseFee.ToInt())
+	}
+	return header, nil
+}
+
+// SimulateBlocks executes a sequence of calls across one or more hypothetical
+// blocks built on top of the given base block. Each block starts from the state
+// left behind by the previous one, so later calls observe the effects of the
+// earlier ones. If requested, the consensus block reward is credited at the
+// end of every simulated block.
+//
+// Note, this function doesn't make any changes in the state/blockchain.
+func (s *BlockChainAPI) SimulateBlocks(ctx context.Context, opts SimOpts, blockNrOrHash *rpc.BlockNumberOrHash) ([]map[string]interface{}, error) {
+	if len(opts.BlockStateCalls) == 0 {
+		return nil, errors.New("empty block list")
+	}
+	if len(opts.BlockStateCalls) > maxSimulateBlocks {
+		return nil, fmt.Errorf("too many blocks: have %d, max %d", len(opts.BlockStateCalls), maxSimulateBlocks)
+	}
+	bNrOrHash := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	if blockNrOrHash != nil {
+		bNrOrHash = *blockNrOrHash
+	}
+	return DoSimulateBlocks(ctx, s.b, opts, bNrOrHash, s.b.RPCEVMTimeout(), s.b.RPCGasCap())
+}
+
+// DoSimulateBlocks runs the given simulation on top of the state at
+// blockNrOrHash. The timeout applies to the simulation as a whole.
+func DoSimulateBlocks(ctx context.Context, b Backend, opts SimOpts, blockNrOrHash rpc.BlockNumberOrHash, timeout time.Duration, globalGasCap uint64) ([]map[string]interface{}, error) {
+	defer func(start time.Time) { log.Debug("Simulating blocks finished", "runtime", time.Since(start)) }(time.Now())
+
+	statedb, base, err := b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if statedb == nil || err != nil {
+		return nil, err
+	}
+	// Work on a private copy so the backend's state is never mutated.
+	statedb = statedb.Copy()
+
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	var (
+		config  = b.ChainConfig()
+		chain   = newSimChain(ctx, b, base)
+		parent  = base
+		results = make([]map[string]interface{}, 0, len(opts.BlockStateCalls))
+	)
+	for i, block := range opts.BlockStateCalls {
+		header, err := makeSimHeader(config, parent, block.BlockOverrides)
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %w", i, err)
+		}
+		if err := block.StateOverrides.Apply(statedb); err != nil {
+			return nil, fmt.Errorf("block %d: %w", i, err)
+		}
+		calls, err := simulateCalls(ctx, chain, statedb, header, block.Calls, timeout, globalGasCap)
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %w", i, err)
+		}
+		if opts.ApplyRewards {
+			if engine := b.Engine(); engine != nil {
+				engine.Finalize(chain, header, statedb, nil, nil)
+			}
+		}
+		header.Root = statedb.IntermediateRoot(config.IsEIP158(header.Number))
+
+		// Logs are only final once the block hash is known. The log index
+		// is renumbered as the state's log counter spans all simulated blocks.
+		var (
+			hash     = header.Hash()
+			logIndex uint
+		)
+		for _, call := range calls {
+			for _, l := range call.Logs {
+				l.BlockHash = hash
+				l.BlockNumber = header.Number.Uint64()
+				l.Index = logIndex
+				logIndex++
+			}
+		}
+		chain.headers[hash] = header
+		parent = header
+
+		fields := RPCMarshalHeader(header)
+		fields["calls"] = calls
+		results = append(results, fields)
+	}
+	return results, nil
+}
+
+// simCallHash derives a unique pseudo transaction hash for a simulated call,
+// used to attribute the logs it emits. Calls carry no nonce or signature, so
+// the real transaction hash is not available.
+func simCallHash(number *big.Int, index int) common.Hash {
+	return crypto.Keccak256Hash(number.Bytes(), big.NewInt(int64(index)).Bytes())
+}
+
+// simulateCalls executes the calls of a single simulated block against the
+// given state, updating the header's gas used as it goes.
+func simulateCalls(ctx context.Context, chain *simChain, statedb *state.StateDB, header *types.Header, calls []TransactionArgs, timeout time.Duration, globalGasCap uint64) ([]simCallResult, error) {
+	var (
+		config  = chain.Config()
+		gp      = new(core.GasPool).AddGas(header.GasLimit)
+		results = make([]simCallResult, 0, len(calls))
+	)
+	for i, args := range calls {
+		// Calls without an explicit gas limit may use whatever is left in the block.
+		if args.Gas == nil {
+			remaining := hexutil.Uint64(gp.Gas())
+			args.Gas = &remaining
+		}
+		msg, err := args.ToMessage(globalGasCap, header.BaseFee)
+		if err != nil {
+			return nil, fmt.Errorf("call %d: %w", i, err)
+		}
+		txHash := simCallHash(header.Number, i)
+		statedb.Prepare(txHash, i)
+
+		blockCtx := core.NewEVMBlockContext(header, chain, nil)
+		evm := vm.NewEVM(blockCtx, core.NewEVMTxContext(msg), statedb, config, vm.Config{NoBaseFee: true})
+
+		// Abort the EVM if the simulation deadline is hit mid-call.
+		done := make(chan struct{})
+		go func() {
+			select {
+			case <-ctx.Done():
+				evm.Cancel()
+			case <-done:
+			}
+		}()
+		result, err := core.ApplyMessage(evm, msg, gp)
+		close(done)
+
+		if evm.Cancelled() {
+			return nil, fmt.Errorf("execution aborted (timeout = %v)", timeout)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("call %d: %w (supplied gas %d)", i, err, msg.Gas())
+		}
+		statedb.Finalise(config.IsEIP158(header.Number))
+		header.GasUsed += result.UsedGas
+
+		res := simCallResult{
+			ReturnValue: result.Return(),
+			Logs:        statedb.GetLogs(txHash, common.Hash{}),
+			GasUsed:     hexutil.Uint64(result.UsedGas),
+			Status:      hexutil.Uint64(types.ReceiptStatusSuccessful),
+		}
+		if res.Logs == nil {
+			res.Logs = []*types.Log{}
+		}
+		if result.Failed() {
+			res.Status = hexutil.Uint64(types.ReceiptStatusFailed)
+			if len(result.Revert()) > 0 {
+				revert := newRevertError(result)
+				res.Error = &simCallError{Message: revert.Error(), Code: revert.ErrorCode(), Data: revert.reason}
+			} else {
+				res.Error = &simCallError{Message: result.Err.Error(), Code: errSimulateVMError}
+			}
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
@@ -0,0 +1,167 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/progpow"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// simBackendMock extends the fee backend mock with a real state database and
+// a consensus engine, enough to drive block simulations.
+type simBackendMock struct {
+	*backendMock
+	db     state.Database
+	root   common.Hash
+	engine consensus.Engine
+}
+
+func newSimBackendMock(t *testing.T, alloc map[common.Address]*big.Int) *simBackendMock {
+	b := &simBackendMock{
+		backendMock: newBackendMock(),
+		db:          state.NewDatabase(rawdb.NewMemoryDatabase()),
+		engine:      progpow.NewFaker(),
+	}
+	b.config.ProgPow = &params.ProgpowConfig{DevFundAddress: common.Address{0xde, 0xf}}
+	statedb, _ := state.New(common.Hash{}, b.db, nil)
+	for addr, balance := range alloc {
+		statedb.SetBalance(addr, balance)
+	}
+	root, err := statedb.Commit(true)
+	if err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+	if err := b.db.TrieDB().Commit(root, false, nil); err != nil {
+		t.Fatalf("failed to commit trie: %v", err)
+	}
+	b.root = root
+	b.current.Root = root
+	return b
+}
+
+func (b *simBackendMock) Engine() consensus.Engine { return b.engine }
+
+func (b *simBackendMock) StateAndHeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error) {
+	statedb, err := state.New(b.root, b.db, nil)
+	return statedb, b.current, err
+}
+
+func TestSimulateBlocks(t *testing.T) {
+	var (
+		sender    = common.Address{0x01}
+		recipient = common.Address{0x02}
+		coinbase  = common.Address{0xc0}
+		logger    = common.Address{0x10}
+		checker   = common.Address{0x11}
+		funds     = new(big.Int).Mul(big.NewInt(params.Flux), big.NewInt(1000))
+		b         = newSimBackendMock(t, map[common.Address]*big.Int{sender: funds})
+	)
+	// logger emits a single LOG0 and stops: PUSH1 0 PUSH1 0 LOG0 STOP
+	logCode := hexutil.Bytes(common.FromHex("0x60006000a000"))
+	// checker returns the balance of the coinbase:
+	// PUSH20 coinbase BALANCE PUSH1 0 MSTORE PUSH1 32 PUSH1 0 RETURN
+	checkCode := hexutil.Bytes(append(append([]byte{0x73}, coinbase.Bytes()...), common.FromHex("0x3160005260206000f3")...))
+
+	value := (*hexutil.Big)(big.NewInt(1000))
+	opts := SimOpts{
+		ApplyRewards: true,
+		BlockStateCalls: []SimBlock{
+			{
+				BlockOverrides: &BlockOverrides{Coinbase: &coinbase},
+				StateOverrides: &StateOverride{
+					logger:  OverrideAccount{Code: &logCode},
+					checker: OverrideAccount{Code: &checkCode},
+				},
+				Calls: []TransactionArgs{
+					{From: &sender, To: &recipient, Value: value},
+					{From: &sender, To: &logger},
+				},
+			},
+			{
+				Calls: []TransactionArgs{
+					{From: &sender, To: &checker},
+				},
+			},
+		},
+	}
+	results, err := DoSimulateBlocks(context.Background(), b, opts, rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber), 0, 0)
+	if err != nil {
+		t.Fatalf("simulation failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("result count mismatch: have %d, want 2", len(results))
+	}
+	// Verify the first block: a plain transfer followed by a logging call.
+	first := results[0]
+	if number := first["number"].(*hexutil.Big).ToInt(); number.Uint64() != b.current.Number.Uint64()+1 {
+		t.Errorf("first block number mismatch: have %d, want %d", number, b.current.Number.Uint64()+1)
+	}
+	calls := first["calls"].([]simCallResult)
+	if len(calls) != 2 {
+		t.Fatalf("first block call count mismatch: have %d, want 2", len(calls))
+	}
+	if calls[0].Status != hexutil.Uint64(types.ReceiptStatusSuccessful) || calls[0].GasUsed != hexutil.Uint64(params.TxGas) {
+		t.Errorf("transfer result mismatch: status %d, gas %d", calls[0].Status, calls[0].GasUsed)
+	}
+	if len(calls[1].Logs) != 1 {
+		t.Fatalf("log count mismatch: have %d, want 1", len(calls[1].Logs))
+	}
+	if log := calls[1].Logs[0]; log.Address != logger || log.BlockHash != first["hash"].(common.Hash) {
+		t.Errorf("log mismatch: address %x, block hash %x", log.Address, log.BlockHash)
+	}
+	if used := first["gasUsed"].(hexutil.Uint64); used != calls[0].GasUsed+calls[1].GasUsed {
+		t.Errorf("block gas used mismatch: have %d, want %d", used, calls[0].GasUsed+calls[1].GasUsed)
+	}
+	// Verify the second block observes the reward credited to the first one's
+	// coinbase and chains onto it.
+	second := results[1]
+	if parent := second["parentHash"].(common.Hash); parent != first["hash"].(common.Hash) {
+		t.Errorf("parent hash mismatch: have %x, want %x", parent, first["hash"])
+	}
+	calls = second["calls"].([]simCallResult)
+	if len(calls) != 1 || calls[0].Status != hexutil.Uint64(types.ReceiptStatusSuccessful) {
+		t.Fatalf("balance check failed: %+v", calls)
+	}
+	if balance := new(big.Int).SetBytes(calls[0].ReturnValue); balance.Sign() == 0 {
+		t.Errorf("coinbase was not rewarded")
+	}
+}
+
+func TestSimulateBlocksInvalidOverrides(t *testing.T) {
+	b := newSimBackendMock(t, nil)
+
+	past := (*hexutil.Big)(big.NewInt(int64(b.current.Time)))
+	opts := SimOpts{BlockStateCalls: []SimBlock{{BlockOverrides: &BlockOverrides{Time: past}}}}
+	if _, err := DoSimulateBlocks(context.Background(), b, opts, rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber), 0, 0); err == nil {
+		t.Fatalf("expected error for non-increasing timestamp")
+	}
+	api := NewBlockChainAPI(b)
+	if _, err := api.SimulateBlocks(context.Background(), SimOpts{}, nil); err == nil {
+		t.Fatalf("expected error for empty simulation")
+	}
+}
@@ -325,6 +325,7 @@ func (b *backendMock) TxPoolContentFrom(addr common.Address) (types.Transactions
 }
 func (b *backendMock) SubscribeNewTxsEvent(chan<- core.NewTxsEvent) event.Subscription { return nil }
 func (b *backendMock) BloomStatus() (uint64, uint64)                                   { return 0, 0 }
+func (b *backendMock) LogIndexStatus() (uint64, uint64)                                { return 0, 0 }
 func (b *backendMock) GetLogs(ctx context.Context, blockHash common.Hash) ([][]*types.Log, error) {
 	return nil, nil
 }
@@ -336,5 +337,11 @@ func (b *backendMock) SubscribePendingLogsEvent(ch chan<- []*types.Log) event.Su
 func (b *backendMock) SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription {
 	return nil
 }
+func (b *backendMock) SubscribeReorgEvent(ch chan<- core.ReorgEvent) event.Subscription {
+	return nil
+}
+func (b *backendMock) SubscribeReorgRefusedEvent(ch chan<- core.ReorgRefusedEvent) event.Subscription {
+	return nil
+}
 
 func (b *backendMock) Engine() consensus.Engine { return nil }
@@ -297,7 +297,7 @@ func (s *LightEthereum) APIs() []rpc.API {
 			Service:   downloader.NewDownloaderAPI(s.handler.downloader, s.eventMux),
 		}, {
 			Namespace: "eth",
-			Service:   filters.NewFilterAPI(s.ApiBackend, true, 5*time.Minute),
+			Service:   filters.NewFilterAPI(s.ApiBackend, true, 5*time.Minute, filters.LogLimits{MaxResults: s.config.RPCLogsMaxResults, MaxRange: s.config.RPCLogsMaxRange}),
 		}, {
 			Namespace: "net",
 			Service:   s.netRPCService,
@@ -1019,7 +1019,8 @@ func (p *clientPeer) Handshake(td *big.Int, head common.Hash, headNum uint64, ge
 		// Add some information which services server can offer.
 		if !server.config.UltraLightOnlyAnnounce {
 			*lists = (*lists).add("serveHeaders", nil)
-			*lists = (*lists).add("serveChainSince", uint64(0))
+			// Bodies and receipts are only available since the history tail
+			*lists = (*lists).add("serveChainSince", server.handler.blockchain.HistoryTail())
 			*lists = (*lists).add("serveStateSince", uint64(0))
 
 			// If local ethereum node is running in archive mode, advertise ourselves we have
@@ -43,6 +43,10 @@ var (
 
 const defaultConnectedBias = time.Minute * 3
 
+// checkpointPinInterval is the time between two lookups of the stable checkpoint
+// of the checkpoint oracle to pin in the local chain.
+const checkpointPinInterval = 10 * time.Minute
+
 type ethBackend interface {
 	ArchiveMode() bool
 	BlockChain() *core.BlockChain
@@ -73,6 +77,8 @@ type LesServer struct {
 	threadsIdle              int // Request serving threads count when system is idle.
 	threadsBusy              int // Request serving threads count when system is busy(block insertion).
 
+	pinnedSections uint64 // Number of CHT sections covered by the last pinned oracle checkpoint
+
 	p2pSrv *p2p.Server
 }
 
@@ -195,6 +201,10 @@ func (s *LesServer) Start() error {
 	s.handler.start()
 	s.wg.Add(1)
 	go s.capacityManagement()
+	if s.oracle != nil && s.oracle.IsRunning() {
+		s.wg.Add(1)
+		go s.pinCheckpoints()
+	}
 	if s.p2pSrv.DiscV5 != nil {
 		s.p2pSrv.DiscV5.RegisterTalkHandler("vfx", s.vfluxServer.ServeEncoded)
 	}
@@ -289,3 +299,38 @@ func (s *LesServer) capacityManagement() {
 		}
 	}
 }
+
+// pinCheckpoints periodically registers the stable checkpoint of the checkpoint
+// oracle contract with the reorg protection of the local chain.
+func (s *LesServer) pinCheckpoints() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(checkpointPinInterval)
+	defer ticker.Stop()
+
+	for {
+		s.pinCheckpoint()
+		select {
+		case <-ticker.C:
+		case <-s.closeCh:
+			return
+		}
+	}
+}
+
+// pinCheckpoint registers the stable checkpoint of the checkpoint oracle with the
+// reorg protection of the local chain, if it is newer than the one registered
+// last. The contract only stores a hash over the section head along with the CHT
+// and bloom trie roots, so the checkpoint is resolved to a block by matching it
+// with the local CHT, which only light servers maintain.
+func (s *LesServer) pinCheckpoint() {
+	checkpoint, _ := s.oracle.StableCheckpoint()
+	if checkpoint == nil || checkpoint.SectionIndex+1 <= s.pinnedSections {
+		return
+	}
+	number := (checkpoint.SectionIndex+1)*s.iConfig.ChtSize - 1
+	s.handler.blockchain.AddCheckpoint(number, checkpoint.SectionHead)
+	s.pinnedSections = checkpoint.SectionIndex + 1
+
+	log.Info("Pinned checkpoint oracle checkpoint", "number", number, "hash", checkpoint.SectionHead)
+}
@@ -103,6 +103,13 @@ func testCheckpointSyncing(t *testing.T, protocol int, syncMode int) {
 				break
 			}
 			expected += 1
+
+			// The server pins the checkpoint in its own chain
+			server.handler.server.pinCheckpoint()
+			checkpoints := server.handler.blockchain.Checkpoints()
+			if len(checkpoints) != 1 || checkpoints[0].Number != config.ChtSize-1 || checkpoints[0].Hash != head {
+				t.Errorf("pinned checkpoints mismatch: have %v, want #%d [%x]", checkpoints, config.ChtSize-1, head)
+			}
 		}
 	}
 
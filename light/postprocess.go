@@ -223,7 +223,7 @@ func (c *ChtIndexerBackend) Commit() error {
 	}
 	// Commit trie changes into trie database in case it's not nil.
 	if nodes != nil {
-		if err := c.triedb.Update(trie.NewWithNodeSet(nodes)); err != nil {
+		if err := c.triedb.Update(root, common.Hash{}, trie.NewWithNodeSet(nodes)); err != nil {
 			return err
 		}
 	}
@@ -470,7 +470,7 @@ func (b *BloomTrieIndexerBackend) Commit() error {
 	}
 	// Commit trie changes into trie database in case it's not nil.
 	if nodes != nil {
-		if err := b.triedb.Update(trie.NewWithNodeSet(nodes)); err != nil {
+		if err := b.triedb.Update(root, common.Hash{}, trie.NewWithNodeSet(nodes)); err != nil {
 			return err
 		}
 	}
@@ -20,6 +20,7 @@ import (
 	"errors"
 	"math/big"
 	"math/rand"
+	"reflect"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -71,6 +72,11 @@ var (
 		Recommit: time.Second,
 		GasCeil:  params.GenesisGasLimit,
 	}
+	testCacheConfig = &core.CacheConfig{
+		TrieDirtyDisabled: true,
+		ValueTransfers:    true,
+		ContractCreations: true,
+	}
 )
 
 func init() {
@@ -136,7 +142,7 @@ func newTestWorkerBackend(t *testing.T, chainConfig *params.ChainConfig, engine
 	}
 	genesis := gspec.MustCommit(db)
 
-	chain, _ := core.NewBlockChain(db, &core.CacheConfig{TrieDirtyDisabled: true}, gspec.Config, engine, vm.Config{}, nil, nil)
+	chain, _ := core.NewBlockChain(db, testCacheConfig, gspec.Config, engine, vm.Config{}, nil, nil)
 	txpool := core.NewTxPool(testTxPoolConfig, chainConfig, chain)
 
 	// Generate a small n-block chain and an uncle block for it
@@ -233,10 +239,11 @@ func testGenerateBlockAndImport(t *testing.T, isClique bool) {
 	w, b := newTestWorker(t, chainConfig, engine, db, 0)
 	defer w.close()
 
-	// This test chain imports the mined blocks.
+	// This test chain imports the mined blocks, recording their value transfers
+	// and contract creations by executing them.
 	db2 := rawdb.NewMemoryDatabase()
 	b.genesis.MustCommit(db2)
-	chain, _ := core.NewBlockChain(db2, nil, b.chain.Config(), engine, vm.Config{}, nil, nil)
+	chain, _ := core.NewBlockChain(db2, testCacheConfig, b.chain.Config(), engine, vm.Config{}, nil, nil)
 	defer chain.Stop()
 
 	// Ignore empty commit here for less noise.
@@ -263,6 +270,15 @@ func testGenerateBlockAndImport(t *testing.T, isClique bool) {
 			if _, err := chain.InsertChain([]*types.Block{block}); err != nil {
 				t.Fatalf("failed to insert new mined block %d: %v", block.NumberU64(), err)
 			}
+			// The miner hands its recordings over along with the block, they
+			// must match the ones of the import
+			number, hash := block.NumberU64(), block.Hash()
+			if have, want := rawdb.ReadValueTransfers(db, number, hash), rawdb.ReadValueTransfers(db2, number, hash); len(want) == 0 || !reflect.DeepEqual(have, want) {
+				t.Fatalf("block %d value transfers mismatch: have %v, want %v", number, have, want)
+			}
+			if have, want := rawdb.ReadContractCreations(db, number, hash), rawdb.ReadContractCreations(db2, number, hash); len(want) == 0 || !reflect.DeepEqual(have, want) {
+				t.Fatalf("block %d contract creations mismatch: have %v, want %v", number, have, want)
+			}
 		case <-time.After(3 * time.Second): // Worker needs 1s to include new changes.
 			t.Fatalf("timeout")
 		}
@@ -0,0 +1,260 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// apiKeyHeader is the HTTP header clients present their API key in.
+	apiKeyHeader = "X-API-Key"
+
+	// apiKeyQuery is the URL query parameter clients may present their API key
+	// in, for the WebSocket clients unable to set headers.
+	apiKeyQuery = "apikey"
+
+	// apiKeyReloadInterval is the minimum time between two checks of the API
+	// key store file for changes.
+	apiKeyReloadInterval = time.Second
+)
+
+var (
+	errAPIKeyExists  = errors.New("API key name already exists")
+	errAPIKeyUnknown = errors.New("unknown API key name")
+)
+
+// APIKey is an entry of the API key store, granting the holder of the secret
+// key access to the methods of the listed namespaces and the listed methods.
+// Only the hash of the key is stored.
+type APIKey struct {
+	Name       string      `json:"name"`
+	Hash       common.Hash `json:"hash"`
+	Namespaces []string    `json:"namespaces,omitempty"`
+	Methods    []string    `json:"methods,omitempty"`
+	Created    time.Time   `json:"created"`
+}
+
+// access returns the RPC access granted by the key.
+func (k *APIKey) access() *rpc.Access {
+	return &rpc.Access{Namespaces: k.Namespaces, Methods: k.Methods}
+}
+
+// APIKeyStore is a file backed store of the API keys granting access to the
+// RPC methods. Changes of the file made by other processes are picked up.
+type APIKeyStore struct {
+	path string
+
+	lock    sync.Mutex
+	keys    []*APIKey
+	hashes  map[common.Hash]*APIKey
+	modTime time.Time // Modification time of the file when last loaded
+	checked time.Time // Last time the file was checked for changes
+}
+
+// OpenAPIKeyStore opens the API key store at the given path. The file is
+// created on the first key issued.
+func OpenAPIKeyStore(path string) (*APIKeyStore, error) {
+	s := &APIKeyStore{path: path}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// load reads the keys from the file if it has changed since it was last read.
+// The caller must hold the lock.
+func (s *APIKeyStore) load() error {
+	s.checked = time.Now()
+
+	stat, err := os.Stat(s.path)
+	if os.IsNotExist(err) {
+		s.keys, s.hashes, s.modTime = nil, make(map[common.Hash]*APIKey), time.Time{}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if s.hashes != nil && stat.ModTime().Equal(s.modTime) {
+		return nil
+	}
+	blob, err := os.ReadFile(s.path)
+	if err != nil {
+		return err
+	}
+	var keys []*APIKey
+	if err := json.Unmarshal(blob, &keys); err != nil {
+		return fmt.Errorf("invalid API key store %s: %v", s.path, err)
+	}
+	hashes := make(map[common.Hash]*APIKey, len(keys))
+	for _, key := range keys {
+		hashes[key.Hash] = key
+	}
+	s.keys, s.hashes, s.modTime = keys, hashes, stat.ModTime()
+	return nil
+}
+
+// save writes the keys into the file, replacing it atomically. The caller must
+// hold the lock.
+func (s *APIKeyStore) save() error {
+	blob, err := json.MarshalIndent(s.keys, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, blob, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return err
+	}
+	s.hashes = nil // Force reloading the new file
+	return s.load()
+}
+
+// Keys returns the entries of the store.
+func (s *APIKeyStore) Keys() ([]*APIKey, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return append([]*APIKey(nil), s.keys...), nil
+}
+
+// Issue generates a new API key under the given name, granting access to the
+// given namespaces and methods. The secret key is returned, it cannot be
+// retrieved from the store later.
+func (s *APIKeyStore) Issue(name string, namespaces, methods []string) (string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.load(); err != nil {
+		return "", err
+	}
+	for _, key := range s.keys {
+		if key.Name == name {
+			return "", errAPIKeyExists
+		}
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	key := hex.EncodeToString(secret)
+	s.keys = append(s.keys, &APIKey{
+		Name:       name,
+		Hash:       hashAPIKey(key),
+		Namespaces: namespaces,
+		Methods:    methods,
+		Created:    time.Now().UTC().Truncate(time.Second),
+	})
+	if err := s.save(); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// Revoke removes the API key with the given name from the store.
+func (s *APIKeyStore) Revoke(name string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.load(); err != nil {
+		return err
+	}
+	for i, key := range s.keys {
+		if key.Name == name {
+			s.keys = append(s.keys[:i], s.keys[i+1:]...)
+			return s.save()
+		}
+	}
+	return errAPIKeyUnknown
+}
+
+// lookup returns the entry of the given secret key, or nil if there is none.
+func (s *APIKeyStore) lookup(key string) *APIKey {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if time.Since(s.checked) > apiKeyReloadInterval {
+		if err := s.load(); err != nil {
+			log.Warn("Failed to reload API keys", "path", s.path, "err", err)
+		}
+	}
+	return s.hashes[hashAPIKey(key)]
+}
+
+// hashAPIKey returns the hash identifying a secret key in the store.
+func hashAPIKey(key string) common.Hash {
+	return sha256.Sum256([]byte(key))
+}
+
+// apiKeyHandler is a handler which restricts the RPC methods the clients may
+// call to the ones granted by the API key they present, or to the default
+// modules if they present none.
+type apiKeyHandler struct {
+	keys     *APIKeyStore
+	fallback *rpc.Access
+	next     http.Handler
+}
+
+func newAPIKeyHandler(keys *APIKeyStore, modules []string, next http.Handler) http.Handler {
+	h := &apiKeyHandler{keys: keys, next: next}
+	if len(modules) > 0 {
+		h.fallback = &rpc.Access{Namespaces: modules}
+	}
+	return h
+}
+
+// ServeHTTP implements http.Handler
+func (h *apiKeyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	secret := r.Header.Get(apiKeyHeader)
+	if secret == "" {
+		secret = r.URL.Query().Get(apiKeyQuery)
+	}
+	if secret == "" {
+		h.next.ServeHTTP(w, r.WithContext(rpc.WithAccess(r.Context(), h.fallback)))
+		return
+	}
+	key := h.keys.lookup(secret)
+	if key == nil {
+		http.Error(w, "invalid API key", http.StatusUnauthorized)
+		return
+	}
+	ctx := rpc.WithAccess(r.Context(), key.access())
+	ctx = rpc.WithAuthSubject(ctx, "apikey:"+key.Name)
+	h.next.ServeHTTP(w, r.WithContext(ctx))
+}
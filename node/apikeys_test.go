@@ -0,0 +1,155 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/internal/testlog"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type apiKeyTestService struct{}
+
+func (apiKeyTestService) Echo(s string) string  { return s }
+func (apiKeyTestService) Other(s string) string { return s }
+
+// Tests that the API key store issues, persists and revokes keys.
+func TestAPIKeyStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys", "apikeys.json")
+	store, err := OpenAPIKeyStore(path)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	key, err := store.Issue("ops", []string{"admin"}, nil)
+	if err != nil {
+		t.Fatalf("failed to issue key: %v", err)
+	}
+	if _, err := store.Issue("ops", []string{"eth"}, nil); err != errAPIKeyExists {
+		t.Fatalf("duplicate key name error mismatch: have %v, want %v", err, errAPIKeyExists)
+	}
+	if _, err := store.Issue("reader", nil, []string{"debug_echo"}); err != nil {
+		t.Fatalf("failed to issue key: %v", err)
+	}
+	// Reopen the store and check the keys
+	store, err = OpenAPIKeyStore(path)
+	if err != nil {
+		t.Fatalf("failed to reopen store: %v", err)
+	}
+	keys, _ := store.Keys()
+	if len(keys) != 2 || keys[0].Name != "ops" || keys[1].Name != "reader" {
+		t.Fatalf("stored keys mismatch: %v", keys)
+	}
+	if entry := store.lookup(key); entry == nil || entry.Name != "ops" {
+		t.Fatalf("key lookup mismatch: %v", entry)
+	}
+	if entry := store.lookup("invalid"); entry != nil {
+		t.Fatalf("invalid key found: %v", entry)
+	}
+	if err := store.Revoke("ops"); err != nil {
+		t.Fatalf("failed to revoke key: %v", err)
+	}
+	if err := store.Revoke("ops"); err != errAPIKeyUnknown {
+		t.Fatalf("unknown key name error mismatch: have %v, want %v", err, errAPIKeyUnknown)
+	}
+	if entry := store.lookup(key); entry != nil {
+		t.Fatalf("revoked key found: %v", entry)
+	}
+}
+
+// Tests that the clients of the HTTP server are restricted to the configured
+// modules, or to the scope of the API key they present.
+func TestAPIKeyAccess(t *testing.T) {
+	store, err := OpenAPIKeyStore(filepath.Join(t.TempDir(), "apikeys.json"))
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	opsKey, _ := store.Issue("ops", []string{"admin"}, nil)
+	readerKey, _ := store.Issue("reader", nil, []string{"debug_echo"})
+
+	apis := []rpc.API{
+		{Namespace: "admin", Service: apiKeyTestService{}},
+		{Namespace: "debug", Service: apiKeyTestService{}},
+		{Namespace: "eth", Service: apiKeyTestService{}},
+		{Namespace: "engine", Service: apiKeyTestService{}, Authenticated: true},
+	}
+	srv := newHTTPServer(testlog.Logger(t, log.LvlDebug), rpc.DefaultHTTPTimeouts)
+	if err := srv.enableRPC(apis, httpConfig{Modules: []string{"eth"}, apiKeys: store}); err != nil {
+		t.Fatalf("failed to enable RPC: %v", err)
+	}
+	if err := srv.setListenAddr("localhost", 0); err != nil {
+		t.Fatalf("failed to set listen address: %v", err)
+	}
+	if err := srv.start(); err != nil {
+		t.Fatalf("failed to start server: %v", err)
+	}
+	defer srv.stop()
+
+	tests := []struct {
+		key     string
+		allowed []string
+		denied  []string
+		modules []string
+	}{
+		{"", []string{"eth_echo"}, []string{"admin_echo", "debug_echo", "engine_echo"}, []string{"eth", "rpc"}},
+		{opsKey, []string{"admin_echo", "admin_other"}, []string{"eth_echo", "engine_echo"}, []string{"admin", "rpc"}},
+		{readerKey, []string{"debug_echo"}, []string{"debug_other", "eth_echo"}, []string{"debug", "rpc"}},
+	}
+	for i, tt := range tests {
+		client, err := rpc.Dial("http://" + srv.listenAddr())
+		if err != nil {
+			t.Fatalf("test %d: failed to dial: %v", i, err)
+		}
+		if tt.key != "" {
+			client.SetHeader(apiKeyHeader, tt.key)
+		}
+		var result string
+		for _, method := range tt.allowed {
+			if err := client.Call(&result, method, "hello"); err != nil {
+				t.Errorf("test %d: allowed call %s failed: %v", i, method, err)
+			}
+		}
+		for _, method := range tt.denied {
+			if err := client.Call(&result, method, "hello"); err == nil {
+				t.Errorf("test %d: denied call %s succeeded", i, method)
+			}
+		}
+		var modules map[string]string
+		if err := client.Call(&modules, "rpc_modules"); err != nil {
+			t.Fatalf("test %d: failed to list modules: %v", i, err)
+		}
+		var names []string
+		for name := range modules {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		if !reflect.DeepEqual(names, tt.modules) {
+			t.Errorf("test %d: modules mismatch: have %v, want %v", i, names, tt.modules)
+		}
+		client.Close()
+	}
+	// Unknown keys are rejected
+	resp := rpcRequest(t, "http://"+srv.listenAddr(), apiKeyHeader, "invalid")
+	resp.Body.Close()
+	if resp.StatusCode != 401 {
+		t.Fatalf("invalid key status mismatch: have %d, want 401", resp.StatusCode)
+	}
+}
@@ -65,6 +65,16 @@ type Config struct {
 	// in memory.
 	DataDir string
 
+	// DBEngine is the key-value database implementation ("leveldb" or "pebble")
+	// backing the node's databases. If empty, the engine recorded for an existing
+	// database is used, defaulting to leveldb for new ones.
+	DBEngine string `toml:",omitempty"`
+
+	// DBSecondary opens the node's databases as read-only, point-in-time views,
+	// without locking the data directory. This allows inspecting the databases
+	// of another node running on the same data directory.
+	DBSecondary bool `toml:"-"`
+
 	// Configuration of peer-to-peer networking.
 	P2P p2p.Config
 
@@ -177,6 +187,27 @@ type Config struct {
 	// private APIs to untrusted users is a major security risk.
 	WSExposeAll bool `toml:",omitempty"`
 
+	// RPCLimits configures the rate, concurrency, batch and response size limits
+	// imposed on the clients of the HTTP and WebSocket RPC servers, counting the
+	// calls over both together. The authenticated engine API is exempt.
+	RPCLimits rpc.Limits `toml:",omitempty"`
+
+	// APIKeys is the path of the API key store. If set, the HTTP and WebSocket
+	// RPC servers serve all the public APIs, restricting the clients without
+	// an API key to HTTPModules and WSModules, and the other clients to the
+	// namespaces and methods granted by their key. Relative paths are resolved
+	// against the instance directory.
+	APIKeys string `toml:",omitempty"`
+
+	// HTTPAccessLog and WSAccessLog are the files the HTTP and WebSocket RPC
+	// servers append a JSON line to for every call served. Empty paths disable
+	// the access logs.
+	HTTPAccessLog string `toml:",omitempty"`
+	WSAccessLog   string `toml:",omitempty"`
+
+	// RPCAccessLog configures the slow call tracing of the access logs.
+	RPCAccessLog rpc.AccessLogConfig `toml:",omitempty"`
+
 	// GraphQLCors is the Cross-Origin Resource Sharing header to send to requesting
 	// clients. Please be aware that CORS is a browser enforced security, it's fully
 	// useless for custom HTTP clients.
@@ -356,6 +387,15 @@ func (c *Config) instanceDir() string {
 	return filepath.Join(c.DataDir, c.name())
 }
 
+// APIKeysPath resolves the path of the API key store, returning an empty string
+// if API keys are disabled.
+func (c *Config) APIKeysPath() string {
+	if c.APIKeys == "" || c.DataDir == "" {
+		return c.APIKeys
+	}
+	return c.ResolvePath(c.APIKeys)
+}
+
 // NodeKey retrieves the currently configured private key of the node, checking
 // first any manually set key, falling back to the one found in the configured
 // data folder. If no key can be found, a new one is generated.
@@ -323,6 +323,10 @@ func (n *Node) openDataDir() error {
 	if err := os.MkdirAll(instdir, 0700); err != nil {
 		return err
 	}
+	// Secondary databases are opened alongside the instance owning the directory
+	if n.config.DBSecondary {
+		return nil
+	}
 	// Lock the instance directory to prevent concurrent use by another instance as well as
 	// accidental use of the instance directory as a database.
 	release, _, err := fileutil.Flock(filepath.Join(instdir, "LOCK"))
@@ -391,10 +395,21 @@ func (n *Node) startRPC() error {
 			return err
 		}
 	}
+	// The HTTP and WebSocket endpoints share the limits, the authenticated engine
+	// API serving the consensus client is exempt from them
 	var (
 		servers   []*httpServer
 		open, all = n.GetAPIs()
+		apiKeys   *APIKeyStore
+		limiter   = rpc.NewLimiter(n.config.RPCLimits)
 	)
+	if path := n.config.APIKeysPath(); path != "" {
+		keys, err := OpenAPIKeyStore(path)
+		if err != nil {
+			return err
+		}
+		apiKeys = keys
+	}
 
 	initHttp := func(server *httpServer, apis []rpc.API, port int) error {
 		if err := server.setListenAddr(n.config.HTTPHost, port); err != nil {
@@ -405,6 +420,10 @@ func (n *Node) startRPC() error {
 			Vhosts:             n.config.HTTPVirtualHosts,
 			Modules:            n.config.HTTPModules,
 			prefix:             n.config.HTTPPathPrefix,
+			limiter:            limiter,
+			apiKeys:            apiKeys,
+			accessLog:          n.config.HTTPAccessLog,
+			accessLogConfig:    n.config.RPCAccessLog,
 		}); err != nil {
 			return err
 		}
@@ -418,9 +437,13 @@ func (n *Node) startRPC() error {
 			return err
 		}
 		if err := server.enableWS(n.rpcAPIs, wsConfig{
-			Modules: n.config.WSModules,
-			Origins: n.config.WSOrigins,
-			prefix:  n.config.WSPathPrefix,
+			Modules:         n.config.WSModules,
+			Origins:         n.config.WSOrigins,
+			prefix:          n.config.WSPathPrefix,
+			limiter:         limiter,
+			apiKeys:         apiKeys,
+			accessLog:       n.config.WSAccessLog,
+			accessLogConfig: n.config.RPCAccessLog,
 		}); err != nil {
 			return err
 		}
@@ -689,7 +712,15 @@ func (n *Node) OpenDatabase(name string, cache, handles int, namespace string, r
 	if n.config.DataDir == "" {
 		db = rawdb.NewMemoryDatabase()
 	} else {
-		db, err = rawdb.NewLevelDBDatabase(n.ResolvePath(name), cache, handles, namespace, readonly)
+		db, err = rawdb.Open(rawdb.OpenOptions{
+			Type:      n.config.DBEngine,
+			Directory: n.ResolvePath(name),
+			Namespace: namespace,
+			Cache:     cache,
+			Handles:   handles,
+			ReadOnly:  readonly,
+			Secondary: n.config.DBSecondary,
+		})
 	}
 
 	if err == nil {
@@ -715,7 +746,16 @@ func (n *Node) OpenDatabaseWithFreezer(name string, cache, handles int, ancient
 	if n.config.DataDir == "" {
 		db = rawdb.NewMemoryDatabase()
 	} else {
-		db, err = rawdb.NewLevelDBDatabaseWithFreezer(n.ResolvePath(name), cache, handles, n.ResolveAncient(name, ancient), namespace, readonly)
+		db, err = rawdb.Open(rawdb.OpenOptions{
+			Type:              n.config.DBEngine,
+			Directory:         n.ResolvePath(name),
+			AncientsDirectory: n.ResolveAncient(name, ancient),
+			Namespace:         namespace,
+			Cache:             cache,
+			Handles:           handles,
+			ReadOnly:          readonly,
+			Secondary:         n.config.DBSecondary,
+		})
 	}
 
 	if err == nil {
@@ -22,15 +22,19 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/rpc"
 
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -479,6 +483,81 @@ func TestWebsocketHTTPOnSeparatePort_WSRequest(t *testing.T) {
 	}
 }
 
+// limitsTestAPI is a minimal API to test the RPC limits with.
+type limitsTestAPI struct{}
+
+func (limitsTestAPI) Ping() string { return "pong" }
+
+// Tests that the HTTP and WebSocket servers hold the clients to a single quota,
+// and that the authenticated engine API is exempt from the limits.
+func TestNodeRPCLimits(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("can't listen:", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	secret := make([]byte, 32)
+	path := filepath.Join(t.TempDir(), "jwtsecret")
+	if err := os.WriteFile(path, []byte(fmt.Sprintf("%#x", secret)), 0600); err != nil {
+		t.Fatalf("failed to write jwt secret: %v", err)
+	}
+	node, err := New(&Config{
+		HTTPHost:  "127.0.0.1",
+		WSHost:    "127.0.0.1",
+		WSPort:    port,
+		AuthAddr:  "127.0.0.1",
+		JWTSecret: path,
+		RPCLimits: rpc.Limits{Rates: map[string]rpc.RateLimit{"*": {Rate: 0, Burst: 1}}},
+	})
+	if err != nil {
+		t.Fatalf("could not create node: %v", err)
+	}
+	defer node.Close()
+
+	node.RegisterAPIs([]rpc.API{
+		{Namespace: "test", Service: limitsTestAPI{}},
+		{Namespace: "engine", Service: limitsTestAPI{}, Authenticated: true},
+	})
+	if err := node.Start(); err != nil {
+		t.Fatalf("could not start node: %v", err)
+	}
+	ping := func(url string, method string) error {
+		client, err := rpc.Dial(url)
+		if err != nil {
+			t.Fatalf("failed to dial %s: %v", url, err)
+		}
+		defer client.Close()
+
+		return client.Call(new(string), method)
+	}
+	// The only call of the quota is taken over HTTP, leaving none for WebSocket
+	if err := ping(node.HTTPEndpoint(), "test_ping"); err != nil {
+		t.Fatalf("http call failed: %v", err)
+	}
+	if err := ping(node.WSEndpoint(), "test_ping"); err == nil {
+		t.Fatalf("websocket call not limited")
+	}
+	// The engine API is not limited at all
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaim{"iat": time.Now().Unix()}).SignedString(secret)
+	if err != nil {
+		t.Fatalf("failed to issue token: %v", err)
+	}
+	client, err := rpc.Dial("http://" + node.httpAuth.listenAddr())
+	if err != nil {
+		t.Fatalf("failed to dial auth endpoint: %v", err)
+	}
+	defer client.Close()
+
+	client.SetHeader("Authorization", "Bearer "+token)
+	for i := 0; i < 3; i++ {
+		if err := client.Call(new(string), "engine_ping"); err != nil {
+			t.Fatalf("engine call %d failed: %v", i, err)
+		}
+	}
+}
+
 type rpcPrefixTest struct {
 	httpPrefix, wsPrefix string
 	// These lists paths on which JSON-RPC should be served / not served.
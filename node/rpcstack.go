@@ -23,6 +23,7 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"os"
 	"sort"
 	"strings"
 	"sync"
@@ -39,21 +40,51 @@ type httpConfig struct {
 	Modules            []string
 	CorsAllowedOrigins []string
 	Vhosts             []string
-	prefix             string // path prefix on which to mount http handler
-	jwtSecret          []byte // optional JWT secret
+	prefix             string       // path prefix on which to mount http handler
+	jwtSecret          []byte       // optional JWT secret
+	limiter            *rpc.Limiter // limits imposed on the calls of the clients, nil if unlimited
+	apiKeys            *APIKeyStore // optional API keys granting access beyond Modules
+	accessLog          string       // optional file to write the access log to
+	accessLogConfig    rpc.AccessLogConfig
 }
 
 // wsConfig is the JSON-RPC/Websocket configuration
 type wsConfig struct {
-	Origins   []string
-	Modules   []string
-	prefix    string // path prefix on which to mount ws handler
-	jwtSecret []byte // optional JWT secret
+	Origins         []string
+	Modules         []string
+	prefix          string       // path prefix on which to mount ws handler
+	jwtSecret       []byte       // optional JWT secret
+	limiter         *rpc.Limiter // limits imposed on the calls of the clients, nil if unlimited
+	apiKeys         *APIKeyStore // optional API keys granting access beyond Modules
+	accessLog       string       // optional file to write the access log to
+	accessLogConfig rpc.AccessLogConfig
 }
 
 type rpcHandler struct {
 	http.Handler
-	server *rpc.Server
+	server    *rpc.Server
+	accessLog *os.File // access log file, nil if disabled
+}
+
+// stop stops the RPC server and closes its access log.
+func (h *rpcHandler) stop() {
+	h.server.Stop()
+	if h.accessLog != nil {
+		h.accessLog.Close()
+	}
+}
+
+// openAccessLog opens the access log file of the server if configured.
+func openAccessLog(srv *rpc.Server, path string, config rpc.AccessLogConfig) (*os.File, error) {
+	if path == "" {
+		return nil, nil
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	srv.SetAccessLog(file, config)
+	return file, nil
 }
 
 type httpServer struct {
@@ -190,10 +221,8 @@ func (h *httpServer) start() error {
 func (h *httpServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// check if ws request and serve if ws enabled
 	ws := h.wsHandler.Load().(*rpcHandler)
-	if ws != nil && isWebsocket(r) {
-		if checkPath(r, h.wsConfig.prefix) {
-			ws.ServeHTTP(w, r)
-		}
+	if ws != nil && isWebsocket(r) && checkPath(r, h.wsConfig.prefix) {
+		ws.ServeHTTP(w, r)
 		return
 	}
 	// if http-rpc is enabled, try to serve request
@@ -261,11 +290,11 @@ func (h *httpServer) doStop() {
 	wsHandler := h.wsHandler.Load().(*rpcHandler)
 	if httpHandler != nil {
 		h.httpHandler.Store((*rpcHandler)(nil))
-		httpHandler.server.Stop()
+		httpHandler.stop()
 	}
 	if wsHandler != nil {
 		h.wsHandler.Store((*rpcHandler)(nil))
-		wsHandler.server.Stop()
+		wsHandler.stop()
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer cancel()
@@ -293,13 +322,23 @@ func (h *httpServer) enableRPC(apis []rpc.API, config httpConfig) error {
 
 	// Create RPC server and handler.
 	srv := rpc.NewServer()
-	if err := RegisterApis(apis, config.Modules, srv); err != nil {
+	srv.SetLimiter(config.limiter)
+	if err := registerScopedApis(apis, config.Modules, config.apiKeys, srv); err != nil {
+		return err
+	}
+	accessLog, err := openAccessLog(srv, config.accessLog, config.accessLogConfig)
+	if err != nil {
 		return err
 	}
+	var handler http.Handler = srv
+	if config.apiKeys != nil {
+		handler = newAPIKeyHandler(config.apiKeys, config.Modules, srv)
+	}
 	h.httpConfig = config
 	h.httpHandler.Store(&rpcHandler{
-		Handler: NewHTTPHandlerStack(srv, config.CorsAllowedOrigins, config.Vhosts, config.jwtSecret),
-		server:  srv,
+		Handler:   NewHTTPHandlerStack(handler, config.CorsAllowedOrigins, config.Vhosts, config.jwtSecret),
+		server:    srv,
+		accessLog: accessLog,
 	})
 	return nil
 }
@@ -309,7 +348,7 @@ func (h *httpServer) disableRPC() bool {
 	handler := h.httpHandler.Load().(*rpcHandler)
 	if handler != nil {
 		h.httpHandler.Store((*rpcHandler)(nil))
-		handler.server.Stop()
+		handler.stop()
 	}
 	return handler != nil
 }
@@ -324,13 +363,23 @@ func (h *httpServer) enableWS(apis []rpc.API, config wsConfig) error {
 	}
 	// Create RPC server and handler.
 	srv := rpc.NewServer()
-	if err := RegisterApis(apis, config.Modules, srv); err != nil {
+	srv.SetLimiter(config.limiter)
+	if err := registerScopedApis(apis, config.Modules, config.apiKeys, srv); err != nil {
+		return err
+	}
+	accessLog, err := openAccessLog(srv, config.accessLog, config.accessLogConfig)
+	if err != nil {
 		return err
 	}
+	handler := srv.WebsocketHandler(config.Origins)
+	if config.apiKeys != nil {
+		handler = newAPIKeyHandler(config.apiKeys, config.Modules, handler)
+	}
 	h.wsConfig = config
 	h.wsHandler.Store(&rpcHandler{
-		Handler: NewWSHandlerStack(srv.WebsocketHandler(config.Origins), config.jwtSecret),
-		server:  srv,
+		Handler:   NewWSHandlerStack(handler, config.jwtSecret),
+		server:    srv,
+		accessLog: accessLog,
 	})
 	return nil
 }
@@ -352,7 +401,7 @@ func (h *httpServer) disableWS() bool {
 	ws := h.wsHandler.Load().(*rpcHandler)
 	if ws != nil {
 		h.wsHandler.Store((*rpcHandler)(nil))
-		ws.server.Stop()
+		ws.stop()
 	}
 	return ws != nil
 }
@@ -473,9 +522,20 @@ func (w *gzipResponseWriter) Write(b []byte) (int, error) {
 	return w.Writer.Write(b)
 }
 
+// Flush sends the data compressed so far to the client, for streamed responses.
+func (w *gzipResponseWriter) Flush() {
+	if gz, ok := w.Writer.(*gzip.Writer); ok {
+		gz.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 func newGzipHandler(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+		// WebSocket connections are hijacked, they cannot be compressed
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") || isWebsocket(r) {
 			next.ServeHTTP(w, r)
 			return
 		}
@@ -537,6 +597,27 @@ func (is *ipcServer) stop() error {
 	return err
 }
 
+// registerScopedApis registers the APIs of the given modules, or all the APIs
+// not requiring authentication if API keys are in use. The API key handler
+// restricts the clients to the modules or the scope of their key in that case.
+func registerScopedApis(apis []rpc.API, modules []string, keys *APIKeyStore, srv *rpc.Server) error {
+	if keys == nil {
+		return RegisterApis(apis, modules, srv)
+	}
+	if bad, available := checkModuleAvailability(modules, apis); len(bad) > 0 {
+		log.Error("Unavailable modules in HTTP API list", "unavailable", bad, "available", available)
+	}
+	for _, api := range apis {
+		if api.Authenticated {
+			continue
+		}
+		if err := srv.RegisterName(api.Namespace, api.Service); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // RegisterApis checks the given modules' availability, generates an allowlist based on the allowed modules,
 // and then registers all of the APIs exposed by the services.
 func RegisterApis(apis []rpc.API, modules []string, srv *rpc.Server) error {
@@ -208,6 +208,15 @@ type ProgpowConfig struct {
 	DevFundAddress       common.Address `json:"devFundAddress"`
 	CommunityFundAddress common.Address `json:"communityFundAddress"`
 	StakerFundAddress    common.Address `json:"stakerFundAddress"`
+
+	// SafeDepth and FinalizedDepth are the number of confirmations after which
+	// a block is reported as safe or finalized. Zero disables the marker.
+	SafeDepth      uint64 `json:"safeDepth,omitempty"`
+	FinalizedDepth uint64 `json:"finalizedDepth,omitempty"`
+
+	// MaxReorgDepth is the maximum number of canonical blocks a reorg may drop
+	// before the heavier fork is refused. Zero allows reorgs of any depth.
+	MaxReorgDepth uint64 `json:"maxReorgDepth,omitempty"`
 }
 
 // String implements the stringer interface, returning the consensus engine details.
@@ -32,6 +32,22 @@ const (
 	// considered probably final and its rotated bits are calculated.
 	BloomConfirms = 256
 
+	// CodeIndexBlocks is the number of blocks a single contract code index section
+	// covers.
+	CodeIndexBlocks uint64 = 1024
+
+	// CodeIndexConfirms is the number of confirmation blocks before a contract
+	// code index section is considered probably final and is indexed.
+	CodeIndexConfirms = 64
+
+	// AccountIndexBlocks is the number of blocks a single account history index
+	// section covers.
+	AccountIndexBlocks uint64 = 256
+
+	// AccountIndexConfirms is the number of confirmation blocks before an account
+	// history index section is considered probably final and is indexed.
+	AccountIndexConfirms = 16
+
 	// CHTFrequency is the block frequency for creating CHTs
 	CHTFrequency = 32768
 
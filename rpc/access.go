@@ -0,0 +1,92 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"strings"
+)
+
+// Access restricts the methods a client may call to the ones of the listed
+// namespaces, plus the individually listed methods. The "*" namespace grants
+// access to all methods. The methods of the rpc namespace are always allowed.
+type Access struct {
+	Namespaces []string
+	Methods    []string
+}
+
+// allows reports whether the access grants calling the given method. A nil
+// access does not restrict the client.
+func (a *Access) allows(method string) bool {
+	if a == nil {
+		return true
+	}
+	namespace := strings.SplitN(method, serviceMethodSeparator, 2)[0]
+	if a.allowsNamespace(namespace) {
+		return true
+	}
+	for _, allowed := range a.Methods {
+		if allowed == method {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsNamespace reports whether the access grants calling all methods of the
+// given namespace.
+func (a *Access) allowsNamespace(namespace string) bool {
+	if a == nil || namespace == MetadataApi {
+		return true
+	}
+	for _, allowed := range a.Namespaces {
+		if allowed == namespace || allowed == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// visible reports whether the access grants calling any method of the given
+// namespace.
+func (a *Access) visible(namespace string) bool {
+	if a.allowsNamespace(namespace) {
+		return true
+	}
+	for _, allowed := range a.Methods {
+		if strings.HasPrefix(allowed, namespace+serviceMethodSeparator) {
+			return true
+		}
+	}
+	return false
+}
+
+type accessContextKey struct{}
+
+// WithAccess returns a copy of the context carrying the access granted to the
+// client. HTTP handlers wrapping the server use it to restrict the methods the
+// clients of the requests they pass on may call.
+func WithAccess(ctx context.Context, access *Access) context.Context {
+	return context.WithValue(ctx, accessContextKey{}, access)
+}
+
+// accessFromContext returns the access stored in ctx, or nil if the client is
+// not restricted.
+func accessFromContext(ctx context.Context) *Access {
+	access, _ := ctx.Value(accessContextKey{}).(*Access)
+	return access
+}
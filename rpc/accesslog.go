@@ -0,0 +1,114 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxAccessLogParams is the maximum number of parameter bytes recorded for a
+// slow call, longer parameters are truncated.
+const maxAccessLogParams = 4096
+
+// secretNamespaces are the namespaces whose methods take passwords or keys as
+// parameters, e.g. personal_unlockAccount or personal_importRawKey. The
+// parameters of their calls are never logged.
+var secretNamespaces = map[string]bool{"personal": true, "account": true, "clef": true}
+
+// AccessLogConfig configures the access log of a server.
+type AccessLogConfig struct {
+	// SlowCallThreshold is the duration above which calls are deemed slow and
+	// may be logged along with their parameters. Zero disables it.
+	SlowCallThreshold time.Duration `toml:",omitempty"`
+
+	// SlowCallSampling is the fraction of the slow calls logged along with
+	// their parameters, none by default. The parameters of the calls to the
+	// namespaces handling secrets are never logged.
+	SlowCallSampling float64 `toml:",omitempty"`
+}
+
+// accessLogEntry is a line of the access log.
+type accessLogEntry struct {
+	Time      time.Time `json:"time"`
+	Transport string    `json:"transport"`
+	Remote    string    `json:"remote"`
+	Client    string    `json:"client,omitempty"`
+	Method    string    `json:"method"`
+	Duration  float64   `json:"duration"` // in milliseconds
+	Code      int       `json:"code,omitempty"`
+	BytesIn   int       `json:"bytesIn"`
+	BytesOut  int       `json:"bytesOut"`
+	Slow      bool      `json:"slow,omitempty"`
+	Params    string    `json:"params,omitempty"`
+}
+
+// accessLogger writes a JSON line for every call served.
+type accessLogger struct {
+	config AccessLogConfig
+
+	lock sync.Mutex
+	out  io.Writer
+	rand *rand.Rand
+}
+
+// log records a served call and the response sent for it, along with its
+// parameters if it is a sampled slow call not carrying secrets.
+func (l *accessLogger) log(ctx context.Context, msg *jsonrpcMessage, resp *jsonrpcMessage, elapsed time.Duration) {
+	info := PeerInfoFromContext(ctx)
+	entry := &accessLogEntry{
+		Time:      time.Now().UTC(),
+		Transport: info.Transport,
+		Remote:    info.RemoteAddr,
+		Client:    info.AuthSubject,
+		Method:    msg.Method,
+		Duration:  float64(elapsed.Microseconds()) / 1000,
+		BytesIn:   len(msg.Params),
+	}
+	if resp != nil {
+		entry.BytesOut = len(resp.Result)
+		if resp.Error != nil {
+			entry.Code, entry.BytesOut = resp.Error.Code, len(resp.Error.Message)
+		}
+	}
+	if stream := StreamFromContext(ctx); stream != nil && stream.sent() {
+		entry.BytesOut = stream.written
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.config.SlowCallThreshold > 0 && elapsed >= l.config.SlowCallThreshold {
+		entry.Slow = true
+		namespace := strings.SplitN(msg.Method, serviceMethodSeparator, 2)[0]
+		if !secretNamespaces[namespace] && l.rand.Float64() < l.config.SlowCallSampling {
+			entry.Params = string(msg.Params)
+			if len(entry.Params) > maxAccessLogParams {
+				entry.Params = entry.Params[:maxAccessLogParams] + "..."
+			}
+		}
+	}
+	blob, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	l.out.Write(append(blob, '\n'))
+}
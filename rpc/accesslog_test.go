@@ -0,0 +1,99 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAccessLog(t *testing.T) {
+	var (
+		out    = new(bytes.Buffer)
+		server = newTestServer()
+	)
+	server.SetAccessLog(out, AccessLogConfig{SlowCallThreshold: 50 * time.Millisecond, SlowCallSampling: 1})
+	server.SetLimits(Limits{ResponseBytes: 64})
+	defer server.Stop()
+
+	// Calls to the namespaces handling secrets are logged without parameters
+	if err := server.RegisterName("personal", new(testService)); err != nil {
+		t.Fatal(err)
+	}
+
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	client, err := Dial(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	var result echoResult
+	if err := client.Call(&result, "test_echo", "hello", 1); err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	if err := client.Call(nil, "test_returnError"); err == nil {
+		t.Fatalf("erroring call succeeded")
+	}
+	if err := client.Call(nil, "test_sleep", 100*time.Millisecond); err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	if err := client.Call(nil, "personal_sleep", 100*time.Millisecond); err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	if err := client.Call(&result, "test_echo", strings.Repeat("x", 100), 1); err == nil {
+		t.Fatalf("oversized response not refused")
+	}
+	var entries []accessLogEntry
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var entry accessLogEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid access log line %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) != 5 {
+		t.Fatalf("access log entry count mismatch: have %d, want 5", len(entries))
+	}
+	for i, entry := range entries {
+		if entry.Transport != "http" || entry.Remote == "" || entry.Time.IsZero() {
+			t.Errorf("entry %d: connection info missing: %+v", i, entry)
+		}
+	}
+	if e := entries[0]; e.Method != "test_echo" || e.Code != 0 || e.BytesIn != len(`["hello",1]`) || e.BytesOut == 0 || e.Slow || e.Params != "" {
+		t.Errorf("successful call entry mismatch: %+v", e)
+	}
+	if e := entries[1]; e.Method != "test_returnError" || e.Code != 444 || e.Slow {
+		t.Errorf("failed call entry mismatch: %+v", e)
+	}
+	if e := entries[2]; e.Method != "test_sleep" || !e.Slow || e.Duration < 100 || e.Params != "[100000000]" {
+		t.Errorf("slow call entry mismatch: %+v", e)
+	}
+	if e := entries[3]; e.Method != "personal_sleep" || !e.Slow || e.Params != "" {
+		t.Errorf("slow secret call entry mismatch: %+v", e)
+	}
+	// The refusal of an oversized response is logged, not the response itself
+	if e := entries[4]; e.Method != "test_echo" || e.Code != -32003 || e.BytesOut != len("response too large") {
+		t.Errorf("oversized response entry mismatch: %+v", e)
+	}
+}
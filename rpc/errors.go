@@ -54,9 +54,14 @@ var (
 	_ Error = new(invalidRequestError)
 	_ Error = new(invalidMessageError)
 	_ Error = new(invalidParamsError)
+	_ Error = new(limitExceededError)
+	_ Error = new(responseTooLargeError)
 )
 
-const defaultErrorCode = -32000
+const (
+	defaultErrorCode       = -32000
+	limitExceededErrorCode = -32005
+)
 
 type methodNotFoundError struct{ method string }
 
@@ -101,3 +106,17 @@ type invalidParamsError struct{ message string }
 func (e *invalidParamsError) ErrorCode() int { return -32602 }
 
 func (e *invalidParamsError) Error() string { return e.message }
+
+// request exceeds the limits imposed on the client
+type limitExceededError struct{ message string }
+
+func (e *limitExceededError) ErrorCode() int { return limitExceededErrorCode }
+
+func (e *limitExceededError) Error() string { return e.message }
+
+// response exceeds the maximum response size
+type responseTooLargeError struct{}
+
+func (e *responseTooLargeError) ErrorCode() int { return -32003 }
+
+func (e *responseTooLargeError) Error() string { return "response too large" }
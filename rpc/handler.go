@@ -61,6 +61,9 @@ type handler struct {
 	conn           jsonWriter                     // where responses will be sent
 	log            log.Logger
 	allowSubscribe bool
+	allowStream    bool          // whether call results may be streamed
+	limits         *Limiter      // limits imposed on incoming calls, nil if unlimited
+	accessLog      *accessLogger // access log of incoming calls, nil if disabled
 
 	subLock    sync.Mutex
 	serverSubs map[ID]*Subscription
@@ -112,13 +115,40 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 	if len(calls) == 0 {
 		return
 	}
+	// Refuse the whole batch if it exceeds the limit:
+	if h.limits != nil && h.limits.limits.BatchItems > 0 && len(calls) > h.limits.limits.BatchItems {
+		throttledBatchMeter.Mark(1)
+		h.startCallProc(func(cp *callProc) {
+			err := &limitExceededError{"batch too large"}
+			answers := make([]*jsonrpcMessage, 0, len(calls))
+			for _, msg := range calls {
+				if msg.hasValidID() {
+					answers = append(answers, msg.errorResponse(err))
+				}
+			}
+			if len(answers) > 0 {
+				h.conn.writeJSON(cp.ctx, answers)
+			} else {
+				h.conn.writeJSON(cp.ctx, errorMessage(err))
+			}
+		})
+		return
+	}
 	// Process calls on a goroutine because they may block indefinitely:
 	h.startCallProc(func(cp *callProc) {
-		answers := make([]*jsonrpcMessage, 0, len(msgs))
+		var (
+			answers = make([]*jsonrpcMessage, 0, len(msgs))
+			size    int
+		)
 		for _, msg := range calls {
-			if answer := h.handleCallMsg(cp, msg); answer != nil {
+			start := time.Now()
+			answer := h.handleCallMsg(cp, msg)
+			if answer != nil {
+				size += len(answer.Result)
+				answer = h.limitResponse(msg, answer, size)
 				answers = append(answers, answer)
 			}
+			h.logAccess(cp, msg, answer, start)
 		}
 		h.addSubscriptions(cp.notifiers)
 		if len(answers) > 0 {
@@ -136,9 +166,18 @@ func (h *handler) handleMsg(msg *jsonrpcMessage) {
 		return
 	}
 	h.startCallProc(func(cp *callProc) {
+		var stream *ResultStream
+		if h.allowStream && msg.isCall() {
+			stream = h.newStream(cp, msg)
+		}
+		start := time.Now()
 		answer := h.handleCallMsg(cp, msg)
-		h.addSubscriptions(cp.notifiers)
 		if answer != nil {
+			answer = h.limitResponse(msg, answer, len(answer.Result))
+		}
+		h.logAccess(cp, msg, answer, start)
+		h.addSubscriptions(cp.notifiers)
+		if answer != nil && (stream == nil || !stream.sent()) {
 			h.conn.writeJSON(cp.ctx, answer)
 		}
 		for _, n := range cp.notifiers {
@@ -147,6 +186,40 @@ func (h *handler) handleMsg(msg *jsonrpcMessage) {
 	})
 }
 
+// newStream installs a stream for the result of a call in the context of the
+// call, if the connection supports streaming.
+func (h *handler) newStream(cp *callProc, msg *jsonrpcMessage) *ResultStream {
+	conn, ok := h.conn.(rawWriter)
+	if !ok {
+		return nil
+	}
+	stream := &ResultStream{ctx: cp.ctx, conn: conn, id: msg.ID}
+	if h.limits != nil {
+		stream.limit = h.limits.limits.ResponseBytes
+	}
+	cp.ctx = context.WithValue(cp.ctx, resultStreamKey{}, stream)
+	return stream
+}
+
+// limitResponse replaces the answer to a call with an error if the size of the
+// response, including the answers preceding it in a batch, exceeds the limit.
+func (h *handler) limitResponse(msg *jsonrpcMessage, answer *jsonrpcMessage, size int) *jsonrpcMessage {
+	if h.limits == nil || h.limits.limits.ResponseBytes == 0 || size <= h.limits.limits.ResponseBytes || answer.Result == nil {
+		return answer
+	}
+	throttledResponseMeter.Mark(1)
+	return msg.errorResponse(&responseTooLargeError{})
+}
+
+// logAccess records a served call and the answer sent for it in the access log,
+// if enabled. Invalid messages are not logged.
+func (h *handler) logAccess(cp *callProc, msg *jsonrpcMessage, answer *jsonrpcMessage, start time.Time) {
+	if h.accessLog == nil || (!msg.isCall() && !msg.isNotification()) {
+		return
+	}
+	h.accessLog.log(cp.ctx, msg, answer, time.Since(start))
+}
+
 // close cancels all requests except for inflightReq and waits for
 // call goroutines to shut down.
 func (h *handler) close(err error, inflightReq *requestOp) {
@@ -303,7 +376,11 @@ func (h *handler) handleCallMsg(ctx *callProc, msg *jsonrpcMessage) *jsonrpcMess
 			if resp.Error.Data != nil {
 				ctx = append(ctx, "errdata", resp.Error.Data)
 			}
-			h.log.Warn("Served "+msg.Method, ctx...)
+			if resp.Error.Code == limitExceededErrorCode {
+				h.log.Debug("Refused "+msg.Method, ctx...)
+			} else {
+				h.log.Warn("Served "+msg.Method, ctx...)
+			}
 		} else {
 			h.log.Debug("Served "+msg.Method, ctx...)
 		}
@@ -317,6 +394,16 @@ func (h *handler) handleCallMsg(ctx *callProc, msg *jsonrpcMessage) *jsonrpcMess
 
 // handleCall processes method calls.
 func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage {
+	if !PeerInfoFromContext(cp.ctx).access.allows(msg.Method) {
+		return msg.errorResponse(&methodNotFoundError{method: msg.Method})
+	}
+	if h.limits != nil && !msg.isUnsubscribe() {
+		release, err := h.limits.admit(cp.ctx, msg.Method)
+		if err != nil {
+			return msg.errorResponse(err)
+		}
+		defer release()
+	}
 	if msg.isSubscribe() {
 		return h.handleSubscribe(cp, msg)
 	}
@@ -387,6 +474,9 @@ func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMes
 // runMethod runs the Go callback for an RPC method.
 func (h *handler) runMethod(ctx context.Context, msg *jsonrpcMessage, callb *callback, args []reflect.Value) *jsonrpcMessage {
 	result, err := callb.call(ctx, msg.Method, args)
+	if stream := StreamFromContext(ctx); stream != nil && stream.started {
+		return stream.finish(msg, err)
+	}
 	if err != nil {
 		return msg.errorResponse(err)
 	}
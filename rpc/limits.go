@@ -0,0 +1,229 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// limiterSweepInterval is the time between two sweeps dropping the state of
+// the clients which have been idle long enough to be back at their full quota.
+const limiterSweepInterval = time.Minute
+
+// Limits configures the resource limits the server imposes on the calls of its
+// clients. Clients are told apart by the subject of their authentication token
+// if they presented one, or by their IP address otherwise. The zero value
+// imposes no limits.
+type Limits struct {
+	// Rates maps method names (e.g. "eth_getLogs"), namespaces (e.g. "debug")
+	// or "*" for all calls to the request rate allowed to each client. A call
+	// has to pass all the rate limits matching it.
+	Rates map[string]RateLimit `toml:",omitempty"`
+
+	// Expensive lists the method names and namespaces whose calls are deemed
+	// expensive. MaxExpensive is the number of expensive calls each client may
+	// have in flight at the same time, zero meaning unlimited.
+	Expensive    []string `toml:",omitempty"`
+	MaxExpensive int      `toml:",omitempty"`
+
+	// BatchItems is the maximum number of requests in a batch.
+	BatchItems int `toml:",omitempty"`
+
+	// ResponseBytes is the maximum size of the results of a call, or of all
+	// the calls of a batch together.
+	ResponseBytes int `toml:",omitempty"`
+}
+
+// RateLimit is a token bucket refilling at Rate tokens per second, holding up
+// to Burst tokens. Each call takes one token.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// DefaultExpensiveMethods are the methods and namespaces deemed expensive by
+// default.
+var DefaultExpensiveMethods = []string{"debug", "eth_getLogs"}
+
+// bucket is the state of a token bucket.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// refill adds the tokens accumulated since the last update of the bucket.
+func (b *bucket) refill(limit RateLimit, now time.Time) {
+	b.tokens += now.Sub(b.last).Seconds() * limit.Rate
+	if b.tokens > float64(limit.Burst) {
+		b.tokens = float64(limit.Burst)
+	}
+	b.last = now
+}
+
+// clientLimits is the limiting state of a single client.
+type clientLimits struct {
+	buckets   map[string]*bucket // Token buckets by the rate limit key
+	expensive int                // Number of expensive calls in flight
+}
+
+// Limiter enforces the configured limits on the calls of all the clients of a
+// server. It can be shared between servers, e.g. the HTTP and WebSocket ones, to
+// hold the clients to the same quota across them.
+type Limiter struct {
+	limits    Limits
+	expensive map[string]bool
+
+	lock    sync.Mutex
+	clients map[string]*clientLimits
+	swept   time.Time
+}
+
+// NewLimiter creates a limiter enforcing the given limits, or returns nil if
+// there are no limits to enforce.
+func NewLimiter(limits Limits) *Limiter {
+	if len(limits.Rates) == 0 && limits.MaxExpensive == 0 && limits.BatchItems == 0 && limits.ResponseBytes == 0 {
+		return nil
+	}
+	l := &Limiter{
+		limits:    limits,
+		expensive: make(map[string]bool),
+		clients:   make(map[string]*clientLimits),
+		swept:     time.Now(),
+	}
+	for _, name := range limits.Expensive {
+		l.expensive[name] = true
+	}
+	return l
+}
+
+// admit checks whether the client may call the given method, taking a token
+// from each of the matching rate limits. The returned function has to be called
+// when the call finishes.
+func (l *Limiter) admit(ctx context.Context, method string) (func(), error) {
+	var (
+		namespace = strings.SplitN(method, serviceMethodSeparator, 2)[0]
+		keys      []string
+		expensive = l.limits.MaxExpensive > 0 && (l.expensive[method] || l.expensive[namespace])
+	)
+	for _, key := range []string{method, namespace, "*"} {
+		if _, ok := l.limits.Rates[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 && !expensive {
+		return func() {}, nil
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.swept) > limiterSweepInterval {
+		l.sweep(now)
+	}
+	id := clientID(ctx)
+	client := l.clients[id]
+	if client == nil {
+		client = &clientLimits{buckets: make(map[string]*bucket)}
+		l.clients[id] = client
+	}
+	if expensive && client.expensive >= l.limits.MaxExpensive {
+		throttledConcurrencyMeter.Mark(1)
+		return nil, &limitExceededError{"too many concurrent expensive calls"}
+	}
+	// Only take the tokens if all the rate limits allow the call
+	for _, key := range keys {
+		limit := l.limits.Rates[key]
+		b := client.buckets[key]
+		if b == nil {
+			b = &bucket{tokens: float64(limit.Burst), last: now}
+			client.buckets[key] = b
+		}
+		b.refill(limit, now)
+		if b.tokens < 1 {
+			throttledRateMeter.Mark(1)
+			return nil, &limitExceededError{"request rate limit exceeded for " + key}
+		}
+	}
+	for _, key := range keys {
+		client.buckets[key].tokens--
+	}
+	if !expensive {
+		return func() {}, nil
+	}
+	client.expensive++
+	return func() {
+		l.lock.Lock()
+		defer l.lock.Unlock()
+
+		client.expensive--
+	}, nil
+}
+
+// sweep drops the state of the clients without calls in flight whose token
+// buckets are all full again. The caller must hold the lock.
+func (l *Limiter) sweep(now time.Time) {
+	for id, client := range l.clients {
+		if client.expensive > 0 {
+			continue
+		}
+		full := true
+		for key, b := range client.buckets {
+			limit := l.limits.Rates[key]
+			if b.refill(limit, now); b.tokens < float64(limit.Burst) {
+				full = false
+				break
+			}
+		}
+		if full {
+			delete(l.clients, id)
+		}
+	}
+	l.swept = now
+}
+
+// clientID returns the identifier of the client making a call, which is the
+// subject of its authentication token or its IP address.
+func clientID(ctx context.Context) string {
+	info := PeerInfoFromContext(ctx)
+	if info.AuthSubject != "" {
+		return "sub:" + info.AuthSubject
+	}
+	host, _, err := net.SplitHostPort(info.RemoteAddr)
+	if err != nil {
+		return info.RemoteAddr
+	}
+	return host
+}
+
+type authSubjectContextKey struct{}
+
+// WithAuthSubject returns a copy of the context carrying the subject of the
+// token the client authenticated with. HTTP handlers wrapping the server use it
+// to identify the clients of the requests they pass on.
+func WithAuthSubject(ctx context.Context, subject string) context.Context {
+	return context.WithValue(ctx, authSubjectContextKey{}, subject)
+}
+
+// authSubjectFromContext returns the authentication subject stored in ctx.
+func authSubjectFromContext(ctx context.Context) string {
+	subject, _ := ctx.Value(authSubjectContextKey{}).(string)
+	return subject
+}
@@ -0,0 +1,158 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newLimitedTestClient starts an HTTP server with the given limits and connects
+// to it.
+func newLimitedTestClient(t *testing.T, limits Limits) *Client {
+	server := newTestServer()
+	server.SetLimits(limits)
+	t.Cleanup(server.Stop)
+
+	ts := httptest.NewServer(server)
+	t.Cleanup(ts.Close)
+
+	client, err := Dial(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Close)
+	return client
+}
+
+// checkErrorCode checks that err is an RPC error with the given code.
+func checkErrorCode(t *testing.T, err error, code int) {
+	t.Helper()
+
+	rpcErr, ok := err.(Error)
+	if !ok {
+		t.Fatalf("wrong error %v, want code %d", err, code)
+	}
+	if rpcErr.ErrorCode() != code {
+		t.Fatalf("wrong error code %d (%v), want %d", rpcErr.ErrorCode(), err, code)
+	}
+}
+
+func TestLimitsRate(t *testing.T) {
+	client := newLimitedTestClient(t, Limits{Rates: map[string]RateLimit{
+		"test_echo": {Rate: 0.001, Burst: 2},
+		"test":      {Rate: 0.001, Burst: 3},
+	}})
+	var result echoResult
+	for i := 0; i < 2; i++ {
+		if err := client.Call(&result, "test_echo", "x", 1); err != nil {
+			t.Fatalf("call %d failed: %v", i, err)
+		}
+	}
+	// The method limit is exhausted, the namespace one is not
+	err := client.Call(&result, "test_echo", "x", 1)
+	checkErrorCode(t, err, limitExceededErrorCode)
+
+	var str string
+	if err := client.Call(&str, "test_rets"); err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	// Both limits are exhausted now, other namespaces are unaffected
+	err = client.Call(&str, "test_rets")
+	checkErrorCode(t, err, limitExceededErrorCode)
+
+	var modules map[string]string
+	if err := client.Call(&modules, "rpc_modules"); err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+}
+
+func TestLimitsExpensive(t *testing.T) {
+	l := NewLimiter(Limits{Expensive: []string{"debug", "eth_getLogs"}, MaxExpensive: 1})
+
+	var (
+		alice = context.WithValue(context.Background(), peerInfoContextKey{}, PeerInfo{RemoteAddr: "10.0.0.1:1000"})
+		bob   = context.WithValue(context.Background(), peerInfoContextKey{}, PeerInfo{RemoteAddr: "10.0.0.1:2000", AuthSubject: "bob"})
+	)
+	release, err := l.admit(alice, "debug_traceBlock")
+	if err != nil {
+		t.Fatalf("first expensive call refused: %v", err)
+	}
+	if _, err := l.admit(alice, "eth_getLogs"); err == nil {
+		t.Fatalf("concurrent expensive call admitted")
+	}
+	if _, err := l.admit(alice, "eth_blockNumber"); err != nil {
+		t.Fatalf("cheap call refused: %v", err)
+	}
+	// Clients authenticated with a token are limited by their subject
+	if _, err := l.admit(bob, "eth_getLogs"); err != nil {
+		t.Fatalf("expensive call of other client refused: %v", err)
+	}
+	release()
+	if _, err := l.admit(alice, "eth_getLogs"); err != nil {
+		t.Fatalf("expensive call refused after release: %v", err)
+	}
+}
+
+func TestLimitsBatch(t *testing.T) {
+	client := newLimitedTestClient(t, Limits{BatchItems: 2})
+
+	batch := make([]BatchElem, 3)
+	for i := range batch {
+		batch[i] = BatchElem{Method: "test_echo", Args: []interface{}{"x", i}, Result: new(echoResult)}
+	}
+	if err := client.BatchCall(batch); err != nil {
+		t.Fatalf("batch call failed: %v", err)
+	}
+	for _, elem := range batch {
+		checkErrorCode(t, elem.Error, limitExceededErrorCode)
+	}
+	if err := client.BatchCall(batch[:2]); err != nil {
+		t.Fatalf("batch call failed: %v", err)
+	}
+	for i, elem := range batch[:2] {
+		if elem.Error != nil {
+			t.Fatalf("batch element %d failed: %v", i, elem.Error)
+		}
+	}
+}
+
+func TestLimitsResponseSize(t *testing.T) {
+	client := newLimitedTestClient(t, Limits{ResponseBytes: 100})
+
+	var result echoResult
+	if err := client.Call(&result, "test_echo", "x", 1); err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	err := client.Call(&result, "test_echo", strings.Repeat("x", 100), 1)
+	checkErrorCode(t, err, -32003)
+
+	// The limit applies to the whole batch
+	batch := make([]BatchElem, 3)
+	for i := range batch {
+		batch[i] = BatchElem{Method: "test_echo", Args: []interface{}{strings.Repeat("x", 30), i}, Result: new(echoResult)}
+	}
+	if err := client.BatchCall(batch); err != nil {
+		t.Fatalf("batch call failed: %v", err)
+	}
+	if batch[0].Error != nil {
+		t.Fatalf("first batch element failed: %v", batch[0].Error)
+	}
+	checkErrorCode(t, batch[2].Error, -32003)
+}
@@ -32,6 +32,12 @@ var (
 	serveTimeHistName = "rpc/duration"
 
 	rpcServingTimer = metrics.NewRegisteredTimer("rpc/duration/all", nil)
+
+	// Requests refused for exceeding the limits, by the limit exceeded.
+	throttledRateMeter        = metrics.NewRegisteredMeter("rpc/throttled/rate", nil)
+	throttledConcurrencyMeter = metrics.NewRegisteredMeter("rpc/throttled/concurrency", nil)
+	throttledBatchMeter       = metrics.NewRegisteredMeter("rpc/throttled/batch", nil)
+	throttledResponseMeter    = metrics.NewRegisteredMeter("rpc/throttled/response", nil)
 )
 
 // updateServeTimeHistogram tracks the serving time of a remote RPC call.
@@ -19,7 +19,9 @@ package rpc
 import (
 	"context"
 	"io"
+	"math/rand"
 	"sync/atomic"
+	"time"
 
 	mapset "github.com/deckarep/golang-set"
 	"github.com/ethereum/go-ethereum/log"
@@ -47,6 +49,14 @@ type Server struct {
 	idgen    func() ID
 	run      int32
 	codecs   mapset.Set
+	config   serverConfig
+}
+
+// serverConfig holds the settings the server applies to the calls of its
+// clients.
+type serverConfig struct {
+	limits    *Limiter      // limits imposed on the calls, nil if unlimited
+	accessLog *accessLogger // access log of the calls, nil if disabled
 }
 
 // NewServer creates a new server instance with no registered handlers.
@@ -67,6 +77,30 @@ func (s *Server) RegisterName(name string, receiver interface{}) error {
 	return s.services.registerName(name, receiver)
 }
 
+// SetLimits configures the limits imposed on the calls of the clients. It must
+// be called before the server starts serving requests.
+func (s *Server) SetLimits(limits Limits) {
+	s.SetLimiter(NewLimiter(limits))
+}
+
+// SetLimiter configures the limiter imposing its limits on the calls of the
+// clients, which may be shared with other servers. It must be called before the
+// server starts serving requests.
+func (s *Server) SetLimiter(limiter *Limiter) {
+	s.config.limits = limiter
+}
+
+// SetAccessLog configures the access log the server writes a JSON line to for
+// every call served. It must be called before the server starts serving
+// requests.
+func (s *Server) SetAccessLog(out io.Writer, config AccessLogConfig) {
+	s.config.accessLog = &accessLogger{
+		config: config,
+		out:    out,
+		rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
 // ServeCodec reads incoming requests from codec, calls the appropriate callback and writes
 // the response back using the given codec. It will block until the codec is closed or the
 // server is stopped. In either case the codec is closed.
@@ -84,7 +118,7 @@ func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
 	s.codecs.Add(codec)
 	defer s.codecs.Remove(codec)
 
-	c := initClient(codec, s.idgen, &s.services)
+	c := initClient(codec, s.idgen, &s.services, &s.config)
 	<-codec.closed()
 	c.Close()
 }
@@ -100,6 +134,7 @@ func (s *Server) serveSingleRequest(ctx context.Context, codec ServerCodec) {
 
 	h := newHandler(ctx, codec, s.idgen, &s.services)
 	h.allowSubscribe = false
+	h.limits, h.accessLog = s.config.limits, s.config.accessLog
 	defer h.close(io.EOF, nil)
 
 	reqs, batch, err := codec.readBatch()
@@ -112,6 +147,7 @@ func (s *Server) serveSingleRequest(ctx context.Context, codec ServerCodec) {
 	if batch {
 		h.handleBatch(reqs)
 	} else {
+		h.allowStream = PeerInfoFromContext(ctx).stream
 		h.handleMsg(reqs[0])
 	}
 }
@@ -135,14 +171,18 @@ type RPCService struct {
 	server *Server
 }
 
-// Modules returns the list of RPC services with their version number
-func (s *RPCService) Modules() map[string]string {
+// Modules returns the list of RPC services available to the caller with their
+// version number
+func (s *RPCService) Modules(ctx context.Context) map[string]string {
 	s.server.services.mu.Lock()
 	defer s.server.services.mu.Unlock()
 
+	access := PeerInfoFromContext(ctx).access
 	modules := make(map[string]string)
 	for name := range s.server.services.services {
-		modules[name] = "1.0"
+		if access.visible(name) {
+			modules[name] = "1.0"
+		}
 	}
 	return modules
 }
@@ -160,6 +200,15 @@ type PeerInfo struct {
 	// Address of client. This will usually contain the IP address and port.
 	RemoteAddr string
 
+	// Subject of the token the client authenticated with, if any.
+	AuthSubject string
+
+	// Methods the client may call, nil if unrestricted.
+	access *Access
+
+	// Whether the client requested call results to be streamed.
+	stream bool
+
 	// Addditional information for HTTP and WebSocket connections.
 	HTTP struct {
 		// Protocol version, i.e. "HTTP/1.1". This is not set for WebSocket.
@@ -0,0 +1,157 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	// StreamHeader is the HTTP header requesting the result of a call to be
+	// streamed, if the method supports it. The request itself is unchanged.
+	StreamHeader = "X-RPC-Stream"
+
+	// streamFlushSize is the amount of encoded result data buffered before it
+	// is written to the connection.
+	streamFlushSize = 64 * 1024
+)
+
+var errStreamUnsupported = errors.New("connection does not support streaming")
+
+// ResultStream writes the elements of the array result of a call to the
+// connection as they are produced, instead of the complete result being built
+// in memory first. Methods supporting streaming obtain it via StreamFromContext
+// and write the elements in order. The value returned by the method is ignored
+// once an element was written.
+//
+// If the method fails after part of the result was written, the response is cut
+// short, leaving the client with an incomplete JSON document. Otherwise the
+// error is returned as usual.
+type ResultStream struct {
+	ctx   context.Context
+	conn  rawWriter
+	id    json.RawMessage
+	limit int // Maximum number of bytes written, zero if unlimited
+
+	buf     bytes.Buffer
+	written int   // Number of bytes written to the connection
+	started bool  // Whether the response was started
+	err     error // Error encountered while writing
+}
+
+// rawWriter is implemented by the codecs able to stream responses.
+type rawWriter interface {
+	writeRaw(ctx context.Context, data []byte) error
+}
+
+type resultStreamKey struct{}
+
+// StreamFromContext returns the stream to write the result of the current call
+// to, or nil if the client did not request streaming.
+func StreamFromContext(ctx context.Context) *ResultStream {
+	stream, _ := ctx.Value(resultStreamKey{}).(*ResultStream)
+	return stream
+}
+
+// Write appends an element to the result array.
+func (s *ResultStream) Write(elem interface{}) error {
+	if s.err != nil {
+		return s.err
+	}
+	enc, err := json.Marshal(elem)
+	if err != nil {
+		return err
+	}
+	if !s.started {
+		s.buf.WriteString(`{"jsonrpc":"2.0","id":`)
+		s.buf.Write(s.id)
+		s.buf.WriteString(`,"result":[`)
+		s.started = true
+	} else {
+		s.buf.WriteByte(',')
+	}
+	s.buf.Write(enc)
+	if s.buf.Len() >= streamFlushSize {
+		return s.flush()
+	}
+	return nil
+}
+
+// flush writes out the buffered data.
+func (s *ResultStream) flush() error {
+	if s.limit > 0 && s.written+s.buf.Len() > s.limit {
+		throttledResponseMeter.Mark(1)
+		s.err = &responseTooLargeError{}
+		return s.err
+	}
+	if s.err = s.conn.writeRaw(s.ctx, s.buf.Bytes()); s.err != nil {
+		return s.err
+	}
+	s.written += s.buf.Len()
+	s.buf.Reset()
+	return nil
+}
+
+// sent reports whether any part of the response was written to the connection.
+func (s *ResultStream) sent() bool {
+	return s.written > 0
+}
+
+// finish completes the streamed response of a call which returned err. The
+// returned message stands for the response in logs and metrics.
+func (s *ResultStream) finish(msg *jsonrpcMessage, err error) *jsonrpcMessage {
+	if err == nil && s.err == nil {
+		s.buf.WriteString("]}\n")
+		s.flush()
+	}
+	if err == nil {
+		err = s.err
+	}
+	if err != nil {
+		return msg.errorResponse(err)
+	}
+	return msg.response(nil)
+}
+
+// writeRaw writes pre-encoded data to the connection, flushing it right away.
+func (c *jsonCodec) writeRaw(ctx context.Context, data []byte) error {
+	c.encMu.Lock()
+	defer c.encMu.Unlock()
+
+	w, ok := c.conn.(io.Writer)
+	if !ok {
+		return errStreamUnsupported
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultWriteTimeout)
+	}
+	c.conn.SetWriteDeadline(deadline)
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if f, ok := c.conn.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
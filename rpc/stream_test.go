@@ -0,0 +1,145 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type streamTestService struct{}
+
+// Numbers returns the numbers below n, failing when reaching fail.
+func (streamTestService) Numbers(ctx context.Context, n int, fail int) ([]int, error) {
+	var (
+		stream = StreamFromContext(ctx)
+		result = []int{}
+	)
+	for i := 0; i < n; i++ {
+		if i == fail {
+			return nil, errors.New("numbers failed")
+		}
+		if stream != nil {
+			if err := stream.Write(i); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		result = append(result, i)
+	}
+	return result, nil
+}
+
+func newStreamTestServer(t *testing.T, limits Limits) *httptest.Server {
+	server := NewServer()
+	if err := server.RegisterName("test", streamTestService{}); err != nil {
+		t.Fatal(err)
+	}
+	server.SetLimits(limits)
+	ts := httptest.NewServer(server)
+	t.Cleanup(func() {
+		ts.Close()
+		server.Stop()
+	})
+	return ts
+}
+
+func streamTestRequest(t *testing.T, url string, body string, stream bool) string {
+	req, _ := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	req.Header.Set("content-type", contentType)
+	if stream {
+		req.Header.Set(StreamHeader, "true")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	blob, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read response: %v", err)
+	}
+	return string(blob)
+}
+
+// Tests that streamed results are identical to regular ones, and that failures
+// are reported as errors until part of the result was sent.
+func TestStreamResult(t *testing.T) {
+	ts := newStreamTestServer(t, Limits{})
+
+	call := `{"jsonrpc":"2.0","id":1,"method":"test_numbers","params":[3,-1]}`
+	want := `{"jsonrpc":"2.0","id":1,"result":[0,1,2]}` + "\n"
+	if have := streamTestRequest(t, ts.URL, call, true); have != want {
+		t.Errorf("streamed response mismatch: have %q, want %q", have, want)
+	}
+	if have := streamTestRequest(t, ts.URL, call, false); have != want {
+		t.Errorf("regular response mismatch: have %q, want %q", have, want)
+	}
+	// Batches are never streamed
+	batch := "[" + call + "]"
+	if have := streamTestRequest(t, ts.URL, batch, true); have != "["+strings.TrimSpace(want)+"]\n" {
+		t.Errorf("batch response mismatch: have %q", have)
+	}
+	// Failures before anything was sent are regular errors
+	var resp jsonrpcMessage
+	failed := streamTestRequest(t, ts.URL, `{"jsonrpc":"2.0","id":1,"method":"test_numbers","params":[3,1]}`, true)
+	if err := json.Unmarshal([]byte(failed), &resp); err != nil || resp.Error == nil || resp.Error.Message != "numbers failed" {
+		t.Errorf("early failure response mismatch: %q", failed)
+	}
+	// Failures after part of the result was sent cut the response short
+	failed = streamTestRequest(t, ts.URL, `{"jsonrpc":"2.0","id":1,"method":"test_numbers","params":[100000,50000]}`, true)
+	if !strings.HasPrefix(failed, `{"jsonrpc":"2.0","id":1,"result":[0,1,2,`) || json.Valid([]byte(failed)) {
+		t.Errorf("late failure response not cut short: %d bytes", len(failed))
+	}
+	// Clients decode streamed results transparently
+	client, err := DialHTTP(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	client.SetHeader(StreamHeader, "true")
+
+	var numbers []int
+	if err := client.Call(&numbers, "test_numbers", 20000, -1); err != nil {
+		t.Fatalf("streamed call failed: %v", err)
+	}
+	if len(numbers) != 20000 || numbers[19999] != 19999 {
+		t.Fatalf("streamed result mismatch: %d numbers", len(numbers))
+	}
+}
+
+// Tests that streamed results are subject to the response size limit.
+func TestStreamResponseSize(t *testing.T) {
+	ts := newStreamTestServer(t, Limits{ResponseBytes: 1024})
+
+	var resp jsonrpcMessage
+	blob := streamTestRequest(t, ts.URL, `{"jsonrpc":"2.0","id":1,"method":"test_numbers","params":[100000,-1]}`, true)
+	if err := json.Unmarshal([]byte(blob), &resp); err != nil || resp.Error == nil || resp.Error.Code != (&responseTooLargeError{}).ErrorCode() {
+		t.Fatalf("oversized response mismatch: %q", blob)
+	}
+	want := `{"jsonrpc":"2.0","id":1,"result":[0,1,2]}` + "\n"
+	if have := streamTestRequest(t, ts.URL, `{"jsonrpc":"2.0","id":1,"method":"test_numbers","params":[3,-1]}`, true); have != want {
+		t.Errorf("small response mismatch: have %q, want %q", have, want)
+	}
+}
@@ -164,7 +164,7 @@ func runRandTest(rt randTest) error {
 				return err
 			}
 			if nodes != nil {
-				if err := triedb.Update(trie.NewWithNodeSet(nodes)); err != nil {
+				if err := triedb.Update(hash, common.Hash{}, trie.NewWithNodeSet(nodes)); err != nil {
 					return err
 				}
 			}
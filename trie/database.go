@@ -87,6 +87,15 @@ type Database struct {
 	childrenSize common.StorageSize // Storage size of the external children tracking
 	preimages    *preimageStore     // The store for caching preimages
 
+	scheme     string                      // Scheme the trie nodes are persisted with
+	diskRoot   common.Hash                 // Root of the state persisted in place (path scheme)
+	layers     map[common.Hash]*diffLayer  // Unpersisted state transitions keyed by root (path scheme)
+	layerNodes map[common.Hash]*cachedNode // Trie nodes referenced by the diff layers (path scheme)
+	layerSeq   uint64                      // Sequence number of the latest diff layer
+	layersSize common.StorageSize          // Storage size of the diff layers
+	diffHead   uint64                      // Id of the latest persisted reverse diff
+	history    uint64                      // Number of reverse diffs to retain
+
 	lock sync.RWMutex
 }
 
@@ -265,6 +274,8 @@ type Config struct {
 	Cache     int    // Memory allowance (MB) to use for caching trie nodes in memory
 	Journal   string // Journal of clean cache to survive node restarts
 	Preimages bool   // Flag whether the preimage of trie key is recorded
+	Scheme    string // Scheme to persist trie nodes with, the stored one if empty
+	History   uint64 // Number of reverse diffs to retain with the path scheme
 }
 
 // NewDatabase creates a new trie database to store ephemeral trie content before
@@ -297,6 +308,19 @@ func NewDatabaseWithConfig(diskdb ethdb.KeyValueStore, config *Config) *Database
 			children: make(map[common.Hash]uint16),
 		}},
 		preimages: preimage,
+		scheme:    rawdb.HashScheme,
+	}
+	if config != nil && config.Scheme != "" {
+		db.scheme = config.Scheme
+	} else if diskdb != nil {
+		db.scheme = rawdb.ReadStateScheme(diskdb)
+	}
+	if db.scheme == rawdb.PathScheme {
+		var history uint64
+		if config != nil {
+			history = config.History
+		}
+		db.initLayers(history)
 	}
 	return db
 }
@@ -339,10 +363,13 @@ func (db *Database) insert(hash common.Hash, size int, node node) {
 }
 
 // node retrieves a cached trie node from memory, or returns nil if none can be
-// found in the memory cache.
-func (db *Database) node(hash common.Hash) node {
-	// Retrieve the node from the clean cache if available
-	if db.cleans != nil {
+// found in the memory cache. The owner and path are only used to locate the node
+// on disk with the path scheme.
+func (db *Database) node(owner common.Hash, path []byte, hash common.Hash) node {
+	// Retrieve the node from the clean cache if available. With the path scheme
+	// the state root is always resolved from the layers or the disk, as only its
+	// presence there tells whether the state is available.
+	if db.cleans != nil && !db.isStateRoot(owner, path) {
 		if enc := db.cleans.Get(nil, hash[:]); enc != nil {
 			memcacheCleanHitMeter.Mark(1)
 			memcacheCleanReadMeter.Mark(int64(len(enc)))
@@ -352,6 +379,9 @@ func (db *Database) node(hash common.Hash) node {
 	// Retrieve the node from the dirty cache if available
 	db.lock.RLock()
 	dirty := db.dirties[hash]
+	if db.scheme == rawdb.PathScheme {
+		dirty = db.layerNodes[hash]
+	}
 	db.lock.RUnlock()
 
 	if dirty != nil {
@@ -362,8 +392,8 @@ func (db *Database) node(hash common.Hash) node {
 	memcacheDirtyMissMeter.Mark(1)
 
 	// Content unavailable in memory, attempt to retrieve from disk
-	enc, err := db.diskdb.Get(hash[:])
-	if err != nil || enc == nil {
+	enc := rawdb.ReadTrieNodeWithScheme(db.diskdb, owner, path, hash, db.scheme)
+	if len(enc) == 0 {
 		return nil
 	}
 	if db.cleans != nil {
@@ -374,8 +404,47 @@ func (db *Database) node(hash common.Hash) node {
 	return mustDecodeNode(hash[:], enc)
 }
 
+// nodeBlob retrieves an encoded trie node from memory or from the persistent
+// database, using the owner and path to locate it with the path scheme.
+func (db *Database) nodeBlob(owner common.Hash, path []byte, hash common.Hash) ([]byte, error) {
+	if db.scheme != rawdb.PathScheme {
+		return db.Node(hash)
+	}
+	if db.cleans != nil && !db.isStateRoot(owner, path) {
+		if enc := db.cleans.Get(nil, hash[:]); enc != nil {
+			memcacheCleanHitMeter.Mark(1)
+			memcacheCleanReadMeter.Mark(int64(len(enc)))
+			return enc, nil
+		}
+	}
+	db.lock.RLock()
+	dirty := db.layerNodes[hash]
+	db.lock.RUnlock()
+
+	if dirty != nil {
+		memcacheDirtyHitMeter.Mark(1)
+		memcacheDirtyReadMeter.Mark(int64(dirty.size))
+		return dirty.rlp(), nil
+	}
+	memcacheDirtyMissMeter.Mark(1)
+
+	enc := rawdb.ReadTrieNodeWithScheme(db.diskdb, owner, path, hash, db.scheme)
+	if len(enc) == 0 {
+		return nil, errors.New("not found")
+	}
+	if db.cleans != nil {
+		db.cleans.Set(hash[:], enc)
+		memcacheCleanMissMeter.Mark(1)
+		memcacheCleanWriteMeter.Mark(int64(len(enc)))
+	}
+	return enc, nil
+}
+
 // Node retrieves an encoded cached trie node from memory. If it cannot be found
 // cached, the method queries the persistent database for the content.
+//
+// With the path scheme, nodes are not addressable by hash on disk, so only the
+// ones cached in memory are available.
 func (db *Database) Node(hash common.Hash) ([]byte, error) {
 	// It doesn't make sense to retrieve the metaroot
 	if hash == (common.Hash{}) {
@@ -392,6 +461,9 @@ func (db *Database) Node(hash common.Hash) ([]byte, error) {
 	// Retrieve the node from the dirty cache if available
 	db.lock.RLock()
 	dirty := db.dirties[hash]
+	if db.scheme == rawdb.PathScheme {
+		dirty = db.layerNodes[hash]
+	}
 	db.lock.RUnlock()
 
 	if dirty != nil {
@@ -401,6 +473,9 @@ func (db *Database) Node(hash common.Hash) ([]byte, error) {
 	}
 	memcacheDirtyMissMeter.Mark(1)
 
+	if db.scheme == rawdb.PathScheme {
+		return nil, errors.New("not found")
+	}
 	// Content unavailable in memory, attempt to retrieve from disk
 	enc := rawdb.ReadTrieNode(db.diskdb, hash)
 	if len(enc) != 0 {
@@ -421,6 +496,13 @@ func (db *Database) Nodes() []common.Hash {
 	db.lock.RLock()
 	defer db.lock.RUnlock()
 
+	if db.scheme == rawdb.PathScheme {
+		hashes := make([]common.Hash, 0, len(db.layerNodes))
+		for hash := range db.layerNodes {
+			hashes = append(hashes, hash)
+		}
+		return hashes
+	}
 	var hashes = make([]common.Hash, 0, len(db.dirties))
 	for hash := range db.dirties {
 		if hash != (common.Hash{}) { // Special case for "root" references/nodes
@@ -438,6 +520,13 @@ func (db *Database) Reference(child common.Hash, parent common.Hash) {
 	db.lock.Lock()
 	defer db.lock.Unlock()
 
+	// With the path scheme only state roots are tracked, by their diff layers
+	if db.scheme == rawdb.PathScheme {
+		if parent == (common.Hash{}) {
+			db.referenceLayer(child)
+		}
+		return
+	}
 	db.reference(child, parent)
 }
 
@@ -472,6 +561,10 @@ func (db *Database) Dereference(root common.Hash) {
 	db.lock.Lock()
 	defer db.lock.Unlock()
 
+	if db.scheme == rawdb.PathScheme {
+		db.dereferenceLayer(root)
+		return
+	}
 	nodes, storage, start := len(db.dirties), db.dirtiesSize, time.Now()
 	db.dereference(root, common.Hash{})
 
@@ -543,6 +636,12 @@ func (db *Database) dereference(child common.Hash, parent common.Hash) {
 // Note, this method is a non-synchronized mutator. It is unsafe to call this
 // concurrently with other mutators.
 func (db *Database) Cap(limit common.StorageSize) error {
+	if db.scheme == rawdb.PathScheme {
+		if db.preimages != nil {
+			db.preimages.commit(false)
+		}
+		return db.capLayers(limit)
+	}
 	// Create a database batch to flush persistent data out. It is important that
 	// outside code doesn't see an inconsistent state (referenced data removed from
 	// memory cache during commit but not yet in persistent storage). This is ensured
@@ -628,6 +727,12 @@ func (db *Database) Cap(limit common.StorageSize) error {
 // Note, this method is a non-synchronized mutator. It is unsafe to call this
 // concurrently with other mutators.
 func (db *Database) Commit(node common.Hash, report bool, callback func(common.Hash)) error {
+	if db.scheme == rawdb.PathScheme {
+		if db.preimages != nil {
+			db.preimages.commit(true)
+		}
+		return db.commitLayers(node, report, callback)
+	}
 	// Create a database batch to flush persistent data out. It is important that
 	// outside code doesn't see an inconsistent state (referenced data removed from
 	// memory cache during commit but not yet in persistent storage). This is ensured
@@ -761,11 +866,17 @@ func (c *cleaner) Delete(key []byte) error {
 }
 
 // Update inserts the dirty nodes in provided nodeset into database and
-// link the account trie with multiple storage tries if necessary.
-func (db *Database) Update(nodes *MergedNodeSet) error {
+// link the account trie with multiple storage tries if necessary. The root
+// and parent are the state roots after and before the changes, only used by
+// the path scheme to stack the changes as a diff layer.
+func (db *Database) Update(root common.Hash, parent common.Hash, nodes *MergedNodeSet) error {
 	db.lock.Lock()
 	defer db.lock.Unlock()
 
+	if db.scheme == rawdb.PathScheme {
+		return db.updateLayers(root, parent, nodes)
+	}
+
 	// Insert dirty nodes into the database. In the same tree, it must be
 	// ensured that children are inserted first, then parent so that children
 	// can be linked with their parent correctly. The order of writing between
@@ -801,15 +912,18 @@ func (db *Database) Size() (common.StorageSize, common.StorageSize) {
 	db.lock.RLock()
 	defer db.lock.RUnlock()
 
+	var preimageSize common.StorageSize
+	if db.preimages != nil {
+		preimageSize = db.preimages.size()
+	}
+	if db.scheme == rawdb.PathScheme {
+		return db.layersSize, preimageSize
+	}
 	// db.dirtiesSize only contains the useful data in the cache, but when reporting
 	// the total memory consumption, the maintenance metadata is also needed to be
 	// counted.
 	var metadataSize = common.StorageSize((len(db.dirties) - 1) * cachedNodeSize)
 	var metarootRefs = common.StorageSize(len(db.dirties[common.Hash{}].children) * (common.HashLength + 2))
-	var preimageSize common.StorageSize
-	if db.preimages != nil {
-		preimageSize = db.preimages.size()
-	}
 	return db.dirtiesSize + db.childrenSize + metadataSize - metarootRefs, preimageSize
 }
 
@@ -64,7 +64,7 @@ func TestIterator(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to commit trie %v", err)
 	}
-	db.Update(NewWithNodeSet(nodes))
+	db.Update(root, common.Hash{}, NewWithNodeSet(nodes))
 
 	trie, _ = New(common.Hash{}, root, db)
 	found := make(map[string]string)
@@ -226,7 +226,7 @@ func TestDifferenceIterator(t *testing.T) {
 		triea.Update([]byte(val.k), []byte(val.v))
 	}
 	rootA, nodesA, _ := triea.Commit(false)
-	dba.Update(NewWithNodeSet(nodesA))
+	dba.Update(rootA, common.Hash{}, NewWithNodeSet(nodesA))
 	triea, _ = New(common.Hash{}, rootA, dba)
 
 	dbb := NewDatabase(rawdb.NewMemoryDatabase())
@@ -235,7 +235,7 @@ func TestDifferenceIterator(t *testing.T) {
 		trieb.Update([]byte(val.k), []byte(val.v))
 	}
 	rootB, nodesB, _ := trieb.Commit(false)
-	dbb.Update(NewWithNodeSet(nodesB))
+	dbb.Update(rootB, common.Hash{}, NewWithNodeSet(nodesB))
 	trieb, _ = New(common.Hash{}, rootB, dbb)
 
 	found := make(map[string]string)
@@ -268,7 +268,7 @@ func TestUnionIterator(t *testing.T) {
 		triea.Update([]byte(val.k), []byte(val.v))
 	}
 	rootA, nodesA, _ := triea.Commit(false)
-	dba.Update(NewWithNodeSet(nodesA))
+	dba.Update(rootA, common.Hash{}, NewWithNodeSet(nodesA))
 	triea, _ = New(common.Hash{}, rootA, dba)
 
 	dbb := NewDatabase(rawdb.NewMemoryDatabase())
@@ -277,7 +277,7 @@ func TestUnionIterator(t *testing.T) {
 		trieb.Update([]byte(val.k), []byte(val.v))
 	}
 	rootB, nodesB, _ := trieb.Commit(false)
-	dbb.Update(NewWithNodeSet(nodesB))
+	dbb.Update(rootB, common.Hash{}, NewWithNodeSet(nodesB))
 	trieb, _ = New(common.Hash{}, rootB, dbb)
 
 	di, _ := NewUnionIterator([]NodeIterator{triea.NodeIterator(nil), trieb.NodeIterator(nil)})
@@ -335,7 +335,7 @@ func testIteratorContinueAfterError(t *testing.T, memonly bool) {
 		tr.Update([]byte(val.k), []byte(val.v))
 	}
 	_, nodes, _ := tr.Commit(false)
-	triedb.Update(NewWithNodeSet(nodes))
+	triedb.Update(common.Hash{}, common.Hash{}, NewWithNodeSet(nodes))
 	if !memonly {
 		triedb.Commit(tr.Hash(), true, nil)
 	}
@@ -427,7 +427,7 @@ func testIteratorContinueAfterSeekError(t *testing.T, memonly bool) {
 		ctr.Update([]byte(val.k), []byte(val.v))
 	}
 	root, nodes, _ := ctr.Commit(false)
-	triedb.Update(NewWithNodeSet(nodes))
+	triedb.Update(root, common.Hash{}, NewWithNodeSet(nodes))
 	if !memonly {
 		triedb.Commit(root, true, nil)
 	}
@@ -546,7 +546,7 @@ func makeLargeTestTrie() (*Database, *StateTrie, *loggingDb) {
 		trie.Update(key, val)
 	}
 	_, nodes, _ := trie.Commit(false)
-	triedb.Update(NewWithNodeSet(nodes))
+	triedb.Update(common.Hash{}, common.Hash{}, NewWithNodeSet(nodes))
 	// Return the generated trie
 	return triedb, trie, logDb
 }
@@ -556,6 +556,8 @@ func TestNodeIteratorLargeTrie(t *testing.T) {
 	// Create some arbitrary test trie to iterate
 	db, trie, logDb := makeLargeTestTrie()
 	db.Cap(0) // flush everything
+	logDb.getCount = 0
+
 	// Do a seek operation
 	trie.NodeIterator(common.FromHex("0x77667766776677766778855885885885"))
 	// master: 24 get operations
@@ -586,7 +588,7 @@ func TestIteratorNodeBlob(t *testing.T) {
 		trie.Update([]byte(val.k), []byte(val.v))
 	}
 	_, nodes, _ := trie.Commit(false)
-	triedb.Update(NewWithNodeSet(nodes))
+	triedb.Update(common.Hash{}, common.Hash{}, NewWithNodeSet(nodes))
 	triedb.Cap(0)
 
 	found := make(map[common.Hash][]byte)
@@ -37,6 +37,8 @@ type NodeSet struct {
 	paths  []string               // the path of dirty nodes, sort by insertion order
 	nodes  map[string]*memoryNode // the map of dirty nodes, keyed by node path
 	leaves []*leaf                // the list of dirty leaves
+
+	deletes []string // the path of deleted nodes, only tracked for the path scheme
 }
 
 // NewNodeSet initializes an empty node set to be used for tracking dirty nodes
@@ -55,6 +57,11 @@ func (set *NodeSet) add(path string, node *memoryNode) {
 	set.nodes[path] = node
 }
 
+// markDeleted marks the node at the provided path as deleted.
+func (set *NodeSet) markDeleted(path []byte) {
+	set.deletes = append(set.deletes, string(path))
+}
+
 // addLeaf caches the provided leaf node.
 func (set *NodeSet) addLeaf(node *leaf) {
 	set.leaves = append(set.leaves, node)
@@ -67,12 +74,16 @@ func (set *NodeSet) Len() int {
 
 // MergedNodeSet represents a merged dirty node set for a group of tries.
 type MergedNodeSet struct {
-	sets map[common.Hash]*NodeSet
+	sets  map[common.Hash]*NodeSet
+	wipes map[common.Hash]struct{} // storage tries deleted as a whole, only used by the path scheme
 }
 
 // NewMergedNodeSet initializes an empty merged set.
 func NewMergedNodeSet() *MergedNodeSet {
-	return &MergedNodeSet{sets: make(map[common.Hash]*NodeSet)}
+	return &MergedNodeSet{
+		sets:  make(map[common.Hash]*NodeSet),
+		wipes: make(map[common.Hash]struct{}),
+	}
 }
 
 // NewWithNodeSet constructs a merged nodeset with the provided single set.
@@ -92,3 +103,10 @@ func (set *MergedNodeSet) Merge(other *NodeSet) error {
 	set.sets[other.owner] = other
 	return nil
 }
+
+// Wipe marks the storage trie of the given account as deleted as a whole, e.g.
+// because the account was self-destructed or recreated. The nodes of the trie
+// merged into the set are applied on top of the wiped trie.
+func (set *MergedNodeSet) Wipe(owner common.Hash) {
+	set.wipes[owner] = struct{}{}
+}
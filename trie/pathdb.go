@@ -0,0 +1,494 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package trie
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+var (
+	pathFlushTimeTimer  = metrics.NewRegisteredResettingTimer("trie/path/flush/time", nil)
+	pathFlushNodesMeter = metrics.NewRegisteredMeter("trie/path/flush/nodes", nil)
+	pathFlushSizeMeter  = metrics.NewRegisteredMeter("trie/path/flush/size", nil)
+	pathRecoverMeter    = metrics.NewRegisteredMeter("trie/path/recover", nil)
+	pathLayersGauge     = metrics.NewRegisteredGauge("trie/path/layers", nil)
+)
+
+// errStateNotRecoverable is returned if the persisted state cannot be rolled
+// back to the requested root with the retained reverse diffs.
+var errStateNotRecoverable = errors.New("state not recoverable")
+
+// diffLayer is a state transition not yet persisted in place, holding the trie
+// nodes changed by it keyed by owner and path. Deleted nodes are tracked with a
+// nil node.
+//
+// Diff layers are stacked on top of each other, with the bottom-most ones being
+// stacked on the persisted state. Only whole layers are persisted, bottom first,
+// so the disk always contains exactly one state, the root of the last flushed
+// layer.
+type diffLayer struct {
+	root   common.Hash // Root hash of the state after the transition
+	base   common.Hash // Root hash of the state the transition was applied on
+	parent *diffLayer  // Parent layer, nil if stacked on the persisted state
+	nodes  map[common.Hash]map[string]*memoryNode
+	wipes  map[common.Hash]struct{} // Storage tries deleted before applying the nodes
+	seq    uint64                   // Creation sequence number of the layer
+	size   common.StorageSize       // Storage size of the changed nodes
+
+	refs     int // Number of external references to the layer root
+	children int // Number of layers stacked on top of this one
+}
+
+// reverseDiff records the trie nodes overwritten when persisting a state
+// transition in place, allowing to roll the persisted state back to its parent.
+type reverseDiff struct {
+	Parent common.Hash       // Root of the state before the transition
+	Root   common.Hash       // Root of the state after the transition
+	Nodes  []reverseDiffNode // Previous content of the overwritten nodes
+}
+
+// reverseDiffNode is the content of a trie node before it was overwritten.
+type reverseDiffNode struct {
+	Owner common.Hash
+	Path  []byte
+	Blob  []byte // Previous node blob, empty if the node did not exist
+}
+
+// initLayers sets up the path scheme state tracking on top of the persisted
+// state.
+func (db *Database) initLayers(history uint64) {
+	db.diskRoot = db.readDiskRoot()
+	db.layers = make(map[common.Hash]*diffLayer)
+	db.layerNodes = make(map[common.Hash]*cachedNode)
+	db.diffHead = rawdb.ReadReverseDiffHead(db.diskdb)
+	db.history = history
+}
+
+// readDiskRoot retrieves the root of the state persisted in place.
+func (db *Database) readDiskRoot() common.Hash {
+	if blob := rawdb.ReadAccountTrieNode(db.diskdb, nil); len(blob) > 0 {
+		return crypto.Keccak256Hash(blob)
+	}
+	return emptyRoot
+}
+
+// isStateRoot reports whether the node at the given owner and path is the root
+// of a state, which must not be resolved from the clean cache with the path
+// scheme.
+func (db *Database) isStateRoot(owner common.Hash, path []byte) bool {
+	return db.scheme == rawdb.PathScheme && owner == (common.Hash{}) && len(path) == 0
+}
+
+// Scheme returns the scheme the trie nodes are persisted with.
+func (db *Database) Scheme() string {
+	return db.scheme
+}
+
+// updateLayers stacks the state transition from parent to root as a new diff
+// layer.
+func (db *Database) updateLayers(root common.Hash, parent common.Hash, nodes *MergedNodeSet) error {
+	if parent == (common.Hash{}) {
+		parent = emptyRoot
+	}
+	if root == parent {
+		return nil
+	}
+	if _, ok := db.layers[root]; ok {
+		return nil
+	}
+	var base *diffLayer
+	if parent != db.diskRoot {
+		base = db.layers[parent]
+		if base == nil {
+			// The persisted state might have been replaced externally, e.g. by
+			// state sync, in which case all the layers in memory are stale.
+			if db.diskRoot = db.readDiskRoot(); parent != db.diskRoot {
+				return fmt.Errorf("parent state %x is not available", parent)
+			}
+			db.dropStaleLayers()
+		} else {
+			base.children++
+		}
+	}
+	db.layerSeq++
+	layer := &diffLayer{
+		root:   root,
+		base:   parent,
+		parent: base,
+		nodes:  make(map[common.Hash]map[string]*memoryNode),
+		wipes:  make(map[common.Hash]struct{}, len(nodes.wipes)),
+		seq:    db.layerSeq,
+	}
+	for owner := range nodes.wipes {
+		layer.wipes[owner] = struct{}{}
+		layer.size += common.HashLength
+	}
+	for owner, subset := range nodes.sets {
+		set := make(map[string]*memoryNode, len(subset.nodes)+len(subset.deletes))
+		for _, path := range subset.deletes {
+			set[path] = &memoryNode{}
+			layer.size += common.StorageSize(len(path))
+		}
+		for path, n := range subset.nodes {
+			set[path] = n
+			layer.size += common.StorageSize(len(path) + common.HashLength + int(n.size))
+
+			if entry := db.layerNodes[n.hash]; entry != nil {
+				entry.parents++
+				continue
+			}
+			db.layerNodes[n.hash] = &cachedNode{node: n.node, size: n.size, parents: 1}
+		}
+		layer.nodes[owner] = set
+	}
+	db.layers[root] = layer
+	db.layersSize += layer.size
+	pathLayersGauge.Update(int64(len(db.layers)))
+	return nil
+}
+
+// removeLayer drops a diff layer from memory without persisting it.
+func (db *Database) removeLayer(layer *diffLayer) {
+	for _, set := range layer.nodes {
+		for _, n := range set {
+			if n.node == nil {
+				continue
+			}
+			if entry := db.layerNodes[n.hash]; entry != nil {
+				if entry.parents--; entry.parents == 0 {
+					delete(db.layerNodes, n.hash)
+				}
+			}
+		}
+	}
+	if layer.parent != nil {
+		layer.parent.children--
+	}
+	delete(db.layers, layer.root)
+	db.layersSize -= layer.size
+	pathLayersGauge.Update(int64(len(db.layers)))
+}
+
+// dropStaleLayers removes all the diff layers which are not stacked on the
+// persisted state any more.
+func (db *Database) dropStaleLayers() {
+	for _, layer := range db.layers {
+		bottom := layer
+		for bottom.parent != nil {
+			bottom = bottom.parent
+		}
+		if bottom.base != db.diskRoot {
+			db.removeLayer(layer)
+		}
+	}
+}
+
+// referenceLayer adds an external reference to the diff layer of a state root.
+func (db *Database) referenceLayer(root common.Hash) {
+	if layer := db.layers[root]; layer != nil {
+		layer.refs++
+	}
+}
+
+// dereferenceLayer removes an external reference from the diff layer of a state
+// root, dropping it along with all its unreferenced ancestors if nothing else is
+// stacked on top.
+func (db *Database) dereferenceLayer(root common.Hash) {
+	layer := db.layers[root]
+	if layer == nil {
+		return
+	}
+	if layer.refs > 0 {
+		layer.refs--
+	}
+	for layer != nil && layer.refs == 0 && layer.children == 0 {
+		parent := layer.parent
+		db.removeLayer(layer)
+		layer = parent
+	}
+}
+
+// flushLayer persists a diff layer stacked directly on the persisted state in
+// place, recording the overwritten nodes into a reverse diff.
+func (db *Database) flushLayer(layer *diffLayer, callback func(common.Hash)) error {
+	var (
+		start = time.Now()
+		batch = db.diskdb.NewBatch()
+		diff  = &reverseDiff{Parent: layer.base, Root: layer.root}
+		nodes int
+	)
+	// Delete the persisted nodes of the wiped storage tries first, apart from
+	// the ones the layer overwrites anyway
+	for owner := range layer.wipes {
+		set := layer.nodes[owner]
+		rawdb.IterateStorageTrieNodes(db.diskdb, owner, func(path []byte, blob []byte) {
+			if _, ok := set[string(path)]; ok {
+				return
+			}
+			rawdb.DeleteStorageTrieNode(batch, owner, path)
+			diff.Nodes = append(diff.Nodes, reverseDiffNode{Owner: owner, Path: path, Blob: blob})
+			nodes++
+		})
+	}
+	for owner, set := range layer.nodes {
+		for path, n := range set {
+			var prev []byte
+			if owner == (common.Hash{}) {
+				prev = rawdb.ReadAccountTrieNode(db.diskdb, []byte(path))
+			} else {
+				prev = rawdb.ReadStorageTrieNode(db.diskdb, owner, []byte(path))
+			}
+			if n.node == nil {
+				if len(prev) == 0 {
+					continue
+				}
+				if owner == (common.Hash{}) {
+					rawdb.DeleteAccountTrieNode(batch, []byte(path))
+				} else {
+					rawdb.DeleteStorageTrieNode(batch, owner, []byte(path))
+				}
+			} else {
+				blob := nodeToBytes(n.node)
+				if bytes.Equal(prev, blob) {
+					continue
+				}
+				rawdb.WriteTrieNodeWithScheme(batch, owner, []byte(path), n.hash, blob, rawdb.PathScheme)
+				if callback != nil {
+					callback(n.hash)
+				}
+			}
+			diff.Nodes = append(diff.Nodes, reverseDiffNode{Owner: owner, Path: []byte(path), Blob: prev})
+			nodes++
+		}
+	}
+	id := db.diffHead
+	if db.history > 0 {
+		blob, err := rlp.EncodeToBytes(diff)
+		if err != nil {
+			return err
+		}
+		id++
+		rawdb.WriteReverseDiff(batch, id, blob)
+		rawdb.WriteReverseDiffHead(batch, id)
+
+		// Prune the reverse diffs which fell out of the retention window
+		for tail := int64(id) - int64(db.history); tail > 0; tail-- {
+			if !rawdb.HasReverseDiff(db.diskdb, uint64(tail)) {
+				break
+			}
+			rawdb.DeleteReverseDiff(batch, uint64(tail))
+		}
+	}
+	size := common.StorageSize(batch.ValueSize())
+	if err := batch.Write(); err != nil {
+		log.Error("Failed to persist state layer", "root", layer.root, "err", err)
+		return err
+	}
+	// Layer persisted, move its nodes to the clean cache and restack its children
+	if db.cleans != nil {
+		for _, set := range layer.nodes {
+			for _, n := range set {
+				if n.node != nil {
+					enc := nodeToBytes(n.node)
+					db.cleans.Set(n.hash[:], enc)
+					memcacheCleanWriteMeter.Mark(int64(len(enc)))
+				}
+			}
+		}
+	}
+	db.diffHead, db.diskRoot = id, layer.root
+	for _, child := range db.layers {
+		if child.parent == layer {
+			child.parent = nil
+		}
+	}
+	layer.children = 0
+	db.removeLayer(layer)
+	db.dropStaleLayers()
+
+	db.flushnodes += uint64(nodes)
+	db.flushsize += size
+	db.flushtime += time.Since(start)
+
+	pathFlushTimeTimer.Update(time.Since(start))
+	pathFlushNodesMeter.Mark(int64(nodes))
+	pathFlushSizeMeter.Mark(int64(size))
+	return nil
+}
+
+// capLayers persists the bottom-most diff layers leading to the most recently
+// created state until the memory used by the layers drops below the limit.
+func (db *Database) capLayers(limit common.StorageSize) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	for db.layersSize > limit {
+		var newest *diffLayer
+		for _, layer := range db.layers {
+			if newest == nil || layer.seq > newest.seq {
+				newest = layer
+			}
+		}
+		if newest == nil {
+			break
+		}
+		for newest.parent != nil {
+			newest = newest.parent
+		}
+		if err := db.flushLayer(newest, nil); err != nil {
+			return err
+		}
+	}
+	log.Debug("Persisted state layers", "nodes", db.flushnodes, "size", db.flushsize, "time", db.flushtime,
+		"layers", len(db.layers), "livesize", db.layersSize)
+	return nil
+}
+
+// commitLayers persists all the diff layers leading to the given state root in
+// place. Layers not stacked on the committed state are dropped.
+func (db *Database) commitLayers(root common.Hash, report bool, callback func(common.Hash)) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	start := time.Now()
+
+	// Nothing to do if the state is already persisted or unknown, the latter
+	// meaning it was already overwritten by a descendant.
+	layer := db.layers[root]
+	if layer == nil {
+		return nil
+	}
+	var chain []*diffLayer
+	for ; layer != nil; layer = layer.parent {
+		chain = append(chain, layer)
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		if err := db.flushLayer(chain[i], callback); err != nil {
+			return err
+		}
+	}
+	logger := log.Info
+	if !report {
+		logger = log.Debug
+	}
+	logger("Persisted state in place", "root", root, "layers", len(chain), "nodes", db.flushnodes, "size", db.flushsize,
+		"time", time.Since(start)+db.flushtime, "livelayers", len(db.layers), "livesize", db.layersSize)
+
+	db.flushnodes, db.flushsize, db.flushtime = 0, 0, 0
+	return nil
+}
+
+// reverseDiffs collects the reverse diffs needed to roll the persisted state
+// back to the given root, newest first.
+func (db *Database) reverseDiffs(root common.Hash) ([]*reverseDiff, error) {
+	var (
+		diffs   []*reverseDiff
+		current = db.diskRoot
+	)
+	for id := db.diffHead; id > 0; id-- {
+		blob := rawdb.ReadReverseDiff(db.diskdb, id)
+		if len(blob) == 0 {
+			break
+		}
+		diff := new(reverseDiff)
+		if err := rlp.DecodeBytes(blob, diff); err != nil {
+			return nil, err
+		}
+		if diff.Root != current {
+			break
+		}
+		diffs = append(diffs, diff)
+		if diff.Parent == root {
+			return diffs, nil
+		}
+		current = diff.Parent
+	}
+	return nil, errStateNotRecoverable
+}
+
+// Recoverable reports whether the persisted state can be rolled back to the
+// given root using the retained reverse diffs. It is always false with the
+// hash scheme.
+func (db *Database) Recoverable(root common.Hash) bool {
+	if db.scheme != rawdb.PathScheme {
+		return false
+	}
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if root == db.diskRoot {
+		return false
+	}
+	_, err := db.reverseDiffs(root)
+	return err == nil
+}
+
+// Recover rolls the persisted state back to the given root by applying the
+// retained reverse diffs. All the in-memory diff layers are dropped, as they
+// were stacked on the newer state.
+func (db *Database) Recover(root common.Hash) error {
+	if db.scheme != rawdb.PathScheme {
+		return errors.New("state recovery requires the path scheme")
+	}
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	diffs, err := db.reverseDiffs(root)
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	for _, diff := range diffs {
+		batch := db.diskdb.NewBatch()
+		for _, n := range diff.Nodes {
+			switch {
+			case len(n.Blob) == 0 && n.Owner == (common.Hash{}):
+				rawdb.DeleteAccountTrieNode(batch, n.Path)
+			case len(n.Blob) == 0:
+				rawdb.DeleteStorageTrieNode(batch, n.Owner, n.Path)
+			case n.Owner == (common.Hash{}):
+				rawdb.WriteAccountTrieNode(batch, n.Path, n.Blob)
+			default:
+				rawdb.WriteStorageTrieNode(batch, n.Owner, n.Path, n.Blob)
+			}
+		}
+		rawdb.DeleteReverseDiff(batch, db.diffHead)
+		rawdb.WriteReverseDiffHead(batch, db.diffHead-1)
+		if err := batch.Write(); err != nil {
+			return err
+		}
+		db.diffHead, db.diskRoot = db.diffHead-1, diff.Parent
+	}
+	db.layers = make(map[common.Hash]*diffLayer)
+	db.layerNodes = make(map[common.Hash]*cachedNode)
+	db.layersSize = 0
+	pathLayersGauge.Update(0)
+	pathRecoverMeter.Mark(int64(len(diffs)))
+
+	log.Info("Rolled back persisted state", "root", root, "diffs", len(diffs), "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
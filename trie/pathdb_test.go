@@ -0,0 +1,246 @@
+// Copyright 2026 The Yottaflux Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package trie
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// pathTestState applies a set of changes on top of the given state, returning
+// the new root. Nil values delete the key.
+func pathTestState(t *testing.T, db *Database, parent common.Hash, changes map[string][]byte) common.Hash {
+	t.Helper()
+
+	tr, err := New(common.Hash{}, parent, db)
+	if err != nil {
+		t.Fatalf("failed to open trie %x: %v", parent, err)
+	}
+	for key, val := range changes {
+		if val == nil {
+			tr.Delete([]byte(key))
+		} else {
+			tr.Update([]byte(key), val)
+		}
+	}
+	root, nodes, err := tr.Commit(false)
+	if err != nil {
+		t.Fatalf("failed to commit trie: %v", err)
+	}
+	merged := NewMergedNodeSet()
+	if nodes != nil {
+		merged.Merge(nodes)
+	}
+	if err := db.Update(root, parent, merged); err != nil {
+		t.Fatalf("failed to update database: %v", err)
+	}
+	db.Reference(root, common.Hash{})
+	return root
+}
+
+// pathTestChanges generates n values keyed by the hash of their index.
+func pathTestChanges(n int, salt byte) map[string][]byte {
+	changes := make(map[string][]byte)
+	for i := 0; i < n; i++ {
+		key := make([]byte, 8)
+		binary.BigEndian.PutUint64(key, uint64(i))
+		changes[string(crypto.Keccak256(key))] = bytes.Repeat([]byte{salt, byte(i)}, 20)
+	}
+	return changes
+}
+
+// checkPathTestState verifies that the given state contains exactly the values.
+func checkPathTestState(t *testing.T, db *Database, root common.Hash, values map[string][]byte) {
+	t.Helper()
+
+	tr, err := New(common.Hash{}, root, db)
+	if err != nil {
+		t.Fatalf("failed to open trie %x: %v", root, err)
+	}
+	count := 0
+	it := NewIterator(tr.NodeIterator(nil))
+	for it.Next() {
+		if want := values[string(it.Key)]; !bytes.Equal(it.Value, want) {
+			t.Fatalf("value mismatch for %x: have %x, want %x", it.Key, it.Value, want)
+		}
+		count++
+	}
+	if it.Err != nil {
+		t.Fatalf("failed to iterate trie %x: %v", root, it.Err)
+	}
+	if count != len(values) {
+		t.Fatalf("value count mismatch: have %d, want %d", count, len(values))
+	}
+}
+
+// countPathNodes counts the account trie nodes persisted in place.
+func countPathNodes(db ethdb.Iteratee) int {
+	it := db.NewIterator(rawdb.TrieNodeAccountPrefix, nil)
+	defer it.Release()
+
+	count := 0
+	for it.Next() {
+		count++
+	}
+	return count
+}
+
+// Tests that states are stacked in memory and persisted in place, with deleted
+// nodes removed from disk.
+func TestPathSchemeCommit(t *testing.T) {
+	diskdb := rawdb.NewMemoryDatabase()
+	db := NewDatabaseWithConfig(diskdb, &Config{Scheme: rawdb.PathScheme, History: 16})
+
+	values := pathTestChanges(200, 1)
+	root1 := pathTestState(t, db, common.Hash{}, values)
+
+	// Delete half of the keys in a second state stacked in memory
+	changes := make(map[string][]byte)
+	for key := range values {
+		if len(changes) == len(values)/2 {
+			break
+		}
+		changes[key] = nil
+	}
+	root2 := pathTestState(t, db, root1, changes)
+
+	values2 := make(map[string][]byte)
+	for key, val := range values {
+		if _, ok := changes[key]; !ok {
+			values2[key] = val
+		}
+	}
+	checkPathTestState(t, db, root1, values)
+	checkPathTestState(t, db, root2, values2)
+
+	if err := db.Commit(root2, false, nil); err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+	if size, _ := db.Size(); size != 0 {
+		t.Fatalf("dangling diff layers after commit: %v", size)
+	}
+	// Reopen the database and check the persisted state
+	db = NewDatabaseWithConfig(diskdb, &Config{Scheme: rawdb.PathScheme})
+	checkPathTestState(t, db, root2, values2)
+
+	if _, err := New(common.Hash{}, root1, db); err == nil {
+		t.Fatalf("overwritten state still available")
+	}
+	// The stale nodes should have been deleted from disk
+	var nodes int
+	tr, _ := New(common.Hash{}, root2, db)
+	for it := tr.NodeIterator(nil); it.Next(true); {
+		if it.Hash() != (common.Hash{}) {
+			nodes++
+		}
+	}
+	if have := countPathNodes(diskdb); have != nodes {
+		t.Fatalf("persisted node count mismatch: have %d, want %d", have, nodes)
+	}
+}
+
+// Tests that capping the diff layers persists the oldest ones first.
+func TestPathSchemeCap(t *testing.T) {
+	diskdb := rawdb.NewMemoryDatabase()
+	db := NewDatabaseWithConfig(diskdb, &Config{Scheme: rawdb.PathScheme, History: 16})
+
+	var (
+		roots  []common.Hash
+		values []map[string][]byte
+		parent common.Hash
+	)
+	for i := 0; i < 4; i++ {
+		changes := pathTestChanges(50, byte(i+1))
+		parent = pathTestState(t, db, parent, changes)
+		roots, values = append(roots, parent), append(values, changes)
+	}
+	size, _ := db.Size()
+	if err := db.Cap(size - 1); err != nil {
+		t.Fatalf("failed to cap layers: %v", err)
+	}
+	if db.diskRoot != roots[0] {
+		t.Fatalf("persisted root mismatch: have %x, want %x", db.diskRoot, roots[0])
+	}
+	for i := range roots {
+		checkPathTestState(t, db, roots[i], values[i])
+	}
+	if err := db.Cap(0); err != nil {
+		t.Fatalf("failed to cap layers: %v", err)
+	}
+	if db.diskRoot != roots[3] || len(db.layers) != 0 {
+		t.Fatalf("layers not persisted: root %x, layers %d", db.diskRoot, len(db.layers))
+	}
+	checkPathTestState(t, db, roots[3], values[3])
+}
+
+// Tests that the persisted state can be rolled back with the reverse diffs, and
+// that only the configured number of diffs are retained.
+func TestPathSchemeRecover(t *testing.T) {
+	diskdb := rawdb.NewMemoryDatabase()
+	db := NewDatabaseWithConfig(diskdb, &Config{Scheme: rawdb.PathScheme, History: 3})
+
+	var (
+		roots  []common.Hash
+		values []map[string][]byte
+		parent common.Hash
+	)
+	for i := 0; i < 5; i++ {
+		changes := pathTestChanges(50+10*i, byte(i+1))
+		parent = pathTestState(t, db, parent, changes)
+		if err := db.Commit(parent, false, nil); err != nil {
+			t.Fatalf("failed to commit state: %v", err)
+		}
+		roots, values = append(roots, parent), append(values, changes)
+	}
+	if have := rawdb.ReadReverseDiffHead(diskdb); have != 5 {
+		t.Fatalf("reverse diff head mismatch: have %d, want 5", have)
+	}
+	for id := uint64(1); id <= 5; id++ {
+		if have, want := rawdb.HasReverseDiff(diskdb, id), id > 2; have != want {
+			t.Fatalf("reverse diff %d presence mismatch: have %v, want %v", id, have, want)
+		}
+	}
+	if db.Recoverable(roots[0]) {
+		t.Fatalf("pruned state reported recoverable")
+	}
+	if !db.Recoverable(roots[1]) {
+		t.Fatalf("retained state reported unrecoverable")
+	}
+	// Stack a state in memory, it should be dropped by the recovery
+	pathTestState(t, db, roots[4], pathTestChanges(10, 0xff))
+
+	if err := db.Recover(roots[1]); err != nil {
+		t.Fatalf("failed to recover state: %v", err)
+	}
+	if len(db.layers) != 0 {
+		t.Fatalf("diff layers not dropped: %d", len(db.layers))
+	}
+	checkPathTestState(t, db, roots[1], values[1])
+
+	if have := rawdb.ReadReverseDiffHead(diskdb); have != 2 {
+		t.Fatalf("reverse diff head mismatch: have %d, want 2", have)
+	}
+	if err := db.Recover(roots[0]); err == nil {
+		t.Fatalf("recovered beyond the retained diffs")
+	}
+}
@@ -137,6 +137,7 @@ func (batch *syncMemBatch) hasCode(hash common.Hash) bool {
 // unknown trie hashes to retrieve, accepts node data associated with said hashes
 // and reconstructs the trie step by step until all is done.
 type Sync struct {
+	scheme   string                       // Scheme the trie nodes are persisted with
 	database ethdb.KeyValueReader         // Persistent database to check for existing entries
 	membatch *syncMemBatch                // Memory buffer to avoid frequent database writes
 	nodeReqs map[string]*nodeRequest      // Pending requests pertaining to a trie node path
@@ -148,6 +149,7 @@ type Sync struct {
 // NewSync creates a new trie data download scheduler.
 func NewSync(root common.Hash, database ethdb.KeyValueReader, callback LeafCallback) *Sync {
 	ts := &Sync{
+		scheme:   rawdb.ReadStateScheme(database),
 		database: database,
 		membatch: newSyncMemBatch(),
 		nodeReqs: make(map[string]*nodeRequest),
@@ -170,7 +172,8 @@ func (s *Sync) AddSubTrie(root common.Hash, path []byte, parent common.Hash, par
 	if s.membatch.hasNode(path) {
 		return
 	}
-	if rawdb.HasTrieNode(s.database, root) {
+	owner, inner := resolvePath(path)
+	if rawdb.HasTrieNodeWithScheme(s.database, owner, inner, root, s.scheme) {
 		return
 	}
 	// Assemble the new sub-trie sync request
@@ -327,7 +330,8 @@ func (s *Sync) ProcessNode(result NodeSyncResult) error {
 func (s *Sync) Commit(dbw ethdb.Batch) error {
 	// Dump the membatch into a database dbw
 	for path, value := range s.membatch.nodes {
-		rawdb.WriteTrieNode(dbw, s.membatch.hashes[path], value)
+		owner, inner := resolvePath([]byte(path))
+		rawdb.WriteTrieNodeWithScheme(dbw, owner, inner, s.membatch.hashes[path], value, s.scheme)
 	}
 	for hash, value := range s.membatch.codes {
 		rawdb.WriteCode(dbw, hash, value)
@@ -436,7 +440,8 @@ func (s *Sync) children(req *nodeRequest, object node) ([]*nodeRequest, error) {
 			// If database says duplicate, then at least the trie node is present
 			// and we hold the assumption that it's NOT legacy contract code.
 			chash := common.BytesToHash(node)
-			if rawdb.HasTrieNode(s.database, chash) {
+			owner, inner := resolvePath(child.path)
+			if rawdb.HasTrieNodeWithScheme(s.database, owner, inner, chash, s.scheme) {
 				continue
 			}
 			// Locally unknown node, schedule for retrieval
@@ -494,3 +499,12 @@ func (s *Sync) commitCodeRequest(req *codeRequest) error {
 	}
 	return nil
 }
+
+// resolvePath splits a node path in hex format into the owner of the trie the
+// node belongs to and the path of the node within that trie.
+func resolvePath(path []byte) (common.Hash, []byte) {
+	if len(path) < 2*common.HashLength {
+		return common.Hash{}, path
+	}
+	return common.BytesToHash(hexToKeybytes(path[:2*common.HashLength])), path[2*common.HashLength:]
+}
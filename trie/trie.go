@@ -23,6 +23,7 @@ import (
 	"fmt"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
 )
@@ -103,7 +104,10 @@ func New(owner common.Hash, root common.Hash, db *Database) (*Trie, error) {
 	trie := &Trie{
 		owner: owner,
 		db:    db,
-		//tracer: newTracer(),
+	}
+	// The path scheme overwrites nodes in place, so deletions need tracking
+	if db != nil && db.scheme == rawdb.PathScheme {
+		trie.tracer = newTracer()
 	}
 	if root != (common.Hash{}) && root != emptyRoot {
 		rootnode, err := trie.resolveHash(root[:], nil)
@@ -219,7 +223,7 @@ func (t *Trie) tryGetNode(origNode node, path []byte, pos int) (item []byte, new
 		if hash == nil {
 			return nil, origNode, 0, errors.New("non-consensus node")
 		}
-		blob, err := t.db.Node(common.BytesToHash(hash))
+		blob, err := t.db.nodeBlob(t.owner, path[:pos], common.BytesToHash(hash))
 		return blob, origNode, 1, err
 	}
 	// Path still needs to be traversed, descend into children
@@ -557,7 +561,7 @@ func (t *Trie) resolve(n node, prefix []byte) (node, error) {
 // node hash and path prefix.
 func (t *Trie) resolveHash(n hashNode, prefix []byte) (node, error) {
 	hash := common.BytesToHash(n)
-	if node := t.db.node(hash); node != nil {
+	if node := t.db.node(t.owner, prefix, hash); node != nil {
 		return node, nil
 	}
 	return nil, &MissingNodeError{Owner: t.owner, NodeHash: hash, Path: prefix}
@@ -567,7 +571,7 @@ func (t *Trie) resolveHash(n hashNode, prefix []byte) (node, error) {
 // with the provided node hash and path prefix.
 func (t *Trie) resolveBlob(n hashNode, prefix []byte) ([]byte, error) {
 	hash := common.BytesToHash(n)
-	blob, _ := t.db.Node(hash)
+	blob, _ := t.db.nodeBlob(t.owner, prefix, hash)
 	if len(blob) != 0 {
 		return blob, nil
 	}
@@ -591,8 +595,16 @@ func (t *Trie) Hash() common.Hash {
 func (t *Trie) Commit(collectLeaf bool) (common.Hash, *NodeSet, error) {
 	defer t.tracer.reset()
 
+	deletes := t.tracer.deleteList()
 	if t.root == nil {
-		return emptyRoot, nil, nil
+		if len(deletes) == 0 {
+			return emptyRoot, nil, nil
+		}
+		nodes := NewNodeSet(t.owner)
+		for _, path := range deletes {
+			nodes.markDeleted(path)
+		}
+		return emptyRoot, nodes, nil
 	}
 	// Derive the hash for all dirty nodes first. We hold the assumption
 	// in the following procedure that all nodes are hashed.
@@ -611,6 +623,9 @@ func (t *Trie) Commit(collectLeaf bool) (common.Hash, *NodeSet, error) {
 	if err != nil {
 		return common.Hash{}, nil, err
 	}
+	for _, path := range deletes {
+		nodes.markDeleted(path)
+	}
 	t.root = newRoot
 	return rootHash, nodes, nil
 }
@@ -69,7 +69,7 @@ func TestTrieTracer(t *testing.T) {
 
 	// Commit the changes and re-create with new root
 	root, nodes, _ := trie.Commit(false)
-	db.Update(NewWithNodeSet(nodes))
+	db.Update(root, common.Hash{}, NewWithNodeSet(nodes))
 	trie, _ = New(common.Hash{}, root, db)
 	trie.tracer = newTracer()
 